@@ -0,0 +1,146 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/archive"
+	cr "github.com/Comcast/trickster/internal/cache/registration"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/runtime"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+const cacheCommandUsage = `Usage: trickster cache <export|import> [options]
+
+Exports the objects in a configured cache to an archive, or imports an archive into it.
+Trickster should not be serving from the cache while the command runs.
+
+Options:
+  -cache string
+        Name of the configured cache (default "default")
+  -config string
+        Path to Trickster Config File
+  -file string
+        Path to the archive file, or - for stdout when exporting and stdin when importing (default "-")
+`
+
+// runCacheCommand runs the cache subcommand with the arguments that follow it, and returns the
+// process exit code
+func runCacheCommand(arguments []string) int {
+
+	if len(arguments) == 0 || (arguments[0] != "export" && arguments[0] != "import") {
+		fmt.Fprint(os.Stderr, cacheCommandUsage)
+		return 2
+	}
+
+	action := arguments[0]
+	f := flag.NewFlagSet(applicationName+" cache "+action, flag.ContinueOnError)
+	f.SetOutput(os.Stderr)
+	f.Usage = func() { fmt.Fprint(os.Stderr, cacheCommandUsage) }
+	configPath := f.String("config", "", "Path to Trickster Config File")
+	cacheName := f.String("cache", "default", "Name of the configured cache")
+	fileName := f.String("file", "-", "Path to the archive file, or - for stdout or stdin")
+	if err := f.Parse(arguments[1:]); err != nil {
+		return 2
+	}
+
+	args := []string{}
+	if *configPath != "" {
+		args = append(args, "-config", *configPath)
+	}
+	if err := config.Load(runtime.ApplicationName, runtime.ApplicationVersion, args); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load configuration:", err.Error())
+		return 1
+	}
+
+	// an archive written to stdout must not be interleaved with log events
+	if *fileName == "-" && config.Logging.LogFile == "" {
+		log.Logger = log.ConsoleLogger("none")
+	} else {
+		log.Init()
+	}
+	defer log.Logger.Close()
+
+	// the command does not serve metrics, so it must not contend for the metrics port
+	config.Metrics.ListenPort = 0
+	config.Metrics.ListenAddress = ""
+	metrics.Init()
+
+	c, err := cr.LoadCacheFromConfig(*cacheName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load cache:", err.Error())
+		return 1
+	}
+	defer closeCaches(c)
+
+	var n int
+	if action == "export" {
+		n, err = exportCache(c, *fileName)
+	} else {
+		n, err = importCache(c, *fileName)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not %s cache [%s]: %s\n", action, *cacheName, err.Error())
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%sed %d objects for cache [%s]\n", action, n, *cacheName)
+	return 0
+}
+
+func exportCache(c cache.Cache, fileName string) (int, error) {
+	if fileName == "-" {
+		return archive.Export(c, os.Stdout)
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return 0, err
+	}
+	n, err := archive.Export(c, file)
+	if err2 := file.Close(); err == nil {
+		err = err2
+	}
+	return n, err
+}
+
+func importCache(c cache.Cache, fileName string) (int, error) {
+	var r io.Reader = os.Stdin
+	if fileName != "-" {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		r = file
+	}
+	return archive.Import(c, r)
+}
+
+// closeCaches closes the cache, and then the caches loaded to compose it, so that a tiered
+// cache drains its write-behind queue before its tiers, and each cache flushes its index
+func closeCaches(c cache.Cache) {
+	c.Close()
+	for _, lc := range cr.Caches {
+		if lc != c {
+			lc.Close()
+		}
+	}
+}
@@ -24,14 +24,15 @@ import (
 	cr "github.com/Comcast/trickster/internal/cache/registration"
 	"github.com/Comcast/trickster/internal/config"
 	"github.com/Comcast/trickster/internal/proxy"
+	"github.com/Comcast/trickster/internal/proxy/encoding"
 	th "github.com/Comcast/trickster/internal/proxy/handlers"
+	"github.com/Comcast/trickster/internal/proxy/warmer"
 	"github.com/Comcast/trickster/internal/routing"
 	rr "github.com/Comcast/trickster/internal/routing/registration"
 	"github.com/Comcast/trickster/internal/runtime"
 	"github.com/Comcast/trickster/internal/util/log"
 	"github.com/Comcast/trickster/internal/util/metrics"
-
-	"github.com/gorilla/handlers"
+	"github.com/Comcast/trickster/internal/util/socket"
 )
 
 var (
@@ -53,6 +54,10 @@ func main() {
 	runtime.ApplicationName = applicationName
 	runtime.ApplicationVersion = applicationVersion
 
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		os.Exit(runCacheCommand(os.Args[2:]))
+	}
+
 	err = config.Load(runtime.ApplicationName, runtime.ApplicationVersion, os.Args[1:])
 	if err != nil {
 		printVersion()
@@ -87,32 +92,44 @@ func main() {
 	cr.LoadCachesFromConfig()
 	th.RegisterPingHandler()
 	th.RegisterConfigHandler()
+	th.RegisterCacheBrowserHandler(cr.Caches, rr.ProxyClients)
+	th.RegisterCacheArchiveHandler(cr.Caches)
 	err = rr.RegisterProxyRoutes()
 	if err != nil {
 		log.Fatal(1, "route registration failed", log.Pairs{"detail": err.Error()})
 	}
+	warmer.StartWarmers(config.Origins, routing.Router)
+
+	tlsEnabled := socket.Enabled(config.Frontend.TLSListenAddress, config.Frontend.TLSListenPort)
+	httpEnabled := socket.Enabled(config.Frontend.ListenAddress, config.Frontend.ListenPort)
 
-	if config.Frontend.TLSListenPort < 1 && config.Frontend.ListenPort < 1 {
+	if !tlsEnabled && !httpEnabled {
 		log.Fatal(1, "no http or https listeners configured", log.Pairs{})
 	}
 
 	wg := sync.WaitGroup{}
 	var l net.Listener
 
-	// if TLS port is configured and at least one origin is mapped to a good tls config,
+	// if TLS port or socket is configured and at least one origin is mapped to a good tls config,
 	// then set up the tls server listener instance
-	if config.Frontend.ServeTLS && config.Frontend.TLSListenPort > 0 {
+	if config.Frontend.ServeTLS && tlsEnabled {
 		wg.Add(1)
 		go func() {
 			tlsConfig, err := config.Config.TLSCertConfig()
 			if err == nil {
-				l, err = proxy.NewListener(
-					config.Frontend.TLSListenAddress,
-					config.Frontend.TLSListenPort,
-					config.Frontend.ConnectionsLimit,
-					tlsConfig)
+				var srv *http.Server
+				// the server is configured first, as enabling HTTP/2 updates the listener's tls config
+				srv, err = proxy.NewHTTPServer(config.Frontend, encoding.Handler(config.Compression, routing.TLSRouter), tlsConfig)
 				if err == nil {
-					err = http.Serve(l, handlers.CompressHandler(routing.TLSRouter))
+					l, err = proxy.NewListener(
+						config.Frontend.TLSListenAddress,
+						config.Frontend.TLSListenPort,
+						config.Frontend.ConnectionsLimit,
+						tlsConfig,
+						config.Frontend.UnixSocketFileMode)
+				}
+				if err == nil {
+					err = srv.Serve(l)
 				}
 			}
 			log.Error("exiting", log.Pairs{"err": err})
@@ -120,15 +137,19 @@ func main() {
 		}()
 	}
 
-	// if the plaintext HTTP port is configured, then set up the http listener instance
-	if config.Frontend.ListenPort > 0 {
+	// if the plaintext HTTP port or socket is configured, then set up the http listener instance
+	if httpEnabled {
 		wg.Add(1)
 		go func() {
 			l, err := proxy.NewListener(config.Frontend.ListenAddress, config.Frontend.ListenPort,
-				config.Frontend.ConnectionsLimit, nil)
+				config.Frontend.ConnectionsLimit, nil, config.Frontend.UnixSocketFileMode)
 
 			if err == nil {
-				err = http.Serve(l, handlers.CompressHandler(routing.Router))
+				var srv *http.Server
+				srv, err = proxy.NewHTTPServer(config.Frontend, encoding.Handler(config.Compression, routing.Router), nil)
+				if err == nil {
+					err = srv.Serve(l)
+				}
 			}
 			log.Error("exiting", log.Pairs{"err": err})
 			wg.Done()
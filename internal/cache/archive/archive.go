@@ -0,0 +1,219 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package archive exports the objects in a cache to a portable archive, and imports them
+// into a cache of any type, so that a new node's cache may be pre-seeded with warm data.
+//
+// An archive is a gzip-compressed stream that begins with the magic "TRKCACHE" and a version
+// byte, followed by a record for each object, which consists of its key, the Unix time in
+// nanoseconds at which it expires, and its value. The key and value are each preceded by their
+// length as a uvarint, and the expiration is a varint.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/index"
+	"github.com/Comcast/trickster/internal/cache/status"
+)
+
+const (
+	magic   = "TRKCACHE"
+	version = byte(1)
+
+	// maxKeyLength and maxValueLength bound the allocations made while reading a record
+	maxKeyLength   = 1 << 16
+	maxValueLength = 1 << 30
+)
+
+// ErrInvalidArchive represents the error "invalid cache archive"
+var ErrInvalidArchive = errors.New("invalid cache archive")
+
+// Exporter writes an archive of the objects that were in a cache when the Exporter was created
+type Exporter struct {
+	cache   cache.Cache
+	objects []cache.ObjectMetadata
+}
+
+// NewExporter returns an Exporter for the objects in the cache, which must implement cache.ObjectLister
+func NewExporter(c cache.Cache) (*Exporter, error) {
+	l, ok := c.(cache.ObjectLister)
+	if !ok {
+		return nil, cache.ErrListUnsupported
+	}
+	objects, err := l.ListObjects()
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{cache: c, objects: objects}, nil
+}
+
+// Export writes the archive to w, and returns the number of objects written. Objects that have
+// expired or been removed since the Exporter was created are skipped, as are objects that have
+// no expiration, or whose value is not stored as bytes, such as the timeseries a memory cache
+// stores by reference.
+func (e *Exporter) Export(w io.Writer) (int, error) {
+
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+
+	if _, err := bw.WriteString(magic); err != nil {
+		return 0, err
+	}
+	if err := bw.WriteByte(version); err != nil {
+		return 0, err
+	}
+
+	var n int
+	buf := make([]byte, binary.MaxVarintLen64)
+	now := time.Now()
+
+	for _, o := range e.objects {
+
+		if o.Key == index.IndexKey || o.Expiration.IsZero() || !o.Expiration.After(now) {
+			continue
+		}
+
+		data, lookupStatus, err := e.read(o.Key)
+		if err != nil || lookupStatus != status.LookupStatusHit || data == nil {
+			continue
+		}
+
+		bw.Write(buf[:binary.PutUvarint(buf, uint64(len(o.Key)))])
+		bw.WriteString(o.Key)
+		bw.Write(buf[:binary.PutVarint(buf, o.Expiration.UnixNano())])
+		bw.Write(buf[:binary.PutUvarint(buf, uint64(len(data)))])
+		if _, err := bw.Write(data); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if err := bw.Flush(); err != nil {
+		return n, err
+	}
+	return n, zw.Close()
+}
+
+// read returns the data of the object in the cache, peeking at it if the cache is able to, so that
+// exporting the cache does not count as an access to every object in it
+func (e *Exporter) read(cacheKey string) ([]byte, status.LookupStatus, error) {
+	if p, ok := e.cache.(cache.ObjectPeeker); ok {
+		return p.Peek(cacheKey)
+	}
+	return e.cache.Retrieve(cacheKey, false)
+}
+
+// Export writes an archive of the objects in the cache to w, and returns the number of objects written
+func Export(c cache.Cache, w io.Writer) (int, error) {
+	e, err := NewExporter(c)
+	if err != nil {
+		return 0, err
+	}
+	return e.Export(w)
+}
+
+// Import stores each object in the archive read from r into the cache, with the TTL remaining until
+// its expiration, and returns the number of objects stored. Objects that have expired are skipped.
+func Import(c cache.Cache, r io.Reader) (int, error) {
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, invalidArchive(err)
+	}
+	br := bufio.NewReader(zr)
+
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return 0, invalidArchive(err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return 0, ErrInvalidArchive
+	}
+	if header[len(magic)] != version {
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, header[len(magic)])
+	}
+
+	var n int
+	for {
+		key, expiration, data, err := readRecord(br)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, invalidArchive(err)
+		}
+		ttl := time.Until(expiration)
+		if ttl <= 0 {
+			continue
+		}
+		if err := c.Store(key, data, ttl); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// readRecord reads the next record from the archive, returning io.EOF if there are none
+func readRecord(br *bufio.Reader) (string, time.Time, []byte, error) {
+
+	l, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	if l == 0 || l > maxKeyLength {
+		return "", time.Time{}, nil, fmt.Errorf("invalid key length %d", l)
+	}
+	key := make([]byte, l)
+	if _, err := io.ReadFull(br, key); err != nil {
+		return "", time.Time{}, nil, unexpectedEOF(err)
+	}
+
+	exp, err := binary.ReadVarint(br)
+	if err != nil {
+		return "", time.Time{}, nil, unexpectedEOF(err)
+	}
+
+	l, err = binary.ReadUvarint(br)
+	if err != nil {
+		return "", time.Time{}, nil, unexpectedEOF(err)
+	}
+	if l > maxValueLength {
+		return "", time.Time{}, nil, fmt.Errorf("invalid value length %d", l)
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return "", time.Time{}, nil, unexpectedEOF(err)
+	}
+
+	return string(key), time.Unix(0, exp), data, nil
+}
+
+// unexpectedEOF converts an io.EOF occurring within a record to io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func invalidArchive(err error) error {
+	return fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+}
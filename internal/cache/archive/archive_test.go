@@ -0,0 +1,210 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/filesystem"
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+type testReferenceObject struct{}
+
+func (r *testReferenceObject) Size() int {
+	return 1
+}
+
+// unlistableCache exposes only the methods of the cache.Cache interface
+type unlistableCache struct {
+	cache.Cache
+}
+
+func newMemoryCache(t *testing.T) *memory.Cache {
+	mc := &memory.Cache{Name: "test", Config: &config.CachingConfig{CacheType: "memory"}}
+	if err := mc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return mc
+}
+
+func newFilesystemCache(t *testing.T) *filesystem.Cache {
+	dir, err := ioutil.TempDir("/tmp", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc := &filesystem.Cache{Name: "test", Config: &config.CachingConfig{CacheType: "filesystem",
+		Filesystem: config.FilesystemCacheConfig{CachePath: dir}}}
+	if err := fc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return fc
+}
+
+// testLastAccess returns the time the object was last accessed in the memory cache
+func testLastAccess(t *testing.T, mc *memory.Cache, key string) time.Time {
+	objects, _ := mc.ListObjects()
+	for _, o := range objects {
+		if o.Key == key {
+			return o.LastAccess
+		}
+	}
+	t.Fatalf("object %s not found", key)
+	return time.Time{}
+}
+
+func TestExportImport(t *testing.T) {
+
+	mc := newMemoryCache(t)
+	mc.Store("test.1", []byte("value1"), time.Hour)
+	mc.Store("test.2", []byte("value2"), time.Minute)
+	mc.StoreReference("test.3", &testReferenceObject{}, time.Hour)
+	mc.Store("test.4", []byte("value4"), time.Hour)
+	mc.SetTTL("test.4", -time.Second)
+	lastAccess := testLastAccess(t, mc, "test.1")
+
+	buf := &bytes.Buffer{}
+	n, err := Export(mc, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reference object and the expired object should be skipped
+	if n != 2 {
+		t.Errorf("expected %d got %d", 2, n)
+	}
+
+	// exporting an object does not count as an access to it
+	if la := testLastAccess(t, mc, "test.1"); !la.Equal(lastAccess) {
+		t.Errorf("expected last access %s got %s", lastAccess, la)
+	}
+
+	fc := newFilesystemCache(t)
+	defer os.RemoveAll(fc.Config.Filesystem.CachePath)
+
+	n, err = Import(fc, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected %d got %d", 2, n)
+	}
+
+	for _, key := range []string{"test.1", "test.2"} {
+		b, ls, err := fc.Retrieve(key, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ls != status.LookupStatusHit {
+			t.Errorf("expected %s got %s", status.LookupStatusHit, ls)
+		}
+		if string(b) != "value"+key[5:] {
+			t.Errorf("expected %s got %s", "value"+key[5:], string(b))
+		}
+	}
+
+	// the imported object should keep the TTL remaining at export
+	ttl := time.Until(fc.Index.GetExpiration("test.2"))
+	if ttl > time.Minute || ttl < 50*time.Second {
+		t.Errorf("expected ttl near %s got %s", time.Minute, ttl)
+	}
+
+	if _, ls, _ := fc.Retrieve("test.4", false); ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+}
+
+func TestExportUnsupported(t *testing.T) {
+	_, err := Export(unlistableCache{newMemoryCache(t)}, &bytes.Buffer{})
+	if err != cache.ErrListUnsupported {
+		t.Errorf("expected error %v got %v", cache.ErrListUnsupported, err)
+	}
+}
+
+func TestImportExpired(t *testing.T) {
+
+	mc := newMemoryCache(t)
+	mc.Store("test.1", []byte("value1"), time.Hour)
+
+	e, err := NewExporter(mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.objects[0].Expiration = time.Now().Add(50 * time.Millisecond)
+
+	buf := &bytes.Buffer{}
+	if n, err := e.Export(buf); err != nil || n != 1 {
+		t.Fatalf("expected %d got %d %v", 1, n, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	mc2 := newMemoryCache(t)
+	n, err := Import(mc2, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("expected %d got %d", 0, n)
+	}
+}
+
+func gzipped(b []byte) []byte {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	zw.Write(b)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestImportInvalid(t *testing.T) {
+
+	mc := newMemoryCache(t)
+	mc.Store("test.1", []byte("value1"), time.Hour)
+	buf := &bytes.Buffer{}
+	if _, err := Export(mc, buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, _ := gzip.NewReader(buf)
+	valid, _ := ioutil.ReadAll(zr)
+
+	tests := [][]byte{
+		[]byte("not an archive"),
+		gzipped([]byte("TRK")),
+		gzipped([]byte("TRKCACHX\x01")),
+		gzipped([]byte("TRKCACHE\x02")),
+		gzipped(valid[:len(valid)-2]),
+		gzipped([]byte("TRKCACHE\x01\x00")),
+	}
+
+	for i, test := range tests {
+		_, err := Import(newMemoryCache(t), bytes.NewReader(test))
+		if !errors.Is(err, ErrInvalidArchive) {
+			t.Errorf("%d: expected error %v got %v", i, ErrInvalidArchive, err)
+		}
+	}
+}
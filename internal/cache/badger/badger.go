@@ -115,6 +115,37 @@ func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
 	})
 }
 
+// Expiration returns the expiration of an object in cache, or the zero time if it has none or is not found
+func (c *Cache) Expiration(cacheKey string) time.Time {
+	if e, err := c.getExpires(cacheKey); err == nil && e > 0 {
+		return time.Unix(int64(e), 0)
+	}
+	return time.Time{}
+}
+
+// ListObjects iterates the keys in the Badger key-value store and returns their sizes and
+// expirations. Badger does not track when keys were written or accessed, so those times are
+// not reported.
+func (c *Cache) ListObjects() ([]cache.ObjectMetadata, error) {
+	objects := make([]cache.ObjectMetadata, 0)
+	err := c.dbh.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			o := cache.ObjectMetadata{Key: string(item.Key()), Size: item.ValueSize()}
+			if e := item.ExpiresAt(); e > 0 {
+				o.Expiration = time.Unix(int64(e), 0)
+			}
+			objects = append(objects, o)
+		}
+		return nil
+	})
+	return objects, err
+}
+
 // Close closes the Badger Cache
 func (c *Cache) Close() error {
 	return c.dbh.Close()
@@ -93,6 +93,35 @@ func TestBadgerCache_Store(t *testing.T) {
 	}
 }
 
+func TestBadgerCache_ListObjects(t *testing.T) {
+	cacheConfig := newCacheConfig(t)
+	defer os.RemoveAll(cacheConfig.Badger.Directory)
+	bc := Cache{Config: &cacheConfig}
+
+	if err := bc.Connect(); err != nil {
+		t.Error(err)
+	}
+	defer bc.Close()
+
+	err := bc.Store(cacheKey, []byte("data"), time.Duration(60)*time.Second)
+	if err != nil {
+		t.Error(err)
+	}
+
+	objects, err := bc.ListObjects()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(objects) != 1 {
+		t.Fatalf("expected %d got %d", 1, len(objects))
+	}
+
+	if objects[0].Key != cacheKey || objects[0].Size != 4 || objects[0].Expiration.IsZero() {
+		t.Errorf("unexpected object metadata %v", objects[0])
+	}
+}
+
 func TestBadgerCache_Remove(t *testing.T) {
 	cacheConfig := newCacheConfig(t)
 	defer os.RemoveAll(cacheConfig.Badger.Directory)
@@ -20,6 +20,7 @@ import (
 	"github.com/coreos/bbolt"
 
 	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/encryption"
 	"github.com/Comcast/trickster/internal/cache/index"
 	"github.com/Comcast/trickster/internal/cache/status"
 	"github.com/Comcast/trickster/internal/config"
@@ -67,7 +68,8 @@ func (c *Cache) Connect() error {
 
 	// Load Index here and pass bytes as param2
 	indexData, _, _ := c.retrieve(index.IndexKey, false, false)
-	c.Index = index.NewIndex(c.Name, c.Config.CacheType, indexData, c.Config.Index, c.BulkRemove, c.storeNoIndex)
+	indexData, flushIndex := encryption.WrapIndex(c.Config, indexData, c.storeNoIndex)
+	c.Index = index.NewIndex(c.Name, c.Config.CacheType, indexData, c.Config.Index, c.BulkRemove, flushIndex)
 	return nil
 }
 
@@ -117,6 +119,16 @@ func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.Loo
 	return c.retrieve(cacheKey, allowExpired, true)
 }
 
+// Expiration returns the expiration of an object in cache, or the zero time if it is not found
+func (c *Cache) Expiration(cacheKey string) time.Time {
+	return c.Index.GetExpiration(cacheKey)
+}
+
+// Peek returns an object in cache, expired or not, without updating its access time
+func (c *Cache) Peek(cacheKey string) ([]byte, status.LookupStatus, error) {
+	return c.retrieve(cacheKey, true, false)
+}
+
 func (c *Cache) retrieve(cacheKey string, allowExpired bool, atime bool) ([]byte, status.LookupStatus, error) {
 
 	locks.Acquire(lockPrefix + cacheKey)
@@ -159,13 +171,13 @@ func (c *Cache) retrieve(cacheKey string, allowExpired bool, atime bool) ([]byte
 		log.Debug("bbolt cache retrieve", log.Pairs{"cacheKey": cacheKey})
 		if atime {
 			c.Index.UpdateObjectAccessTime(cacheKey)
+			cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(data)))
 		}
-		cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(data)))
 		locks.Release(lockPrefix + cacheKey)
 		return o.Value, status.LookupStatusHit, nil
 	}
 	// Cache Object has been expired but not reaped, go ahead and delete it
-	c.remove(cacheKey, false)
+	c.remove(cacheKey)
 	b, err := cache.ObserveCacheMiss(cacheKey, c.Name, c.Config.CacheType)
 	locks.Release(lockPrefix + cacheKey)
 
@@ -182,11 +194,11 @@ func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
 // Remove removes an object in cache, if present
 func (c *Cache) Remove(cacheKey string) {
 	locks.Acquire(lockPrefix + cacheKey)
-	c.remove(cacheKey, false)
+	c.remove(cacheKey)
 	locks.Release(lockPrefix + cacheKey)
 }
 
-func (c *Cache) remove(cacheKey string, noLock bool) error {
+func (c *Cache) remove(cacheKey string) error {
 
 	err := c.dbh.Update(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(c.Config.BBolt.Bucket))
@@ -196,20 +208,28 @@ func (c *Cache) remove(cacheKey string, noLock bool) error {
 		log.Error("bbolt cache key delete failure", log.Pairs{"cacheKey": cacheKey, "reason": err.Error()})
 		return err
 	}
-	c.Index.RemoveObject(cacheKey, noLock)
+	c.Index.RemoveObject(cacheKey)
 	cache.ObserveCacheDel(c.Name, c.Config.CacheType, 0)
 	log.Debug("bbolt cache key delete", log.Pairs{"key": cacheKey})
 	return nil
 }
 
-// BulkRemove removes a list of objects from the cache
+// BulkRemove removes a list of objects from the cache. noLock is not used for BBolt
 func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
 	for _, cacheKey := range cacheKeys {
-		c.remove(cacheKey, noLock)
+		c.remove(cacheKey)
 	}
 }
 
-// Close closes the Cache
+// ListObjects returns the metadata of the objects in the cache, as recorded in the Index
+func (c *Cache) ListObjects() ([]cache.ObjectMetadata, error) {
+	return c.Index.ListObjects(), nil
+}
+
+// Close flushes the Cache's Index and closes the Cache
 func (c *Cache) Close() error {
+	if c.Index != nil {
+		c.Index.Flush()
+	}
 	return c.dbh.Close()
 }
@@ -26,6 +26,9 @@ import (
 // ErrKNF represents the error "key not found in cache"
 var ErrKNF = errors.New("key not found in cache")
 
+// ErrListUnsupported represents the error "cache does not support listing its objects"
+var ErrListUnsupported = errors.New("cache does not support listing its objects")
+
 // Cache is the interface for the supported caching fabrics
 // When making new cache types, Retrieve() must return an error on cache miss
 type Cache interface {
@@ -54,6 +57,51 @@ type MemoryCache interface {
 	RetrieveReference(cacheKey string, allowExpired bool) (interface{}, status.LookupStatus, error)
 }
 
+// TieredCache is the interface for a MemoryCache fronting a second, shared cache. StoreReference
+// and RetrieveReference operate on the memory tier, except that when RetrieveReference misses the
+// memory tier but finds the object in the shared tier, it returns the object's serialized []byte
+// so the caller can decode it and promote the result into the memory tier with PromoteReference.
+type TieredCache interface {
+	MemoryCache
+	StoreTiered(cacheKey string, ref ReferenceObject, data []byte, ttl time.Duration) error
+	PromoteReference(cacheKey string, ref ReferenceObject) error
+}
+
+// ObjectLister is the interface for a cache that is able to enumerate the objects it holds,
+// for introspection. Caches that manage their own retention, and so have no Index, may be
+// unable to report every field of the ObjectMetadata, and leave those fields zero-valued.
+type ObjectLister interface {
+	ListObjects() ([]ObjectMetadata, error)
+}
+
+// ObjectPeeker is the interface for a cache that is able to read an object for introspection
+// without the side effects of a lookup: the object's access time and eviction policy state are
+// left untouched, no hit is recorded, and an expired object is returned rather than removed
+type ObjectPeeker interface {
+	Peek(cacheKey string) ([]byte, status.LookupStatus, error)
+}
+
+// ReferencePeeker is the interface for a MemoryCache that is able to peek at the objects it
+// holds by reference, as an ObjectPeeker does at serialized objects
+type ReferencePeeker interface {
+	PeekReference(cacheKey string) (interface{}, status.LookupStatus, error)
+}
+
+// ExpirationReporter is the interface for a cache that is able to report when an object it holds
+// expires. Expiration returns the zero time if the object has no expiration or is not found.
+type ExpirationReporter interface {
+	Expiration(cacheKey string) time.Time
+}
+
+// ObjectMetadata describes an object held in a cache
+type ObjectMetadata struct {
+	Key        string
+	Size       int64
+	Expiration time.Time
+	LastWrite  time.Time
+	LastAccess time.Time
+}
+
 // ReferenceObject defines an interface for a cache object possessing the ability to report
 // the approximate comprehensive byte size of its members, to assist with cache size management
 type ReferenceObject interface {
@@ -90,8 +138,24 @@ func ObserveCacheEvent(cache, cacheType, event, reason string) {
 	metrics.CacheEvents.WithLabelValues(cache, cacheType, event, reason).Inc()
 }
 
+// ObserveCacheEvictions increments counters as objects are evicted from the cache
+func ObserveCacheEvictions(cache, cacheType, policy, reason string, count int) {
+	metrics.CacheEvictions.WithLabelValues(cache, cacheType, policy, reason).Add(float64(count))
+}
+
+// ObserveCacheTierLookup increments counters as lookups are performed against the tiers of a tiered cache
+func ObserveCacheTierLookup(cache, tier, status string) {
+	metrics.CacheTierLookups.WithLabelValues(cache, tier, status).Inc()
+}
+
 // ObserveCacheSizeChange adjust counters and gauges as the cache size changes due to object operations
 func ObserveCacheSizeChange(cache, cacheType string, byteCount, objectCount int64) {
 	metrics.CacheObjects.WithLabelValues(cache, cacheType).Set(float64(objectCount))
 	metrics.CacheBytes.WithLabelValues(cache, cacheType).Set(float64(byteCount))
 }
+
+// ObserveOriginCacheSizeChange adjusts gauges as the size of an origin's share of the cache changes
+func ObserveOriginCacheSizeChange(cache, cacheType, originName string, byteCount, objectCount int64) {
+	metrics.CacheOriginObjects.WithLabelValues(cache, cacheType, originName).Set(float64(objectCount))
+	metrics.CacheOriginBytes.WithLabelValues(cache, cacheType, originName).Set(float64(byteCount))
+}
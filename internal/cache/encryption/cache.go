@@ -0,0 +1,145 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package encryption
+
+import (
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/index"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// Cache wraps another Cache, encrypting objects as they are stored and decrypting
+// them as they are retrieved. Objects that cannot be decrypted are treated as misses.
+type Cache struct {
+	cache.Cache
+	Name   string
+	Config *config.CachingConfig
+
+	encryptor *Encryptor
+}
+
+// NewCache returns a Cache that encrypts the objects stored in the provided Cache
+func NewCache(cacheName string, cfg *config.CachingConfig, c cache.Cache) *Cache {
+	return &Cache{Cache: c, Name: cacheName, Config: cfg}
+}
+
+// Connect loads the encryption keys and connects the wrapped Cache
+func (c *Cache) Connect() error {
+	e, err := NewEncryptor(c.Config.Encryption)
+	if err != nil {
+		log.Error("cache encryption setup failed", log.Pairs{"cacheName": c.Name, "detail": err.Error()})
+		return err
+	}
+	c.encryptor = e
+	log.Info("cache encryption enabled", log.Pairs{"cacheName": c.Name, "keyID": c.Config.Encryption.KeyID})
+	return c.Cache.Connect()
+}
+
+// Store encrypts the data and places it in the wrapped Cache
+func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	b, err := c.encryptor.Seal(cacheKey, data)
+	if err != nil {
+		cache.ObserveCacheEvent(c.Name, c.Config.CacheType, "error", "failed to encrypt object")
+		return err
+	}
+	return c.Cache.Store(cacheKey, b, ttl)
+}
+
+// Retrieve gets data from the wrapped Cache and decrypts it
+func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	data, ls, err := c.Cache.Retrieve(cacheKey, allowExpired)
+	if err != nil || ls != status.LookupStatusHit {
+		return data, ls, err
+	}
+	b, err := c.encryptor.Open(cacheKey, data)
+	if err != nil {
+		log.Warn("cache object could not be decrypted", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey, "detail": err.Error()})
+		cache.ObserveCacheEvent(c.Name, c.Config.CacheType, "error", "failed to decrypt object")
+		return nil, status.LookupStatusKeyMiss, cache.ErrKNF
+	}
+	return b, ls, nil
+}
+
+// Peek gets data from the wrapped Cache without the side effects of a lookup, if it supports
+// peeking, and decrypts it
+func (c *Cache) Peek(cacheKey string) ([]byte, status.LookupStatus, error) {
+	p, ok := c.Cache.(cache.ObjectPeeker)
+	if !ok {
+		return c.Retrieve(cacheKey, true)
+	}
+	data, ls, err := p.Peek(cacheKey)
+	if err != nil || ls != status.LookupStatusHit {
+		return data, ls, err
+	}
+	b, err := c.encryptor.Open(cacheKey, data)
+	if err != nil {
+		return nil, status.LookupStatusKeyMiss, cache.ErrKNF
+	}
+	return b, ls, nil
+}
+
+// Expiration returns the expiration of an object in the wrapped Cache, if it is able to report it
+func (c *Cache) Expiration(cacheKey string) time.Time {
+	if er, ok := c.Cache.(cache.ExpirationReporter); ok {
+		return er.Expiration(cacheKey)
+	}
+	return time.Time{}
+}
+
+// ListObjects returns the metadata of the objects in the wrapped Cache, if it supports listing
+func (c *Cache) ListObjects() ([]cache.ObjectMetadata, error) {
+	if l, ok := c.Cache.(cache.ObjectLister); ok {
+		return l.ListObjects()
+	}
+	return nil, cache.ErrListUnsupported
+}
+
+// WrapIndex decrypts the persisted index data of a cache configured for encryption, and
+// returns it along with a flush function that encrypts the index before passing it to flush.
+// Plaintext index data is returned as-is, so that encryption can be enabled on an existing
+// cache; it is encrypted the next time the index is flushed. For caches not configured for
+// encryption, the data and flush function are returned unchanged.
+func WrapIndex(cfg *config.CachingConfig, data []byte,
+	flush func(string, []byte)) ([]byte, func(string, []byte)) {
+
+	if cfg == nil || cfg.Encryption.KeyID == "" {
+		return data, flush
+	}
+
+	e, err := NewEncryptor(cfg.Encryption)
+	if err != nil {
+		log.Error("cache index encryption setup failed", log.Pairs{"cacheName": cfg.Name, "detail": err.Error()})
+		// the index is not persisted, rather than persisting it unencrypted
+		return nil, func(string, []byte) {}
+	}
+
+	if IsEncrypted(data) {
+		if data, err = e.Open(index.IndexKey, data); err != nil {
+			log.Warn("cache index could not be decrypted", log.Pairs{"cacheName": cfg.Name, "detail": err.Error()})
+			data = nil
+		}
+	}
+
+	return data, func(cacheKey string, b []byte) {
+		eb, err := e.Seal(cacheKey, b)
+		if err != nil {
+			log.Error("cache index could not be encrypted", log.Pairs{"cacheName": cfg.Name, "detail": err.Error()})
+			return
+		}
+		flush(cacheKey, eb)
+	}
+}
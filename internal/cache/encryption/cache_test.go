@@ -0,0 +1,244 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package encryption
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/index"
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+const cacheKey = "cacheKey"
+
+func newTestCache(t *testing.T, keyID string) (*Cache, *memory.Cache) {
+	cfg := &config.CachingConfig{Name: "test", CacheType: "memory", Encryption: newTestEncryptionConfig(keyID)}
+	mc := &memory.Cache{Name: "test", Config: cfg}
+	c := NewCache("test", cfg, mc)
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return c, mc
+}
+
+func TestCacheConnect(t *testing.T) {
+	cfg := &config.CachingConfig{Name: "test", CacheType: "memory", Encryption: newTestEncryptionConfig("k3")}
+	c := NewCache("test", cfg, &memory.Cache{Name: "test", Config: cfg})
+	if err := c.Connect(); err == nil {
+		t.Errorf("expected error for unloaded key")
+	}
+}
+
+func TestCacheStoreRetrieve(t *testing.T) {
+
+	c, mc := newTestCache(t, "k1")
+
+	err := c.Store(cacheKey, []byte("data"), 60*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the wrapped cache should hold the encrypted object
+	b, _, err := mc.Retrieve(cacheKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(b) {
+		t.Errorf("expected encrypted object in wrapped cache")
+	}
+
+	b, ls, err := c.Retrieve(cacheKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls != status.LookupStatusHit {
+		t.Errorf("expected %s got %s", status.LookupStatusHit, ls)
+	}
+	if string(b) != "data" {
+		t.Errorf("expected %s got %s", "data", string(b))
+	}
+
+	// a miss in the wrapped cache should be passed through
+	_, ls, err = c.Retrieve("missingKey", false)
+	if err == nil {
+		t.Errorf("expected error for missing key")
+	}
+	if ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+}
+
+func TestCachePeek(t *testing.T) {
+
+	c, _ := newTestCache(t, "k1")
+
+	err := c.Store(cacheKey, []byte("data"), 60*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, ls, err := c.Peek(cacheKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls != status.LookupStatusHit || string(b) != "data" {
+		t.Errorf("expected %s got %s", "data", string(b))
+	}
+
+	_, ls, err = c.Peek("missingKey")
+	if err == nil || ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+}
+
+func TestCacheRetrieveUndecryptable(t *testing.T) {
+
+	c, mc := newTestCache(t, "k1")
+
+	// a plaintext object, such as one stored before encryption was enabled, is a miss
+	mc.Store(cacheKey, []byte("data"), 60*time.Second)
+	_, ls, err := c.Retrieve(cacheKey, false)
+	if err == nil {
+		t.Errorf("expected error for plaintext object")
+	}
+	if ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+
+	// an object stored under another key is a miss
+	c.Store(cacheKey, []byte("data"), 60*time.Second)
+	b, _, _ := mc.Retrieve(cacheKey, false)
+	mc.Store("otherKey", b, 60*time.Second)
+	_, ls, err = c.Retrieve("otherKey", false)
+	if err == nil {
+		t.Errorf("expected error for relocated object")
+	}
+	if ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+}
+
+func TestCacheKeyRotation(t *testing.T) {
+
+	c, mc := newTestCache(t, "k1")
+	c.Store(cacheKey, []byte("data"), 60*time.Second)
+
+	// rotate the active key, retaining the previous one. the wrapped cache is
+	// not reconnected, since that would reset the memory cache
+	c.Config.Encryption.KeyID = "k2"
+	c2 := NewCache("test", c.Config, mc)
+	c2.encryptor = newTestEncryptor(t, "k2")
+
+	b, _, err := c2.Retrieve(cacheKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "data" {
+		t.Errorf("expected %s got %s", "data", string(b))
+	}
+
+	c2.Store(cacheKey, []byte("data2"), 60*time.Second)
+	b, _, _ = mc.Retrieve(cacheKey, false)
+	if string(b[minHeaderSize:minHeaderSize+2]) != "k2" {
+		t.Errorf("expected key id %s got %s", "k2", string(b[minHeaderSize:minHeaderSize+2]))
+	}
+}
+
+func TestCacheListObjects(t *testing.T) {
+
+	c, _ := newTestCache(t, "k1")
+	c.Store(cacheKey, []byte("data"), 60*time.Second)
+
+	objects, err := c.ListObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Key != cacheKey {
+		t.Errorf("expected %s in %v", cacheKey, objects)
+	}
+
+	c.Cache = &unlistableCache{c.Cache}
+	if _, err = c.ListObjects(); err != cache.ErrListUnsupported {
+		t.Errorf("expected %v got %v", cache.ErrListUnsupported, err)
+	}
+}
+
+// unlistableCache hides the ListObjects method of the cache it wraps
+type unlistableCache struct {
+	cache.Cache
+}
+
+func TestWrapIndex(t *testing.T) {
+
+	var flushed []byte
+	flush := func(cacheKey string, data []byte) {
+		flushed = data
+	}
+
+	// without encryption, the data and flush function are unchanged
+	cfg := &config.CachingConfig{Name: "test"}
+	data, fn := WrapIndex(cfg, []byte("index"), flush)
+	if string(data) != "index" {
+		t.Errorf("expected %s got %s", "index", string(data))
+	}
+	fn(index.IndexKey, []byte("index"))
+	if string(flushed) != "index" {
+		t.Errorf("expected %s got %s", "index", string(flushed))
+	}
+
+	// a plaintext index is accepted, and encrypted when flushed
+	cfg.Encryption = newTestEncryptionConfig("k1")
+	data, fn = WrapIndex(cfg, []byte("index"), flush)
+	if string(data) != "index" {
+		t.Errorf("expected %s got %s", "index", string(data))
+	}
+	fn(index.IndexKey, []byte("index"))
+	if !IsEncrypted(flushed) {
+		t.Errorf("expected encrypted index")
+	}
+
+	// an encrypted index is decrypted
+	data, _ = WrapIndex(cfg, flushed, flush)
+	if string(data) != "index" {
+		t.Errorf("expected %s got %s", "index", string(data))
+	}
+
+	// an index that cannot be decrypted is discarded
+	cfg.Encryption.Keys = map[string][]byte{"k2": testKey2}
+	cfg.Encryption.KeyID = "k2"
+	data, _ = WrapIndex(cfg, flushed, flush)
+	if data != nil {
+		t.Errorf("expected nil index got %s", string(data))
+	}
+
+	// when the encryptor cannot be created, the index is not persisted
+	cfg.Encryption.KeyID = "k3"
+	flushed = nil
+	data, fn = WrapIndex(cfg, []byte("index"), flush)
+	if data != nil {
+		t.Errorf("expected nil index got %s", string(data))
+	}
+	fn(index.IndexKey, []byte("index"))
+	if flushed != nil {
+		t.Errorf("expected index not to be flushed")
+	}
+}
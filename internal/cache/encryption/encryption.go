@@ -0,0 +1,144 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+// Package encryption provides at-rest encryption of cached objects with AES-GCM
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// Each encrypted object begins with a header of the magic bytes, the format version,
+// and the ID of the key it was encrypted with, followed by the nonce and ciphertext:
+//
+//	magic(4) | version(1) | keyIDLen(1) | keyID | nonce(12) | ciphertext+tag
+var magic = []byte{0, 'e', 'n', 'c'}
+
+const (
+	formatVersion = byte(1)
+	nonceSize     = 12
+	minHeaderSize = 6
+)
+
+// ErrNotEncrypted indicates the object does not have an encryption header
+var ErrNotEncrypted = errors.New("object is not encrypted")
+
+// ErrInvalidObject indicates the object has an encryption header but could not be decrypted
+var ErrInvalidObject = errors.New("encrypted object could not be decrypted")
+
+// Encryptor seals objects with the active key, and opens objects sealed with any configured key
+type Encryptor struct {
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+// NewEncryptor returns an Encryptor for the provided encryption config, whose keys must be loaded
+func NewEncryptor(cfg config.CacheEncryptionConfig) (*Encryptor, error) {
+
+	if _, ok := cfg.Keys[cfg.KeyID]; !ok {
+		return nil, fmt.Errorf("encryption key [%s] is not loaded", cfg.KeyID)
+	}
+
+	e := &Encryptor{keyID: cfg.KeyID, aeads: make(map[string]cipher.AEAD, len(cfg.Keys))}
+	for id, key := range cfg.Keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid encryption key id [%s]", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		e.aeads[id] = aead
+	}
+
+	return e, nil
+}
+
+// Seal encrypts the data with the active key. The cache key is authenticated along with the
+// header, so that an object cannot be moved to a different key without failing to Open
+func (e *Encryptor) Seal(cacheKey string, data []byte) ([]byte, error) {
+
+	aead := e.aeads[e.keyID]
+
+	hl := minHeaderSize + len(e.keyID)
+	out := make([]byte, hl+nonceSize, hl+nonceSize+len(data)+aead.Overhead())
+	copy(out, magic)
+	out[4] = formatVersion
+	out[5] = byte(len(e.keyID))
+	copy(out[minHeaderSize:], e.keyID)
+
+	nonce := out[hl : hl+nonceSize]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, nonce, data, additionalData(out[:hl], cacheKey)), nil
+}
+
+// Open decrypts data that was sealed for the cache key with any of the Encryptor's keys
+func (e *Encryptor) Open(cacheKey string, data []byte) ([]byte, error) {
+
+	if !IsEncrypted(data) {
+		return nil, ErrNotEncrypted
+	}
+
+	if data[4] != formatVersion {
+		return nil, ErrInvalidObject
+	}
+
+	hl := minHeaderSize + int(data[5])
+	if len(data) < hl+nonceSize {
+		return nil, ErrInvalidObject
+	}
+
+	aead, ok := e.aeads[string(data[minHeaderSize:hl])]
+	if !ok {
+		return nil, fmt.Errorf("encryption key [%s] is not configured", string(data[minHeaderSize:hl]))
+	}
+
+	b, err := aead.Open(nil, data[hl:hl+nonceSize], data[hl+nonceSize:], additionalData(data[:hl], cacheKey))
+	if err != nil {
+		return nil, ErrInvalidObject
+	}
+
+	return b, nil
+}
+
+// IsEncrypted returns true if the data begins with an encryption header
+func IsEncrypted(data []byte) bool {
+	if len(data) < minHeaderSize {
+		return false
+	}
+	for i := range magic {
+		if data[i] != magic[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func additionalData(header []byte, cacheKey string) []byte {
+	ad := make([]byte, 0, len(header)+len(cacheKey))
+	ad = append(ad, header...)
+	return append(ad, cacheKey...)
+}
@@ -0,0 +1,164 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+var testKey1 = []byte("0123456789abcdef0123456789abcdef")
+var testKey2 = []byte("fedcba9876543210")
+
+func newTestEncryptionConfig(keyID string) config.CacheEncryptionConfig {
+	return config.CacheEncryptionConfig{KeyID: keyID, Keys: map[string][]byte{"k1": testKey1, "k2": testKey2}}
+}
+
+func newTestEncryptor(t *testing.T, keyID string) *Encryptor {
+	e, err := NewEncryptor(newTestEncryptionConfig(keyID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestNewEncryptor(t *testing.T) {
+
+	_, err := NewEncryptor(newTestEncryptionConfig("k3"))
+	if err == nil {
+		t.Errorf("expected error for unloaded key")
+	}
+
+	cfg := newTestEncryptionConfig("k1")
+	cfg.Keys["k3"] = []byte("short")
+	_, err = NewEncryptor(cfg)
+	if err == nil {
+		t.Errorf("expected error for invalid key size")
+	}
+
+	cfg = newTestEncryptionConfig("k1")
+	cfg.Keys[""] = testKey1
+	_, err = NewEncryptor(cfg)
+	if err == nil {
+		t.Errorf("expected error for empty key id")
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+
+	e := newTestEncryptor(t, "k1")
+	data := []byte("test data")
+
+	b, err := e.Seal("cacheKey", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsEncrypted(b) {
+		t.Errorf("expected encryption header")
+	}
+
+	if bytes.Contains(b, data) {
+		t.Errorf("expected ciphertext not to contain the plaintext")
+	}
+
+	b2, _ := e.Seal("cacheKey", data)
+	if bytes.Equal(b, b2) {
+		t.Errorf("expected a unique nonce for each seal")
+	}
+
+	out, err := e.Open("cacheKey", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Errorf("expected %s got %s", string(data), string(out))
+	}
+
+	// an empty object should round trip
+	b, _ = e.Seal("cacheKey", []byte{})
+	out, err = e.Open("cacheKey", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 0 {
+		t.Errorf("expected empty object got %s", string(out))
+	}
+}
+
+func TestOpenFailures(t *testing.T) {
+
+	e := newTestEncryptor(t, "k1")
+	b, _ := e.Seal("cacheKey", []byte("test data"))
+
+	tests := []struct {
+		name     string
+		cacheKey string
+		data     func() []byte
+		expected error
+	}{
+		{"plaintext", "cacheKey", func() []byte { return []byte("plaintext data") }, ErrNotEncrypted},
+		{"short", "cacheKey", func() []byte { return b[:4] }, ErrNotEncrypted},
+		{"truncated", "cacheKey", func() []byte { return b[:10] }, ErrInvalidObject},
+		{"wrong cache key", "otherKey", func() []byte { return b }, ErrInvalidObject},
+		{"bad version", "cacheKey", func() []byte {
+			c := append([]byte{}, b...)
+			c[4] = 9
+			return c
+		}, ErrInvalidObject},
+		{"tampered", "cacheKey", func() []byte {
+			c := append([]byte{}, b...)
+			c[len(c)-1] ^= 0xff
+			return c
+		}, ErrInvalidObject},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := e.Open(test.cacheKey, test.data())
+			if err != test.expected {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+
+	// an object sealed with the previous key can be opened after the active key changes
+	e1 := newTestEncryptor(t, "k1")
+	b, _ := e1.Seal("cacheKey", []byte("test data"))
+
+	e2 := newTestEncryptor(t, "k2")
+	out, err := e2.Open("cacheKey", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "test data" {
+		t.Errorf("expected %s got %s", "test data", string(out))
+	}
+
+	b2, _ := e2.Seal("cacheKey", []byte("test data"))
+	if string(b2[minHeaderSize:minHeaderSize+2]) != "k2" {
+		t.Errorf("expected key id %s got %s", "k2", string(b2[minHeaderSize:minHeaderSize+2]))
+	}
+
+	// once the previous key is removed, its objects can no longer be opened
+	e3, _ := NewEncryptor(config.CacheEncryptionConfig{KeyID: "k2", Keys: map[string][]byte{"k2": testKey2}})
+	_, err = e3.Open("cacheKey", b)
+	if err == nil {
+		t.Errorf("expected error for removed key")
+	}
+}
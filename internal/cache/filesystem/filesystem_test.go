@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Comcast/trickster/internal/cache/encryption"
+	"github.com/Comcast/trickster/internal/cache/index"
 	"github.com/Comcast/trickster/internal/cache/status"
 	"github.com/Comcast/trickster/internal/util/log"
 
@@ -198,6 +200,76 @@ func TestFilesystemCache_StoreNoIndex(t *testing.T) {
 	}
 }
 
+func TestFilesystemCache_EncryptedIndex(t *testing.T) {
+
+	cacheConfig := newCacheConfig(t)
+	defer os.RemoveAll(cacheConfig.Filesystem.CachePath)
+	cacheConfig.Encryption = config.CacheEncryptionConfig{KeyID: "test",
+		Keys: map[string][]byte{"test": []byte("0123456789abcdef")}}
+	fc := Cache{Config: &cacheConfig}
+
+	err := fc.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = fc.Store(cacheKey, []byte("data"), time.Duration(60)*time.Second)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// flush the index as the index flusher would
+	_, flush := encryption.WrapIndex(&cacheConfig, nil, fc.storeNoIndex)
+	flush(index.IndexKey, fc.Index.ToBytes())
+
+	data, _, err := fc.retrieve(index.IndexKey, false, false)
+	if err != nil {
+		t.Error(err)
+	}
+	if !encryption.IsEncrypted(data) {
+		t.Errorf("expected encrypted index")
+	}
+
+	// the encrypted index should be loaded on the next connect
+	fc2 := Cache{Config: &cacheConfig}
+	err = fc2.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+	if fc2.Index.GetExpiration(cacheKey).IsZero() {
+		t.Errorf("expected %s in index", cacheKey)
+	}
+}
+
+func TestFilesystemCache_Close(t *testing.T) {
+
+	cacheConfig := newCacheConfig(t)
+	defer os.RemoveAll(cacheConfig.Filesystem.CachePath)
+	fc := Cache{Config: &cacheConfig}
+
+	err := fc.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = fc.Store(cacheKey, []byte("data"), time.Duration(60)*time.Second)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// closing the cache should flush the index, to be loaded on the next connect
+	fc.Close()
+
+	fc2 := Cache{Config: &cacheConfig}
+	err = fc2.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+	if fc2.Index.GetExpiration(cacheKey).IsZero() {
+		t.Errorf("expected %s in index", cacheKey)
+	}
+}
+
 func BenchmarkCache_StoreNoIndex(b *testing.B) {
 	fc := storeBenchmark(b)
 	defer fc.Close()
@@ -14,8 +14,8 @@
 package index
 
 import (
-	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Comcast/trickster/internal/cache"
@@ -29,16 +29,25 @@ import (
 // IndexKey is the key under which the index will write itself to its associated cache
 const IndexKey = "cache.index"
 
-var indexLock = sync.Mutex{}
+// indexShardCount is the number of shards across which an Index distributes its Objects,
+// each guarded by its own lock. It must be a power of 2
+const indexShardCount = 32
+
+// indexShard is a partition of the Objects in an Index
+type indexShard struct {
+	mtx     sync.RWMutex
+	objects map[string]*Object
+}
 
 // Index maintains metadata about a Cache when Retention enforcement is managed internally,
 // like memory or bbolt. It is not used for independently managed caches like Redis.
 type Index struct {
-	// CacheSize represents the size of the cache in bytes
+	// CacheSize represents the size of the cache in bytes, and is updated atomically
 	CacheSize int64 `msg:"cache_size"`
-	// ObjectCount represents the count of objects in the Cache
+	// ObjectCount represents the count of objects in the Cache, and is updated atomically
 	ObjectCount int64 `msg:"object_count"`
-	// Objects is a map of Objects in the Cache
+	// Objects is a map of Objects in the Cache. It is only populated while the Index is
+	// serialized or deserialized, since the Index otherwise keeps its Objects in shards
 	Objects map[string]*Object `msg:"objects"`
 
 	name           string                             `msg:"-"`
@@ -48,24 +57,43 @@ type Index struct {
 	reapInterval   time.Duration                      `msg:"-"`
 	flushInterval  time.Duration                      `msg:"-"`
 	flushFunc      func(cacheKey string, data []byte) `msg:"-"`
-	lastWrite      time.Time                          `msg:"-"`
+	lastWrite      int64                              `msg:"-"`
+	policy         evictionPolicy                     `msg:"-"`
+	evictions      chan bool                          `msg:"-"`
+	shards         [indexShardCount]*indexShard       `msg:"-"`
+	limits         *sizeLimits                        `msg:"-"`
+	// origins tracks the share of the cache owned by each origin that uses it
+	origins []*originUsage `msg:"-"`
+	// evictLock ensures that only one eviction exercise runs at a time
+	evictLock *sync.Mutex `msg:"-"`
 }
 
 // ToBytes returns a serialized byte slice representing the Index
 func (idx *Index) ToBytes() []byte {
-	bytes, _ := idx.MarshalMsg(nil)
+	bytes, _ := idx.snapshotIndex().MarshalMsg(nil)
 	return bytes
 }
 
 // Object contains metadata about an item in the Cache
 type Object struct {
+	// lastAccess is the time the Object was last accessed, in Unix nanoseconds. It is updated
+	// atomically, so that accessing an Object needs only a read lock on its shard.
+	// The atomically-accessed fields are first, to ensure their alignment on 32-bit platforms
+	lastAccess int64 `msg:"-"`
+	// hits is the number of times the Object has been written or accessed, and is updated atomically
+	hits int64 `msg:"-"`
+	// priority holds the bits of the Object's float64 eviction priority under the LFU and GDS
+	// policies, and is updated atomically
+	priority uint64 `msg:"-"`
+
 	// Key represents the name of the Object and is the accessor in a hashed collection of Cache Objects
 	Key string `msg:"key"`
 	// Expiration represents the time that the Object expires from Cache
 	Expiration time.Time `msg:"expiration"`
 	// LastWrite is the time the object was last Written
 	LastWrite time.Time `msg:"lastwrite"`
-	// LastAccess is the time the object was last Accessed
+	// LastAccess is the time the object was last Accessed. While the Object is in an Index,
+	// it is only current in the copies returned by a snapshot of the Index
 	LastAccess time.Time `msg:"lastaccess"`
 	// Size the size of the Object in bytes
 	Size int64 `msg:"size"`
@@ -75,6 +103,12 @@ type Object struct {
 	// DirectValue is an interface value for storing objects by reference to a memory cache
 	// Since we'd never recover a memory cache index from memory on startup, no need to msgpk
 	ReferenceValue cache.ReferenceObject `msg:"-"`
+	// Origin is the name of the origin that owns the Object, as determined by its key prefix,
+	// or empty if no origin using the cache owns it. It is determined again when the Index is loaded
+	Origin string `msg:"-"`
+
+	// frequent indicates the 2Q policy has admitted the Object to its frequently-used queue
+	frequent bool `msg:"-"`
 }
 
 // ToBytes returns a serialized byte slice representing the Object
@@ -90,14 +124,30 @@ func ObjectFromBytes(data []byte) (*Object, error) {
 	return o, err
 }
 
+// snapshot returns a copy of the Object's metadata.
+// It must be called with the Object's shard locked
+func (o *Object) snapshot() *Object {
+	lastAccess := atomic.LoadInt64(&o.lastAccess)
+	return &Object{
+		Key:        o.Key,
+		Expiration: o.Expiration,
+		LastWrite:  o.LastWrite,
+		LastAccess: time.Unix(0, lastAccess),
+		Size:       o.Size,
+		Origin:     o.Origin,
+		lastAccess: lastAccess,
+		hits:       atomic.LoadInt64(&o.hits),
+		priority:   atomic.LoadUint64(&o.priority),
+		frequent:   o.frequent,
+	}
+}
+
 // NewIndex returns a new Index based on the provided inputs
 func NewIndex(cacheName, cacheType string, indexData []byte, cfg config.CacheIndexConfig, bulkRemoveFunc func([]string, bool), flushFunc func(cacheKey string, data []byte)) *Index {
 	i := &Index{}
 
 	if len(indexData) > 0 {
 		i.UnmarshalMsg(indexData)
-	} else {
-		i.Objects = make(map[string]*Object)
 	}
 
 	i.name = cacheName
@@ -107,6 +157,36 @@ func NewIndex(cacheName, cacheType string, indexData []byte, cfg config.CacheInd
 	i.reapInterval = cfg.ReapInterval
 	i.bulkRemoveFunc = bulkRemoveFunc
 	i.config = cfg
+	i.policy = newEvictionPolicy(cfg)
+	i.limits = &sizeLimits{
+		maxBytes:       cfg.MaxSizeBytes,
+		backoffBytes:   cfg.MaxSizeBackoffBytes,
+		maxObjects:     cfg.MaxSizeObjects,
+		backoffObjects: cfg.MaxSizeBackoffObjects,
+		bytesReason:    "size_bytes",
+		objectsReason:  "size_objects",
+	}
+	i.origins = newOriginUsages(cacheName, cacheType, cfg)
+
+	i.evictLock = &sync.Mutex{}
+	for j := range i.shards {
+		i.shards[j] = &indexShard{objects: make(map[string]*Object)}
+	}
+	for k, o := range i.Objects {
+		o.lastAccess = o.LastAccess.UnixNano()
+		if u := i.owner(k); u != nil {
+			o.Origin = u.name
+			u.add(i, o.Size, 1)
+		}
+		i.policy.touch(o, true)
+		i.shard(k).objects[k] = o
+	}
+	i.Objects = nil
+
+	if cfg.MaxSizeBytes > 0 || cfg.MaxSizeObjects > 0 || i.hasQuotas() {
+		i.evictions = make(chan bool, 1)
+		go i.evictor()
+	}
 
 	if flushFunc != nil {
 		if i.flushInterval > 0 {
@@ -128,23 +208,74 @@ func NewIndex(cacheName, cacheType string, indexData []byte, cfg config.CacheInd
 	return i
 }
 
+// shard returns the shard holding the Object with the provided key
+func (idx *Index) shard(key string) *indexShard {
+	// 32-bit FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return idx.shards[h&(indexShardCount-1)]
+}
+
+// snapshot returns copies of the Objects in the Index, locking one shard at a time
+func (idx *Index) snapshot() []*Object {
+	objects := make([]*Object, 0)
+	for _, s := range idx.shards {
+		s.mtx.RLock()
+		for _, o := range s.objects {
+			objects = append(objects, o.snapshot())
+		}
+		s.mtx.RUnlock()
+	}
+	return objects
+}
+
+// snapshotIndex returns a copy of the Index with its Objects collected in the Objects map for serialization
+func (idx *Index) snapshotIndex() *Index {
+	objects := idx.snapshot()
+	i := &Index{
+		CacheSize:   atomic.LoadInt64(&idx.CacheSize),
+		ObjectCount: atomic.LoadInt64(&idx.ObjectCount),
+		Objects:     make(map[string]*Object, len(objects)),
+	}
+	for _, o := range objects {
+		i.Objects[o.Key] = o
+	}
+	return i
+}
+
 // UpdateObjectAccessTime updates the LastAccess for the object with the provided key
 func (idx *Index) UpdateObjectAccessTime(key string) {
-	indexLock.Lock()
-	if _, ok := idx.Objects[key]; ok {
-		idx.Objects[key].LastAccess = time.Now()
+	s := idx.shard(key)
+	s.mtx.RLock()
+	if o, ok := s.objects[key]; ok {
+		atomic.StoreInt64(&o.lastAccess, time.Now().UnixNano())
+		idx.policy.touch(o, false)
 	}
-	indexLock.Unlock()
-
+	s.mtx.RUnlock()
 }
 
 // UpdateObjectTTL updates the Expiration for the object with the provided key
 func (idx *Index) UpdateObjectTTL(key string, ttl time.Duration) {
-	indexLock.Lock()
-	if _, ok := idx.Objects[key]; ok {
-		idx.Objects[key].Expiration = time.Now().Add(ttl)
+	s := idx.shard(key)
+	s.mtx.Lock()
+	if o, ok := s.objects[key]; ok {
+		o.Expiration = time.Now().Add(ttl)
+	}
+	s.mtx.Unlock()
+}
+
+// ListObjects returns the metadata of each Object in the Index
+func (idx *Index) ListObjects() []cache.ObjectMetadata {
+	snapshot := idx.snapshot()
+	objects := make([]cache.ObjectMetadata, 0, len(snapshot))
+	for _, o := range snapshot {
+		objects = append(objects, cache.ObjectMetadata{Key: o.Key, Size: o.Size,
+			Expiration: o.Expiration, LastWrite: o.LastWrite, LastAccess: o.LastAccess})
 	}
-	indexLock.Unlock()
+	return objects
 }
 
 // UpdateObject writes or updates the Index Metadata for the provided Object
@@ -155,9 +286,8 @@ func (idx *Index) UpdateObject(obj *Object) {
 		return
 	}
 
-	indexLock.Lock()
-
-	idx.lastWrite = time.Now()
+	now := time.Now()
+	atomic.StoreInt64(&idx.lastWrite, now.UnixNano())
 
 	if obj.ReferenceValue != nil {
 		obj.Size = int64(obj.ReferenceValue.Size())
@@ -165,72 +295,99 @@ func (idx *Index) UpdateObject(obj *Object) {
 		obj.Size = int64(len(obj.Value))
 	}
 	obj.Value = nil
-	obj.LastAccess = time.Now()
-	obj.LastWrite = obj.LastAccess
+	obj.LastAccess = now
+	obj.LastWrite = now
+	obj.lastAccess = now.UnixNano()
 
-	if o, ok := idx.Objects[key]; ok {
-		idx.CacheSize += o.Size - idx.Objects[key].Size
-	} else {
-		idx.CacheSize += obj.Size
-		idx.ObjectCount++
+	u := idx.owner(key)
+	if u != nil {
+		obj.Origin = u.name
 	}
 
-	cache.ObserveCacheSizeChange(idx.name, idx.cacheType, idx.CacheSize, idx.ObjectCount)
+	var cacheSize, objectCount, sizeChange, countChange int64
 
-	idx.Objects[key] = obj
-	indexLock.Unlock()
+	s := idx.shard(key)
+	s.mtx.Lock()
+	if o, ok := s.objects[key]; ok {
+		sizeChange = obj.Size - o.Size
+		cacheSize = atomic.AddInt64(&idx.CacheSize, sizeChange)
+		objectCount = atomic.LoadInt64(&idx.ObjectCount)
+		obj.hits = atomic.LoadInt64(&o.hits)
+		obj.frequent = o.frequent
+	} else {
+		sizeChange, countChange = obj.Size, 1
+		cacheSize = atomic.AddInt64(&idx.CacheSize, sizeChange)
+		objectCount = atomic.AddInt64(&idx.ObjectCount, countChange)
+	}
+	idx.policy.touch(obj, true)
+	s.objects[key] = obj
+	s.mtx.Unlock()
+
+	cache.ObserveCacheSizeChange(idx.name, idx.cacheType, cacheSize, objectCount)
+	u.add(idx, sizeChange, countChange)
+
+	// signal the evictor without waiting, since it may already be running
+	if idx.evictions != nil && (idx.isFull() || u.overQuota() != "") {
+		select {
+		case idx.evictions <- true:
+		default:
+		}
+	}
 }
 
 // RemoveObject removes an Object's Metadata from the Index
-func (idx *Index) RemoveObject(key string, noLock bool) {
+func (idx *Index) RemoveObject(key string) {
 
-	if !noLock {
-		indexLock.Lock()
-		idx.lastWrite = time.Now()
-	}
-	if o, ok := idx.Objects[key]; ok {
-		idx.CacheSize -= o.Size
-		idx.ObjectCount--
+	s := idx.shard(key)
+	s.mtx.Lock()
+	atomic.StoreInt64(&idx.lastWrite, time.Now().UnixNano())
+	if o, ok := s.objects[key]; ok {
+		cacheSize := atomic.AddInt64(&idx.CacheSize, -o.Size)
+		objectCount := atomic.AddInt64(&idx.ObjectCount, -1)
 
 		cache.ObserveCacheOperation(idx.name, idx.cacheType, "del", "none", float64(o.Size))
 
-		delete(idx.Objects, key)
-		cache.ObserveCacheSizeChange(idx.name, idx.cacheType, idx.CacheSize, idx.ObjectCount)
-	}
-	if !noLock {
-		indexLock.Unlock()
+		delete(s.objects, key)
+		cache.ObserveCacheSizeChange(idx.name, idx.cacheType, cacheSize, objectCount)
+		idx.owner(key).add(idx, -o.Size, -1)
 	}
+	s.mtx.Unlock()
 
 }
 
 // GetExpiration returns the cache index's expiration for the object of the given key
 func (idx *Index) GetExpiration(cacheKey string) time.Time {
-	indexLock.Lock()
-	if o, ok := idx.Objects[cacheKey]; ok {
-		indexLock.Unlock()
+	s := idx.shard(cacheKey)
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if o, ok := s.objects[cacheKey]; ok {
 		return o.Expiration
 	}
-	indexLock.Unlock()
 	return time.Time{}
 }
 
 // flusher periodically calls the cache's index flush func that writes the cache index to disk
 func (idx *Index) flusher() {
-	var lastFlush time.Time
+	var lastFlush int64
 	for {
 		time.Sleep(idx.flushInterval)
-		if idx.lastWrite.Before(lastFlush) {
+		if atomic.LoadInt64(&idx.lastWrite) < lastFlush {
 			continue
 		}
 		idx.flushOnce()
-		lastFlush = time.Now()
+		lastFlush = time.Now().UnixNano()
+	}
+}
+
+// Flush writes the Index to its cache with the cache's index flush func
+func (idx *Index) Flush() {
+	if idx.flushFunc != nil {
+		idx.flushOnce()
 	}
 }
 
 func (idx *Index) flushOnce() {
-	indexLock.Lock()
-	bytes, err := idx.MarshalMsg(nil)
-	indexLock.Unlock()
+	bytes, err := idx.snapshotIndex().MarshalMsg(nil)
 	if err != nil {
 		log.Warn("unable to serialize index for flushing", log.Pairs{"cacheName": idx.name, "detail": err.Error()})
 		return
@@ -246,106 +403,197 @@ func (idx *Index) reaper() {
 	}
 }
 
+// evictor removes Objects under the Index's eviction policy as soon as a write
+// puts the cache over its maximum size, or an origin over its quota
+func (idx *Index) evictor() {
+	for range idx.evictions {
+		idx.evictLock.Lock()
+		idx.evict()
+		idx.evictLock.Unlock()
+	}
+}
+
+// evict removes Objects under the Index's eviction policy, first from each origin that exceeds its
+// quota, and then from the whole cache if it still exceeds its maximum size, and returns true if any
+// Objects were removed. It must be called with the evict lock held
+func (idx *Index) evict() bool {
+
+	var evicted bool
+
+	for _, u := range idx.origins {
+		if u.overQuota() == "" {
+			continue
+		}
+		objects := make([]*Object, 0)
+		for _, o := range idx.snapshot() {
+			if o.Origin == u.name {
+				objects = append(objects, o)
+			}
+		}
+		removals, evictionType := idx.selectEvictionsWithin(objects, atomic.LoadInt64(&u.bytes),
+			atomic.LoadInt64(&u.objects), u.limits, u.name)
+		if len(removals) > 0 {
+			idx.bulkRemoveFunc(removals, false)
+			idx.observeEvictions(removals, evictionType)
+			evicted = true
+		}
+	}
+
+	if idx.isFull() {
+		removals, evictionType := idx.selectEvictions(idx.snapshot())
+		if len(removals) > 0 {
+			idx.bulkRemoveFunc(removals, false)
+			idx.observeEvictions(removals, evictionType)
+			evicted = true
+		}
+	}
+
+	return evicted
+}
+
 type objectsAtime []*Object
 
-// reap makes a single iteration through the cache index to to find and remove expired elements
-// and evict least-recently-accessed elements to maintain the Maximum allowed Cache Size
+// reap makes a single iteration through the cache index to find and remove expired elements, and
+// evict elements under the Index's eviction policy to maintain the Maximum allowed Cache Size. Each
+// shard is locked only while its expired keys are collected, and size-based evictions are selected
+// from a snapshot of the Index, so that reaping does not stall the request path. Since the removals
+// are made after the shards are unlocked, an Object that is rewritten in the meantime may still be
+// removed, which costs only a cache miss.
 func (idx *Index) reap() {
 
-	indexLock.Lock()
-	defer indexLock.Unlock()
+	idx.evictLock.Lock()
+	defer idx.evictLock.Unlock()
 
 	removals := make([]string, 0)
-	remainders := make(objectsAtime, 0, idx.ObjectCount)
 
 	var cacheChanged bool
 
 	now := time.Now()
 
-	for _, o := range idx.Objects {
-		if o.Key == IndexKey {
-			continue
-		}
-		if o.Expiration.Before(now) && !o.Expiration.IsZero() {
-			removals = append(removals, o.Key)
-		} else {
-			remainders = append(remainders, o)
+	for _, s := range idx.shards {
+		s.mtx.RLock()
+		for _, o := range s.objects {
+			if o.Key == IndexKey {
+				continue
+			}
+			if o.Expiration.Before(now) && !o.Expiration.IsZero() {
+				removals = append(removals, o.Key)
+			}
 		}
+		s.mtx.RUnlock()
 	}
 
 	if len(removals) > 0 {
-		cache.ObserveCacheEvent(idx.name, idx.cacheType, "eviction", "ttl")
-		idx.bulkRemoveFunc(removals, true)
+		idx.bulkRemoveFunc(removals, false)
+		idx.observeEvictions(removals, "ttl")
 		cacheChanged = true
 	}
 
-	if ((idx.config.MaxSizeBytes > 0 && idx.CacheSize > idx.config.MaxSizeBytes) || (idx.config.MaxSizeObjects > 0 && idx.ObjectCount > idx.config.MaxSizeObjects)) && len(remainders) > 0 {
+	if idx.evict() {
+		cacheChanged = true
+	}
 
-		var evictionType string
-		if idx.config.MaxSizeBytes > 0 && idx.CacheSize > idx.config.MaxSizeBytes {
-			evictionType = "size_bytes"
-		} else if idx.config.MaxSizeObjects > 0 && idx.ObjectCount > idx.config.MaxSizeObjects {
-			evictionType = "size_objects"
-		} else {
-			return
-		}
+	if cacheChanged {
+		atomic.StoreInt64(&idx.lastWrite, time.Now().UnixNano())
+	}
+}
 
-		log.Debug("max cache size reached. evicting least-recently-accessed records",
-			log.Pairs{
-				"reason":         evictionType,
-				"cacheSizeBytes": idx.CacheSize, "maxSizeBytes": idx.config.MaxSizeBytes,
-				"cacheSizeObjects": idx.ObjectCount, "maxSizeObjects": idx.config.MaxSizeObjects,
-			},
-		)
+func (idx *Index) observeEvictions(removals []string, evictionType string) {
+	cache.ObserveCacheEvent(idx.name, idx.cacheType, "eviction", evictionType)
+	cache.ObserveCacheEvictions(idx.name, idx.cacheType, idx.config.EvictionPolicy.String(), evictionType, len(removals))
+}
 
-		removals = make([]string, 0)
+// isFull returns true if the cache exceeds its maximum size in bytes or objects
+func (idx *Index) isFull() bool {
+	return idx.limits.exceeded(atomic.LoadInt64(&idx.CacheSize), atomic.LoadInt64(&idx.ObjectCount)) != ""
+}
 
-		sort.Sort(remainders)
+// hasQuotas returns true if any origin using the cache has a quota
+func (idx *Index) hasQuotas() bool {
+	for _, u := range idx.origins {
+		if u.limits != nil {
+			return true
+		}
+	}
+	return false
+}
 
-		i := 0
-		j := len(remainders)
+// selectEvictions returns the keys of the snapshot Objects that the Index's eviction policy selects
+// for removal to bring the cache under its Maximum Size, and the type of size limit that was exceeded.
+// It must be called with the evict lock held
+func (idx *Index) selectEvictions(objects []*Object) ([]string, string) {
+	return idx.selectEvictionsWithin(objects, atomic.LoadInt64(&idx.CacheSize),
+		atomic.LoadInt64(&idx.ObjectCount), idx.limits, "")
+}
 
-		if evictionType == "size_bytes" {
-			bytesNeeded := (idx.CacheSize - idx.config.MaxSizeBytes)
-			if idx.config.MaxSizeBytes > idx.config.MaxSizeBackoffBytes {
-				bytesNeeded += idx.config.MaxSizeBackoffBytes
-			}
-			bytesSelected := int64(0)
-			for bytesSelected < bytesNeeded && i < j {
-				removals = append(removals, remainders[i].Key)
-				bytesSelected += remainders[i].Size
-				i++
-			}
-		} else {
-			objectsNeeded := (idx.ObjectCount - idx.config.MaxSizeObjects)
-			if idx.config.MaxSizeObjects > idx.config.MaxSizeBackoffObjects {
-				objectsNeeded += idx.config.MaxSizeBackoffObjects
-			}
-			objectsSelected := int64(0)
-			for objectsSelected < objectsNeeded && i < j {
-				removals = append(removals, remainders[i].Key)
-				objectsSelected++
-				i++
-			}
-		}
+// selectEvictionsWithin returns the keys of the Objects that the Index's eviction policy selects for
+// removal to bring a cache, or the named origin's share of it, of the provided size within its limits,
+// and the reason for the evictions. It must be called with the evict lock held
+func (idx *Index) selectEvictionsWithin(objects []*Object, cacheSize, objectCount int64,
+	l *sizeLimits, originName string) ([]string, string) {
 
-		if len(removals) > 0 {
-			cache.ObserveCacheEvent(idx.name, idx.cacheType, "eviction", evictionType)
-			idx.bulkRemoveFunc(removals, true)
-			cacheChanged = true
+	evictionType := l.exceeded(cacheSize, objectCount)
+	if evictionType == "" {
+		return nil, ""
+	}
+
+	remainders := make([]*Object, 0, len(objects))
+	for _, o := range objects {
+		if o.Key != IndexKey {
+			remainders = append(remainders, o)
 		}
+	}
+	if len(remainders) == 0 {
+		return nil, ""
+	}
 
-		log.Debug("size-based cache eviction exercise completed",
-			log.Pairs{
-				"reason":         evictionType,
-				"cacheSizeBytes": idx.CacheSize, "maxSizeBytes": idx.config.MaxSizeBytes,
-				"cacheSizeObjects": idx.ObjectCount, "maxSizeObjects": idx.config.MaxSizeObjects,
-			})
+	log.Debug("max cache size reached. evicting records",
+		log.Pairs{
+			"reason": evictionType, "policy": idx.config.EvictionPolicy, "originName": originName,
+			"cacheSizeBytes": cacheSize, "maxSizeBytes": l.maxBytes,
+			"cacheSizeObjects": objectCount, "maxSizeObjects": l.maxObjects,
+		},
+	)
 
+	removals := make([]string, 0)
+
+	idx.policy.sort(remainders)
+
+	i := 0
+	j := len(remainders)
+
+	if evictionType == l.bytesReason {
+		bytesNeeded := (cacheSize - l.maxBytes)
+		if l.maxBytes > l.backoffBytes {
+			bytesNeeded += l.backoffBytes
+		}
+		bytesSelected := int64(0)
+		for bytesSelected < bytesNeeded && i < j {
+			removals = append(removals, remainders[i].Key)
+			idx.policy.evicted(remainders[i])
+			bytesSelected += remainders[i].Size
+			i++
+		}
+	} else {
+		objectsNeeded := (objectCount - l.maxObjects)
+		if l.maxObjects > l.backoffObjects {
+			objectsNeeded += l.backoffObjects
+		}
+		objectsSelected := int64(0)
+		for objectsSelected < objectsNeeded && i < j {
+			removals = append(removals, remainders[i].Key)
+			idx.policy.evicted(remainders[i])
+			objectsSelected++
+			i++
+		}
 	}
-	if cacheChanged {
-		idx.lastWrite = time.Now()
-	}
+
+	log.Debug("size-based cache eviction records selected",
+		log.Pairs{
+			"reason": evictionType, "policy": idx.config.EvictionPolicy, "count": len(removals),
+		})
+
+	return removals, evictionType
 }
 
 // Len returns the length of an array of Prometheus model.Times
@@ -15,6 +15,8 @@ package index
 
 import (
 	"sort"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -30,11 +32,20 @@ var testBulkIndex *Index
 
 func testBulkRemoveFunc(cacheKeys []string, noLock bool) {
 	for _, cacheKey := range cacheKeys {
-		testBulkIndex.RemoveObject(cacheKey, noLock)
+		testBulkIndex.RemoveObject(cacheKey)
 	}
 }
 func fakeFlusherFunc(string, []byte) {}
 
+// testObject returns the Object with the provided key from the Index's shards
+func testObject(idx *Index, key string) (*Object, bool) {
+	s := idx.shard(key)
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	o, ok := s.objects[key]
+	return o, ok
+}
+
 type testReferenceObject struct {
 }
 
@@ -69,6 +80,24 @@ func TestNewIndex(t *testing.T) {
 
 }
 
+func TestFlush(t *testing.T) {
+
+	idx := NewIndex("test", "test", nil, config.CacheIndexConfig{}, testBulkRemoveFunc, nil)
+	// an index without a flush func should not panic
+	idx.Flush()
+
+	var flushed []byte
+	idx = NewIndex("test", "test", nil, config.CacheIndexConfig{}, testBulkRemoveFunc,
+		func(cacheKey string, data []byte) { flushed = data })
+	idx.UpdateObject(&Object{Key: "test", Value: []byte("test_value")})
+	idx.Flush()
+
+	idx2 := NewIndex("test", "test", flushed, config.CacheIndexConfig{}, testBulkRemoveFunc, nil)
+	if _, ok := testObject(idx2, "test"); !ok {
+		t.Errorf("expected key %s to be present", "test")
+	}
+}
+
 func TestReap(t *testing.T) {
 
 	cacheConfig := &config.CachingConfig{CacheType: "test", Index: config.CacheIndexConfig{ReapInterval: time.Second * time.Duration(10), FlushInterval: time.Second * time.Duration(10)}}
@@ -111,27 +140,27 @@ func TestReap(t *testing.T) {
 	// trigger size-based reap eviction of some elements
 	idx.reap()
 
-	if _, ok := idx.Objects["test.1"]; ok {
+	if _, ok := testObject(idx, "test.1"); ok {
 		t.Errorf("expected key %s to be missing", "test.1")
 	}
 
-	if _, ok := idx.Objects["test.2"]; ok {
+	if _, ok := testObject(idx, "test.2"); ok {
 		t.Errorf("expected key %s to be missing", "test.2")
 	}
 
-	if _, ok := idx.Objects["test.3"]; ok {
+	if _, ok := testObject(idx, "test.3"); ok {
 		t.Errorf("expected key %s to be missing", "test.3")
 	}
 
-	if _, ok := idx.Objects["test.4"]; ok {
+	if _, ok := testObject(idx, "test.4"); ok {
 		t.Errorf("expected key %s to be missing", "test.4")
 	}
 
-	if _, ok := idx.Objects["test.5"]; ok {
+	if _, ok := testObject(idx, "test.5"); ok {
 		t.Errorf("expected key %s to be missing", "test.5")
 	}
 
-	if _, ok := idx.Objects["test.6"]; !ok {
+	if _, ok := testObject(idx, "test.6"); !ok {
 		t.Errorf("expected key %s to be present", "test.6")
 	}
 
@@ -143,16 +172,48 @@ func TestReap(t *testing.T) {
 
 	// only cache index should be left
 
-	if _, ok := idx.Objects["test.6"]; ok {
+	if _, ok := testObject(idx, "test.6"); ok {
 		t.Errorf("expected key %s to be missing", "test.6")
 	}
 
-	if _, ok := idx.Objects["test.7"]; ok {
+	if _, ok := testObject(idx, "test.7"); ok {
 		t.Errorf("expected key %s to be missing", "test.7")
 	}
 
 }
 
+func TestEvictOnStore(t *testing.T) {
+
+	cacheConfig := &config.CachingConfig{CacheType: "test", Index: config.CacheIndexConfig{ReapInterval: 0, FlushInterval: 0}}
+	cacheConfig.Index.MaxSizeObjects = 3
+	cacheConfig.Index.MaxSizeBackoffObjects = 1
+
+	idx := NewIndex("test", "test", nil, cacheConfig.Index, testBulkRemoveFunc, fakeFlusherFunc)
+	testBulkIndex = idx
+
+	for i := 1; i <= 4; i++ {
+		idx.UpdateObject(&Object{Key: "test." + strconv.Itoa(i), Value: []byte("test_value")})
+	}
+
+	// the evictor runs asynchronously, without waiting for a reap
+	var count int64
+	for i := 0; i < 100; i++ {
+		if count = atomic.LoadInt64(&idx.ObjectCount); count == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if count != 2 {
+		t.Errorf("expected %d got %d", 2, count)
+	}
+
+	if _, ok := testObject(idx, "test.4"); !ok {
+		t.Errorf("expected key %s to be present", "test.4")
+	}
+
+}
+
 func TestObjectFromBytes(t *testing.T) {
 
 	obj := &Object{}
@@ -175,39 +236,59 @@ func TestUpdateObject(t *testing.T) {
 	idx := NewIndex("test", "test", nil, cacheConfig.Index, testBulkRemoveFunc, fakeFlusherFunc)
 
 	idx.UpdateObject(&obj)
-	if _, ok := idx.Objects["test"]; ok {
+	if _, ok := testObject(idx, "test"); ok {
 		t.Errorf("test object should be missing from index")
 	}
 
 	obj.Key = "test"
 
 	idx.UpdateObject(&obj)
-	if _, ok := idx.Objects["test"]; !ok {
+	if _, ok := testObject(idx, "test"); !ok {
 		t.Errorf("test object missing from index")
 	}
 
 	// do it again to cover the index hit case
-	idx.UpdateObject(&obj)
-	if _, ok := idx.Objects["test"]; !ok {
+	idx.UpdateObject(&Object{Key: "test", Value: []byte("test_value")})
+	if _, ok := testObject(idx, "test"); !ok {
 		t.Errorf("test object missing from index")
 	}
 
-	idx.Objects["test"].LastAccess = time.Time{}
+	o, _ := testObject(idx, "test")
+	o.lastAccess = 0
 	idx.UpdateObjectAccessTime("test")
 
-	if idx.Objects["test"].LastAccess.IsZero() {
+	if o.snapshot().LastAccess.Equal(time.Unix(0, 0)) {
 		t.Errorf("test object last access time is wrong")
 	}
 
 	obj = Object{Key: "test2", ReferenceValue: &testReferenceObject{}}
 
 	idx.UpdateObject(&obj)
-	if _, ok := idx.Objects["test2"]; !ok {
+	if _, ok := testObject(idx, "test2"); !ok {
 		t.Errorf("test object missing from index")
 	}
 
 }
 
+func TestListObjects(t *testing.T) {
+
+	cacheConfig := &config.CachingConfig{CacheType: "test", Index: config.CacheIndexConfig{ReapInterval: time.Second * time.Duration(10), FlushInterval: time.Second * time.Duration(10)}}
+	idx := NewIndex("test", "test", nil, cacheConfig.Index, testBulkRemoveFunc, fakeFlusherFunc)
+
+	exp := time.Now().Add(time.Minute)
+	idx.UpdateObject(&Object{Key: "test", Value: []byte("test_value"), Expiration: exp})
+
+	objects := idx.ListObjects()
+	if len(objects) != 1 {
+		t.Fatalf("expected %d got %d", 1, len(objects))
+	}
+
+	o := objects[0]
+	if o.Key != "test" || o.Size != 10 || !o.Expiration.Equal(exp) || o.LastWrite.IsZero() || o.LastAccess.IsZero() {
+		t.Errorf("unexpected object metadata %v", o)
+	}
+}
+
 func TestRemoveObject(t *testing.T) {
 
 	obj := Object{Key: "test", Value: []byte("test_value")}
@@ -215,12 +296,12 @@ func TestRemoveObject(t *testing.T) {
 	idx := NewIndex("test", "test", nil, cacheConfig.Index, testBulkRemoveFunc, fakeFlusherFunc)
 
 	idx.UpdateObject(&obj)
-	if _, ok := idx.Objects["test"]; !ok {
+	if _, ok := testObject(idx, "test"); !ok {
 		t.Errorf("test object missing from index")
 	}
 
-	idx.RemoveObject("test", false)
-	if _, ok := idx.Objects["test"]; ok {
+	idx.RemoveObject("test")
+	if _, ok := testObject(idx, "test"); ok {
 		t.Errorf("test object should be missing from index")
 	}
 
@@ -284,3 +365,125 @@ func TestUpdateObjectTTL(t *testing.T) {
 	}
 
 }
+
+const benchmarkIndexObjects = 10000
+
+// benchmarkIndex returns an Index with no reaper or flusher, populated with count Objects
+func benchmarkIndex(b *testing.B, count int) *Index {
+	idx := NewIndex("bench", "test", nil, config.CacheIndexConfig{}, nil, nil)
+	idx.bulkRemoveFunc = func(cacheKeys []string, noLock bool) {
+		for _, cacheKey := range cacheKeys {
+			idx.RemoveObject(cacheKey)
+		}
+	}
+	for i := 0; i < count; i++ {
+		idx.UpdateObject(&Object{Key: "bench." + strconv.Itoa(i), Value: []byte("test_value"),
+			Expiration: time.Now().Add(time.Hour)})
+	}
+	return idx
+}
+
+// benchmarkRetrieve exercises the Index as a cache's Retrieve does
+func benchmarkRetrieve(idx *Index, key string) {
+	if !idx.GetExpiration(key).IsZero() {
+		idx.UpdateObjectAccessTime(key)
+	}
+}
+
+// benchmarkReaper reaps the Index continually until the returned func is called
+func benchmarkReaper(idx *Index) func() {
+	done := make(chan bool)
+	stopped := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				close(stopped)
+				return
+			default:
+				idx.reap()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func BenchmarkIndex_ConcurrentRetrieve(b *testing.B) {
+	idx := benchmarkIndex(b, benchmarkIndexObjects)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			benchmarkRetrieve(idx, "bench."+strconv.Itoa(i%benchmarkIndexObjects))
+			i++
+		}
+	})
+}
+
+func BenchmarkIndex_ConcurrentStore(b *testing.B) {
+	idx := benchmarkIndex(b, 0)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			idx.UpdateObject(&Object{Key: "bench." + strconv.Itoa(i%benchmarkIndexObjects), Value: []byte("test_value")})
+			i++
+		}
+	})
+}
+
+func BenchmarkIndex_ConcurrentStoreRetrieve(b *testing.B) {
+	idx := benchmarkIndex(b, benchmarkIndexObjects)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := "bench." + strconv.Itoa(i%benchmarkIndexObjects)
+			if i%10 == 0 {
+				idx.UpdateObject(&Object{Key: key, Value: []byte("test_value")})
+			} else {
+				benchmarkRetrieve(idx, key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkIndex_ConcurrentStoreRetrieveDuringReap(b *testing.B) {
+	idx := benchmarkIndex(b, benchmarkIndexObjects)
+	stop := benchmarkReaper(idx)
+	defer stop()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := "bench." + strconv.Itoa(i%benchmarkIndexObjects)
+			if i%10 == 0 {
+				idx.UpdateObject(&Object{Key: key, Value: []byte("test_value"), Expiration: time.Now().Add(time.Hour)})
+			} else {
+				benchmarkRetrieve(idx, key)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkIndex_ConcurrentRetrieveDuringOtherReap measures the impact of reaping
+// a large cache on the request path of another cache
+func BenchmarkIndex_ConcurrentRetrieveDuringOtherReap(b *testing.B) {
+	idx := benchmarkIndex(b, benchmarkIndexObjects)
+	other := benchmarkIndex(b, benchmarkIndexObjects*10)
+	stop := benchmarkReaper(other)
+	defer stop()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			benchmarkRetrieve(idx, "bench."+strconv.Itoa(i%benchmarkIndexObjects))
+			i++
+		}
+	})
+}
@@ -0,0 +1,192 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package index
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// evictionPolicy orders the Objects in an Index for size-based eviction
+type evictionPolicy interface {
+	// touch records that the Object was written (when isWrite is true) or accessed. A write is
+	// recorded before the Object is added to the Index, while accesses are recorded with only
+	// a read lock on the Object's shard, and so may run concurrently for the same Object
+	touch(o *Object, isWrite bool)
+	// evicted records that the snapshot Object was selected for size-based eviction
+	evicted(o *Object)
+	// sort orders the snapshot Objects so that the first should be evicted first
+	sort(objects []*Object)
+}
+
+// newEvictionPolicy returns the evictionPolicy configured for the Index
+func newEvictionPolicy(cfg config.CacheIndexConfig) evictionPolicy {
+	switch cfg.EvictionPolicy {
+	case config.EvictionPolicyLFU:
+		return &greedyDualPolicy{weight: func(*Object) float64 { return 1 }}
+	case config.EvictionPolicyGDS:
+		cost := float64(cfg.RefetchCostBytes)
+		return &greedyDualPolicy{weight: func(o *Object) float64 {
+			size := float64(o.Size)
+			if size < 1 {
+				size = 1
+			}
+			return (cost + size) / size
+		}}
+	case config.EvictionPolicy2Q:
+		return &twoQueuePolicy{ghosts: make(map[string]bool)}
+	}
+	return &lruPolicy{}
+}
+
+// lruPolicy evicts the least-recently-accessed Objects first
+type lruPolicy struct{}
+
+func (p *lruPolicy) touch(o *Object, isWrite bool) {}
+
+func (p *lruPolicy) evicted(o *Object) {}
+
+func (p *lruPolicy) sort(objects []*Object) {
+	sort.Sort(objectsAtime(objects))
+}
+
+// greedyDualPolicy evicts the Objects with the lowest priority first. An Object's priority is
+// its weighted hit count plus the inflation value, which is raised to the priority of each evicted
+// Object. This ages the priority of Objects that are no longer accessed, so that they are
+// eventually evicted in favor of newer ones. With a constant weight, this is LFU with Dynamic
+// Aging; weighing by refetch cost over size, it is GreedyDual-Size with Frequency
+type greedyDualPolicy struct {
+	// inflation holds the bits of the float64 inflation value, and is accessed atomically
+	inflation uint64
+	weight    func(*Object) float64
+}
+
+func loadFloat64(addr *uint64) float64 {
+	return math.Float64frombits(atomic.LoadUint64(addr))
+}
+
+func storeFloat64(addr *uint64, v float64) {
+	atomic.StoreUint64(addr, math.Float64bits(v))
+}
+
+func (p *greedyDualPolicy) touch(o *Object, isWrite bool) {
+	hits := atomic.AddInt64(&o.hits, 1)
+	storeFloat64(&o.priority, loadFloat64(&p.inflation)+float64(hits)*p.weight(o))
+}
+
+// evicted is only called by one eviction exercise at a time, so the inflation value
+// can be loaded and raised without a compare-and-swap
+func (p *greedyDualPolicy) evicted(o *Object) {
+	if priority := loadFloat64(&o.priority); priority > loadFloat64(&p.inflation) {
+		storeFloat64(&p.inflation, priority)
+	}
+}
+
+func (p *greedyDualPolicy) sort(objects []*Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		pi, pj := loadFloat64(&objects[i].priority), loadFloat64(&objects[j].priority)
+		if pi == pj {
+			return objects[i].LastAccess.Before(objects[j].LastAccess)
+		}
+		return pi < pj
+	})
+}
+
+const (
+	// twoQueueInRatio is the share of the Index that new Objects may occupy
+	// before they are evicted ahead of the frequently-used Objects
+	twoQueueInRatio = 0.25
+	// twoQueueGhostRatio is the number of evicted keys remembered by the 2Q policy,
+	// as a ratio of the number of Objects in the Index
+	twoQueueGhostRatio = 0.5
+	// twoQueueMinGhosts is the minimum number of evicted keys remembered by the 2Q policy
+	twoQueueMinGhosts = 128
+)
+
+// twoQueuePolicy implements 2Q. New Objects are admitted to a FIFO queue and, when that queue
+// holds more than its share of the Index, are evicted first regardless of how often they are
+// accessed. The keys evicted from it are remembered, and an Object that is written again under
+// a remembered key is admitted to an LRU queue of frequently-used Objects instead. This way,
+// a single scan of many Objects cannot flush the frequently-used ones from the cache.
+type twoQueuePolicy struct {
+	mtx        sync.Mutex
+	ghosts     map[string]bool
+	ghostOrder []string
+	maxGhosts  int
+}
+
+func (p *twoQueuePolicy) touch(o *Object, isWrite bool) {
+	if !isWrite {
+		return
+	}
+	p.mtx.Lock()
+	if p.ghosts[o.Key] {
+		delete(p.ghosts, o.Key)
+		o.frequent = true
+	}
+	p.mtx.Unlock()
+}
+
+func (p *twoQueuePolicy) evicted(o *Object) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if o.frequent || p.ghosts[o.Key] {
+		return
+	}
+	p.ghosts[o.Key] = true
+	p.ghostOrder = append(p.ghostOrder, o.Key)
+	for len(p.ghostOrder) > p.maxGhosts {
+		// a key that was readmitted and evicted again may appear twice in ghostOrder, in which
+		// case it is forgotten early. This only costs its next write an admission to the FIFO queue
+		delete(p.ghosts, p.ghostOrder[0])
+		p.ghostOrder = p.ghostOrder[1:]
+	}
+}
+
+func (p *twoQueuePolicy) sort(objects []*Object) {
+
+	p.mtx.Lock()
+	p.maxGhosts = int(float64(len(objects)) * twoQueueGhostRatio)
+	if p.maxGhosts < twoQueueMinGhosts {
+		p.maxGhosts = twoQueueMinGhosts
+	}
+	p.mtx.Unlock()
+
+	in := make([]*Object, 0, len(objects))
+	frequent := make([]*Object, 0, len(objects))
+	for _, o := range objects {
+		if o.frequent {
+			frequent = append(frequent, o)
+		} else {
+			in = append(in, o)
+		}
+	}
+
+	sort.Slice(in, func(i, j int) bool { return in[i].LastWrite.Before(in[j].LastWrite) })
+	sort.Sort(objectsAtime(frequent))
+
+	// the oldest new Objects in excess of the queue's share are evicted first, then the
+	// least-recently-used frequent Objects, and finally the remaining new Objects
+	excess := len(in) - int(float64(len(objects))*twoQueueInRatio)
+	if excess < 0 {
+		excess = 0
+	}
+	n := copy(objects, in[:excess])
+	n += copy(objects[n:], frequent)
+	copy(objects[n:], in[excess:])
+}
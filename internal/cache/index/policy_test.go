@@ -0,0 +1,170 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package index
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+func testPolicyIndex(policy config.EvictionPolicy) *Index {
+	cfg := config.CacheIndexConfig{EvictionPolicy: policy, RefetchCostBytes: 100}
+	idx := NewIndex("test", "test", nil, cfg, testBulkRemoveFunc, nil)
+	// the size limits are set after the index is created so that
+	// the evictor does not run, and evictions are made by the test
+	idx.limits.maxObjects = 4
+	idx.limits.backoffObjects = 1
+	testBulkIndex = idx
+	return idx
+}
+
+func testPolicyOrder(idx *Index) []string {
+	objects := idx.snapshot()
+	idx.policy.sort(objects)
+	keys := make([]string, len(objects))
+	for i, o := range objects {
+		keys[i] = o.Key
+	}
+	return keys
+}
+
+func testExpectOrder(t *testing.T, idx *Index, expected ...string) {
+	t.Helper()
+	order := testPolicyOrder(idx)
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v got %v", expected, order)
+		}
+	}
+}
+
+func TestNewEvictionPolicy(t *testing.T) {
+
+	tests := []struct {
+		p        config.EvictionPolicy
+		expected string
+	}{
+		{config.EvictionPolicyLRU, "*index.lruPolicy"},
+		{config.EvictionPolicyLFU, "*index.greedyDualPolicy"},
+		{config.EvictionPolicy2Q, "*index.twoQueuePolicy"},
+		{config.EvictionPolicyGDS, "*index.greedyDualPolicy"},
+	}
+
+	for _, test := range tests {
+		p := newEvictionPolicy(config.CacheIndexConfig{EvictionPolicy: test.p})
+		if s := fmt.Sprintf("%T", p); s != test.expected {
+			t.Errorf("expected %s got %s", test.expected, s)
+		}
+	}
+}
+
+func TestLRUPolicy(t *testing.T) {
+	idx := testPolicyIndex(config.EvictionPolicyLRU)
+	idx.UpdateObject(&Object{Key: "test.1", Value: []byte("a")})
+	idx.UpdateObject(&Object{Key: "test.2", Value: []byte("a")})
+	idx.UpdateObject(&Object{Key: "test.3", Value: []byte("a")})
+	o1, _ := testObject(idx, "test.1")
+	o1.lastAccess = time.Now().Add(time.Minute).UnixNano()
+	o2, _ := testObject(idx, "test.2")
+	o2.lastAccess = time.Now().Add(-time.Minute).UnixNano()
+	testExpectOrder(t, idx, "test.2", "test.3", "test.1")
+}
+
+func TestLFUPolicy(t *testing.T) {
+	idx := testPolicyIndex(config.EvictionPolicyLFU)
+	idx.UpdateObject(&Object{Key: "test.1", Value: []byte("a")})
+	idx.UpdateObject(&Object{Key: "test.2", Value: []byte("a")})
+	idx.UpdateObject(&Object{Key: "test.3", Value: []byte("a")})
+
+	for i := 0; i < 3; i++ {
+		idx.UpdateObjectAccessTime("test.1")
+	}
+	idx.UpdateObjectAccessTime("test.3")
+
+	// the rewrite should carry over the hits from the previous object
+	idx.UpdateObject(&Object{Key: "test.3", Value: []byte("a")})
+
+	testExpectOrder(t, idx, "test.2", "test.3", "test.1")
+
+	// evicting the hot object raises the priority of the objects that follow
+	o, _ := testObject(idx, "test.1")
+	idx.policy.evicted(o.snapshot())
+	idx.UpdateObject(&Object{Key: "test.4", Value: []byte("a")})
+	testExpectOrder(t, idx, "test.2", "test.3", "test.1", "test.4")
+}
+
+func TestGDSPolicy(t *testing.T) {
+	idx := testPolicyIndex(config.EvictionPolicyGDS)
+	idx.UpdateObject(&Object{Key: "test.large", Value: make([]byte, 1000)})
+	idx.UpdateObject(&Object{Key: "test.small", Value: make([]byte, 10)})
+	idx.UpdateObject(&Object{Key: "test.medium", Value: make([]byte, 100)})
+	testExpectOrder(t, idx, "test.large", "test.medium", "test.small")
+
+	// enough accesses make the large object more valuable than the medium one
+	for i := 0; i < 2; i++ {
+		idx.UpdateObjectAccessTime("test.large")
+	}
+	testExpectOrder(t, idx, "test.medium", "test.large", "test.small")
+}
+
+func Test2QPolicy(t *testing.T) {
+	idx := testPolicyIndex(config.EvictionPolicy2Q)
+
+	idx.UpdateObject(&Object{Key: "test.1", Value: []byte("a")})
+	idx.UpdateObjectAccessTime("test.1")
+
+	// test.1 is in the FIFO queue, and is evicted first, despite its access
+	removals, _ := idx.selectEvictions(idx.snapshot())
+	if len(removals) != 0 {
+		t.Errorf("expected no removals got %v", removals)
+	}
+	for i := 2; i <= 5; i++ {
+		idx.UpdateObject(&Object{Key: "test." + strconv.Itoa(i), Value: []byte("a")})
+	}
+	removals, _ = idx.selectEvictions(idx.snapshot())
+	testBulkRemoveFunc(removals, false)
+	if len(removals) != 2 || removals[0] != "test.1" || removals[1] != "test.2" {
+		t.Errorf("expected [test.1 test.2] got %v", removals)
+	}
+
+	// test.1 is readmitted to the frequently-used queue
+	idx.UpdateObject(&Object{Key: "test.1", Value: []byte("a")})
+	if o, _ := testObject(idx, "test.1"); !o.frequent {
+		t.Errorf("expected %s to be frequent", "test.1")
+	}
+
+	// the FIFO objects beyond the queue's share are evicted ahead of test.1
+	testExpectOrder(t, idx, "test.3", "test.4", "test.1", "test.5")
+}
+
+func Test2QPolicyGhostLimit(t *testing.T) {
+	p := newEvictionPolicy(config.CacheIndexConfig{EvictionPolicy: config.EvictionPolicy2Q}).(*twoQueuePolicy)
+	p.sort(nil)
+	for i := 0; i < twoQueueMinGhosts+10; i++ {
+		p.evicted(&Object{Key: strconv.Itoa(i)})
+	}
+	if len(p.ghosts) != twoQueueMinGhosts {
+		t.Errorf("expected %d got %d", twoQueueMinGhosts, len(p.ghosts))
+	}
+	if p.ghosts["0"] {
+		t.Errorf("expected %s to be forgotten", "0")
+	}
+}
@@ -0,0 +1,120 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package index
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+// sizeLimits are the maximum sizes of a cache, or of an origin's share of it, and the reasons
+// recorded for the evictions that enforce them. A maximum of 0 is unlimited
+type sizeLimits struct {
+	maxBytes       int64
+	backoffBytes   int64
+	maxObjects     int64
+	backoffObjects int64
+	bytesReason    string
+	objectsReason  string
+}
+
+// exceeded returns the reason for evicting Objects from a cache or origin of the provided size,
+// or an empty string if it is within the limits
+func (l *sizeLimits) exceeded(bytes, objects int64) string {
+	if l.maxBytes > 0 && bytes > l.maxBytes {
+		return l.bytesReason
+	}
+	if l.maxObjects > 0 && objects > l.maxObjects {
+		return l.objectsReason
+	}
+	return ""
+}
+
+// originUsage tracks the size of an origin's share of the cache, and its quota, if it has one
+type originUsage struct {
+	// bytes and objects are updated atomically, and are first to ensure their alignment on 32-bit platforms
+	bytes   int64
+	objects int64
+
+	name   string
+	prefix string
+	limits *sizeLimits
+}
+
+// overQuota returns the reason for evicting the origin's Objects, or an empty string if it is within its quota
+func (u *originUsage) overQuota() string {
+	if u == nil || u.limits == nil {
+		return ""
+	}
+	return u.limits.exceeded(atomic.LoadInt64(&u.bytes), atomic.LoadInt64(&u.objects))
+}
+
+// add adjusts the origin's usage by the provided number of bytes and objects
+func (u *originUsage) add(idx *Index, bytes, objects int64) {
+	if u == nil {
+		return
+	}
+	b := atomic.AddInt64(&u.bytes, bytes)
+	o := atomic.AddInt64(&u.objects, objects)
+	cache.ObserveOriginCacheSizeChange(idx.name, idx.cacheType, u.name, b, o)
+}
+
+// newOriginUsages returns the usage trackers for the origins that use the cache, ordered so that
+// an origin whose key prefix begins with another origin's prefix is matched first
+func newOriginUsages(cacheName, cacheType string, cfg config.CacheIndexConfig) []*originUsage {
+
+	usages := make([]*originUsage, 0, len(cfg.OriginKeyPrefixes))
+	for name, prefix := range cfg.OriginKeyPrefixes {
+		u := &originUsage{name: name, prefix: prefix}
+		if q, ok := cfg.OriginQuotas[name]; ok && q != nil && (q.MaxSizeBytes > 0 || q.MaxSizeObjects > 0) {
+			u.limits = &sizeLimits{
+				maxBytes:       q.MaxSizeBytes,
+				backoffBytes:   q.MaxSizeBackoffBytes,
+				maxObjects:     q.MaxSizeObjects,
+				backoffObjects: q.MaxSizeBackoffObjects,
+				bytesReason:    "quota_bytes",
+				objectsReason:  "quota_objects",
+			}
+			metrics.CacheOriginMaxObjects.WithLabelValues(cacheName, cacheType, name).Set(float64(q.MaxSizeObjects))
+			metrics.CacheOriginMaxBytes.WithLabelValues(cacheName, cacheType, name).Set(float64(q.MaxSizeBytes))
+		}
+		usages = append(usages, u)
+	}
+
+	// origins sharing a key prefix share their objects, which are attributed to the first by name
+	sort.Slice(usages, func(i, j int) bool {
+		if len(usages[i].prefix) != len(usages[j].prefix) {
+			return len(usages[i].prefix) > len(usages[j].prefix)
+		}
+		return usages[i].name < usages[j].name
+	})
+
+	return usages
+}
+
+// owner returns the usage tracker of the origin that owns the Object with the provided key,
+// or nil if no origin using the cache owns it
+func (idx *Index) owner(key string) *originUsage {
+	for _, u := range idx.origins {
+		if strings.HasPrefix(key, u.prefix) {
+			return u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package index
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+func testQuotaConfig() config.CacheIndexConfig {
+	return config.CacheIndexConfig{
+		OriginKeyPrefixes: map[string]string{"quiet": "quiet.", "noisy": "noisy.", "nested": "noisy.nested."},
+		OriginQuotas: map[string]*config.CacheOriginQuotaConfig{
+			"noisy": {MaxSizeObjects: 4, MaxSizeBackoffObjects: 1},
+		},
+	}
+}
+
+// testWaitForObjects waits for the evictor to bring the origin's object count to the expected value
+func testWaitForObjects(u *originUsage, expected int64) int64 {
+	var count int64
+	for i := 0; i < 100; i++ {
+		if count = atomic.LoadInt64(&u.objects); count == expected {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return count
+}
+
+func TestSizeLimitsExceeded(t *testing.T) {
+
+	l := &sizeLimits{maxBytes: 10, maxObjects: 2, bytesReason: "bytes", objectsReason: "objects"}
+
+	tests := []struct {
+		bytes, objects int64
+		expected       string
+	}{
+		{10, 2, ""},
+		{11, 3, "bytes"},
+		{10, 3, "objects"},
+	}
+
+	for i, test := range tests {
+		if r := l.exceeded(test.bytes, test.objects); r != test.expected {
+			t.Errorf("test %d: expected %s got %s", i, test.expected, r)
+		}
+	}
+
+	l = &sizeLimits{}
+	if r := l.exceeded(100, 100); r != "" {
+		t.Errorf("expected no limits got %s", r)
+	}
+}
+
+func TestOriginOwnership(t *testing.T) {
+
+	idx := NewIndex("test", "test", nil, testQuotaConfig(), testBulkRemoveFunc, nil)
+	testBulkIndex = idx
+
+	idx.UpdateObject(&Object{Key: "quiet.1", Value: []byte("test_value")})
+	idx.UpdateObject(&Object{Key: "noisy.nested.1", Value: []byte("test_value")})
+	idx.UpdateObject(&Object{Key: "other.1", Value: []byte("test_value")})
+
+	for key, origin := range map[string]string{"quiet.1": "quiet", "noisy.nested.1": "nested", "other.1": ""} {
+		o, ok := testObject(idx, key)
+		if !ok {
+			t.Errorf("expected key %s to be present", key)
+			continue
+		}
+		if o.Origin != origin {
+			t.Errorf("expected %s got %s", origin, o.Origin)
+		}
+	}
+
+	u := idx.owner("quiet.1")
+	if u.name != "quiet" || u.objects != 1 || u.bytes != 10 {
+		t.Errorf("unexpected usage for origin quiet: %d objects %d bytes", u.objects, u.bytes)
+	}
+
+	// rewriting an object changes only its origin's size
+	idx.UpdateObject(&Object{Key: "quiet.1", Value: []byte("test")})
+	if u.objects != 1 || u.bytes != 4 {
+		t.Errorf("unexpected usage for origin quiet: %d objects %d bytes", u.objects, u.bytes)
+	}
+
+	idx.RemoveObject("quiet.1")
+	if u.objects != 0 || u.bytes != 0 {
+		t.Errorf("unexpected usage for origin quiet: %d objects %d bytes", u.objects, u.bytes)
+	}
+
+	// the owners of the objects in a serialized index are determined when it is loaded
+	idx2 := NewIndex("test", "test", idx.ToBytes(), testQuotaConfig(), testBulkRemoveFunc, nil)
+	if o, _ := testObject(idx2, "noisy.nested.1"); o.Origin != "nested" {
+		t.Errorf("expected %s got %s", "nested", o.Origin)
+	}
+	if u := idx2.owner("noisy.nested.1"); u.objects != 1 {
+		t.Errorf("expected %d got %d", 1, u.objects)
+	}
+}
+
+func TestOriginQuotaEviction(t *testing.T) {
+
+	cfg := testQuotaConfig()
+	cfg.MaxSizeObjects = 8
+
+	idx := NewIndex("test", "test", nil, cfg, testBulkRemoveFunc, nil)
+	testBulkIndex = idx
+
+	for i := 1; i <= 3; i++ {
+		idx.UpdateObject(&Object{Key: "quiet." + strconv.Itoa(i), Value: []byte("test_value")})
+	}
+	for i := 1; i <= 5; i++ {
+		idx.UpdateObject(&Object{Key: "noisy." + strconv.Itoa(i), Value: []byte("test_value")})
+	}
+
+	// the noisy origin exceeds its quota, so its objects are evicted down to the quota less the backoff,
+	// which brings the cache within its limit without evicting the quiet origin's objects
+	if count := testWaitForObjects(idx.owner("noisy.1"), 3); count != 3 {
+		t.Errorf("expected %d got %d", 3, count)
+	}
+
+	if u := idx.owner("quiet.1"); atomic.LoadInt64(&u.objects) != 3 {
+		t.Errorf("expected %d got %d", 3, atomic.LoadInt64(&u.objects))
+	}
+
+	if _, ok := testObject(idx, "noisy.5"); !ok {
+		t.Errorf("expected key %s to be present", "noisy.5")
+	}
+
+	if _, ok := testObject(idx, "noisy.1"); ok {
+		t.Errorf("expected key %s to be evicted", "noisy.1")
+	}
+}
+
+func TestQuotaThenCacheEviction(t *testing.T) {
+
+	cfg := testQuotaConfig()
+	cfg.MaxSizeObjects = 6
+	cfg.MaxSizeBackoffObjects = 1
+
+	idx := NewIndex("test", "test", nil, cfg, testBulkRemoveFunc, nil)
+	testBulkIndex = idx
+
+	idx.evictLock.Lock()
+	for i := 1; i <= 4; i++ {
+		idx.UpdateObject(&Object{Key: "quiet." + strconv.Itoa(i), Value: []byte("test_value")})
+	}
+	for i := 1; i <= 5; i++ {
+		idx.UpdateObject(&Object{Key: "noisy." + strconv.Itoa(i), Value: []byte("test_value")})
+	}
+
+	// the noisy origin is brought to 3 objects, and the cache, still over its limit
+	// with 7, is then brought to 5 objects by evicting from all origins
+	if !idx.evict() {
+		t.Errorf("expected evictions")
+	}
+	idx.evictLock.Unlock()
+
+	if count := atomic.LoadInt64(&idx.ObjectCount); count != 5 {
+		t.Errorf("expected %d got %d", 5, count)
+	}
+	if count := atomic.LoadInt64(&idx.owner("noisy.1").objects); count > 3 {
+		t.Errorf("expected no more than %d got %d", 3, count)
+	}
+}
+
+func TestHasQuotas(t *testing.T) {
+
+	idx := NewIndex("test", "test", nil, config.CacheIndexConfig{}, testBulkRemoveFunc, nil)
+	if idx.hasQuotas() {
+		t.Errorf("expected no quotas")
+	}
+
+	idx = NewIndex("test", "test", nil, testQuotaConfig(), testBulkRemoveFunc, nil)
+	if !idx.hasQuotas() {
+		t.Errorf("expected quotas")
+	}
+}
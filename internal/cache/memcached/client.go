@@ -0,0 +1,369 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss indicates the requested key was not found on the memcached server
+var ErrCacheMiss = errors.New("memcached: cache miss")
+
+// ErrNoServers indicates the client was created without any memcached servers
+var ErrNoServers = errors.New("memcached: no servers configured")
+
+// virtualNodes is the number of points each server is given on the consistent hash ring
+const virtualNodes = 160
+
+var (
+	crlf            = []byte("\r\n")
+	respStored      = []byte("STORED\r\n")
+	respNotStored   = []byte("NOT_STORED\r\n")
+	respDeleted     = []byte("DELETED\r\n")
+	respTouched     = []byte("TOUCHED\r\n")
+	respNotFound    = []byte("NOT_FOUND\r\n")
+	respEnd         = []byte("END\r\n")
+	respValue       = []byte("VALUE ")
+	respVersion     = []byte("VERSION ")
+	respError       = []byte("ERROR")
+	respClientError = []byte("CLIENT_ERROR ")
+	respServerError = []byte("SERVER_ERROR ")
+)
+
+// hashRing maps keys to servers using consistent hashing, so that adding or removing a
+// server only remaps the keys that hash to the points it owns on the ring
+type hashRing struct {
+	points  []uint32
+	servers map[uint32]string
+}
+
+func newHashRing(servers []string) *hashRing {
+	r := &hashRing{
+		points:  make([]uint32, 0, len(servers)*virtualNodes),
+		servers: make(map[uint32]string, len(servers)*virtualNodes),
+	}
+	for _, s := range servers {
+		for i := 0; i < virtualNodes; i++ {
+			p := crc32.ChecksumIEEE([]byte(s + "-" + strconv.Itoa(i)))
+			if _, ok := r.servers[p]; ok {
+				continue
+			}
+			r.servers[p] = s
+			r.points = append(r.points, p)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// server returns the server that owns the key
+func (r *hashRing) server(key string) string {
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.servers[r.points[i]]
+}
+
+// conn is a connection to a memcached server
+type conn struct {
+	nc net.Conn
+	rw *bufio.ReadWriter
+}
+
+// pool is a set of idle connections to a memcached server
+type pool struct {
+	addr  string
+	mtx   sync.Mutex
+	conns []*conn
+}
+
+// client is a memcached text protocol client that shards keys across servers
+type client struct {
+	ring         *hashRing
+	pools        map[string]*pool
+	maxIdle      int
+	dialTimeout  time.Duration
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func newClient(servers []string, maxIdle int, dialTimeout, readTimeout, writeTimeout time.Duration) (*client, error) {
+	if len(servers) == 0 {
+		return nil, ErrNoServers
+	}
+	c := &client{
+		ring:         newHashRing(servers),
+		pools:        make(map[string]*pool, len(servers)),
+		maxIdle:      maxIdle,
+		dialTimeout:  dialTimeout,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+	}
+	for _, s := range servers {
+		c.pools[s] = &pool{addr: s}
+	}
+	return c, nil
+}
+
+// getConn returns an idle connection to the server, or a new one if none are idle
+func (c *client) getConn(addr string) (*conn, error) {
+	p := c.pools[addr]
+	p.mtx.Lock()
+	if n := len(p.conns); n > 0 {
+		cn := p.conns[n-1]
+		p.conns = p.conns[:n-1]
+		p.mtx.Unlock()
+		return cn, nil
+	}
+	p.mtx.Unlock()
+	nc, err := net.DialTimeout("tcp", addr, c.dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{nc: nc, rw: bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc))}, nil
+}
+
+// release returns the connection to its server's pool, unless the operation failed in a way
+// that may have left unread data on it, in which case it is closed
+func (c *client) release(addr string, cn *conn, err error) {
+	if err != nil && err != ErrCacheMiss {
+		cn.nc.Close()
+		return
+	}
+	p := c.pools[addr]
+	p.mtx.Lock()
+	if len(p.conns) < c.maxIdle {
+		p.conns = append(p.conns, cn)
+		p.mtx.Unlock()
+		return
+	}
+	p.mtx.Unlock()
+	cn.nc.Close()
+}
+
+// do runs fn on a connection to the server, applying the read and write timeouts
+func (c *client) do(addr string, fn func(*bufio.ReadWriter) error) error {
+	cn, err := c.getConn(addr)
+	if err != nil {
+		return err
+	}
+	var wd, rd time.Time
+	now := time.Now()
+	if c.writeTimeout > 0 {
+		wd = now.Add(c.writeTimeout)
+	}
+	if c.readTimeout > 0 {
+		// the response is read once the request is written, so the read deadline follows the write deadline
+		rd = now.Add(c.writeTimeout + c.readTimeout)
+	}
+	cn.nc.SetWriteDeadline(wd)
+	cn.nc.SetReadDeadline(rd)
+	err = fn(cn.rw)
+	c.release(addr, cn, err)
+	return err
+}
+
+// exptime converts a ttl to a memcached expiration time. TTLs longer than 30 days
+// must be provided to memcached as an absolute unix timestamp
+func exptime(ttl time.Duration) int64 {
+	if ttl < time.Second {
+		return 1
+	}
+	if ttl > 30*24*time.Hour {
+		return time.Now().Add(ttl).Unix()
+	}
+	return int64(ttl / time.Second)
+}
+
+// readLine reads a response line, and returns an error for memcached error responses
+func readLine(rw *bufio.ReadWriter) ([]byte, error) {
+	line, err := rw.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(line, respClientError), bytes.HasPrefix(line, respServerError), bytes.HasPrefix(line, respError):
+		return nil, fmt.Errorf("memcached: %s", string(bytes.TrimSpace(line)))
+	}
+	return line, nil
+}
+
+// expect reads a response line and returns an error if it is not the expected response
+func expect(rw *bufio.ReadWriter, expected []byte) error {
+	line, err := readLine(rw)
+	if err != nil {
+		return err
+	}
+	switch {
+	case bytes.Equal(line, expected):
+		return nil
+	case bytes.Equal(line, respNotFound):
+		return ErrCacheMiss
+	case bytes.Equal(line, respNotStored):
+		return fmt.Errorf("memcached: item not stored")
+	}
+	return fmt.Errorf("memcached: unexpected response %q", string(line))
+}
+
+func (c *client) set(key string, value []byte, ttl time.Duration) error {
+	return c.do(c.ring.server(key), func(rw *bufio.ReadWriter) error {
+		if _, err := fmt.Fprintf(rw, "set %s 0 %d %d\r\n", key, exptime(ttl), len(value)); err != nil {
+			return err
+		}
+		rw.Write(value)
+		rw.Write(crlf)
+		if err := rw.Flush(); err != nil {
+			return err
+		}
+		return expect(rw, respStored)
+	})
+}
+
+func (c *client) delete(key string) error {
+	return c.do(c.ring.server(key), func(rw *bufio.ReadWriter) error {
+		if _, err := fmt.Fprintf(rw, "delete %s\r\n", key); err != nil {
+			return err
+		}
+		if err := rw.Flush(); err != nil {
+			return err
+		}
+		return expect(rw, respDeleted)
+	})
+}
+
+func (c *client) touch(key string, ttl time.Duration) error {
+	return c.do(c.ring.server(key), func(rw *bufio.ReadWriter) error {
+		if _, err := fmt.Fprintf(rw, "touch %s %d\r\n", key, exptime(ttl)); err != nil {
+			return err
+		}
+		if err := rw.Flush(); err != nil {
+			return err
+		}
+		return expect(rw, respTouched)
+	})
+}
+
+func (c *client) get(key string) ([]byte, error) {
+	m, err := c.getMulti([]string{key})
+	if err != nil {
+		return nil, err
+	}
+	v, ok := m[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return v, nil
+}
+
+// getMulti retrieves the keys with one request to each of the servers they map to.
+// Keys that are not found are absent from the returned map
+func (c *client) getMulti(keys []string) (map[string][]byte, error) {
+	byServer := make(map[string][]string)
+	for _, k := range keys {
+		s := c.ring.server(k)
+		byServer[s] = append(byServer[s], k)
+	}
+	m := make(map[string][]byte, len(keys))
+	for addr, ks := range byServer {
+		err := c.do(addr, func(rw *bufio.ReadWriter) error {
+			rw.WriteString("get")
+			for _, k := range ks {
+				rw.WriteString(" " + k)
+			}
+			rw.Write(crlf)
+			if err := rw.Flush(); err != nil {
+				return err
+			}
+			return readValues(rw, m)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// readValues reads VALUE responses into m until the END response
+func readValues(rw *bufio.ReadWriter, m map[string][]byte) error {
+	for {
+		line, err := readLine(rw)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(line, respEnd) {
+			return nil
+		}
+		if !bytes.HasPrefix(line, respValue) {
+			return fmt.Errorf("memcached: unexpected response %q", string(line))
+		}
+		// VALUE <key> <flags> <bytes>\r\n
+		var key string
+		var flags uint32
+		var size int
+		if _, err := fmt.Sscanf(string(line), "VALUE %s %d %d", &key, &flags, &size); err != nil {
+			return fmt.Errorf("memcached: unexpected response %q", string(line))
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(rw, value); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(value, crlf) {
+			return fmt.Errorf("memcached: corrupt value for key %s", key)
+		}
+		m[key] = value[:size]
+	}
+}
+
+// version checks that the server is reachable
+func (c *client) version(addr string) error {
+	return c.do(addr, func(rw *bufio.ReadWriter) error {
+		rw.WriteString("version\r\n")
+		if err := rw.Flush(); err != nil {
+			return err
+		}
+		line, err := readLine(rw)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasPrefix(line, respVersion) {
+			return fmt.Errorf("memcached: unexpected response %q", string(line))
+		}
+		return nil
+	})
+}
+
+// close closes all idle connections
+func (c *client) close() error {
+	for _, p := range c.pools {
+		p.mtx.Lock()
+		for _, cn := range p.conns {
+			cn.nc.Close()
+		}
+		p.conns = nil
+		p.mtx.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package memcached
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashRing(t *testing.T) {
+
+	servers := []string{"a:11211", "b:11211", "c:11211"}
+	r := newHashRing(servers)
+
+	if len(r.points) != len(servers)*virtualNodes {
+		t.Errorf("expected %d got %d", len(servers)*virtualNodes, len(r.points))
+	}
+
+	counts := make(map[string]int)
+	assigned := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		k := "key" + strconv.Itoa(i)
+		s := r.server(k)
+		counts[s]++
+		assigned[k] = s
+	}
+
+	for _, s := range servers {
+		if counts[s] < 500 {
+			t.Errorf("expected a balanced distribution, got %d keys for %s", counts[s], s)
+		}
+	}
+
+	// removing a server only remaps the keys it owned
+	r = newHashRing(servers[:2])
+	for k, s := range assigned {
+		if s != servers[2] && r.server(k) != s {
+			t.Errorf("expected %s to remain on %s, got %s", k, s, r.server(k))
+		}
+	}
+
+}
+
+func TestExptime(t *testing.T) {
+
+	if e := exptime(time.Duration(0)); e != 1 {
+		t.Errorf("expected %d got %d", 1, e)
+	}
+
+	if e := exptime(time.Hour); e != 3600 {
+		t.Errorf("expected %d got %d", 3600, e)
+	}
+
+	// longer than 30 days is an absolute timestamp
+	if e := exptime(time.Duration(60*24) * time.Hour); e < time.Now().Unix() {
+		t.Errorf("expected unix timestamp got %d", e)
+	}
+
+}
+
+func TestReadValues(t *testing.T) {
+
+	tests := []struct {
+		response string
+		expected int
+		err      bool
+	}{
+		{"VALUE a 0 1\r\n1\r\nVALUE b 0 2\r\n22\r\nEND\r\n", 2, false},
+		{"END\r\n", 0, false},
+		{"SERVER_ERROR out of memory\r\n", 0, true},
+		{"VALUE a 0 x\r\n", 0, true},
+		{"VALUE a 0 1\r\n12\r\nEND\r\n", 0, true},
+		{"VALUE a 0 1\r\n", 0, true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rw := bufio.NewReadWriter(bufio.NewReader(strings.NewReader(test.response)), nil)
+			m := make(map[string][]byte)
+			err := readValues(rw, m)
+			if (err != nil) != test.err {
+				t.Errorf("expected error %t got %v", test.err, err)
+			}
+			if !test.err && len(m) != test.expected {
+				t.Errorf("expected %d got %d", test.expected, len(m))
+			}
+		})
+	}
+
+}
+
+func TestExpect(t *testing.T) {
+
+	tests := []struct {
+		response string
+		err      error
+	}{
+		{"STORED\r\n", nil},
+		{"NOT_FOUND\r\n", ErrCacheMiss},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rw := bufio.NewReadWriter(bufio.NewReader(strings.NewReader(test.response)), nil)
+			if err := expect(rw, respStored); err != test.err {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+		})
+	}
+
+	for _, response := range []string{"NOT_STORED\r\n", "ERROR\r\n", "CLIENT_ERROR bad data chunk\r\n", "EXISTS\r\n"} {
+		rw := bufio.NewReadWriter(bufio.NewReader(strings.NewReader(response)), nil)
+		if err := expect(rw, respStored); err == nil {
+			t.Errorf("expected error for %q", response)
+		}
+	}
+
+}
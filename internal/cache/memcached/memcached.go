@@ -0,0 +1,290 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package memcached provides a Cache backed by one or more memcached servers
+package memcached
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// Memcached is the string "memcached"
+const Memcached = "memcached"
+
+// Each stored value begins with a byte indicating whether it holds the object's data, or
+// a manifest of the chunks the data was split into because it exceeded the item size limit
+const (
+	valueInline  = byte(0)
+	valueChunked = byte(1)
+)
+
+// itemOverheadBytes is reserved from the item size limit for the key and the server's item header
+const itemOverheadBytes = 1024
+
+// maxKeyLength is the length beyond which keys are hashed. Memcached limits keys to 250
+// bytes, and chunk keys are suffixed with the chunk's generation and index
+const maxKeyLength = 200
+
+// Cache represents a memcached cache object that conforms to the Cache interface
+type Cache struct {
+	Name   string
+	Config *config.CachingConfig
+
+	client *client
+}
+
+// manifest describes the chunks of an object that was split across multiple keys.
+// Each Store of a chunked object uses a new generation, so that readers never
+// reassemble chunks from different versions of the object
+type manifest struct {
+	generation int64
+	chunks     int
+	length     int
+}
+
+// Configuration returns the Configuration for the Cache object
+func (c *Cache) Configuration() *config.CachingConfig {
+	return c.Config
+}
+
+// Connect creates the memcached client and checks that each server is reachable
+func (c *Cache) Connect() error {
+	mc := c.Config.Memcached
+	log.Info("connecting to memcached", log.Pairs{"servers": strings.Join(mc.Servers, ",")})
+	var err error
+	c.client, err = newClient(mc.Servers, mc.PoolSize, durationFromMS(mc.DialTimeoutMS),
+		durationFromMS(mc.ReadTimeoutMS), durationFromMS(mc.WriteTimeoutMS))
+	if err != nil {
+		return err
+	}
+	for _, s := range mc.Servers {
+		if err = c.client.version(s); err != nil {
+			log.Error("memcached server is unreachable", log.Pairs{"server": s, "detail": err.Error()})
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkSize returns the largest number of bytes stored in a single item
+func (c *Cache) chunkSize() int {
+	if n := c.Config.Memcached.MaxItemSizeBytes - itemOverheadBytes; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// key returns the memcached key for the cacheKey. Keys that are too long for memcached
+// or contain whitespace or control characters are replaced with their hash
+func key(cacheKey string) string {
+	if len(cacheKey) <= maxKeyLength && strings.IndexFunc(cacheKey, func(r rune) bool { return r <= ' ' || r == 0x7f }) == -1 {
+		return cacheKey
+	}
+	h := md5.Sum([]byte(cacheKey))
+	return "md5." + hex.EncodeToString(h[:])
+}
+
+func chunkKey(k string, generation int64, i int) string {
+	return k + ".chunk." + strconv.FormatInt(generation, 36) + "." + strconv.Itoa(i)
+}
+
+func (m *manifest) chunkKeys(k string) []string {
+	keys := make([]string, m.chunks)
+	for i := range keys {
+		keys[i] = chunkKey(k, m.generation, i)
+	}
+	return keys
+}
+
+func (m *manifest) marshal() []byte {
+	b := make([]byte, 1+3*binary.MaxVarintLen64)
+	b[0] = valueChunked
+	n := 1
+	n += binary.PutVarint(b[n:], m.generation)
+	n += binary.PutVarint(b[n:], int64(m.chunks))
+	n += binary.PutVarint(b[n:], int64(m.length))
+	return b[:n]
+}
+
+// parseManifest returns the manifest stored in a chunked value, or nil if the value is not a valid manifest
+func parseManifest(b []byte) *manifest {
+	if len(b) == 0 || b[0] != valueChunked {
+		return nil
+	}
+	b = b[1:]
+	var vals [3]int64
+	for i := range vals {
+		v, n := binary.Varint(b)
+		if n <= 0 {
+			return nil
+		}
+		vals[i] = v
+		b = b[n:]
+	}
+	if vals[1] < 1 || vals[2] < 0 {
+		return nil
+	}
+	return &manifest{generation: vals[0], chunks: int(vals[1]), length: int(vals[2])}
+}
+
+// Store places the data into the memcached cache using the provided Key and TTL. Data larger
+// than the item size limit is split across multiple chunk keys, which are written before the
+// manifest at the object's key
+func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "set", "none", float64(len(data)))
+	log.Debug("memcached cache store", log.Pairs{"key": cacheKey})
+
+	k := key(cacheKey)
+	cs := c.chunkSize()
+	if len(data)+1 <= cs {
+		return c.client.set(k, append([]byte{valueInline}, data...), ttl)
+	}
+
+	m := &manifest{generation: time.Now().UnixNano(), chunks: (len(data) + cs - 1) / cs, length: len(data)}
+	for i, ck := range m.chunkKeys(k) {
+		end := (i + 1) * cs
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := c.client.set(ck, data[i*cs:end], ttl); err != nil {
+			return err
+		}
+	}
+	return c.client.set(k, m.marshal(), ttl)
+}
+
+// Retrieve gets data from the memcached cache using the provided Key, reassembling it from its chunks
+// if necessary. Because memcached manages Object Expiration internally, allowExpired is not used.
+func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	k := key(cacheKey)
+	b, err := c.client.get(k)
+	if err == nil {
+		b, err = c.reassemble(k, b)
+	}
+
+	if err == nil {
+		log.Debug("memcached cache retrieve", log.Pairs{"key": cacheKey})
+		cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(b)))
+		return b, status.LookupStatusHit, nil
+	}
+
+	if err == ErrCacheMiss {
+		log.Debug("memcached cache miss", log.Pairs{"key": cacheKey})
+		cache.ObserveCacheMiss(cacheKey, c.Name, c.Config.CacheType)
+		return nil, status.LookupStatusKeyMiss, cache.ErrKNF
+	}
+
+	log.Debug("memcached cache retrieve failed", log.Pairs{"key": cacheKey, "reason": err.Error()})
+	cache.ObserveCacheMiss(cacheKey, c.Name, c.Config.CacheType)
+	return nil, status.LookupStatusError, err
+}
+
+// reassemble returns the object's data from the value stored at its key, retrieving its chunks if it was split.
+// If any chunk has been evicted, the object is considered a cache miss
+func (c *Cache) reassemble(k string, b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, ErrCacheMiss
+	}
+	if b[0] == valueInline {
+		return b[1:], nil
+	}
+	m := parseManifest(b)
+	if m == nil {
+		return nil, ErrCacheMiss
+	}
+	keys := m.chunkKeys(k)
+	chunks, err := c.client.getMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, m.length)
+	for _, ck := range keys {
+		chunk, ok := chunks[ck]
+		if !ok {
+			return nil, ErrCacheMiss
+		}
+		data = append(data, chunk...)
+	}
+	if len(data) != m.length {
+		return nil, ErrCacheMiss
+	}
+	return data, nil
+}
+
+// chunkKeys returns the chunk keys of the object stored at k, if it was split
+func (c *Cache) chunkKeys(k string) []string {
+	b, err := c.client.get(k)
+	if err != nil {
+		return nil
+	}
+	if m := parseManifest(b); m != nil {
+		return m.chunkKeys(k)
+	}
+	return nil
+}
+
+// Remove removes an object and its chunks from the cache, if present
+func (c *Cache) Remove(cacheKey string) {
+	log.Debug("memcached cache remove", log.Pairs{"key": cacheKey})
+	c.remove(cacheKey)
+	cache.ObserveCacheDel(c.Name, c.Config.CacheType, 0)
+}
+
+func (c *Cache) remove(cacheKey string) {
+	k := key(cacheKey)
+	for _, ck := range c.chunkKeys(k) {
+		c.client.delete(ck)
+	}
+	c.client.delete(k)
+}
+
+// SetTTL updates the TTL for the provided cache object and its chunks
+func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
+	k := key(cacheKey)
+	for _, ck := range c.chunkKeys(k) {
+		c.client.touch(ck, ttl)
+	}
+	c.client.touch(k, ttl)
+}
+
+// BulkRemove removes a list of objects from the cache. noLock is not used for memcached
+func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
+	log.Debug("memcached cache bulk remove", log.Pairs{})
+	for _, cacheKey := range cacheKeys {
+		c.remove(cacheKey)
+	}
+	cache.ObserveCacheDel(c.Name, c.Config.CacheType, float64(len(cacheKeys)))
+}
+
+// Close closes the idle connections to the memcached servers
+func (c *Cache) Close() error {
+	log.Info("closing memcached connections", log.Pairs{})
+	if c.client == nil {
+		return nil
+	}
+	return c.client.close()
+}
+
+func durationFromMS(input int) time.Duration {
+	return time.Duration(int64(input)) * time.Millisecond
+}
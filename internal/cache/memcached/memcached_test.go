@@ -0,0 +1,367 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+const cacheKey = `cacheKey`
+
+// testServer is an in-process stand-in for a memcached server, implementing
+// the subset of the text protocol used by the client
+type testServer struct {
+	ln    net.Listener
+	mtx   sync.Mutex
+	items map[string]*testItem
+}
+
+type testItem struct {
+	value      []byte
+	expiration time.Time
+}
+
+func newTestServer(t testing.TB) *testServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{ln: ln, items: make(map[string]*testItem)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *testServer) Close() {
+	s.ln.Close()
+}
+
+func (s *testServer) Len() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.items)
+}
+
+func (s *testServer) expiration(exptime string) time.Time {
+	e, _ := strconv.ParseInt(exptime, 10, 64)
+	if e == 0 {
+		return time.Time{}
+	}
+	if e > 30*24*60*60 {
+		return time.Unix(e, 0)
+	}
+	return time.Now().Add(time.Duration(e) * time.Second)
+}
+
+func (s *testServer) lookup(key string) *testItem {
+	it, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+	if !it.expiration.IsZero() && it.expiration.Before(time.Now()) {
+		delete(s.items, key)
+		return nil
+	}
+	return it
+}
+
+func (s *testServer) serve(c net.Conn) {
+	defer c.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			rw.WriteString("ERROR\r\n")
+			rw.Flush()
+			continue
+		}
+		s.mtx.Lock()
+		switch {
+		case f[0] == "set" && len(f) == 5:
+			n, _ := strconv.Atoi(f[4])
+			b := make([]byte, n+2)
+			if _, err := io.ReadFull(rw, b); err != nil {
+				s.mtx.Unlock()
+				return
+			}
+			s.items[f[1]] = &testItem{value: b[:n], expiration: s.expiration(f[3])}
+			rw.WriteString("STORED\r\n")
+		case f[0] == "get":
+			for _, k := range f[1:] {
+				if it := s.lookup(k); it != nil {
+					fmt.Fprintf(rw, "VALUE %s 0 %d\r\n", k, len(it.value))
+					rw.Write(it.value)
+					rw.WriteString("\r\n")
+				}
+			}
+			rw.WriteString("END\r\n")
+		case f[0] == "delete" && len(f) == 2:
+			if it := s.lookup(f[1]); it != nil {
+				delete(s.items, f[1])
+				rw.WriteString("DELETED\r\n")
+			} else {
+				rw.WriteString("NOT_FOUND\r\n")
+			}
+		case f[0] == "touch" && len(f) == 3:
+			if it := s.lookup(f[1]); it != nil {
+				it.expiration = s.expiration(f[2])
+				rw.WriteString("TOUCHED\r\n")
+			} else {
+				rw.WriteString("NOT_FOUND\r\n")
+			}
+		case f[0] == "version":
+			rw.WriteString("VERSION test\r\n")
+		default:
+			rw.WriteString("ERROR\r\n")
+		}
+		s.mtx.Unlock()
+		rw.Flush()
+	}
+}
+
+func setupMemcachedCache(t testing.TB, servers int) (*Cache, []*testServer) {
+	log.Logger = log.ConsoleLogger("none")
+	ts := make([]*testServer, servers)
+	mc := config.NewCacheConfig().Memcached
+	mc.Servers = make([]string, servers)
+	for i := range ts {
+		ts[i] = newTestServer(t)
+		mc.Servers[i] = ts[i].Addr()
+	}
+	mc.MaxItemSizeBytes = itemOverheadBytes + 64
+	cacheConfig := &config.CachingConfig{CacheType: "memcached", Memcached: mc}
+	c := &Cache{Name: "test", Config: cacheConfig}
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return c, ts
+}
+
+func closeTestServers(ts []*testServer) {
+	for _, s := range ts {
+		s.Close()
+	}
+}
+
+func TestConfiguration(t *testing.T) {
+	c, ts := setupMemcachedCache(t, 1)
+	defer closeTestServers(ts)
+	defer c.Close()
+	if c.Configuration().CacheType != "memcached" {
+		t.Errorf("expected %s got %s", "memcached", c.Configuration().CacheType)
+	}
+}
+
+func TestConnectFailed(t *testing.T) {
+	cfg := config.NewCacheConfig()
+	cfg.Memcached.Servers = nil
+	c := &Cache{Name: "test", Config: cfg}
+	if err := c.Connect(); err != ErrNoServers {
+		t.Errorf("expected %v got %v", ErrNoServers, err)
+	}
+
+	s := newTestServer(t)
+	s.Close()
+	cfg.Memcached.Servers = []string{s.Addr()}
+	if err := c.Connect(); err == nil {
+		t.Errorf("expected error for unreachable server %s", s.Addr())
+	}
+}
+
+func TestStoreRetrieve(t *testing.T) {
+
+	c, ts := setupMemcachedCache(t, 3)
+	defer closeTestServers(ts)
+	defer c.Close()
+
+	_, s, err := c.Retrieve(cacheKey, false)
+	if err != cache.ErrKNF || s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+	tests := [][]byte{
+		[]byte("data"),
+		{},
+		bytes.Repeat([]byte("0123456789"), 100), // split into 16 chunks
+	}
+
+	for i, data := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := c.Store(cacheKey, data, time.Minute)
+			if err != nil {
+				t.Error(err)
+			}
+			b, s, err := c.Retrieve(cacheKey, false)
+			if err != nil || s != status.LookupStatusHit {
+				t.Errorf("expected %s got %s", status.LookupStatusHit, s)
+			}
+			if !bytes.Equal(b, data) {
+				t.Errorf("expected %s got %s", string(data), string(b))
+			}
+		})
+	}
+
+	// the chunks were distributed across the servers
+	for _, s := range ts {
+		if s.Len() == 0 {
+			t.Errorf("expected items on server %s", s.Addr())
+		}
+	}
+
+}
+
+func TestRetrieveMissingChunk(t *testing.T) {
+
+	c, ts := setupMemcachedCache(t, 1)
+	defer closeTestServers(ts)
+	defer c.Close()
+
+	c.Store(cacheKey, bytes.Repeat([]byte("a"), 200), time.Minute)
+	ck := c.chunkKeys(key(cacheKey))
+	if len(ck) != 4 {
+		t.Fatalf("expected %d got %d", 4, len(ck))
+	}
+
+	c.client.delete(ck[2])
+	_, s, err := c.Retrieve(cacheKey, false)
+	if err != cache.ErrKNF || s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+}
+
+func TestRemove(t *testing.T) {
+
+	c, ts := setupMemcachedCache(t, 2)
+	defer closeTestServers(ts)
+	defer c.Close()
+
+	c.Store(cacheKey, bytes.Repeat([]byte("a"), 200), time.Minute)
+	c.Store(cacheKey+"2", []byte("data"), time.Minute)
+
+	c.Remove(cacheKey)
+	_, s, _ := c.Retrieve(cacheKey, false)
+	if s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+	// the chunks were removed along with the manifest
+	if n := ts[0].Len() + ts[1].Len(); n != 1 {
+		t.Errorf("expected %d got %d", 1, n)
+	}
+
+	c.BulkRemove([]string{cacheKey + "2", "missing"}, true)
+	if n := ts[0].Len() + ts[1].Len(); n != 0 {
+		t.Errorf("expected %d got %d", 0, n)
+	}
+
+}
+
+func TestSetTTL(t *testing.T) {
+
+	c, ts := setupMemcachedCache(t, 1)
+	defer closeTestServers(ts)
+	defer c.Close()
+
+	c.Store(cacheKey, bytes.Repeat([]byte("a"), 200), time.Minute)
+	c.SetTTL(cacheKey, time.Hour)
+
+	ts[0].mtx.Lock()
+	for k, it := range ts[0].items {
+		if time.Until(it.expiration) < time.Minute {
+			t.Errorf("expected ttl of %s for %s, got %s", time.Hour, k, time.Until(it.expiration))
+		}
+	}
+	ts[0].mtx.Unlock()
+
+	c.SetTTL(cacheKey, time.Duration(0))
+	time.Sleep(time.Duration(1100) * time.Millisecond)
+	_, s, _ := c.Retrieve(cacheKey, false)
+	if s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+}
+
+func TestKey(t *testing.T) {
+
+	if key(cacheKey) != cacheKey {
+		t.Errorf("expected %s got %s", cacheKey, key(cacheKey))
+	}
+
+	for _, k := range []string{"key with spaces", strings.Repeat("a", 251)} {
+		if h := key(k); len(h) != 36 || !strings.HasPrefix(h, "md5.") {
+			t.Errorf("expected hashed key got %s", h)
+		}
+	}
+
+}
+
+func BenchmarkCache_Store(b *testing.B) {
+	c, ts := setupMemcachedCache(b, 1)
+	defer closeTestServers(ts)
+	defer c.Close()
+	for n := 0; n < b.N; n++ {
+		if err := c.Store(cacheKey+strconv.Itoa(n), []byte("data"+strconv.Itoa(n)), time.Minute); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func BenchmarkCache_Retrieve(b *testing.B) {
+	c, ts := setupMemcachedCache(b, 1)
+	defer closeTestServers(ts)
+	defer c.Close()
+	c.Store(cacheKey, []byte("data"), time.Minute)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, _, err := c.Retrieve(cacheKey, false); err != nil {
+			b.Error(err)
+		}
+	}
+}
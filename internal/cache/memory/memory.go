@@ -114,6 +114,31 @@ func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.Loo
 	return nil, s, nil
 }
 
+// Expiration returns the expiration of an object in cache, or the zero time if it is not found
+func (c *Cache) Expiration(cacheKey string) time.Time {
+	return c.Index.GetExpiration(cacheKey)
+}
+
+// Peek returns an object in cache, expired or not, without updating its access time
+func (c *Cache) Peek(cacheKey string) ([]byte, status.LookupStatus, error) {
+	o, s, err := c.retrieve(cacheKey, true, false)
+	if err != nil {
+		return nil, s, err
+	}
+	return o.Value, s, nil
+}
+
+// PeekReference returns an object reference in cache, expired or not, without updating its access time
+func (c *Cache) PeekReference(cacheKey string) (interface{}, status.LookupStatus, error) {
+	o, s, err := c.retrieve(cacheKey, true, false)
+	if err != nil {
+		return nil, s, err
+	}
+	return o.ReferenceValue, s, nil
+}
+
+// retrieve looks for an object in cache. When atime is false, the object is only peeked at, so
+// its access time is not updated and the lookup is not recorded as a hit
 func (c *Cache) retrieve(cacheKey string, allowExpired bool, atime bool) (*index.Object, status.LookupStatus, error) {
 
 	locks.Acquire(lockPrefix + cacheKey)
@@ -128,13 +153,13 @@ func (c *Cache) retrieve(cacheKey string, allowExpired bool, atime bool) (*index
 			log.Debug("memory cache retrieve", log.Pairs{"cacheKey": cacheKey})
 			if atime {
 				c.Index.UpdateObjectAccessTime(cacheKey)
+				cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(o.Value)))
 			}
-			cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(o.Value)))
 			locks.Release(lockPrefix + cacheKey)
 			return o, status.LookupStatusHit, nil
 		}
 		// Cache Object has been expired but not reaped, go ahead and delete it
-		go c.remove(cacheKey, false)
+		go c.remove(cacheKey)
 	}
 	locks.Release(lockPrefix + cacheKey)
 	_, err := cache.ObserveCacheMiss(cacheKey, c.Name, c.Config.CacheType)
@@ -149,24 +174,29 @@ func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
 
 // Remove removes an object from the cache
 func (c *Cache) Remove(cacheKey string) {
-	c.remove(cacheKey, false)
+	c.remove(cacheKey)
 }
 
-func (c *Cache) remove(cacheKey string, noLock bool) {
+func (c *Cache) remove(cacheKey string) {
 	locks.Acquire(lockPrefix + cacheKey)
 	c.client.Delete(cacheKey)
-	c.Index.RemoveObject(cacheKey, noLock)
+	c.Index.RemoveObject(cacheKey)
 	cache.ObserveCacheDel(c.Name, c.Config.CacheType, 0)
 	locks.Release(lockPrefix + cacheKey)
 }
 
-// BulkRemove removes a list of objects from the cache
+// BulkRemove removes a list of objects from the cache. noLock is not used for Memory
 func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
 	for _, cacheKey := range cacheKeys {
-		c.remove(cacheKey, noLock)
+		c.remove(cacheKey)
 	}
 }
 
+// ListObjects returns the metadata of the objects in the cache, as recorded in the Index
+func (c *Cache) ListObjects() ([]cache.ObjectMetadata, error) {
+	return c.Index.ListObjects(), nil
+}
+
 // Close is not used for Cache, and is here to fully prototype the Cache Interface
 func (c *Cache) Close() error {
 	return nil
@@ -197,6 +197,46 @@ func TestCache_Retrieve(t *testing.T) {
 	}
 }
 
+func TestCache_Peek(t *testing.T) {
+
+	cacheConfig := newCacheConfig(t)
+	mc := Cache{Config: &cacheConfig}
+
+	err := mc.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = mc.Store(cacheKey, []byte("data"), time.Duration(60)*time.Second)
+	if err != nil {
+		t.Error(err)
+	}
+	lastAccess := mc.Index.ListObjects()[0].LastAccess
+
+	// expire the object, which is still returned, without updating its access time
+	mc.SetTTL(cacheKey, -1*time.Hour)
+	time.Sleep(time.Millisecond)
+
+	data, ls, err := mc.Peek(cacheKey)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(data) != "data" {
+		t.Errorf("wanted \"%s\". got \"%s\"", "data", data)
+	}
+	if ls != status.LookupStatusHit {
+		t.Errorf("expected %s got %s", status.LookupStatusHit, ls)
+	}
+	if objects := mc.Index.ListObjects(); len(objects) != 1 || !objects[0].LastAccess.Equal(lastAccess) {
+		t.Errorf("expected last access time %s got %v", lastAccess, objects)
+	}
+
+	_, ls, err = mc.PeekReference("missing")
+	if err == nil || ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+}
+
 func BenchmarkCache_Retrieve(b *testing.B) {
 	mc := storeBenchmark(b)
 
@@ -0,0 +1,57 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package redis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// objectMagic begins each object the cache stores in Redis, ahead of the object's expiration
+var objectMagic = []byte("trk\x01")
+
+// objectHeaderSize is the length of the magic and expiration that precede an object's value
+const objectHeaderSize = 12
+
+// marshalObject returns the object's value, preceded by its expiration
+func marshalObject(data []byte, expiration time.Time) []byte {
+	b := make([]byte, objectHeaderSize+len(data))
+	copy(b, objectMagic)
+	copy(b[len(objectMagic):], marshalExpiration(expiration))
+	copy(b[objectHeaderSize:], data)
+	return b
+}
+
+// marshalExpiration encodes the expiration in Unix nanoseconds, or 0 if it is zero
+func marshalExpiration(expiration time.Time) []byte {
+	b := make([]byte, objectHeaderSize-len(objectMagic))
+	if !expiration.IsZero() {
+		binary.BigEndian.PutUint64(b, uint64(expiration.UnixNano()))
+	}
+	return b
+}
+
+// unmarshalObject returns the value of an object as stored by marshalObject, and its expiration.
+// Values stored by earlier versions of Trickster have no expiration, and are returned unaltered
+func unmarshalObject(b []byte) ([]byte, time.Time) {
+	if len(b) < objectHeaderSize || !bytes.HasPrefix(b, objectMagic) {
+		return b, time.Time{}
+	}
+	var expiration time.Time
+	if n := binary.BigEndian.Uint64(b[len(objectMagic):objectHeaderSize]); n > 0 {
+		expiration = time.Unix(0, int64(n))
+	}
+	return b[objectHeaderSize:], expiration
+}
@@ -14,6 +14,9 @@
 package redis
 
 import (
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -32,8 +35,9 @@ type Cache struct {
 	Name   string
 	Config *config.CachingConfig
 
-	client redis.Cmdable
-	closer func() error
+	client      redis.Cmdable
+	closer      func() error
+	clientCache *clientCache
 }
 
 // Configuration returns the Configuration for the Cache object
@@ -71,26 +75,59 @@ func (c *Cache) Connect() error {
 		c.closer = client.Close
 		c.client = client
 	}
-	return c.client.Ping().Err()
+	if err := c.client.Ping().Err(); err != nil {
+		return err
+	}
+	if c.Config.Redis.ClientCacheMaxObjects > 0 {
+		return c.startClientCache()
+	}
+	return nil
 }
 
-// Store places the the data into the Redis Cache using the provided Key and TTL
+// Store places the the data into the Redis Cache using the provided Key and TTL. The object's expiration
+// is stored with it, and Redis retains it for the configured stale retention period beyond its TTL,
+// so that it can still be retrieved for revalidation once it has expired
 func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
 	cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "set", "none", float64(len(data)))
 	log.Debug("redis cache store", log.Pairs{"key": cacheKey})
-	return c.client.Set(cacheKey, data, ttl).Err()
+	var expiration time.Time
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl)
+	}
+	key := c.key(cacheKey)
+	err := c.client.Set(key, marshalObject(data, expiration), c.retention(ttl)).Err()
+	c.clientCache.remove(key)
+	return err
 }
 
-// Retrieve gets data from the Redis Cache using the provided Key
-// because Redis manages Object Expiration internally, allowExpired is not used.
+// Retrieve gets data from the Redis Cache using the provided Key. Objects that have expired,
+// but are still retained by Redis, are returned only when allowExpired is true
 func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
-	res, err := c.client.Get(cacheKey).Result()
+
+	key := c.key(cacheKey)
+
+	var err error
+	b, ok := c.clientCache.get(key)
+	if !ok {
+		seq := c.clientCache.sequence()
+		var res string
+		res, err = c.client.Get(key).Result()
+		if err == nil {
+			b = []byte(res)
+			c.clientCache.set(key, b, seq)
+		}
+	}
 
 	if err == nil {
-		data := []byte(res)
-		log.Debug("redis cache retrieve", log.Pairs{"key": cacheKey})
-		cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(data)))
-		return data, status.LookupStatusHit, nil
+		data, expiration := unmarshalObject(b)
+		if allowExpired || expiration.IsZero() || expiration.After(time.Now()) {
+			log.Debug("redis cache retrieve", log.Pairs{"key": cacheKey})
+			cache.ObserveCacheOperation(c.Name, c.Config.CacheType, "get", "hit", float64(len(data)))
+			return data, status.LookupStatusHit, nil
+		}
+		log.Debug("redis cache miss on expired object", log.Pairs{"key": cacheKey})
+		_, err = cache.ObserveCacheMiss(cacheKey, c.Name, c.Config.CacheType)
+		return nil, status.LookupStatusKeyMiss, err
 	}
 
 	if err == redis.Nil {
@@ -107,28 +144,177 @@ func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.Loo
 // Remove removes an object in cache, if present
 func (c *Cache) Remove(cacheKey string) {
 	log.Debug("redis cache remove", log.Pairs{"key": cacheKey})
-	c.client.Del(cacheKey)
+	key := c.key(cacheKey)
+	c.client.Del(key)
+	c.clientCache.remove(key)
 	cache.ObserveCacheDel(c.Name, c.Config.CacheType, 0)
 }
 
+// setTTLScript updates the expiration stored with an object, if the object has one, and its Redis TTL
+var setTTLScript = redis.NewScript(`
+if redis.call('GETRANGE', KEYS[1], 0, ` + strconv.Itoa(len(objectMagic)-1) + `) == ARGV[1] then
+	redis.call('SETRANGE', KEYS[1], ` + strconv.Itoa(len(objectMagic)) + `, ARGV[2])
+end
+if tonumber(ARGV[3]) > 0 then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return redis.call('PERSIST', KEYS[1])
+`)
+
 // SetTTL updates the TTL for the provided cache object
 func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
-	c.client.Expire(cacheKey, ttl)
+	var expiration time.Time
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl)
+	}
+	key := c.key(cacheKey)
+	setTTLScript.Run(c.client, []string{key}, objectMagic, marshalExpiration(expiration),
+		int64(c.retention(ttl)/time.Millisecond))
+	c.clientCache.remove(key)
 }
 
 // BulkRemove removes a list of objects from the cache. noLock is not used for Redis
 func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
 	log.Debug("redis cache bulk remove", log.Pairs{})
-	c.client.Del(cacheKeys...)
+	keys := make([]string, len(cacheKeys))
+	for i, k := range cacheKeys {
+		keys[i] = c.key(k)
+		c.clientCache.remove(keys[i])
+	}
+	c.client.Del(keys...)
 	cache.ObserveCacheDel(c.Name, c.Config.CacheType, float64(len(cacheKeys)))
 }
 
+// Expiration returns the expiration of an object in cache, or the zero time if it has none or is not
+// found. It is derived from the object's Redis TTL, which includes the stale retention period.
+func (c *Cache) Expiration(cacheKey string) time.Time {
+	ttl, err := c.client.PTTL(c.key(cacheKey)).Result()
+	// PTTL is -2 for keys that do not exist, and -1 for keys with no expiration
+	if err != nil || ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl - time.Duration(c.Config.Redis.StaleRetentionSecs)*time.Second)
+}
+
+// ListObjects scans the keys in the Redis database and returns their sizes and expirations.
+// Only keys in the cache's namespace are listed, if it has one. Redis does not track when
+// keys were written or accessed, so those times are not reported.
+func (c *Cache) ListObjects() ([]cache.ObjectMetadata, error) {
+	if cc, ok := c.client.(*redis.ClusterClient); ok {
+		var mtx sync.Mutex
+		objects := make([]cache.ObjectMetadata, 0)
+		err := cc.ForEachMaster(func(client *redis.Client) error {
+			o, err := c.scanObjects(client)
+			mtx.Lock()
+			objects = append(objects, o...)
+			mtx.Unlock()
+			return err
+		})
+		return objects, err
+	}
+	return c.scanObjects(c.client)
+}
+
+// scanCount is the number of keys requested in each SCAN when listing objects
+const scanCount = 1000
+
+func (c *Cache) scanObjects(client redis.Cmdable) ([]cache.ObjectMetadata, error) {
+
+	objects := make([]cache.ObjectMetadata, 0)
+
+	var match string
+	if c.Config.Redis.KeyNamespace != "" {
+		match = escapePattern(c.key("")) + "*"
+	}
+	staleRetention := time.Duration(c.Config.Redis.StaleRetentionSecs) * time.Second
+
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(cursor, match, scanCount).Result()
+		if err != nil {
+			return objects, err
+		}
+
+		if len(keys) > 0 {
+			pipe := client.Pipeline()
+			sizes := make([]*redis.IntCmd, len(keys))
+			ttls := make([]*redis.DurationCmd, len(keys))
+			for i, key := range keys {
+				sizes[i] = pipe.StrLen(key)
+				ttls[i] = pipe.PTTL(key)
+			}
+			// errors are checked per command, since keys may be removed after they are scanned
+			pipe.Exec()
+
+			now := time.Now()
+			for i, key := range keys {
+				size, err := sizes[i].Result()
+				if err != nil {
+					continue
+				}
+				ttl, err := ttls[i].Result()
+				// PTTL is -2 for keys that no longer exist, and -1 for keys with no expiration
+				if err != nil || ttl == -2*time.Millisecond {
+					continue
+				}
+				if size >= objectHeaderSize {
+					size -= objectHeaderSize
+				}
+				o := cache.ObjectMetadata{Key: strings.TrimPrefix(key, c.key("")), Size: size}
+				if ttl > 0 {
+					// objects that are only retained for revalidation have already expired
+					if ttl <= staleRetention {
+						continue
+					}
+					o.Expiration = now.Add(ttl - staleRetention)
+				}
+				objects = append(objects, o)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return objects, nil
+		}
+	}
+}
+
 // Close disconnects from the Redis Cache
 func (c *Cache) Close() error {
 	log.Info("closing redis connection", log.Pairs{})
+	c.clientCache.close()
 	return c.closer()
 }
 
+// key returns the Redis key of the cache object, in the cache's namespace if it has one
+func (c *Cache) key(cacheKey string) string {
+	if c.Config.Redis.KeyNamespace == "" {
+		return cacheKey
+	}
+	return c.Config.Redis.KeyNamespace + ":" + cacheKey
+}
+
+// retention returns the Redis TTL of an object with the provided TTL
+func (c *Cache) retention(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return 0
+	}
+	return ttl + time.Duration(c.Config.Redis.StaleRetentionSecs)*time.Second
+}
+
+// escapePattern escapes the characters in s that have special meaning in a Redis glob-style pattern
+func escapePattern(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 func durationFromMS(input int) time.Duration {
 	return time.Duration(int64(input)) * time.Millisecond
 }
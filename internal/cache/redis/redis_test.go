@@ -316,6 +316,43 @@ func TestRedisCache_Store(t *testing.T) {
 	}
 }
 
+func TestRedisCache_ListObjects(t *testing.T) {
+	rc, close := setupRedisCache(clientTypeStandard)
+	defer close()
+
+	err := rc.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+
+	rc.Store(cacheKey, []byte("data"), time.Duration(60)*time.Second)
+	rc.Store(cacheKey+"2", []byte("more data"), 0)
+
+	objects, err := rc.ListObjects()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(objects) != 2 {
+		t.Fatalf("expected %d got %d", 2, len(objects))
+	}
+
+	for _, o := range objects {
+		switch o.Key {
+		case cacheKey:
+			if o.Size != 4 || o.Expiration.IsZero() {
+				t.Errorf("unexpected object metadata %v", o)
+			}
+		case cacheKey + "2":
+			if o.Size != 9 || !o.Expiration.IsZero() {
+				t.Errorf("unexpected object metadata %v", o)
+			}
+		default:
+			t.Errorf("unexpected key %s", o.Key)
+		}
+	}
+}
+
 func BenchmarkCache_Store(b *testing.B) {
 	rc, close := storeBenchmark(b)
 	if rc == nil {
@@ -517,3 +554,145 @@ func BenchmarkCache_BulkRemove(b *testing.B) {
 		}
 	}
 }
+
+func TestRedisCache_RetrieveExpired(t *testing.T) {
+	rc, close := setupRedisCache(clientTypeStandard)
+	defer close()
+	rc.Config.Redis.StaleRetentionSecs = 60
+
+	err := rc.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+	defer rc.Close()
+
+	err = rc.Store(cacheKey, []byte("data"), time.Duration(10)*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// an expired object is a miss unless expired objects are allowed
+	_, ls, err := rc.Retrieve(cacheKey, false)
+	if err == nil {
+		t.Errorf("expected key not found error for %s", cacheKey)
+	}
+	if ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, ls)
+	}
+
+	data, ls, err := rc.Retrieve(cacheKey, true)
+	if err != nil {
+		t.Error(err)
+	}
+	if ls != status.LookupStatusHit {
+		t.Errorf("expected %s got %s", status.LookupStatusHit, ls)
+	}
+	if string(data) != "data" {
+		t.Errorf("wanted \"%s\". got \"%s\"", "data", data)
+	}
+
+	// Redis retains the object for the stale retention period beyond its TTL
+	ttl, err := rc.client.PTTL(cacheKey).Result()
+	if err != nil {
+		t.Error(err)
+	}
+	if ttl < 59*time.Second {
+		t.Errorf("expected ttl of at least %s got %s", 59*time.Second, ttl)
+	}
+
+	// SetTTL updates the stored expiration
+	rc.SetTTL(cacheKey, time.Duration(60)*time.Second)
+	if _, ls, _ = rc.Retrieve(cacheKey, false); ls != status.LookupStatusHit {
+		t.Errorf("expected %s got %s", status.LookupStatusHit, ls)
+	}
+}
+
+func TestRedisCache_KeyNamespace(t *testing.T) {
+	rc, close := setupRedisCache(clientTypeStandard)
+	defer close()
+	rc.Config.Redis.KeyNamespace = "test*ns"
+
+	err := rc.Connect()
+	if err != nil {
+		t.Error(err)
+	}
+	defer rc.Close()
+
+	rc.Store(cacheKey, []byte("data"), time.Duration(60)*time.Second)
+	rc.client.Set("other", "data", 0)
+
+	if n, _ := rc.client.Exists("test*ns:" + cacheKey).Result(); n != 1 {
+		t.Errorf("expected key %s to exist", "test*ns:"+cacheKey)
+	}
+
+	data, _, err := rc.Retrieve(cacheKey, false)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(data) != "data" {
+		t.Errorf("wanted \"%s\". got \"%s\"", "data", data)
+	}
+
+	// only the objects in the namespace are listed, by their cache keys
+	objects, err := rc.ListObjects()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(objects) != 1 || objects[0].Key != cacheKey {
+		t.Errorf("unexpected objects %v", objects)
+	}
+
+	rc.BulkRemove([]string{cacheKey}, false)
+	if n, _ := rc.client.Exists("test*ns:" + cacheKey).Result(); n != 0 {
+		t.Errorf("expected key %s to be removed", "test*ns:"+cacheKey)
+	}
+}
+
+func TestRedisCache_ClientCacheUnsupported(t *testing.T) {
+
+	// the test server does not support client-side caching
+	rc, close := setupRedisCache(clientTypeStandard)
+	defer close()
+	rc.Config.Redis.ClientCacheMaxObjects = 10
+
+	if err := rc.Connect(); err == nil {
+		t.Errorf("expected error enabling client-side caching")
+	}
+
+	rc, close2 := setupRedisCache(clientTypeCluster)
+	defer close2()
+	rc.Config.Redis.ClientCacheMaxObjects = 10
+
+	const expected = "client-side caching is not supported by the redis cluster client type"
+	if err := rc.startClientCache(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %s got %v", expected, err)
+	}
+}
+
+func TestMarshalObject(t *testing.T) {
+
+	expiration := time.Unix(1577836800, 0)
+	data, exp := unmarshalObject(marshalObject([]byte("data"), expiration))
+	if string(data) != "data" || !exp.Equal(expiration) {
+		t.Errorf("expected %s %s got %s %s", "data", expiration, data, exp)
+	}
+
+	data, exp = unmarshalObject(marshalObject([]byte("data"), time.Time{}))
+	if string(data) != "data" || !exp.IsZero() {
+		t.Errorf("expected %s with no expiration got %s %s", "data", data, exp)
+	}
+
+	// objects stored without an expiration are returned as they are
+	data, exp = unmarshalObject([]byte("data"))
+	if string(data) != "data" || !exp.IsZero() {
+		t.Errorf("expected %s with no expiration got %s %s", "data", data, exp)
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	const expected = `a\*b\?c\[d\]e\\f`
+	if s := escapePattern(`a*b?c[d]e\f`); s != expected {
+		t.Errorf("expected %s got %s", expected, s)
+	}
+}
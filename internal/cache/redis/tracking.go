@@ -0,0 +1,419 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// trackingChannel is the channel on which Redis publishes client-side caching invalidations
+const trackingChannel = "__redis__:invalidate"
+
+// trackingRetryInterval is how long to wait before reestablishing a failed invalidation connection
+const trackingRetryInterval = 5 * time.Second
+
+// trackingPingInterval is how long the invalidation connection may be idle before it is checked with a PING
+const trackingPingInterval = 15 * time.Second
+
+// trackingPingTimeout is how long to wait for the reply to a PING, or for the remainder of a partially
+// received reply, before the invalidation connection is considered failed
+const trackingPingTimeout = 5 * time.Second
+
+// clientCache holds the objects most recently retrieved from Redis in-process. It relies on Redis 6
+// client-side caching in broadcasting mode, which publishes the keys of the modified objects to a
+// dedicated invalidation connection. Objects are only cached while that connection is established,
+// since any invalidations published while it is down are lost. The methods of a nil clientCache
+// do nothing, so that the Cache can use them whether or not client-side caching is enabled
+type clientCache struct {
+	maxObjects int
+	dial       func() (net.Conn, error)
+	password   string
+	prefix     string
+	// the invalidation connection is checked with a PING once it has been idle for pingInterval,
+	// and is considered failed if no reply is received within pingTimeout. 0 disables the check
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+
+	mtx      sync.Mutex
+	objects  map[string][]byte
+	tracking bool
+	// seq is incremented by each invalidation, so that an object retrieved from Redis
+	// while it is being invalidated is not cached
+	seq uint64
+
+	conn   net.Conn
+	closed bool
+}
+
+// startClientCache establishes the Cache's invalidation connection, and starts the client-side cache
+func (c *Cache) startClientCache() error {
+
+	cc := &clientCache{
+		maxObjects:   c.Config.Redis.ClientCacheMaxObjects,
+		password:     c.Config.Redis.Password,
+		objects:      make(map[string][]byte),
+		pingInterval: trackingPingInterval,
+		pingTimeout:  trackingPingTimeout,
+	}
+	if c.Config.Redis.KeyNamespace != "" {
+		cc.prefix = c.key("")
+	}
+
+	switch c.Config.Redis.ClientType {
+	case "cluster":
+		return errors.New("client-side caching is not supported by the redis cluster client type")
+	case "sentinel":
+		opts, _ := c.sentinelOpts()
+		cc.dial = func() (net.Conn, error) {
+			addr, err := sentinelMasterAddr(opts)
+			if err != nil {
+				return nil, err
+			}
+			return net.DialTimeout("tcp", addr, opts.DialTimeout)
+		}
+	default:
+		opts, _ := c.clientOpts()
+		network := opts.Network
+		if network == "" {
+			network = "tcp"
+		}
+		cc.dial = func() (net.Conn, error) {
+			return net.DialTimeout(network, opts.Addr, opts.DialTimeout)
+		}
+	}
+
+	conn, err := cc.connect()
+	if err != nil {
+		return fmt.Errorf("unable to enable redis client-side caching: %s", err.Error())
+	}
+	c.clientCache = cc
+	go cc.listen(conn)
+	return nil
+}
+
+// sentinelMasterAddr returns the address of the master node from the first sentinel that reports one
+func sentinelMasterAddr(opts *redis.FailoverOptions) (string, error) {
+	var err error
+	for _, addr := range opts.SentinelAddrs {
+		sentinel := redis.NewSentinelClient(&redis.Options{Addr: addr, DialTimeout: opts.DialTimeout})
+		var res []string
+		res, err = sentinel.GetMasterAddrByName(opts.MasterName).Result()
+		sentinel.Close()
+		if err == nil && len(res) == 2 {
+			return net.JoinHostPort(res[0], res[1]), nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no sentinel reported an address for master %s", opts.MasterName)
+	}
+	return "", err
+}
+
+// connect dials Redis and subscribes to the invalidations of the keys in the cache's namespace
+func (cc *clientCache) connect() (net.Conn, error) {
+
+	conn, err := cc.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	do := func(args ...string) (interface{}, error) {
+		if err := writeCommand(conn, args...); err != nil {
+			return nil, err
+		}
+		return readReply(r)
+	}
+
+	if cc.password != "" {
+		if _, err = do("AUTH", cc.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	res, err := do("CLIENT", "ID")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	id, ok := res.(int64)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected client id reply %v", res)
+	}
+
+	// the connection redirects the invalidations to itself, since they are delivered as Pub/Sub messages
+	args := []string{"CLIENT", "TRACKING", "ON", "REDIRECT", strconv.FormatInt(id, 10), "BCAST"}
+	if cc.prefix != "" {
+		args = append(args, "PREFIX", cc.prefix)
+	}
+	if _, err = do(args...); err == nil {
+		_, err = do("SUBSCRIBE", trackingChannel)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	cc.mtx.Lock()
+	if cc.closed {
+		cc.mtx.Unlock()
+		conn.Close()
+		return nil, errors.New("client-side cache is closed")
+	}
+	cc.conn = conn
+	cc.mtx.Unlock()
+	cc.reset(true)
+
+	return &bufferedConn{Conn: conn, r: r}, nil
+}
+
+// listen applies the invalidations received on the connection until it fails, and then reconnects,
+// until the client-side cache is closed
+func (cc *clientCache) listen(conn net.Conn) {
+	for {
+		err := cc.receive(conn)
+		cc.reset(false)
+		for {
+			cc.mtx.Lock()
+			closed := cc.closed
+			cc.mtx.Unlock()
+			if closed {
+				return
+			}
+			log.Warn("redis client-side cache invalidation connection failed", log.Pairs{"reason": err.Error()})
+			time.Sleep(trackingRetryInterval)
+			if conn, err = cc.connect(); err == nil {
+				break
+			}
+		}
+	}
+}
+
+// receive applies the invalidation messages received on the connection, until it fails. A connection
+// that is idle is checked with a PING, so that one that is silently broken (e.g., half-open) is detected
+// and reestablished, rather than objects being cached while their invalidations are lost
+func (cc *clientCache) receive(conn net.Conn) error {
+	defer conn.Close()
+	r := conn.(*bufferedConn).r
+	var pinged bool
+	for {
+		if cc.pingInterval > 0 {
+			timeout := cc.pingInterval
+			if pinged {
+				timeout = cc.pingTimeout
+			}
+			// wait for a reply without consuming it, so that the deadline cannot interrupt a partial reply
+			conn.SetReadDeadline(time.Now().Add(timeout))
+			if _, err := r.Peek(1); err != nil {
+				if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+					return err
+				}
+				if pinged {
+					return fmt.Errorf("no reply to ping within %s", cc.pingTimeout)
+				}
+				conn.SetWriteDeadline(time.Now().Add(cc.pingTimeout))
+				if err = writeCommand(conn, "PING"); err != nil {
+					return err
+				}
+				pinged = true
+				continue
+			}
+			conn.SetReadDeadline(time.Now().Add(cc.pingTimeout))
+		}
+		res, err := readReply(r)
+		if err != nil {
+			return err
+		}
+		// any reply, including the PING's, shows that the connection is alive
+		pinged = false
+		msg, ok := res.([]interface{})
+		if !ok || len(msg) != 3 || msg[0] != "message" || msg[1] != trackingChannel {
+			continue
+		}
+		// the keys are nil when all keys are invalidated, such as by FLUSHALL
+		keys, _ := msg[2].([]interface{})
+		cc.invalidate(keys)
+	}
+}
+
+// invalidate removes the keys from the client-side cache, or all keys if keys is nil
+func (cc *clientCache) invalidate(keys []interface{}) {
+	cc.mtx.Lock()
+	cc.seq++
+	if keys == nil {
+		cc.objects = make(map[string][]byte)
+	}
+	for _, k := range keys {
+		if key, ok := k.(string); ok {
+			delete(cc.objects, key)
+		}
+	}
+	cc.mtx.Unlock()
+}
+
+// reset empties the client-side cache, and sets whether objects may be cached
+func (cc *clientCache) reset(tracking bool) {
+	cc.mtx.Lock()
+	cc.seq++
+	cc.objects = make(map[string][]byte)
+	cc.tracking = tracking
+	cc.mtx.Unlock()
+}
+
+// get returns the object cached for the key, if it is present
+func (cc *clientCache) get(key string) ([]byte, bool) {
+	if cc == nil {
+		return nil, false
+	}
+	cc.mtx.Lock()
+	b, ok := cc.objects[key]
+	cc.mtx.Unlock()
+	return b, ok
+}
+
+// sequence returns the current invalidation sequence, which must be provided to set
+// with the object retrieved from Redis after sequence is called
+func (cc *clientCache) sequence() uint64 {
+	if cc == nil {
+		return 0
+	}
+	cc.mtx.Lock()
+	defer cc.mtx.Unlock()
+	return cc.seq
+}
+
+// set caches the object retrieved from Redis, unless any objects were invalidated since seq.
+// When the cache is full, an arbitrary object is replaced
+func (cc *clientCache) set(key string, b []byte, seq uint64) {
+	if cc == nil {
+		return
+	}
+	cc.mtx.Lock()
+	defer cc.mtx.Unlock()
+	if !cc.tracking || seq != cc.seq {
+		return
+	}
+	if _, ok := cc.objects[key]; !ok && len(cc.objects) >= cc.maxObjects {
+		for k := range cc.objects {
+			delete(cc.objects, k)
+			break
+		}
+	}
+	cc.objects[key] = b
+}
+
+// remove removes the key from the client-side cache
+func (cc *clientCache) remove(key string) {
+	if cc == nil {
+		return
+	}
+	cc.invalidate([]interface{}{key})
+}
+
+// close closes the invalidation connection, and stops caching objects
+func (cc *clientCache) close() {
+	if cc == nil {
+		return
+	}
+	cc.mtx.Lock()
+	cc.closed = true
+	if cc.conn != nil {
+		cc.conn.Close()
+	}
+	cc.mtx.Unlock()
+	cc.reset(false)
+}
+
+// bufferedConn is a net.Conn whose replies are read through r
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// writeCommand writes the command to w in the Redis protocol
+func writeCommand(w io.Writer, args ...string) error {
+	b := make([]byte, 0, 64)
+	b = append(b, '*')
+	b = strconv.AppendInt(b, int64(len(args)), 10)
+	b = append(b, '\r', '\n')
+	for _, arg := range args {
+		b = append(b, '$')
+		b = strconv.AppendInt(b, int64(len(arg)), 10)
+		b = append(b, '\r', '\n')
+		b = append(b, arg...)
+		b = append(b, '\r', '\n')
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// readReply reads a reply in the Redis protocol from r. Integers are returned as int64, strings as
+// string, arrays as []interface{}, and null values as nil. Error replies are returned as errors
+func readReply(r *bufio.Reader) (interface{}, error) {
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+	kind, line := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return nil, errors.New(line)
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return string(b[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		a := make([]interface{}, n)
+		for i := range a {
+			if a[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+
+	return nil, fmt.Errorf("invalid redis reply %q", line)
+}
@@ -0,0 +1,230 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package redis
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTrackingServer accepts a single connection and replies to the commands that establish
+// client-side caching. The tracking command's arguments are sent on the returned channel, and
+// any replies sent on the provided channel are written to the connection once it is subscribed
+func testTrackingServer(t *testing.T, messages chan string) (net.Listener, chan []string) {
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracking := make(chan []string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			cmd, err := readReply(r)
+			if err != nil {
+				return
+			}
+			args := make([]string, 0)
+			for _, a := range cmd.([]interface{}) {
+				args = append(args, a.(string))
+			}
+			switch strings.Join(args[:2], " ") {
+			case "CLIENT ID":
+				conn.Write([]byte(":7\r\n"))
+			case "CLIENT TRACKING":
+				tracking <- args
+				conn.Write([]byte("+OK\r\n"))
+			case "SUBSCRIBE " + trackingChannel:
+				conn.Write([]byte("*3\r\n$9\r\nsubscribe\r\n$20\r\n" + trackingChannel + "\r\n:1\r\n"))
+				for m := range messages {
+					conn.Write([]byte(m))
+				}
+				return
+			default:
+				conn.Write([]byte("-ERR unknown command\r\n"))
+			}
+		}
+	}()
+
+	return l, tracking
+}
+
+func testWaitForMiss(cc *clientCache, key string) bool {
+	for i := 0; i < 100; i++ {
+		if _, ok := cc.get(key); !ok {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestClientCacheInvalidation(t *testing.T) {
+
+	messages := make(chan string)
+	l, tracking := testTrackingServer(t, messages)
+	defer l.Close()
+
+	cc := &clientCache{maxObjects: 2, prefix: "ns:", objects: make(map[string][]byte),
+		dial: func() (net.Conn, error) { return net.Dial("tcp", l.Addr().String()) }}
+
+	conn, err := cc.connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go cc.listen(conn)
+	defer cc.close()
+
+	const expected = "CLIENT TRACKING ON REDIRECT 7 BCAST PREFIX ns:"
+	if args := strings.Join(<-tracking, " "); args != expected {
+		t.Errorf("expected %s got %s", expected, args)
+	}
+
+	cc.set("ns:a", []byte("a"), cc.sequence())
+	cc.set("ns:b", []byte("b"), cc.sequence())
+	if b, ok := cc.get("ns:a"); !ok || string(b) != "a" {
+		t.Errorf("expected %s got %s", "a", string(b))
+	}
+
+	// an object retrieved before an invalidation is not cached
+	seq := cc.sequence()
+	cc.remove("ns:c")
+	cc.set("ns:c", []byte("c"), seq)
+	if _, ok := cc.get("ns:c"); ok {
+		t.Errorf("expected key %s to not be cached", "ns:c")
+	}
+
+	messages <- "*3\r\n$7\r\nmessage\r\n$20\r\n" + trackingChannel + "\r\n*1\r\n$4\r\nns:a\r\n"
+	if !testWaitForMiss(cc, "ns:a") {
+		t.Errorf("expected key %s to be invalidated", "ns:a")
+	}
+	if _, ok := cc.get("ns:b"); !ok {
+		t.Errorf("expected key %s to be cached", "ns:b")
+	}
+
+	// a null list of keys invalidates every key
+	messages <- "*3\r\n$7\r\nmessage\r\n$20\r\n" + trackingChannel + "\r\n*-1\r\n"
+	if !testWaitForMiss(cc, "ns:b") {
+		t.Errorf("expected key %s to be invalidated", "ns:b")
+	}
+
+	// no objects are cached once the invalidation connection fails
+	close(messages)
+	for i := 0; i < 100; i++ {
+		cc.mtx.Lock()
+		ok := cc.tracking
+		cc.mtx.Unlock()
+		if !ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cc.set("ns:a", []byte("a"), cc.sequence())
+	if _, ok := cc.get("ns:a"); ok {
+		t.Errorf("expected key %s to not be cached", "ns:a")
+	}
+}
+
+func TestClientCacheMissedPing(t *testing.T) {
+
+	// the server stops replying once the connection is subscribed, as if it were half-open
+	messages := make(chan string)
+	l, tracking := testTrackingServer(t, messages)
+	defer l.Close()
+	defer close(messages)
+
+	cc := &clientCache{maxObjects: 2, objects: make(map[string][]byte),
+		pingInterval: 10 * time.Millisecond, pingTimeout: 10 * time.Millisecond,
+		dial: func() (net.Conn, error) { return net.Dial("tcp", l.Addr().String()) }}
+
+	conn, err := cc.connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tracking
+
+	// receive returns once the ping is missed, so that listen stops caching and reconnects
+	err = cc.receive(conn)
+	if err == nil || !strings.Contains(err.Error(), "no reply to ping") {
+		t.Errorf("expected ping timeout error got %v", err)
+	}
+}
+
+func TestClientCacheSet(t *testing.T) {
+
+	cc := &clientCache{maxObjects: 2, objects: make(map[string][]byte), tracking: true}
+	for _, k := range []string{"a", "b", "c"} {
+		cc.set(k, []byte(k), cc.sequence())
+	}
+	if len(cc.objects) != 2 {
+		t.Errorf("expected %d got %d", 2, len(cc.objects))
+	}
+	if _, ok := cc.get("c"); !ok {
+		t.Errorf("expected key %s to be cached", "c")
+	}
+
+	// a nil clientCache caches nothing
+	var ncc *clientCache
+	ncc.set("a", []byte("a"), ncc.sequence())
+	if _, ok := ncc.get("a"); ok {
+		t.Errorf("expected key %s to not be cached", "a")
+	}
+	ncc.remove("a")
+	ncc.close()
+}
+
+func TestReadReply(t *testing.T) {
+
+	r := bufio.NewReader(strings.NewReader("+OK\r\n-ERR failed\r\n:42\r\n$3\r\nabc\r\n$-1\r\n*2\r\n$1\r\na\r\n:1\r\n!\r\n"))
+
+	if v, err := readReply(r); err != nil || v != "OK" {
+		t.Errorf("expected %s got %v %v", "OK", v, err)
+	}
+	if _, err := readReply(r); err == nil || err.Error() != "ERR failed" {
+		t.Errorf("expected error %s got %v", "ERR failed", err)
+	}
+	if v, err := readReply(r); err != nil || v != int64(42) {
+		t.Errorf("expected %d got %v %v", 42, v, err)
+	}
+	if v, err := readReply(r); err != nil || v != "abc" {
+		t.Errorf("expected %s got %v %v", "abc", v, err)
+	}
+	if v, err := readReply(r); err != nil || v != nil {
+		t.Errorf("expected nil got %v %v", v, err)
+	}
+	if v, err := readReply(r); err != nil || len(v.([]interface{})) != 2 {
+		t.Errorf("expected array of 2 got %v %v", v, err)
+	}
+	if _, err := readReply(r); err == nil {
+		t.Errorf("expected error for invalid reply")
+	}
+}
+
+func TestWriteCommand(t *testing.T) {
+	var sb strings.Builder
+	writeCommand(&sb, "CLIENT", "ID")
+	const expected = "*2\r\n$6\r\nCLIENT\r\n$2\r\nID\r\n"
+	if sb.String() != expected {
+		t.Errorf("expected %q got %q", expected, sb.String())
+	}
+}
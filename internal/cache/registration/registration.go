@@ -19,9 +19,12 @@ import (
 	"github.com/Comcast/trickster/internal/cache"
 	"github.com/Comcast/trickster/internal/cache/badger"
 	"github.com/Comcast/trickster/internal/cache/bbolt"
+	"github.com/Comcast/trickster/internal/cache/encryption"
 	"github.com/Comcast/trickster/internal/cache/filesystem"
+	"github.com/Comcast/trickster/internal/cache/memcached"
 	"github.com/Comcast/trickster/internal/cache/memory"
 	"github.com/Comcast/trickster/internal/cache/redis"
+	"github.com/Comcast/trickster/internal/cache/tiered"
 	"github.com/Comcast/trickster/internal/config"
 )
 
@@ -31,6 +34,8 @@ const (
 	ctRedis      = "redis"
 	ctBBolt      = "bbolt"
 	ctBadger     = "badger"
+	ctTiered     = "tiered"
+	ctMemcached  = "memcached"
 )
 
 // Caches maintains a list of active caches
@@ -46,14 +51,51 @@ func GetCache(cacheName string) (cache.Cache, error) {
 
 // LoadCachesFromConfig iterates the Caching Confi and Connects/Maps each Cache
 func LoadCachesFromConfig() {
+	// tiered caches are loaded last, since they are composed of the other caches
 	for k, v := range config.Caches {
-		c := NewCache(k, v)
-		Caches[k] = c
+		if v.CacheType != ctTiered {
+			Caches[k] = NewCache(k, v)
+		}
 	}
+	for k, v := range config.Caches {
+		if v.CacheType == ctTiered {
+			Caches[k] = NewCache(k, v)
+		}
+	}
+}
+
+// LoadCacheFromConfig Connects and Maps the named Cache, after the Caches composing it if it is tiered,
+// and returns an error if it is not configured or cannot be connected
+func LoadCacheFromConfig(cacheName string) (cache.Cache, error) {
+	cfg, ok := config.Caches[cacheName]
+	if !ok {
+		return nil, fmt.Errorf("Could not find Cache named [%s]", cacheName)
+	}
+	if cfg.CacheType == ctTiered {
+		for _, name := range []string{cfg.Tiered.L1CacheName, cfg.Tiered.L2CacheName} {
+			if _, ok := Caches[name]; !ok {
+				if _, err := LoadCacheFromConfig(name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	c := newCache(cacheName, cfg)
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	Caches[cacheName] = c
+	return c, nil
 }
 
 // NewCache returns a Cache object based on the provided config.CachingConfig
 func NewCache(cacheName string, cfg *config.CachingConfig) cache.Cache {
+	c := newCache(cacheName, cfg)
+	c.Connect()
+	return c
+}
+
+func newCache(cacheName string, cfg *config.CachingConfig) cache.Cache {
 
 	var c cache.Cache
 
@@ -66,11 +108,23 @@ func NewCache(cacheName string, cfg *config.CachingConfig) cache.Cache {
 		c = &bbolt.Cache{Name: cacheName, Config: cfg}
 	case ctBadger:
 		c = &badger.Cache{Name: cacheName, Config: cfg}
+	case ctMemcached:
+		c = &memcached.Cache{Name: cacheName, Config: cfg}
+	case ctTiered:
+		tc := &tiered.Cache{Name: cacheName, Config: cfg}
+		if l1, ok := Caches[cfg.Tiered.L1CacheName].(cache.MemoryCache); ok {
+			tc.L1 = l1
+		}
+		tc.L2 = Caches[cfg.Tiered.L2CacheName]
+		c = tc
 	default:
 		// Default to MemoryCache
 		c = &memory.Cache{Name: cacheName, Config: cfg}
 	}
 
-	c.Connect()
+	if cfg.Encryption.KeyID != "" {
+		c = encryption.NewCache(cacheName, cfg, c)
+	}
+
 	return c
 }
@@ -18,6 +18,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/Comcast/trickster/internal/cache"
 	"github.com/Comcast/trickster/internal/config"
 	"github.com/Comcast/trickster/internal/util/metrics"
 )
@@ -66,6 +67,46 @@ func TestLoadCachesFromConfig(t *testing.T) {
 
 }
 
+func TestLoadCacheFromConfig(t *testing.T) {
+
+	err := config.Load("trickster", "test", []string{"-log-level", "debug", "-origin-url", "http://1", "-origin-type", "test"})
+	if err != nil {
+		t.Errorf("Could not load configuration: %s", err.Error())
+	}
+
+	Caches = make(map[string]cache.Cache)
+
+	fs := newCacheConfig(t, "filesystem")
+	defer os.RemoveAll(fs.Filesystem.CachePath)
+	config.Caches["l1"] = newCacheConfig(t, "memory")
+	config.Caches["l2"] = fs
+	tc := newCacheConfig(t, "tiered")
+	tc.Tiered = config.TieredCacheConfig{L1CacheName: "l1", L2CacheName: "l2"}
+	config.Caches["tiered"] = tc
+
+	c, err := LoadCacheFromConfig("tiered")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the tiers should be loaded first
+	for _, key := range []string{"l1", "l2", "tiered"} {
+		if _, err = GetCache(key); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err = GetCache("default"); err == nil {
+		t.Errorf("expected error")
+	}
+
+	_, err = LoadCacheFromConfig("foo")
+	if err == nil {
+		t.Errorf("expected error")
+	}
+
+}
+
 func newCacheConfig(t *testing.T, cacheType string) *config.CachingConfig {
 
 	bd := "."
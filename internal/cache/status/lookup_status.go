@@ -43,6 +43,8 @@ const (
 	LookupStatusNegativeCacheHit
 	// LookupStatusError indicates that there was an error looking up the object in the cache
 	LookupStatusError
+	// LookupStatusStale indicates that an expired object was served from the cache because the origin is unavailable
+	LookupStatusStale
 )
 
 var cacheLookupStatusNames = map[string]LookupStatus{
@@ -56,6 +58,7 @@ var cacheLookupStatusNames = map[string]LookupStatus{
 	"proxy-only":  LookupStatusProxyOnly,
 	"nchit":       LookupStatusNegativeCacheHit,
 	"error":       LookupStatusError,
+	"stale":       LookupStatusStale,
 }
 
 var cacheLookupStatusValues = map[LookupStatus]string{
@@ -69,6 +72,7 @@ var cacheLookupStatusValues = map[LookupStatus]string{
 	LookupStatusProxyOnly:        "proxy-only",
 	LookupStatusNegativeCacheHit: "nchit",
 	LookupStatusError:            "error",
+	LookupStatusStale:            "stale",
 }
 
 func (s LookupStatus) String() string {
@@ -20,7 +20,7 @@ func TestLookupStatusString(t *testing.T) {
 	t1 := LookupStatusHit
 	t2 := LookupStatusKeyMiss
 
-	var t3 LookupStatus = 10
+	var t3 LookupStatus = 11
 
 	if t1.String() != "hit" {
 		t.Errorf("expected %s got %s", "hit", t1.String())
@@ -30,7 +30,7 @@ func TestLookupStatusString(t *testing.T) {
 		t.Errorf("expected %s got %s", "kmiss", t2.String())
 	}
 
-	if t3.String() != "10" {
+	if t3.String() != "11" {
 		t.Errorf("expected %s got %s", "9", t3.String())
 	}
 }
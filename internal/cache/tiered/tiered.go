@@ -0,0 +1,297 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package tiered provides a Cache that composes a memory cache in front of a shared cache
+package tiered
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// Cache tier names, as used in metrics
+const (
+	tierL1 = "l1"
+	tierL2 = "l2"
+)
+
+// ErrMissingTier indicates a tiered cache was connected without both of its tiers
+var ErrMissingTier = errors.New("tiered cache requires an l1 memory cache and an l2 cache")
+
+// Cache defines a Tiered Cache client that conforms to the TieredCache interface.
+// L1 is a memory cache that retains objects by reference, and L2 is a shared cache
+// that retains them serialized. Objects found only in L2 are promoted into L1.
+type Cache struct {
+	Name   string
+	Config *config.CachingConfig
+	L1     cache.MemoryCache
+	L2     cache.Cache
+
+	queue     chan *writeOp
+	done      chan bool
+	closeOnce sync.Once
+}
+
+// writeOp is an operation queued for L2 when the WriteMode is "behind"
+type writeOp struct {
+	cacheKey string
+	data     []byte
+	ttl      time.Duration
+	remove   bool
+	// setTTL indicates the operation updates the TTL of the object, rather than storing it
+	setTTL bool
+}
+
+// Configuration returns the Configuration for the Cache object
+func (c *Cache) Configuration() *config.CachingConfig {
+	return c.Config
+}
+
+// Connect starts the L2 write-behind worker when configured. The tiers are connected
+// separately, since they are also registered as caches in their own right.
+func (c *Cache) Connect() error {
+	if c.L1 == nil || c.L2 == nil {
+		log.Error("tiered cache setup failed", log.Pairs{"name": c.Name, "l1": c.Config.Tiered.L1CacheName, "l2": c.Config.Tiered.L2CacheName})
+		return ErrMissingTier
+	}
+	log.Info("tiered cache setup", log.Pairs{"name": c.Name, "l1": c.Config.Tiered.L1CacheName,
+		"l2": c.Config.Tiered.L2CacheName, "writeMode": c.Config.Tiered.WriteMode})
+	if c.Config.Tiered.WriteMode == "behind" {
+		c.queue = make(chan *writeOp, c.Config.Tiered.WriteBehindQueueSize)
+		c.done = make(chan bool)
+		go c.writeBehind()
+	}
+	return nil
+}
+
+// writeBehind applies queued operations to L2 in the order they were queued
+func (c *Cache) writeBehind() {
+	for op := range c.queue {
+		if err := c.applyL2(op); err != nil {
+			log.Error("tiered cache failed to write to l2", log.Pairs{"name": c.Name, "cacheKey": op.cacheKey, "detail": err.Error()})
+		}
+	}
+	close(c.done)
+}
+
+func (c *Cache) applyL2(op *writeOp) error {
+	switch {
+	case op.remove:
+		c.L2.Remove(op.cacheKey)
+	case op.setTTL:
+		c.L2.SetTTL(op.cacheKey, op.ttl)
+	default:
+		return c.L2.Store(op.cacheKey, op.data, op.ttl)
+	}
+	return nil
+}
+
+// writeL2 applies the operation to L2 immediately, or queues it to be applied when the WriteMode is
+// "behind". When the queue is full, writeL2 blocks until there is room, so that L2 operations remain
+// in order.
+func (c *Cache) writeL2(op *writeOp) error {
+	if c.queue == nil {
+		return c.applyL2(op)
+	}
+	c.queue <- op
+	return nil
+}
+
+// l1TTL caps the ttl to the maximum L1 TTL
+func (c *Cache) l1TTL(ttl time.Duration) time.Duration {
+	if c.Config.Tiered.L1MaxTTL > 0 && ttl > c.Config.Tiered.L1MaxTTL {
+		return c.Config.Tiered.L1MaxTTL
+	}
+	return ttl
+}
+
+// l2TTL caps the ttl to the maximum L2 TTL
+func (c *Cache) l2TTL(ttl time.Duration) time.Duration {
+	if c.Config.Tiered.L2MaxTTL > 0 && ttl > c.Config.Tiered.L2MaxTTL {
+		return c.Config.Tiered.L2MaxTTL
+	}
+	return ttl
+}
+
+// Store places an object in both tiers using the specified key and ttl
+func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	c.L1.Store(cacheKey, data, c.l1TTL(ttl))
+	return c.writeL2(&writeOp{cacheKey: cacheKey, data: data, ttl: c.l2TTL(ttl)})
+}
+
+// StoreReference stores an object in L1 without requiring serialization. It is not written to L2
+func (c *Cache) StoreReference(cacheKey string, ref cache.ReferenceObject, ttl time.Duration) error {
+	return c.L1.StoreReference(cacheKey, ref, c.l1TTL(ttl))
+}
+
+// StoreTiered stores an object by reference in L1, and its serialized data in L2
+func (c *Cache) StoreTiered(cacheKey string, ref cache.ReferenceObject, data []byte, ttl time.Duration) error {
+	c.L1.StoreReference(cacheKey, ref, c.l1TTL(ttl))
+	return c.writeL2(&writeOp{cacheKey: cacheKey, data: data, ttl: c.l2TTL(ttl)})
+}
+
+// promotionTTL returns the TTL of an object promoted from L2 into L1: the maximum L1 TTL, capped at
+// the object's remaining lifetime in L2. It returns false if the object has already expired in L2.
+func (c *Cache) promotionTTL(cacheKey string) (time.Duration, bool) {
+	ttl := c.Config.Tiered.L1MaxTTL
+	if er, ok := c.L2.(cache.ExpirationReporter); ok {
+		if exp := er.Expiration(cacheKey); !exp.IsZero() {
+			remaining := time.Until(exp)
+			if remaining <= 0 {
+				return 0, false
+			}
+			if ttl <= 0 || remaining < ttl {
+				ttl = remaining
+			}
+		}
+	}
+	return ttl, true
+}
+
+// PromoteReference stores an object that was retrieved from L2 by reference in L1, for the maximum L1 TTL
+// or the object's remaining lifetime in L2, whichever is shorter. Objects that have expired in L2 are
+// not promoted.
+func (c *Cache) PromoteReference(cacheKey string, ref cache.ReferenceObject) error {
+	ttl, ok := c.promotionTTL(cacheKey)
+	if !ok {
+		return nil
+	}
+	log.Debug("tiered cache promoting object", log.Pairs{"name": c.Name, "cacheKey": cacheKey, "ttl": ttl})
+	return c.L1.StoreReference(cacheKey, ref, ttl)
+}
+
+// Retrieve looks for an object in L1 and then L2, and returns it (or an error if not found).
+// Objects found only in L2 are promoted into L1, unless they have expired.
+func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	b, s, err := c.L1.Retrieve(cacheKey, allowExpired)
+	if err == nil && s == status.LookupStatusHit && b != nil {
+		cache.ObserveCacheTierLookup(c.Name, tierL1, "hit")
+		return b, s, nil
+	}
+	cache.ObserveCacheTierLookup(c.Name, tierL1, "miss")
+	b, s, err = c.retrieveL2(cacheKey, allowExpired)
+	if err != nil || s != status.LookupStatusHit {
+		return b, s, err
+	}
+	if ttl, ok := c.promotionTTL(cacheKey); ok {
+		c.L1.Store(cacheKey, b, ttl)
+	}
+	return b, s, nil
+}
+
+// RetrieveReference looks for an object reference in L1 and returns it. If it is not found, the object's
+// serialized data is retrieved from L2 and returned for the caller to decode and promote into L1.
+func (c *Cache) RetrieveReference(cacheKey string, allowExpired bool) (interface{}, status.LookupStatus, error) {
+	ref, s, err := c.L1.RetrieveReference(cacheKey, allowExpired)
+	if err == nil && s == status.LookupStatusHit && ref != nil {
+		cache.ObserveCacheTierLookup(c.Name, tierL1, "hit")
+		return ref, s, nil
+	}
+	cache.ObserveCacheTierLookup(c.Name, tierL1, "miss")
+	b, s, err := c.retrieveL2(cacheKey, allowExpired)
+	if err != nil || s != status.LookupStatusHit {
+		return nil, s, err
+	}
+	return b, s, nil
+}
+
+func (c *Cache) retrieveL2(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	b, s, err := c.L2.Retrieve(cacheKey, allowExpired)
+	if err != nil || s != status.LookupStatusHit {
+		cache.ObserveCacheTierLookup(c.Name, tierL2, "miss")
+		return nil, s, err
+	}
+	cache.ObserveCacheTierLookup(c.Name, tierL2, "hit")
+	return b, s, nil
+}
+
+// Peek looks for an object in L1 and then L2 without the side effects of a lookup, so that
+// objects found only in L2 are not promoted into L1
+func (c *Cache) Peek(cacheKey string) ([]byte, status.LookupStatus, error) {
+	if p, ok := c.L1.(cache.ObjectPeeker); ok {
+		if b, s, err := p.Peek(cacheKey); err == nil && s == status.LookupStatusHit && b != nil {
+			return b, s, nil
+		}
+	}
+	return c.peekL2(cacheKey)
+}
+
+// PeekReference looks for an object reference in L1 without the side effects of a lookup. If it is
+// not found, the object's serialized data is peeked at in L2 and returned for the caller to decode
+func (c *Cache) PeekReference(cacheKey string) (interface{}, status.LookupStatus, error) {
+	if p, ok := c.L1.(cache.ReferencePeeker); ok {
+		if ref, s, err := p.PeekReference(cacheKey); err == nil && s == status.LookupStatusHit && ref != nil {
+			return ref, s, nil
+		}
+	}
+	b, s, err := c.peekL2(cacheKey)
+	if err != nil || s != status.LookupStatusHit {
+		return nil, s, err
+	}
+	return b, s, nil
+}
+
+// peekL2 peeks at an object in L2, or retrieves it when L2 is unable to peek
+func (c *Cache) peekL2(cacheKey string) ([]byte, status.LookupStatus, error) {
+	if p, ok := c.L2.(cache.ObjectPeeker); ok {
+		return p.Peek(cacheKey)
+	}
+	return c.L2.Retrieve(cacheKey, true)
+}
+
+// SetTTL updates the TTL for the provided cache object in both tiers
+func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
+	c.L1.SetTTL(cacheKey, c.l1TTL(ttl))
+	c.writeL2(&writeOp{cacheKey: cacheKey, ttl: c.l2TTL(ttl), setTTL: true})
+}
+
+// Remove removes an object from both tiers
+func (c *Cache) Remove(cacheKey string) {
+	c.L1.Remove(cacheKey)
+	c.writeL2(&writeOp{cacheKey: cacheKey, remove: true})
+}
+
+// BulkRemove removes a list of objects from both tiers
+func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
+	c.L1.BulkRemove(cacheKeys, noLock)
+	for _, cacheKey := range cacheKeys {
+		c.writeL2(&writeOp{cacheKey: cacheKey, remove: true})
+	}
+}
+
+// ListObjects returns the metadata of the objects in L2, which holds every object in the
+// tiered cache, while L1 holds only those that were recently written or promoted
+func (c *Cache) ListObjects() ([]cache.ObjectMetadata, error) {
+	if l, ok := c.L2.(cache.ObjectLister); ok {
+		return l.ListObjects()
+	}
+	return nil, cache.ErrListUnsupported
+}
+
+// Close writes any queued operations to L2. The tiers themselves are not closed,
+// since they are also registered as caches in their own right.
+func (c *Cache) Close() error {
+	c.closeOnce.Do(func() {
+		if c.queue != nil {
+			close(c.queue)
+			<-c.done
+		}
+	})
+	return nil
+}
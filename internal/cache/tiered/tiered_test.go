@@ -0,0 +1,312 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package tiered
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+const cacheKey = "cacheKey"
+
+type testReferenceObject struct {
+}
+
+func (r *testReferenceObject) Size() int {
+	return 1
+}
+
+func newTestCache(t *testing.T, writeMode string) *Cache {
+	l1 := &memory.Cache{Name: "l1", Config: config.NewCacheConfig()}
+	if err := l1.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	l2 := &memory.Cache{Name: "l2", Config: config.NewCacheConfig()}
+	if err := l2.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewCacheConfig()
+	cfg.CacheType = "tiered"
+	cfg.Tiered.WriteMode = writeMode
+	cfg.Tiered.L1MaxTTL = time.Minute
+	cfg.Tiered.L2MaxTTL = time.Hour
+	c := &Cache{Name: "test", Config: cfg, L1: l1, L2: l2}
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestConfiguration(t *testing.T) {
+	c := newTestCache(t, "through")
+	if c.Configuration().CacheType != "tiered" {
+		t.Errorf("expected %s got %s", "tiered", c.Configuration().CacheType)
+	}
+}
+
+func TestConnectMissingTier(t *testing.T) {
+	c := &Cache{Name: "test", Config: config.NewCacheConfig()}
+	if err := c.Connect(); err != ErrMissingTier {
+		t.Errorf("expected %v got %v", ErrMissingTier, err)
+	}
+}
+
+func TestStoreRetrieve(t *testing.T) {
+
+	c := newTestCache(t, "through")
+
+	err := c.Store(cacheKey, []byte("data"), time.Duration(24)*time.Hour)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// both tiers are written, with capped ttls
+	for _, tier := range []*memory.Cache{c.L1.(*memory.Cache), c.L2.(*memory.Cache)} {
+		o, s, err := tier.Retrieve(cacheKey, false)
+		if err != nil || s != status.LookupStatusHit || string(o) != "data" {
+			t.Errorf("expected %s got %s in cache %s", "data", string(o), tier.Name)
+		}
+	}
+
+	if exp := c.L1.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) > time.Minute {
+		t.Errorf("expected l1 expiration within %s got %s", time.Minute, time.Until(exp))
+	}
+
+	if exp := c.L2.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) > time.Hour {
+		t.Errorf("expected l2 expiration within %s got %s", time.Hour, time.Until(exp))
+	}
+
+	// read-through from l2, with promotion into l1
+	c.L1.Remove(cacheKey)
+	o, s, err := c.Retrieve(cacheKey, false)
+	if err != nil || s != status.LookupStatusHit || string(o) != "data" {
+		t.Errorf("expected %s got %s", "data", string(o))
+	}
+
+	o, s, err = c.L1.Retrieve(cacheKey, false)
+	if err != nil || s != status.LookupStatusHit || string(o) != "data" {
+		t.Errorf("expected %s got %s", "data", string(o))
+	}
+
+	c.Remove(cacheKey)
+	_, s, err = c.Retrieve(cacheKey, false)
+	if err == nil || s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+}
+
+func TestStoreTieredRetrieveReference(t *testing.T) {
+
+	c := newTestCache(t, "through")
+	ref := &testReferenceObject{}
+
+	err := c.StoreTiered(cacheKey, ref, []byte("data"), time.Hour)
+	if err != nil {
+		t.Error(err)
+	}
+
+	ifc, s, err := c.RetrieveReference(cacheKey, false)
+	if err != nil || s != status.LookupStatusHit || ifc != ref {
+		t.Errorf("expected reference, got %v", ifc)
+	}
+
+	// an l1 miss returns the serialized data from l2 for the caller to promote
+	c.L1.Remove(cacheKey)
+	ifc, s, err = c.RetrieveReference(cacheKey, false)
+	if b, ok := ifc.([]byte); err != nil || s != status.LookupStatusHit || !ok || string(b) != "data" {
+		t.Errorf("expected %s got %v", "data", ifc)
+	}
+
+	err = c.PromoteReference(cacheKey, ref)
+	if err != nil {
+		t.Error(err)
+	}
+
+	ifc, _, _ = c.RetrieveReference(cacheKey, false)
+	if ifc != ref {
+		t.Errorf("expected reference, got %v", ifc)
+	}
+
+	c.BulkRemove([]string{cacheKey}, false)
+	_, s, err = c.RetrieveReference(cacheKey, false)
+	if err == nil || s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+	// stored by reference in l1 only
+	c.StoreReference(cacheKey, ref, time.Hour)
+	_, s, _ = c.L2.Retrieve(cacheKey, false)
+	if s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+}
+
+func TestPromotionTTL(t *testing.T) {
+
+	c := newTestCache(t, "through")
+	ref := &testReferenceObject{}
+
+	// objects are promoted for no longer than their remaining lifetime in l2
+	c.L2.Store(cacheKey, []byte("data"), 10*time.Second)
+	if _, s, _ := c.Retrieve(cacheKey, false); s != status.LookupStatusHit {
+		t.Errorf("expected %s got %s", status.LookupStatusHit, s)
+	}
+	if exp := c.L1.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) > 10*time.Second {
+		t.Errorf("expected l1 expiration within %s got %s", 10*time.Second, time.Until(exp))
+	}
+
+	c.L1.Remove(cacheKey)
+	if err := c.PromoteReference(cacheKey, ref); err != nil {
+		t.Error(err)
+	}
+	if exp := c.L1.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) > 10*time.Second {
+		t.Errorf("expected l1 expiration within %s got %s", 10*time.Second, time.Until(exp))
+	}
+
+	// expired objects are returned when allowed, but not promoted
+	c.L1.Remove(cacheKey)
+	c.L2.SetTTL(cacheKey, -time.Second)
+	if b, s, _ := c.Retrieve(cacheKey, true); s != status.LookupStatusHit || string(b) != "data" {
+		t.Errorf("expected %s got %s", "data", string(b))
+	}
+	if _, s, _ := c.L1.Retrieve(cacheKey, true); s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+	if err := c.PromoteReference(cacheKey, ref); err != nil {
+		t.Error(err)
+	}
+	if _, s, _ := c.L1.RetrieveReference(cacheKey, true); s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+}
+
+func TestPeek(t *testing.T) {
+
+	c := newTestCache(t, "through")
+	ref := &testReferenceObject{}
+	c.StoreTiered(cacheKey, ref, []byte("data"), time.Hour)
+
+	ifc, s, err := c.PeekReference(cacheKey)
+	if err != nil || s != status.LookupStatusHit || ifc != ref {
+		t.Errorf("expected reference, got %v", ifc)
+	}
+
+	// objects found only in l2 are not promoted into l1
+	c.L1.Remove(cacheKey)
+	ifc, s, err = c.PeekReference(cacheKey)
+	if b, ok := ifc.([]byte); err != nil || s != status.LookupStatusHit || !ok || string(b) != "data" {
+		t.Errorf("expected %s got %v", "data", ifc)
+	}
+	b, s, err := c.Peek(cacheKey)
+	if err != nil || s != status.LookupStatusHit || string(b) != "data" {
+		t.Errorf("expected %s got %s", "data", string(b))
+	}
+	if _, s, _ = c.L1.Retrieve(cacheKey, false); s != status.LookupStatusKeyMiss {
+		t.Errorf("expected %s got %s", status.LookupStatusKeyMiss, s)
+	}
+
+	c.L2 = &unlistableCache{c.L2}
+	if b, _, _ = c.Peek(cacheKey); string(b) != "data" {
+		t.Errorf("expected %s got %s", "data", string(b))
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	c := newTestCache(t, "through")
+	c.Store(cacheKey, []byte("data"), time.Hour)
+	c.L1.Remove(cacheKey)
+
+	// objects are listed from l2
+	objects, err := c.ListObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Key != cacheKey {
+		t.Errorf("expected %s in %v", cacheKey, objects)
+	}
+
+	c.L2 = &unlistableCache{c.L2}
+	if _, err = c.ListObjects(); err != cache.ErrListUnsupported {
+		t.Errorf("expected %v got %v", cache.ErrListUnsupported, err)
+	}
+}
+
+// unlistableCache hides the ListObjects method of the cache it wraps
+type unlistableCache struct {
+	cache.Cache
+}
+
+func TestWriteBehind(t *testing.T) {
+
+	c := newTestCache(t, "behind")
+
+	for i := 0; i < 10; i++ {
+		c.Store(cacheKey, []byte{byte(i)}, time.Hour)
+	}
+	c.Remove("other")
+	c.Close()
+
+	o, s, err := c.L2.Retrieve(cacheKey, false)
+	if err != nil || s != status.LookupStatusHit || len(o) != 1 || o[0] != 9 {
+		t.Errorf("expected %v got %v", []byte{9}, o)
+	}
+
+	// closing again is a no-op
+	if err := c.Close(); err != nil {
+		t.Error(err)
+	}
+
+	// a TTL update is applied to l2 after the store that was queued before it
+	c = newTestCache(t, "behind")
+	c.Store(cacheKey, []byte("data"), time.Minute)
+	c.SetTTL(cacheKey, time.Duration(24)*time.Hour)
+	c.Close()
+
+	if exp := c.L2.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) < time.Minute ||
+		time.Until(exp) > time.Hour {
+		t.Errorf("expected l2 expiration within %s got %s", time.Hour, time.Until(exp))
+	}
+
+}
+
+func TestSetTTL(t *testing.T) {
+
+	c := newTestCache(t, "through")
+	c.Store(cacheKey, []byte("data"), time.Minute)
+	c.SetTTL(cacheKey, time.Duration(24)*time.Hour)
+
+	if exp := c.L1.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) > time.Minute {
+		t.Errorf("expected l1 expiration within %s got %s", time.Minute, time.Until(exp))
+	}
+
+	if exp := c.L2.(*memory.Cache).Index.GetExpiration(cacheKey); time.Until(exp) < time.Minute ||
+		time.Until(exp) > time.Hour {
+		t.Errorf("expected l2 expiration within %s got %s", time.Hour, time.Until(exp))
+	}
+
+}
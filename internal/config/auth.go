@@ -0,0 +1,174 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthenticatorNone is the authenticator name that disables authentication for a path
+const AuthenticatorNone = "none"
+
+// AuthenticatorConfig is a collection of configurations for authenticating frontend requests
+type AuthenticatorConfig struct {
+	// AuthenticatorType is the method of authentication ('basic', 'api_key' or 'jwt')
+	AuthenticatorType string `toml:"type"`
+	// Realm is the realm presented to clients that fail to authenticate
+	Realm string `toml:"realm"`
+
+	// HtpasswdPath is the path to the htpasswd file of the users of a 'basic' authenticator
+	HtpasswdPath string `toml:"htpasswd_path"`
+
+	// APIKeys maps each key accepted by an 'api_key' authenticator to the name of its identity
+	APIKeys map[string]string `toml:"api_keys"`
+	// APIKeyHeader is the name of the request header providing the key. The default is 'X-API-Key'
+	APIKeyHeader string `toml:"api_key_header"`
+
+	// JWKSPath is the path to a JSON Web Key Set file of the keys that sign the tokens of a 'jwt' authenticator
+	JWKSPath string `toml:"jwks_path"`
+	// JWKSURL is the URL of a JSON Web Key Set of the keys that sign the tokens
+	JWKSURL string `toml:"jwks_url"`
+	// OIDCIssuer is the URL of an OpenID Connect issuer, from whose discovery document the JWKSURL is loaded,
+	// and which the 'iss' claim of each token must match when Issuer is not set
+	OIDCIssuer string `toml:"oidc_issuer"`
+	// JWKSRefreshSecs is how often the JWKSURL is reloaded. The default is 3600
+	JWKSRefreshSecs int `toml:"jwks_refresh_secs"`
+	// Issuer is the value that the 'iss' claim of each token must match, when set
+	Issuer string `toml:"issuer"`
+	// Audience is a value that the 'aud' claim of each token must include, when set
+	Audience string `toml:"audience"`
+	// IdentityClaim is the name of the claim that identifies the token's subject. The default is 'sub'
+	IdentityClaim string `toml:"identity_claim"`
+	// ClaimsHeaders maps the names of claims to the names of the request headers that are set to their values
+	ClaimsHeaders map[string]string `toml:"claims_headers"`
+	// LeewaySecs is the allowed clock skew when validating the 'exp' and 'nbf' claims. The default is 60
+	LeewaySecs int `toml:"leeway_secs"`
+
+	// UpstreamAuthorization is the handling of the client's Authorization header when proxying
+	// ('passthrough', 'strip' or 'replace'). The default is 'passthrough'
+	UpstreamAuthorization string `toml:"upstream_authorization"`
+	// UpstreamAuthorizationValue is the value of the Authorization header sent to the origin, when replaced
+	UpstreamAuthorizationValue string `toml:"upstream_authorization_value"`
+}
+
+var authenticatorTypes = map[string]bool{"basic": true, "api_key": true, "jwt": true}
+
+var upstreamAuthorizationTypes = map[string]bool{"passthrough": true, "strip": true, "replace": true}
+
+// Clone returns an exact copy of the subject AuthenticatorConfig
+func (ac *AuthenticatorConfig) Clone() *AuthenticatorConfig {
+	c := *ac
+	if ac.APIKeys != nil {
+		c.APIKeys = make(map[string]string, len(ac.APIKeys))
+		for k, v := range ac.APIKeys {
+			c.APIKeys[k] = v
+		}
+	}
+	if ac.ClaimsHeaders != nil {
+		c.ClaimsHeaders = make(map[string]string, len(ac.ClaimsHeaders))
+		for k, v := range ac.ClaimsHeaders {
+			c.ClaimsHeaders[k] = v
+		}
+	}
+	return &c
+}
+
+// verifyAuthenticatorConfigs validates each Authenticator and the Authenticator names referenced
+// by each origin and path, and sets the defaults of each Authenticator
+func (c *TricksterConfig) verifyAuthenticatorConfigs() error {
+
+	for k, ac := range c.Authenticators {
+
+		if k == AuthenticatorNone {
+			return fmt.Errorf("invalid authenticator config name [%s]", k)
+		}
+
+		ac.AuthenticatorType = strings.ToLower(ac.AuthenticatorType)
+		if !authenticatorTypes[ac.AuthenticatorType] {
+			return fmt.Errorf("invalid type [%s] provided in authenticator config [%s]", ac.AuthenticatorType, k)
+		}
+
+		switch ac.AuthenticatorType {
+		case "basic":
+			if ac.HtpasswdPath == "" {
+				return fmt.Errorf("missing htpasswd_path in authenticator config [%s]", k)
+			}
+		case "api_key":
+			if len(ac.APIKeys) == 0 {
+				return fmt.Errorf("missing api_keys in authenticator config [%s]", k)
+			}
+			for _, id := range ac.APIKeys {
+				if id == "" {
+					return fmt.Errorf("missing identity of api key in authenticator config [%s]", k)
+				}
+			}
+		case "jwt":
+			if ac.JWKSPath == "" && ac.JWKSURL == "" && ac.OIDCIssuer == "" {
+				return fmt.Errorf("missing jwks_path, jwks_url or oidc_issuer in authenticator config [%s]", k)
+			}
+		}
+
+		if ac.Realm == "" {
+			ac.Realm = defaultAuthenticatorRealm
+		}
+		if ac.APIKeyHeader == "" {
+			ac.APIKeyHeader = defaultAuthenticatorAPIKeyHeader
+		}
+		if ac.JWKSRefreshSecs <= 0 {
+			ac.JWKSRefreshSecs = defaultAuthenticatorJWKSRefreshSecs
+		}
+		if ac.IdentityClaim == "" {
+			ac.IdentityClaim = defaultAuthenticatorIdentityClaim
+		}
+		if ac.LeewaySecs <= 0 {
+			ac.LeewaySecs = defaultAuthenticatorLeewaySecs
+		}
+		if ac.Issuer == "" && ac.OIDCIssuer != "" {
+			ac.Issuer = ac.OIDCIssuer
+		}
+
+		ac.UpstreamAuthorization = strings.ToLower(ac.UpstreamAuthorization)
+		if ac.UpstreamAuthorization == "" {
+			ac.UpstreamAuthorization = defaultAuthenticatorUpstreamAuthorization
+		}
+		if !upstreamAuthorizationTypes[ac.UpstreamAuthorization] {
+			return fmt.Errorf("invalid upstream_authorization [%s] provided in authenticator config [%s]", ac.UpstreamAuthorization, k)
+		}
+	}
+
+	// the cache archive handler exposes and overwrites the cached data of every origin, so it must be authenticated
+	if c.Main != nil && c.Main.CacheArchiveHandlerPath != "" {
+		if _, ok := c.Authenticators[c.Main.CacheArchiveAuthenticatorName]; !ok {
+			return fmt.Errorf("invalid cache_archive_authenticator [%s] provided in main config; an authenticator is required when cache_archive_handler_path is set",
+				c.Main.CacheArchiveAuthenticatorName)
+		}
+	}
+
+	for k, oc := range c.Origins {
+		if _, ok := c.Authenticators[oc.AuthenticatorName]; !ok && oc.AuthenticatorName != "" &&
+			oc.AuthenticatorName != AuthenticatorNone {
+			return fmt.Errorf("invalid authenticator name [%s] provided in origin config [%s]", oc.AuthenticatorName, k)
+		}
+		for _, p := range oc.Paths {
+			if _, ok := c.Authenticators[p.AuthenticatorName]; !ok && p.AuthenticatorName != "" &&
+				p.AuthenticatorName != AuthenticatorNone {
+				return fmt.Errorf("invalid authenticator name [%s] provided in path config [%s] for origin [%s]",
+					p.AuthenticatorName, p.Path, k)
+			}
+		}
+	}
+
+	return nil
+}
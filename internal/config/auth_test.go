@@ -0,0 +1,100 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import "testing"
+
+func TestVerifyAuthenticatorConfigs(t *testing.T) {
+
+	config := NewConfig()
+	ac := &AuthenticatorConfig{AuthenticatorType: "JWT", OIDCIssuer: "https://issuer"}
+	config.Authenticators["test"] = ac
+	config.Origins["default"].AuthenticatorName = "test"
+
+	err := config.verifyAuthenticatorConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if ac.AuthenticatorType != "jwt" || ac.Issuer != "https://issuer" || ac.Realm != "trickster" ||
+		ac.IdentityClaim != "sub" || ac.JWKSRefreshSecs != 3600 || ac.LeewaySecs != 60 ||
+		ac.APIKeyHeader != "X-API-Key" || ac.UpstreamAuthorization != "passthrough" {
+		t.Errorf("unexpected defaults %v", ac)
+	}
+
+	tests := []struct {
+		cfg      AuthenticatorConfig
+		expected string
+	}{
+		{AuthenticatorConfig{AuthenticatorType: "ldap"}, "invalid type [ldap] provided in authenticator config [test]"},
+		{AuthenticatorConfig{AuthenticatorType: "basic"}, "missing htpasswd_path in authenticator config [test]"},
+		{AuthenticatorConfig{AuthenticatorType: "api_key"}, "missing api_keys in authenticator config [test]"},
+		{AuthenticatorConfig{AuthenticatorType: "api_key", APIKeys: map[string]string{"key": ""}},
+			"missing identity of api key in authenticator config [test]"},
+		{AuthenticatorConfig{AuthenticatorType: "jwt"},
+			"missing jwks_path, jwks_url or oidc_issuer in authenticator config [test]"},
+		{AuthenticatorConfig{AuthenticatorType: "basic", HtpasswdPath: "test", UpstreamAuthorization: "drop"},
+			"invalid upstream_authorization [drop] provided in authenticator config [test]"},
+	}
+
+	for i, test := range tests {
+		*ac = test.cfg
+		err = config.verifyAuthenticatorConfigs()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("test %d: expected %s got %v", i, test.expected, err)
+		}
+	}
+
+	*ac = AuthenticatorConfig{AuthenticatorType: "basic", HtpasswdPath: "test"}
+	config.Origins["default"].AuthenticatorName = "missing"
+	err = config.verifyAuthenticatorConfigs()
+	expected := "invalid authenticator name [missing] provided in origin config [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+
+	config.Origins["default"].AuthenticatorName = "test"
+	config.Origins["default"].Paths["root"] = &PathConfig{Path: "/", AuthenticatorName: "none"}
+	if err = config.verifyAuthenticatorConfigs(); err != nil {
+		t.Error(err)
+	}
+
+	config.Origins["default"].Paths["root"].AuthenticatorName = "missing"
+	err = config.verifyAuthenticatorConfigs()
+	expected = "invalid authenticator name [missing] provided in path config [/] for origin [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+
+	delete(config.Origins["default"].Paths, "root")
+	config.Authenticators["none"] = ac
+	err = config.verifyAuthenticatorConfigs()
+	expected = "invalid authenticator config name [none]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+}
+
+func TestAuthenticatorConfigClone(t *testing.T) {
+	ac := &AuthenticatorConfig{AuthenticatorType: "api_key", APIKeys: map[string]string{"key": "tenant"},
+		ClaimsHeaders: map[string]string{"sub": "X-User"}}
+	ac2 := ac.Clone()
+	if ac2.AuthenticatorType != "api_key" || ac2.APIKeys["key"] != "tenant" || ac2.ClaimsHeaders["sub"] != "X-User" {
+		t.Errorf("expected %v got %v", ac, ac2)
+	}
+	ac2.APIKeys["key"] = "other"
+	ac2.ClaimsHeaders["sub"] = "X-Other"
+	if ac.APIKeys["key"] != "tenant" || ac.ClaimsHeaders["sub"] != "X-User" {
+		t.Errorf("clone shares state with the original config: %v", ac)
+	}
+}
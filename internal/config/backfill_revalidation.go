@@ -0,0 +1,61 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// BackfillRevalidationRule defines how often cached timeseries data younger than MaxAge is refetched from the origin
+type BackfillRevalidationRule struct {
+	// MaxAge is the age of the youngest data to which the rule no longer applies
+	MaxAge time.Duration
+	// Interval is the minimum time between refetches of the data. 0 refetches the data on every request
+	Interval time.Duration
+}
+
+// BackfillRevalidationSchedule is a list of BackfillRevalidationRules, sorted from youngest to oldest MaxAge
+type BackfillRevalidationSchedule []BackfillRevalidationRule
+
+// MaxAge returns the MaxAge of the oldest rule in the schedule, beyond which data is never revalidated
+func (s BackfillRevalidationSchedule) MaxAge() time.Duration {
+	if len(s) == 0 {
+		return 0
+	}
+	return s[len(s)-1].MaxAge
+}
+
+// newBackfillRevalidationSchedule returns a BackfillRevalidationSchedule from a map of
+// max age seconds to refetch interval seconds, as provided in the config file
+func newBackfillRevalidationSchedule(m map[string]int) (BackfillRevalidationSchedule, error) {
+	s := make(BackfillRevalidationSchedule, 0, len(m))
+	for k, v := range m {
+		age, err := strconv.Atoi(k)
+		if err != nil || age <= 0 {
+			return nil, fmt.Errorf(`invalid backfill revalidation age: %s`, k)
+		}
+		if v < 0 {
+			return nil, fmt.Errorf(`invalid backfill revalidation interval for age %s: %d`, k, v)
+		}
+		s = append(s, BackfillRevalidationRule{
+			MaxAge:   time.Duration(age) * time.Second,
+			Interval: time.Duration(v) * time.Second,
+		})
+	}
+	sort.Slice(s, func(i, j int) bool { return s[i].MaxAge < s[j].MaxAge })
+	return s, nil
+}
@@ -0,0 +1,58 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBackfillRevalidationSchedule(t *testing.T) {
+
+	s, err := newBackfillRevalidationSchedule(map[string]int{"3600": 300, "300": 0})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(s) != 2 {
+		t.Errorf("expected %d got %d", 2, len(s))
+		return
+	}
+
+	if s[0].MaxAge != time.Duration(300)*time.Second || s[0].Interval != 0 {
+		t.Errorf("unexpected rule %v", s[0])
+	}
+
+	if s[1].MaxAge != time.Hour || s[1].Interval != time.Duration(300)*time.Second {
+		t.Errorf("unexpected rule %v", s[1])
+	}
+
+	if s.MaxAge() != time.Hour {
+		t.Errorf("expected %s got %s", time.Hour, s.MaxAge())
+	}
+
+	if BackfillRevalidationSchedule(nil).MaxAge() != 0 {
+		t.Errorf("expected %d got %s", 0, BackfillRevalidationSchedule(nil).MaxAge())
+	}
+
+	_, err = newBackfillRevalidationSchedule(map[string]int{"5m": 0})
+	if err == nil {
+		t.Errorf("expected error for invalid age")
+	}
+
+	_, err = newBackfillRevalidationSchedule(map[string]int{"300": -1})
+	if err == nil {
+		t.Errorf("expected error for invalid interval")
+	}
+}
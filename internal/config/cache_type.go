@@ -29,6 +29,10 @@ const (
 	CacheTypeBbolt
 	// CacheTypeBadgerDB indicates a BadgerDB cache
 	CacheTypeBadgerDB
+	// CacheTypeTiered indicates a memory cache in front of another cache
+	CacheTypeTiered
+	// CacheTypeMemcached indicates a Memcached cache
+	CacheTypeMemcached
 )
 
 // CacheTypeNames is a map of cache types keyed by name
@@ -38,6 +42,8 @@ var CacheTypeNames = map[string]CacheType{
 	"redis":      CacheTypeRedis,
 	"bbolt":      CacheTypeBbolt,
 	"badger":     CacheTypeBadgerDB,
+	"tiered":     CacheTypeTiered,
+	"memcached":  CacheTypeMemcached,
 }
 
 // CacheTypeValues is a map of cache types keyed by internal id
@@ -47,6 +53,8 @@ var CacheTypeValues = map[CacheType]string{
 	CacheTypeRedis:      "redis",
 	CacheTypeBbolt:      "bbolt",
 	CacheTypeBadgerDB:   "badger",
+	CacheTypeTiered:     "tiered",
+	CacheTypeMemcached:  "memcached",
 }
 
 func (t CacheType) String() string {
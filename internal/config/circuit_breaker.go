@@ -0,0 +1,99 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitBreakerConfig is a collection of Configurations for the circuit breaker that stops
+// Trickster from making requests to an origin that is failing or slow
+type CircuitBreakerConfig struct {
+	// FailureRatio is the ratio of failed requests to the origin, between 0 and 1, at which the circuit
+	// opens and further requests fail fast. 0 disables the circuit breaker
+	FailureRatio float64 `toml:"failure_ratio"`
+	// LatencyThresholdMS is the time, in milliseconds, after which a request that has not
+	// received a response is counted as failed. 0 counts only errors as failures
+	LatencyThresholdMS int `toml:"latency_threshold_ms"`
+	// MinRequests is the minimum number of requests in the window before the circuit may open
+	MinRequests int `toml:"min_requests"`
+	// WindowSecs is the duration of the window in which the ratio of failed requests is measured
+	WindowSecs int `toml:"window_secs"`
+	// OpenSecs is the time that the circuit remains open before a single request is permitted
+	// to test the origin. The circuit closes if the request succeeds
+	OpenSecs int `toml:"open_secs"`
+	// ServeStale indicates that expired objects in the cache are served while the circuit is open,
+	// rather than failing the requests for them
+	ServeStale bool `toml:"serve_stale"`
+
+	// LatencyThreshold is the time.Duration representation of LatencyThresholdMS
+	LatencyThreshold time.Duration `toml:"-"`
+	// Window is the time.Duration representation of WindowSecs
+	Window time.Duration `toml:"-"`
+	// OpenDuration is the time.Duration representation of OpenSecs
+	OpenDuration time.Duration `toml:"-"`
+}
+
+// NewCircuitBreakerConfig returns a CircuitBreakerConfig with the default settings
+func NewCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		MinRequests: defaultCircuitBreakerMinRequests,
+		WindowSecs:  defaultCircuitBreakerWindowSecs,
+		OpenSecs:    defaultCircuitBreakerOpenSecs,
+	}
+}
+
+// Enabled returns true if the CircuitBreakerConfig has a failure ratio at which the circuit opens
+func (cb *CircuitBreakerConfig) Enabled() bool {
+	return cb.FailureRatio > 0
+}
+
+// verifyCircuitBreakerConfigs validates the circuit breaker of each origin that has one, and sets its durations
+func (c *TricksterConfig) verifyCircuitBreakerConfigs() error {
+
+	for k, oc := range c.Origins {
+
+		cb := &oc.CircuitBreaker
+		if cb.FailureRatio == 0 {
+			continue
+		}
+
+		if cb.FailureRatio < 0 || cb.FailureRatio > 1 {
+			return fmt.Errorf("invalid failure_ratio [%g] provided in circuit breaker config for origin [%s]",
+				cb.FailureRatio, k)
+		}
+		if cb.LatencyThresholdMS < 0 {
+			return fmt.Errorf("invalid latency_threshold_ms [%d] provided in circuit breaker config for origin [%s]",
+				cb.LatencyThresholdMS, k)
+		}
+		if cb.MinRequests <= 0 {
+			return fmt.Errorf("invalid min_requests [%d] provided in circuit breaker config for origin [%s]",
+				cb.MinRequests, k)
+		}
+		if cb.WindowSecs <= 0 {
+			return fmt.Errorf("invalid window_secs [%d] provided in circuit breaker config for origin [%s]",
+				cb.WindowSecs, k)
+		}
+		if cb.OpenSecs <= 0 {
+			return fmt.Errorf("invalid open_secs [%d] provided in circuit breaker config for origin [%s]",
+				cb.OpenSecs, k)
+		}
+
+		cb.LatencyThreshold = time.Duration(cb.LatencyThresholdMS) * time.Millisecond
+		cb.Window = time.Duration(cb.WindowSecs) * time.Second
+		cb.OpenDuration = time.Duration(cb.OpenSecs) * time.Second
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyCircuitBreakerConfigs(t *testing.T) {
+
+	config := NewConfig()
+	cb := &config.Origins["default"].CircuitBreaker
+
+	// the circuit breaker is disabled by default, so its settings aren't checked
+	cb.MinRequests = 0
+	err := config.verifyCircuitBreakerConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+
+	cb.FailureRatio = 0.5
+	err = config.verifyCircuitBreakerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid min_requests")
+	}
+
+	cb.MinRequests = 10
+	err = config.verifyCircuitBreakerConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if cb.OpenDuration != defaultCircuitBreakerOpenSecs*time.Second {
+		t.Errorf("expected %d got %d", defaultCircuitBreakerOpenSecs*time.Second, cb.OpenDuration)
+	}
+
+	cb.FailureRatio = 1.5
+	err = config.verifyCircuitBreakerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid failure_ratio")
+	}
+
+	cb.FailureRatio = 1
+	cb.LatencyThresholdMS = -1
+	err = config.verifyCircuitBreakerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid latency_threshold_ms")
+	}
+
+	cb.LatencyThresholdMS = 0
+	cb.WindowSecs = 0
+	err = config.verifyCircuitBreakerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid window_secs")
+	}
+
+	cb.WindowSecs = 1
+	cb.OpenSecs = 0
+	err = config.verifyCircuitBreakerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid open_secs")
+	}
+}
@@ -0,0 +1,90 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compressionEncodings are the content encodings in which Trickster is able to compress responses
+var compressionEncodings = map[string]bool{"br": true, "zstd": true, "gzip": true}
+
+// CompressionConfig is a collection of Configurations for compressing the responses to clients
+type CompressionConfig struct {
+	// EncodingList is the list of content encodings (br, zstd and gzip) offered to clients, in order
+	// of preference when a client accepts several equally. An empty list disables compression
+	EncodingList []string `toml:"encodings"`
+	// MinSizeBytes is the size below which a response is not compressed
+	MinSizeBytes int `toml:"min_size_bytes"`
+	// ContentTypeList is the list of the media types of the responses that are compressed
+	ContentTypeList []string `toml:"content_types"`
+
+	// ContentTypes is the map version of ContentTypeList for fast lookup
+	ContentTypes map[string]bool `toml:"-"`
+}
+
+// NewCompressionConfig returns a CompressionConfig with the default settings
+func NewCompressionConfig() *CompressionConfig {
+	return &CompressionConfig{
+		EncodingList:    defaultCompressionEncodings(),
+		MinSizeBytes:    defaultCompressionMinSizeBytes,
+		ContentTypeList: defaultCompressableTypes(),
+	}
+}
+
+// Clone returns an exact copy of the subject *CompressionConfig
+func (cc *CompressionConfig) Clone() *CompressionConfig {
+	c := &CompressionConfig{MinSizeBytes: cc.MinSizeBytes}
+	if cc.EncodingList != nil {
+		c.EncodingList = make([]string, len(cc.EncodingList))
+		copy(c.EncodingList, cc.EncodingList)
+	}
+	if cc.ContentTypeList != nil {
+		c.ContentTypeList = make([]string, len(cc.ContentTypeList))
+		copy(c.ContentTypeList, cc.ContentTypeList)
+	}
+	if cc.ContentTypes != nil {
+		c.ContentTypes = make(map[string]bool)
+		for k := range cc.ContentTypes {
+			c.ContentTypes[k] = true
+		}
+	}
+	return c
+}
+
+// verifyCompressionConfig validates the compression config, and maps its content types
+func (c *TricksterConfig) verifyCompressionConfig() error {
+
+	cc := c.Compression
+
+	for i, e := range cc.EncodingList {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !compressionEncodings[e] {
+			return fmt.Errorf("invalid encoding [%s] provided in compression config", cc.EncodingList[i])
+		}
+		cc.EncodingList[i] = e
+	}
+
+	if cc.MinSizeBytes < 0 {
+		return fmt.Errorf("invalid min_size_bytes [%d] provided in compression config", cc.MinSizeBytes)
+	}
+
+	cc.ContentTypes = make(map[string]bool)
+	for _, t := range cc.ContentTypeList {
+		cc.ContentTypes[strings.ToLower(t)] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+func TestCompressionConfigClone(t *testing.T) {
+
+	cc := NewCompressionConfig()
+	cc.ContentTypes = map[string]bool{"text/plain": true}
+
+	cc2 := cc.Clone()
+	cc2.EncodingList[0] = "gzip"
+	cc2.ContentTypeList[0] = "text/csv"
+	cc2.ContentTypes["text/csv"] = true
+
+	if cc.EncodingList[0] != "br" {
+		t.Errorf("expected %s got %s", "br", cc.EncodingList[0])
+	}
+	if cc.ContentTypeList[0] == "text/csv" || cc.ContentTypes["text/csv"] {
+		t.Errorf("expected the clone's content types to be independent")
+	}
+	if cc2.MinSizeBytes != defaultCompressionMinSizeBytes {
+		t.Errorf("expected %d got %d", defaultCompressionMinSizeBytes, cc2.MinSizeBytes)
+	}
+}
+
+func TestVerifyCompressionConfig(t *testing.T) {
+
+	c := NewConfig()
+	c.Compression.EncodingList = []string{" Gzip ", "zstd"}
+	c.Compression.ContentTypeList = []string{"Application/JSON"}
+
+	if err := c.verifyCompressionConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Compression.EncodingList[0] != "gzip" {
+		t.Errorf("expected %s got %s", "gzip", c.Compression.EncodingList[0])
+	}
+	if !c.Compression.ContentTypes["application/json"] {
+		t.Errorf("expected application/json to be compressed")
+	}
+
+	c.Compression.EncodingList = nil
+	c.Compression.MinSizeBytes = -1
+	expected := "invalid min_size_bytes [-1] provided in compression config"
+	if err := c.verifyCompressionConfig(); err == nil || err.Error() != expected {
+		t.Errorf("expected error `%s` got `%v`", expected, err)
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -48,6 +50,18 @@ var Metrics *MetricsConfig
 // NegativeCacheConfigs is the NegativeCacheConfig subsection of the Running Configuration
 var NegativeCacheConfigs map[string]NegativeCacheConfig
 
+// Rules is the RuleSetConfig subsection of the Running Configuration
+var Rules map[string]RuleSetConfig
+
+// Limits is the LimitConfig subsection of the Running Configuration
+var Limits map[string]*LimitConfig
+
+// Authenticators is the AuthenticatorConfig subsection of the Running Configuration
+var Authenticators map[string]*AuthenticatorConfig
+
+// Compression is the CompressionConfig subsection of the Running Configuration
+var Compression *CompressionConfig
+
 // Flags is a collection of command line flags that Trickster loads.
 var Flags = TricksterFlags{}
 var providedOriginURL string
@@ -73,6 +87,14 @@ type TricksterConfig struct {
 	Metrics *MetricsConfig `toml:"metrics"`
 	// NegativeCacheConfigs is a map of NegativeCacheConfigs
 	NegativeCacheConfigs map[string]NegativeCacheConfig `toml:"negative_caches"`
+	// Rules is a map of RuleSetConfigs
+	Rules map[string]RuleSetConfig `toml:"rules"`
+	// Limits is a map of LimitConfigs
+	Limits map[string]*LimitConfig `toml:"limits"`
+	// Authenticators is a map of AuthenticatorConfigs
+	Authenticators map[string]*AuthenticatorConfig `toml:"authenticators"`
+	// Compression provides configurations for compressing the responses to clients
+	Compression *CompressionConfig `toml:"compression"`
 
 	activeCaches map[string]bool
 }
@@ -85,6 +107,14 @@ type MainConfig struct {
 	ConfigHandlerPath string `toml:"config_handler_path"`
 	// PingHandlerPath provides the path to register the Ping Handler for checking that Trickster is running
 	PingHandlerPath string `toml:"ping_handler_path"`
+	// CacheBrowserHandlerPath provides the path to register the Cache Browser Handler for listing the objects in each cache
+	CacheBrowserHandlerPath string `toml:"cache_browser_handler_path"`
+	// CacheArchiveHandlerPath provides the path to register the Cache Archive Handler for exporting and importing
+	// the objects in each cache. It is disabled by default
+	CacheArchiveHandlerPath string `toml:"cache_archive_handler_path"`
+	// CacheArchiveAuthenticatorName is the name of the Authenticator that authenticates requests to the
+	// Cache Archive Handler, which is required when the handler is enabled
+	CacheArchiveAuthenticatorName string `toml:"cache_archive_authenticator"`
 }
 
 // OriginConfig is a collection of configurations for prometheus origins proxied by Trickster
@@ -105,6 +135,22 @@ type OriginConfig struct {
 	KeepAliveTimeoutSecs int64 `toml:"keep_alive_timeout_secs"`
 	// MaxIdleConns defines maximum number of open keep-alive connections to maintain
 	MaxIdleConns int `toml:"max_idle_conns"`
+	// MaxConnsPerHost is the maximum number of connections to each host of the origin. HTTP/2 requests
+	// share connections, so a small number of connections can carry many requests. 0 permits any number
+	MaxConnsPerHost int `toml:"max_conns_per_host"`
+	// HTTP2 indicates whether HTTP/2 is negotiated with an https origin, falling back to HTTP/1.1
+	// if the origin does not support it
+	HTTP2 bool `toml:"http2"`
+	// H2C indicates whether cleartext HTTP/2 (h2c) is used, with prior knowledge, to make requests to
+	// an http origin. The origin must support h2c
+	H2C bool `toml:"h2c"`
+	// MaxUpstreamConcurrency is the maximum number of requests to the origin in flight at once.
+	// Further requests wait for a request in flight to complete. 0 permits any number
+	MaxUpstreamConcurrency int `toml:"max_upstream_concurrency"`
+	// LimitNames is the list of names of the Limits applied to requests for all paths of this origin
+	LimitNames []string `toml:"limits"`
+	// AuthenticatorName is the name of the Authenticator that authenticates requests for all paths of this origin
+	AuthenticatorName string `toml:"authenticator"`
 	// CacheName provides the name of the configured cache where the origin client will store it's cache data
 	CacheName string `toml:"cache_name"`
 	// CacheKeyPrefix defines the cache key prefix the origin will use when writing objects to the cache
@@ -127,12 +173,29 @@ type OriginConfig struct {
 	// BackfillToleranceSecs prevents values with timestamps newer than the provided number of seconds from being cached
 	// this allows propagation of upstream backfill operations that modify recently-served data
 	BackfillToleranceSecs int64 `toml:"backfill_tolerance_secs"`
+	// BackfillRevalidationSecs maps the max age, in seconds, of recently-cached timeseries data to the minimum
+	// number of seconds between refetches of that data from the origin, in order to correct any backfilled values
+	BackfillRevalidationSecs map[string]int `toml:"backfill_revalidation_secs"`
+	// StepLadderSecs is a list of step durations, in seconds, to which timeseries request steps are snapped up,
+	// so that requests for nearly-identical steps share a cache key
+	StepLadderSecs []int `toml:"step_ladder_secs"`
+	// StepLadderResample, when true, resamples responses for snapped requests back to the client's requested step
+	StepLadderResample bool `toml:"step_ladder_resample"`
+	// Warmer provides options for periodically warming the origin's cache
+	Warmer WarmerConfig `toml:"warmer"`
+	// Retries provides options for retrying and hedging requests to the origin
+	Retries RetryConfig `toml:"retries"`
+	// CircuitBreaker provides options for failing fast while the origin is failing or slow
+	CircuitBreaker CircuitBreakerConfig `toml:"circuit_breaker"`
 	// PathList is a list of PathConfigs that control the behavior of the given paths when requested
 	Paths map[string]*PathConfig `toml:"paths"`
 	// NegativeCacheName provides the name of the Negative Cache Config to be used by this Origin
 	NegativeCacheName string `toml:"negative_cache_name"`
 	// TimeseriesTTLSecs specifies the cache TTL of timeseries objects
 	TimeseriesTTLSecs int `toml:"timeseries_ttl_secs"`
+	// TimeseriesEmptyExtentTTLSecs specifies how long a queried range of a cached timeseries that returned no data
+	// is considered covered, before it is queried from the origin again. 0 means until the timeseries expires
+	TimeseriesEmptyExtentTTLSecs int `toml:"timeseries_empty_extent_ttl_secs"`
 	// TimeseriesTTLSecs specifies the cache TTL of fast forward data
 	FastForwardTTLSecs int `toml:"fastforward_ttl_secs"`
 	// MaxTTLSecs specifies the maximum allowed TTL for any cache object
@@ -166,10 +229,17 @@ type OriginConfig struct {
 	Timeout time.Duration `toml:"-"`
 	// BackfillTolerance is the time.Duration representation of BackfillToleranceSecs
 	BackfillTolerance time.Duration `toml:"-"`
+	// BackfillRevalidation is the parsed representation of BackfillRevalidationSecs
+	BackfillRevalidation BackfillRevalidationSchedule `toml:"-"`
+	// StepLadder is the time.Duration representation of StepLadderSecs, sorted from finest to coarsest
+	StepLadder []time.Duration `toml:"-"`
 	// ValueRetention is the time.Duration representation of ValueRetentionSecs
 	ValueRetention time.Duration `toml:"-"`
 	// Scheme is the layer 7 protocol indicator (e.g. 'http'), derived from OriginURL
 	Scheme string `toml:"-"`
+	// UnixSocketPath is the path of the Unix domain socket through which the origin is reached,
+	// derived from an OriginURL with an http+unix or https+unix scheme
+	UnixSocketPath string `toml:"-"`
 	// Host is the upstream hostname/IP[:port] the origin client will connect to when fetching uncached data, derived from OriginURL
 	Host string `toml:"-"`
 	// PathPrefix provides any prefix added to the front of the requested path when constructing the upstream request url, derived from OriginURL
@@ -182,6 +252,8 @@ type OriginConfig struct {
 	TimeseriesEvictionMethod TimeseriesEvictionMethod `toml:"-"`
 	// TimeseriesTTL is the parsed value of TimeseriesTTLSecs
 	TimeseriesTTL time.Duration `toml:"-"`
+	// TimeseriesEmptyExtentTTL is the parsed value of TimeseriesEmptyExtentTTLSecs
+	TimeseriesEmptyExtentTTL time.Duration `toml:"-"`
 	// FastForwardTTL is the parsed value of FastForwardTTL
 	FastForwardTTL time.Duration `toml:"-"`
 	// FastForwardPath is the PathConfig to use for upstream Fast Forward Requests
@@ -210,6 +282,12 @@ type CachingConfig struct {
 	BBolt BBoltCacheConfig `toml:"bbolt"`
 	// Badger provides options for BadgerDB caching
 	Badger BadgerCacheConfig `toml:"badger"`
+	// Memcached provides options for Memcached caching
+	Memcached MemcachedCacheConfig `toml:"memcached"`
+	// Tiered provides options for Tiered caching
+	Tiered TieredCacheConfig `toml:"tiered"`
+	// Encryption provides options for encrypting cached objects at rest
+	Encryption CacheEncryptionConfig `toml:"encryption"`
 
 	//  Synthetic Values
 
@@ -236,9 +314,39 @@ type CacheIndexConfig struct {
 	// MaxSizeBackoffObjects indicates how far under max_size_objects the cache size must
 	// be to complete object-size-based eviction exercise.
 	MaxSizeBackoffObjects int64 `toml:"max_size_backoff_objects"`
+	// EvictionPolicyName specifies which policy ("lru", "lfu", "2q", "gds") selects the objects
+	// to evict when the cache exceeds its maximum size
+	EvictionPolicyName string `toml:"eviction_policy"`
+	// RefetchCostBytes is the cost of refetching an object from the origin, beyond transferring its bytes,
+	// expressed as an equivalent number of bytes. It is used by the "gds" eviction policy
+	RefetchCostBytes int64 `toml:"refetch_cost_bytes"`
+	// OriginQuotas maps the names of origins that use the cache to their quotas of it, which the
+	// Index enforces by evicting the origin's objects before those of the rest of the cache
+	OriginQuotas map[string]*CacheOriginQuotaConfig `toml:"origin_quotas"`
 
 	ReapInterval  time.Duration `toml:"-"`
 	FlushInterval time.Duration `toml:"-"`
+	// EvictionPolicy is the parsed value of EvictionPolicyName
+	EvictionPolicy EvictionPolicy `toml:"-"`
+	// OriginKeyPrefixes maps the names of the origins that use the cache to the prefix of their
+	// cache keys, by which the Index determines the origin that owns each object
+	OriginKeyPrefixes map[string]string `toml:"-"`
+}
+
+// CacheOriginQuotaConfig is a collection of Configurations for limiting an origin's share of a cache
+type CacheOriginQuotaConfig struct {
+	// MaxSizeBytes indicates how large the origin's objects can grow in bytes before the Index
+	// evicts them. 0 means the origin is limited only by the cache's max_size_bytes
+	MaxSizeBytes int64 `toml:"max_size_bytes"`
+	// MaxSizeBackoffBytes indicates how far below max_size_bytes the origin's objects must be
+	// to complete a byte-size-based eviction exercise.
+	MaxSizeBackoffBytes int64 `toml:"max_size_backoff_bytes"`
+	// MaxSizeObjects indicates how many objects the origin can have before the Index evicts them.
+	// 0 means the origin is limited only by the cache's max_size_objects
+	MaxSizeObjects int64 `toml:"max_size_objects"`
+	// MaxSizeBackoffObjects indicates how far under max_size_objects the origin's objects must be
+	// to complete an object-size-based eviction exercise.
+	MaxSizeBackoffObjects int64 `toml:"max_size_backoff_objects"`
 }
 
 // RedisCacheConfig is a collection of Configurations for Connecting to Redis
@@ -281,6 +389,31 @@ type RedisCacheConfig struct {
 	IdleTimeoutMS int `toml:"idle_timeout_ms"`
 	// IdleCheckFrequencyMS is the frequency of idle checks made by idle connections reaper.
 	IdleCheckFrequencyMS int `toml:"idle_check_frequency_ms"`
+	// KeyNamespace is prepended to the key of each object the cache stores in Redis, so that
+	// several Trickster deployments can share a Redis database without their keys colliding
+	KeyNamespace string `toml:"key_namespace"`
+	// StaleRetentionSecs is how long Redis retains an object after it expires, so that it can be revalidated
+	StaleRetentionSecs int `toml:"stale_retention_secs"`
+	// ClientCacheMaxObjects is the number of objects retrieved from Redis that are cached in-process,
+	// and invalidated through Redis client-side caching. 0 disables client-side caching
+	ClientCacheMaxObjects int `toml:"client_cache_max_objects"`
+}
+
+// MemcachedCacheConfig is a collection of Configurations for Connecting to Memcached
+type MemcachedCacheConfig struct {
+	// Servers represents the FQDN:port or IPAddress:Port of each Memcached server. Keys are distributed
+	// across the servers using consistent hashing
+	Servers []string `toml:"servers"`
+	// DialTimeoutMS is the timeout for establishing new connections.
+	DialTimeoutMS int `toml:"dial_timeout_ms"`
+	// ReadTimeoutMS is the timeout for reading a response from a server.
+	ReadTimeoutMS int `toml:"read_timeout_ms"`
+	// WriteTimeoutMS is the timeout for writing a request to a server.
+	WriteTimeoutMS int `toml:"write_timeout_ms"`
+	// PoolSize is the maximum number of idle connections retained for each server.
+	PoolSize int `toml:"pool_size"`
+	// MaxItemSizeBytes is the Memcached server's item size limit. Larger objects are split across multiple keys.
+	MaxItemSizeBytes int `toml:"max_item_size_bytes"`
 }
 
 // BadgerCacheConfig is a collection of Configurations for storing cached data on the Filesystem in a Badger key-value store
@@ -299,6 +432,28 @@ type BBoltCacheConfig struct {
 	Bucket string `toml:"bucket"`
 }
 
+// TieredCacheConfig is a collection of Configurations for composing a memory cache in front of a shared cache
+type TieredCacheConfig struct {
+	// L1CacheName is the name of the memory cache that serves as the first tier
+	L1CacheName string `toml:"l1_cache_name"`
+	// L2CacheName is the name of the shared cache (e.g., redis or filesystem) that serves as the second tier
+	L2CacheName string `toml:"l2_cache_name"`
+	// WriteMode indicates whether writes reach the second tier before returning ("through"),
+	// or are queued and written to it asynchronously ("behind")
+	WriteMode string `toml:"write_mode"`
+	// WriteBehindQueueSize is the number of writes that may be queued for the second tier when WriteMode is "behind"
+	WriteBehindQueueSize int `toml:"write_behind_queue_size"`
+	// L1MaxTTLSecs caps the TTL of objects stored in the first tier, and is the TTL of objects promoted into it
+	L1MaxTTLSecs int `toml:"l1_max_ttl_secs"`
+	// L2MaxTTLSecs caps the TTL of objects stored in the second tier. 0 means uncapped
+	L2MaxTTLSecs int `toml:"l2_max_ttl_secs"`
+
+	// L1MaxTTL is the parsed value of L1MaxTTLSecs
+	L1MaxTTL time.Duration `toml:"-"`
+	// L2MaxTTL is the parsed value of L2MaxTTLSecs
+	L2MaxTTL time.Duration `toml:"-"`
+}
+
 // FilesystemCacheConfig is a collection of Configurations for storing cached data on the Filesystem
 type FilesystemCacheConfig struct {
 	// CachePath represents the path on disk where our cache will live
@@ -307,16 +462,32 @@ type FilesystemCacheConfig struct {
 
 // FrontendConfig is a collection of configurations for the main http frontend for the application
 type FrontendConfig struct {
-	// ListenAddress is IP address for the main http listener for the application
+	// ListenAddress is IP address for the main http listener for the application,
+	// or the path of its Unix domain socket (unix:///path/to/socket)
 	ListenAddress string `toml:"listen_address"`
 	// ListenPort is TCP Port for the main http listener for the application
 	ListenPort int `toml:"listen_port"`
-	// TLSListenAddress is IP address for the tls  http listener for the application
+	// TLSListenAddress is IP address for the tls  http listener for the application,
+	// or the path of its Unix domain socket (unix:///path/to/socket)
 	TLSListenAddress string `toml:"tls_listen_address"`
 	// TLSListenPort is the TCP Port for the tls http listener for the application
 	TLSListenPort int `toml:"tls_listen_port"`
 	// ConnectionsLimit indicates how many concurrent front end connections trickster will handle at any time
 	ConnectionsLimit int `toml:"connections_limit"`
+	// HTTP2 indicates whether the TLS listener negotiates HTTP/2 with clients that support it
+	HTTP2 bool `toml:"http2"`
+	// H2C indicates whether the plaintext listener accepts cleartext HTTP/2 (h2c) connections,
+	// both with prior knowledge and by upgrading HTTP/1.1 requests
+	H2C bool `toml:"h2c"`
+	// HTTP2MaxConcurrentStreams is the maximum number of concurrent streams on each HTTP/2 connection.
+	// 0 uses the default of 250
+	HTTP2MaxConcurrentStreams int `toml:"http2_max_concurrent_streams"`
+	// UnixSocketMode is the octal file mode (e.g., '0660') of the Unix domain sockets of the listeners
+	// whose addresses are socket paths. Empty leaves the mode that results from the process's umask
+	UnixSocketMode string `toml:"unix_socket_mode"`
+
+	// UnixSocketFileMode is the os.FileMode representation of UnixSocketMode
+	UnixSocketFileMode os.FileMode `toml:"-"`
 
 	// ServeTLS indicates whether to listen and serve on the TLS port, meaning
 	// at least one origin configuration has a valid certificate and key file configured.
@@ -333,10 +504,16 @@ type LoggingConfig struct {
 
 // MetricsConfig is a collection of Metrics Collection configurations
 type MetricsConfig struct {
-	// ListenAddress is IP address from which the Application Metrics are available for pulling at /metrics
+	// ListenAddress is IP address from which the Application Metrics are available for pulling at /metrics,
+	// or the path of a Unix domain socket (unix:///path/to/socket)
 	ListenAddress string `toml:"listen_address"`
 	// ListenPort is TCP Port from which the Application Metrics are available for pulling at /metrics
 	ListenPort int `toml:"listen_port"`
+	// UnixSocketMode is the octal file mode (e.g., '0660') of the metrics listener's Unix domain socket
+	UnixSocketMode string `toml:"unix_socket_mode"`
+
+	// UnixSocketFileMode is the os.FileMode representation of UnixSocketMode
+	UnixSocketFileMode os.FileMode `toml:"-"`
 }
 
 // NegativeCacheConfig is a collection of response codes and their TTLs
@@ -362,8 +539,8 @@ func NewConfig() *TricksterConfig {
 			LogLevel: defaultLogLevel,
 		},
 		Main: &MainConfig{
-			ConfigHandlerPath: defaultConfigHandlerPath,
-			PingHandlerPath:   defaultPingHandlerPath,
+			ConfigHandlerPath:       defaultConfigHandlerPath,
+			PingHandlerPath:         defaultPingHandlerPath,
 		},
 		Metrics: &MetricsConfig{
 			ListenPort: defaultMetricsListenPort,
@@ -377,6 +554,10 @@ func NewConfig() *TricksterConfig {
 		NegativeCacheConfigs: map[string]NegativeCacheConfig{
 			"default": NewNegativeCacheConfig(),
 		},
+		Rules:          make(map[string]RuleSetConfig),
+		Limits:         make(map[string]*LimitConfig),
+		Authenticators: make(map[string]*AuthenticatorConfig),
+		Compression:    NewCompressionConfig(),
 	}
 }
 
@@ -391,10 +572,12 @@ func NewCacheConfig() *CachingConfig {
 	return &CachingConfig{
 		CacheType:   defaultCacheType,
 		CacheTypeID: defaultCacheTypeID,
-		Redis:       RedisCacheConfig{ClientType: defaultRedisClientType, Protocol: defaultRedisProtocol, Endpoint: defaultRedisEndpoint, Endpoints: []string{defaultRedisEndpoint}},
+		Redis:       RedisCacheConfig{ClientType: defaultRedisClientType, Protocol: defaultRedisProtocol, Endpoint: defaultRedisEndpoint, Endpoints: []string{defaultRedisEndpoint}, StaleRetentionSecs: defaultRedisStaleRetentionSecs},
 		Filesystem:  FilesystemCacheConfig{CachePath: defaultCachePath},
 		BBolt:       BBoltCacheConfig{Filename: defaultBBoltFile, Bucket: defaultBBoltBucket},
 		Badger:      BadgerCacheConfig{Directory: defaultCachePath, ValueDirectory: defaultCachePath},
+		Memcached:   MemcachedCacheConfig{Servers: []string{defaultMemcachedServer}, DialTimeoutMS: defaultMemcachedTimeoutMS, ReadTimeoutMS: defaultMemcachedTimeoutMS, WriteTimeoutMS: defaultMemcachedTimeoutMS, PoolSize: defaultMemcachedPoolSize, MaxItemSizeBytes: defaultMemcachedMaxItemSizeBytes},
+		Tiered:      TieredCacheConfig{WriteMode: defaultTieredWriteMode, WriteBehindQueueSize: defaultTieredWriteBehindQueueSize, L1MaxTTLSecs: defaultTieredL1MaxTTLSecs},
 		Index: CacheIndexConfig{
 			ReapIntervalSecs:      defaultCacheIndexReap,
 			FlushIntervalSecs:     defaultCacheIndexFlush,
@@ -402,6 +585,9 @@ func NewCacheConfig() *CachingConfig {
 			MaxSizeBackoffBytes:   defaultMaxSizeBackoffBytes,
 			MaxSizeObjects:        defaultMaxSizeObjects,
 			MaxSizeBackoffObjects: defaultMaxSizeBackoffObjects,
+			EvictionPolicyName:    defaultEvictionPolicyName,
+			EvictionPolicy:        defaultEvictionPolicy,
+			RefetchCostBytes:      defaultRefetchCostBytes,
 		},
 	}
 }
@@ -438,6 +624,9 @@ func NewOriginConfig() *OriginConfig {
 		MaxObjectSizeBytes:           defaultMaxObjectSizeBytes,
 		TLS:                          &TLSConfig{},
 		CompressableTypeList:         defaultCompressableTypes(),
+		Warmer:                       NewWarmerConfig(),
+		Retries:                      NewRetryConfig(),
+		CircuitBreaker:               NewCircuitBreakerConfig(),
 	}
 }
 
@@ -462,12 +651,58 @@ func (c *TricksterConfig) setDefaults(metadata *toml.MetaData) error {
 	}
 
 	err = c.verifyTLSConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyEncryptionConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyWarmerConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyRetryConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyCircuitBreakerConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyPathConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyRuleConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyLimitConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyAuthenticatorConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifyCompressionConfig()
 
 	return err
 }
 
 var pathMembers = []string{"path", "match_type", "handler", "methods", "cache_key_params", "cache_key_headers", "default_ttl_secs",
-	"request_headers", "response_headers", "response_headers", "response_code", "response_body", "no_metrics", "progressive_collapsed_forwarding"}
+	"request_headers", "response_headers", "response_headers", "response_code", "response_body", "no_metrics", "progressive_collapsed_forwarding",
+	"downsample_rules", "downsample_source_steps_secs", "rules", "limits", "authenticator", "timeout_secs"}
 
 func (c *TricksterConfig) validateConfigMappings() error {
 	for k, oc := range c.Origins {
@@ -475,6 +710,29 @@ func (c *TricksterConfig) validateConfigMappings() error {
 			return fmt.Errorf("invalid cache name [%s] provided in origin config [%s]", oc.CacheName, k)
 		}
 	}
+	for k, cc := range c.Caches {
+		p, ok := evictionPolicyNames[cc.Index.EvictionPolicyName]
+		if !ok {
+			return fmt.Errorf("invalid eviction policy [%s] provided in cache config [%s]", cc.Index.EvictionPolicyName, k)
+		}
+		cc.Index.EvictionPolicy = p
+		if cc.CacheTypeID != CacheTypeTiered {
+			continue
+		}
+		if l1, ok := c.Caches[cc.Tiered.L1CacheName]; !ok || l1.CacheTypeID != CacheTypeMemory {
+			return fmt.Errorf("invalid l1 cache name [%s] provided in tiered cache config [%s]", cc.Tiered.L1CacheName, k)
+		}
+		if l2, ok := c.Caches[cc.Tiered.L2CacheName]; !ok || l2.CacheTypeID == CacheTypeMemory ||
+			l2.CacheTypeID == CacheTypeTiered {
+			return fmt.Errorf("invalid l2 cache name [%s] provided in tiered cache config [%s]", cc.Tiered.L2CacheName, k)
+		}
+		if cc.Tiered.L1MaxTTLSecs <= 0 {
+			return fmt.Errorf("invalid l1 max ttl [%d] provided in tiered cache config [%s]", cc.Tiered.L1MaxTTLSecs, k)
+		}
+		if cc.Tiered.WriteMode != "through" && cc.Tiered.WriteMode != "behind" {
+			return fmt.Errorf("invalid write mode [%s] provided in tiered cache config [%s]", cc.Tiered.WriteMode, k)
+		}
+	}
 	return nil
 }
 
@@ -528,6 +786,30 @@ func (c *TricksterConfig) processOriginConfigs(metadata *toml.MetaData) {
 			oc.MaxIdleConns = v.MaxIdleConns
 		}
 
+		if metadata.IsDefined("origins", k, "max_conns_per_host") {
+			oc.MaxConnsPerHost = v.MaxConnsPerHost
+		}
+
+		if metadata.IsDefined("origins", k, "http2") {
+			oc.HTTP2 = v.HTTP2
+		}
+
+		if metadata.IsDefined("origins", k, "h2c") {
+			oc.H2C = v.H2C
+		}
+
+		if metadata.IsDefined("origins", k, "max_upstream_concurrency") {
+			oc.MaxUpstreamConcurrency = v.MaxUpstreamConcurrency
+		}
+
+		if metadata.IsDefined("origins", k, "limits") {
+			oc.LimitNames = v.LimitNames
+		}
+
+		if metadata.IsDefined("origins", k, "authenticator") {
+			oc.AuthenticatorName = v.AuthenticatorName
+		}
+
 		if metadata.IsDefined("origins", k, "keep_alive_timeout_secs") {
 			oc.KeepAliveTimeoutSecs = v.KeepAliveTimeoutSecs
 		}
@@ -547,6 +829,10 @@ func (c *TricksterConfig) processOriginConfigs(metadata *toml.MetaData) {
 			oc.TimeseriesTTLSecs = v.TimeseriesTTLSecs
 		}
 
+		if metadata.IsDefined("origins", k, "timeseries_empty_extent_ttl_secs") {
+			oc.TimeseriesEmptyExtentTTLSecs = v.TimeseriesEmptyExtentTTLSecs
+		}
+
 		if metadata.IsDefined("origins", k, "max_ttl_secs") {
 			oc.MaxTTLSecs = v.MaxTTLSecs
 		}
@@ -563,6 +849,90 @@ func (c *TricksterConfig) processOriginConfigs(metadata *toml.MetaData) {
 			oc.BackfillToleranceSecs = v.BackfillToleranceSecs
 		}
 
+		if metadata.IsDefined("origins", k, "backfill_revalidation_secs") {
+			oc.BackfillRevalidationSecs = v.BackfillRevalidationSecs
+		}
+
+		if metadata.IsDefined("origins", k, "step_ladder_secs") {
+			oc.StepLadderSecs = v.StepLadderSecs
+		}
+
+		if metadata.IsDefined("origins", k, "step_ladder_resample") {
+			oc.StepLadderResample = v.StepLadderResample
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "requests") {
+			oc.Warmer.Requests = v.Warmer.Requests
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "dashboard_paths") {
+			oc.Warmer.DashboardPaths = v.Warmer.DashboardPaths
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "interval_secs") {
+			oc.Warmer.IntervalSecs = v.Warmer.IntervalSecs
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "range_secs") {
+			oc.Warmer.RangeSecs = v.Warmer.RangeSecs
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "step_secs") {
+			oc.Warmer.StepSecs = v.Warmer.StepSecs
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "concurrency") {
+			oc.Warmer.Concurrency = v.Warmer.Concurrency
+		}
+
+		if metadata.IsDefined("origins", k, "warmer", "headers") {
+			oc.Warmer.Headers = v.Warmer.Headers
+		}
+
+		if metadata.IsDefined("origins", k, "retries", "max_retries") {
+			oc.Retries.MaxRetries = v.Retries.MaxRetries
+		}
+
+		if metadata.IsDefined("origins", k, "retries", "backoff_ms") {
+			oc.Retries.BackoffMS = v.Retries.BackoffMS
+		}
+
+		if metadata.IsDefined("origins", k, "retries", "max_backoff_ms") {
+			oc.Retries.MaxBackoffMS = v.Retries.MaxBackoffMS
+		}
+
+		if metadata.IsDefined("origins", k, "retries", "statuses") {
+			oc.Retries.Statuses = v.Retries.Statuses
+		}
+
+		if metadata.IsDefined("origins", k, "retries", "hedge_delay_ms") {
+			oc.Retries.HedgeDelayMS = v.Retries.HedgeDelayMS
+		}
+
+		if metadata.IsDefined("origins", k, "circuit_breaker", "failure_ratio") {
+			oc.CircuitBreaker.FailureRatio = v.CircuitBreaker.FailureRatio
+		}
+
+		if metadata.IsDefined("origins", k, "circuit_breaker", "latency_threshold_ms") {
+			oc.CircuitBreaker.LatencyThresholdMS = v.CircuitBreaker.LatencyThresholdMS
+		}
+
+		if metadata.IsDefined("origins", k, "circuit_breaker", "min_requests") {
+			oc.CircuitBreaker.MinRequests = v.CircuitBreaker.MinRequests
+		}
+
+		if metadata.IsDefined("origins", k, "circuit_breaker", "window_secs") {
+			oc.CircuitBreaker.WindowSecs = v.CircuitBreaker.WindowSecs
+		}
+
+		if metadata.IsDefined("origins", k, "circuit_breaker", "open_secs") {
+			oc.CircuitBreaker.OpenSecs = v.CircuitBreaker.OpenSecs
+		}
+
+		if metadata.IsDefined("origins", k, "circuit_breaker", "serve_stale") {
+			oc.CircuitBreaker.ServeStale = v.CircuitBreaker.ServeStale
+		}
+
 		if metadata.IsDefined("origins", k, "paths") {
 			var j = 0
 			for l, p := range v.Paths {
@@ -587,6 +957,33 @@ func (c *TricksterConfig) processOriginConfigs(metadata *toml.MetaData) {
 					p.MatchType = PathMatchTypeExact
 					p.MatchTypeName = p.MatchType.String()
 				}
+
+				if len(p.DownsampleRules) > 0 {
+					p.DownsampleMethods = make(map[string]DownsampleMethod, len(p.DownsampleRules))
+					for fn, mn := range p.DownsampleRules {
+						if dm, ok := DownsampleMethodNames[strings.ToLower(mn)]; ok {
+							p.DownsampleMethods[fn] = dm
+						} else {
+							LoaderWarnings = append(LoaderWarnings, fmt.Sprintf("unknown downsample method '%s' for function '%s' in path %s", mn, fn, p.Path))
+						}
+					}
+				}
+
+				if p.TimeoutSecs > 0 {
+					p.Timeout = time.Duration(p.TimeoutSecs) * time.Second
+				}
+
+				if len(p.DownsampleSourceStepsSecs) > 0 {
+					p.DownsampleSourceSteps = make([]time.Duration, 0, len(p.DownsampleSourceStepsSecs))
+					for _, s := range p.DownsampleSourceStepsSecs {
+						if s > 0 {
+							p.DownsampleSourceSteps = append(p.DownsampleSourceSteps, time.Duration(s)*time.Second)
+						}
+					}
+					sort.Slice(p.DownsampleSourceSteps, func(i, j int) bool {
+						return p.DownsampleSourceSteps[i] > p.DownsampleSourceSteps[j]
+					})
+				}
 				oc.Paths[p.Path+"-"+strings.Join(p.Methods, "-")] = p
 				j++
 			}
@@ -647,6 +1044,14 @@ func (c *TricksterConfig) processCachingConfigs(metadata *toml.MetaData) {
 
 	// setCachingDefaults assumes that processOriginConfigs was just ran
 
+	// the tiers of an active tiered cache are active, even if no origin uses them directly
+	for k, v := range c.Caches {
+		if _, ok := c.activeCaches[k]; ok && strings.ToLower(v.CacheType) == "tiered" {
+			c.activeCaches[v.Tiered.L1CacheName] = true
+			c.activeCaches[v.Tiered.L2CacheName] = true
+		}
+	}
+
 	for k, v := range c.Caches {
 
 		if _, ok := c.activeCaches[k]; !ok {
@@ -689,6 +1094,18 @@ func (c *TricksterConfig) processCachingConfigs(metadata *toml.MetaData) {
 			cc.Index.MaxSizeBackoffObjects = v.Index.MaxSizeBackoffObjects
 		}
 
+		if metadata.IsDefined("caches", k, "index", "eviction_policy") {
+			cc.Index.EvictionPolicyName = strings.ToLower(v.Index.EvictionPolicyName)
+		}
+
+		if metadata.IsDefined("caches", k, "index", "refetch_cost_bytes") {
+			cc.Index.RefetchCostBytes = v.Index.RefetchCostBytes
+		}
+
+		if metadata.IsDefined("caches", k, "index", "origin_quotas") {
+			cc.Index.OriginQuotas = v.Index.OriginQuotas
+		}
+
 		if cc.CacheTypeID == CacheTypeRedis {
 
 			var hasEndpoint, hasEndpoints bool
@@ -781,6 +1198,18 @@ func (c *TricksterConfig) processCachingConfigs(metadata *toml.MetaData) {
 			if metadata.IsDefined("caches", k, "redis", "idle_check_frequency_ms") {
 				cc.Redis.IdleCheckFrequencyMS = v.Redis.IdleCheckFrequencyMS
 			}
+
+			if metadata.IsDefined("caches", k, "redis", "key_namespace") {
+				cc.Redis.KeyNamespace = v.Redis.KeyNamespace
+			}
+
+			if metadata.IsDefined("caches", k, "redis", "stale_retention_secs") {
+				cc.Redis.StaleRetentionSecs = v.Redis.StaleRetentionSecs
+			}
+
+			if metadata.IsDefined("caches", k, "redis", "client_cache_max_objects") {
+				cc.Redis.ClientCacheMaxObjects = v.Redis.ClientCacheMaxObjects
+			}
 		}
 
 		if metadata.IsDefined("caches", k, "filesystem", "cache_path") {
@@ -803,6 +1232,62 @@ func (c *TricksterConfig) processCachingConfigs(metadata *toml.MetaData) {
 			cc.Badger.ValueDirectory = v.Badger.ValueDirectory
 		}
 
+		if metadata.IsDefined("caches", k, "memcached", "servers") {
+			cc.Memcached.Servers = v.Memcached.Servers
+		}
+
+		if metadata.IsDefined("caches", k, "memcached", "dial_timeout_ms") {
+			cc.Memcached.DialTimeoutMS = v.Memcached.DialTimeoutMS
+		}
+
+		if metadata.IsDefined("caches", k, "memcached", "read_timeout_ms") {
+			cc.Memcached.ReadTimeoutMS = v.Memcached.ReadTimeoutMS
+		}
+
+		if metadata.IsDefined("caches", k, "memcached", "write_timeout_ms") {
+			cc.Memcached.WriteTimeoutMS = v.Memcached.WriteTimeoutMS
+		}
+
+		if metadata.IsDefined("caches", k, "memcached", "pool_size") {
+			cc.Memcached.PoolSize = v.Memcached.PoolSize
+		}
+
+		if metadata.IsDefined("caches", k, "memcached", "max_item_size_bytes") {
+			cc.Memcached.MaxItemSizeBytes = v.Memcached.MaxItemSizeBytes
+		}
+
+		if metadata.IsDefined("caches", k, "encryption", "key_id") {
+			cc.Encryption.KeyID = v.Encryption.KeyID
+		}
+
+		if metadata.IsDefined("caches", k, "encryption", "key_files") {
+			cc.Encryption.KeyFiles = v.Encryption.KeyFiles
+		}
+
+		if metadata.IsDefined("caches", k, "tiered", "l1_cache_name") {
+			cc.Tiered.L1CacheName = v.Tiered.L1CacheName
+		}
+
+		if metadata.IsDefined("caches", k, "tiered", "l2_cache_name") {
+			cc.Tiered.L2CacheName = v.Tiered.L2CacheName
+		}
+
+		if metadata.IsDefined("caches", k, "tiered", "write_mode") {
+			cc.Tiered.WriteMode = strings.ToLower(v.Tiered.WriteMode)
+		}
+
+		if metadata.IsDefined("caches", k, "tiered", "write_behind_queue_size") {
+			cc.Tiered.WriteBehindQueueSize = v.Tiered.WriteBehindQueueSize
+		}
+
+		if metadata.IsDefined("caches", k, "tiered", "l1_max_ttl_secs") {
+			cc.Tiered.L1MaxTTLSecs = v.Tiered.L1MaxTTLSecs
+		}
+
+		if metadata.IsDefined("caches", k, "tiered", "l2_max_ttl_secs") {
+			cc.Tiered.L2MaxTTLSecs = v.Tiered.L2MaxTTLSecs
+		}
+
 		c.Caches[k] = cc
 	}
 }
@@ -816,20 +1301,32 @@ func (c *TricksterConfig) copy() *TricksterConfig {
 	nc.Main.ConfigHandlerPath = c.Main.ConfigHandlerPath
 	nc.Main.InstanceID = c.Main.InstanceID
 	nc.Main.PingHandlerPath = c.Main.PingHandlerPath
+	nc.Main.CacheBrowserHandlerPath = c.Main.CacheBrowserHandlerPath
+	nc.Main.CacheArchiveHandlerPath = c.Main.CacheArchiveHandlerPath
+	nc.Main.CacheArchiveAuthenticatorName = c.Main.CacheArchiveAuthenticatorName
 
 	nc.Logging.LogFile = c.Logging.LogFile
 	nc.Logging.LogLevel = c.Logging.LogLevel
 
 	nc.Metrics.ListenAddress = c.Metrics.ListenAddress
 	nc.Metrics.ListenPort = c.Metrics.ListenPort
+	nc.Metrics.UnixSocketMode = c.Metrics.UnixSocketMode
+	nc.Metrics.UnixSocketFileMode = c.Metrics.UnixSocketFileMode
 
 	nc.Frontend.ListenAddress = c.Frontend.ListenAddress
 	nc.Frontend.ListenPort = c.Frontend.ListenPort
 	nc.Frontend.TLSListenAddress = c.Frontend.TLSListenAddress
 	nc.Frontend.TLSListenPort = c.Frontend.TLSListenPort
 	nc.Frontend.ConnectionsLimit = c.Frontend.ConnectionsLimit
+	nc.Frontend.HTTP2 = c.Frontend.HTTP2
+	nc.Frontend.H2C = c.Frontend.H2C
+	nc.Frontend.HTTP2MaxConcurrentStreams = c.Frontend.HTTP2MaxConcurrentStreams
+	nc.Frontend.UnixSocketMode = c.Frontend.UnixSocketMode
+	nc.Frontend.UnixSocketFileMode = c.Frontend.UnixSocketFileMode
 	nc.Frontend.ServeTLS = c.Frontend.ServeTLS
 
+	nc.Compression = c.Compression.Clone()
+
 	for k, v := range c.Origins {
 		nc.Origins[k] = v.Clone()
 	}
@@ -842,6 +1339,18 @@ func (c *TricksterConfig) copy() *TricksterConfig {
 		nc.NegativeCacheConfigs[k] = v.Clone()
 	}
 
+	for k, v := range c.Rules {
+		nc.Rules[k] = v.Clone()
+	}
+
+	for k, v := range c.Limits {
+		nc.Limits[k] = v.Clone()
+	}
+
+	for k, v := range c.Authenticators {
+		nc.Authenticators[k] = v.Clone()
+	}
+
 	return nc
 }
 
@@ -861,6 +1370,7 @@ func (c *TricksterConfig) String() string {
 			}
 			// also strip out potentially sensitive headers
 			hideAuthorizationCredentials(v.HealthCheckHeaders)
+			hideAuthorizationCredentials(v.Warmer.Headers)
 
 			if v.Paths != nil {
 				for _, p := range v.Paths {
@@ -878,6 +1388,16 @@ func (c *TricksterConfig) String() string {
 		}
 	}
 
+	// strip Authenticator credentials
+	for _, v := range cp.Authenticators {
+		if v != nil {
+			v.APIKeys = nil
+			if v.UpstreamAuthorizationValue != "" {
+				v.UpstreamAuthorizationValue = "*****"
+			}
+		}
+	}
+
 	var buf bytes.Buffer
 	e := toml.NewEncoder(&buf)
 	e.Encode(cp)
@@ -902,6 +1422,7 @@ func (oc *OriginConfig) Clone() *OriginConfig {
 	o.DearticulateUpstreamRanges = oc.DearticulateUpstreamRanges
 	o.BackfillTolerance = oc.BackfillTolerance
 	o.BackfillToleranceSecs = oc.BackfillToleranceSecs
+	o.StepLadderResample = oc.StepLadderResample
 	o.CacheName = oc.CacheName
 	o.CacheKeyPrefix = oc.CacheKeyPrefix
 	o.FastForwardDisable = oc.FastForwardDisable
@@ -915,6 +1436,11 @@ func (oc *OriginConfig) Clone() *OriginConfig {
 	o.IsDefault = oc.IsDefault
 	o.KeepAliveTimeoutSecs = oc.KeepAliveTimeoutSecs
 	o.MaxIdleConns = oc.MaxIdleConns
+	o.MaxConnsPerHost = oc.MaxConnsPerHost
+	o.HTTP2 = oc.HTTP2
+	o.H2C = oc.H2C
+	o.MaxUpstreamConcurrency = oc.MaxUpstreamConcurrency
+	o.AuthenticatorName = oc.AuthenticatorName
 	o.MaxTTLSecs = oc.MaxTTLSecs
 	o.MaxTTL = oc.MaxTTL
 	o.MaxObjectSizeBytes = oc.MaxObjectSizeBytes
@@ -924,6 +1450,7 @@ func (oc *OriginConfig) Clone() *OriginConfig {
 	o.PathPrefix = oc.PathPrefix
 	o.RevalidationFactor = oc.RevalidationFactor
 	o.Scheme = oc.Scheme
+	o.UnixSocketPath = oc.UnixSocketPath
 	o.Timeout = oc.Timeout
 	o.TimeoutSecs = oc.TimeoutSecs
 	o.TimeseriesRetention = oc.TimeseriesRetention
@@ -932,8 +1459,32 @@ func (oc *OriginConfig) Clone() *OriginConfig {
 	o.TimeseriesEvictionMethod = oc.TimeseriesEvictionMethod
 	o.TimeseriesTTL = oc.TimeseriesTTL
 	o.TimeseriesTTLSecs = oc.TimeseriesTTLSecs
+	o.TimeseriesEmptyExtentTTL = oc.TimeseriesEmptyExtentTTL
+	o.TimeseriesEmptyExtentTTLSecs = oc.TimeseriesEmptyExtentTTLSecs
 	o.ValueRetention = oc.ValueRetention
 
+	if oc.BackfillRevalidationSecs != nil {
+		o.BackfillRevalidationSecs = make(map[string]int)
+		for k, v := range oc.BackfillRevalidationSecs {
+			o.BackfillRevalidationSecs[k] = v
+		}
+	}
+
+	if oc.BackfillRevalidation != nil {
+		o.BackfillRevalidation = make(BackfillRevalidationSchedule, len(oc.BackfillRevalidation))
+		copy(o.BackfillRevalidation, oc.BackfillRevalidation)
+	}
+
+	if oc.StepLadderSecs != nil {
+		o.StepLadderSecs = make([]int, len(oc.StepLadderSecs))
+		copy(o.StepLadderSecs, oc.StepLadderSecs)
+	}
+
+	if oc.StepLadder != nil {
+		o.StepLadder = make([]time.Duration, len(oc.StepLadder))
+		copy(o.StepLadder, oc.StepLadder)
+	}
+
 	if oc.CompressableTypeList != nil {
 		o.CompressableTypeList = make([]string, len(oc.CompressableTypeList))
 		copy(o.CompressableTypeList, oc.CompressableTypeList)
@@ -951,6 +1502,11 @@ func (oc *OriginConfig) Clone() *OriginConfig {
 		o.HealthCheckHeaders[k] = v
 	}
 
+	if oc.LimitNames != nil {
+		o.LimitNames = make([]string, len(oc.LimitNames))
+		copy(o.LimitNames, oc.LimitNames)
+	}
+
 	o.Paths = make(map[string]*PathConfig)
 	for l, p := range oc.Paths {
 		o.Paths[l] = p.Clone()
@@ -974,6 +1530,10 @@ func (oc *OriginConfig) Clone() *OriginConfig {
 		o.FastForwardPath = oc.FastForwardPath.Clone()
 	}
 
+	o.Warmer = oc.Warmer.Clone()
+	o.Retries = oc.Retries.Clone()
+	o.CircuitBreaker = oc.CircuitBreaker
+
 	return o
 
 }
@@ -994,6 +1554,24 @@ func (cc *CachingConfig) Clone() *CachingConfig {
 	c.Index.MaxSizeObjects = cc.Index.MaxSizeObjects
 	c.Index.ReapInterval = cc.Index.ReapInterval
 	c.Index.ReapIntervalSecs = cc.Index.ReapIntervalSecs
+	c.Index.EvictionPolicyName = cc.Index.EvictionPolicyName
+	c.Index.EvictionPolicy = cc.Index.EvictionPolicy
+	c.Index.RefetchCostBytes = cc.Index.RefetchCostBytes
+
+	if cc.Index.OriginQuotas != nil {
+		c.Index.OriginQuotas = make(map[string]*CacheOriginQuotaConfig, len(cc.Index.OriginQuotas))
+		for k, v := range cc.Index.OriginQuotas {
+			q := *v
+			c.Index.OriginQuotas[k] = &q
+		}
+	}
+
+	if cc.Index.OriginKeyPrefixes != nil {
+		c.Index.OriginKeyPrefixes = make(map[string]string, len(cc.Index.OriginKeyPrefixes))
+		for k, v := range cc.Index.OriginKeyPrefixes {
+			c.Index.OriginKeyPrefixes[k] = v
+		}
+	}
 
 	c.Badger.Directory = cc.Badger.Directory
 	c.Badger.ValueDirectory = cc.Badger.ValueDirectory
@@ -1010,6 +1588,9 @@ func (cc *CachingConfig) Clone() *CachingConfig {
 	c.Redis.Endpoints = cc.Redis.Endpoints
 	c.Redis.IdleCheckFrequencyMS = cc.Redis.IdleCheckFrequencyMS
 	c.Redis.IdleTimeoutMS = cc.Redis.IdleTimeoutMS
+	c.Redis.KeyNamespace = cc.Redis.KeyNamespace
+	c.Redis.StaleRetentionSecs = cc.Redis.StaleRetentionSecs
+	c.Redis.ClientCacheMaxObjects = cc.Redis.ClientCacheMaxObjects
 	c.Redis.MaxConnAgeMS = cc.Redis.MaxConnAgeMS
 	c.Redis.MaxRetries = cc.Redis.MaxRetries
 	c.Redis.MaxRetryBackoffMS = cc.Redis.MaxRetryBackoffMS
@@ -1023,6 +1604,36 @@ func (cc *CachingConfig) Clone() *CachingConfig {
 	c.Redis.SentinelMaster = cc.Redis.SentinelMaster
 	c.Redis.WriteTimeoutMS = cc.Redis.WriteTimeoutMS
 
+	c.Memcached.Servers = cc.Memcached.Servers
+	c.Memcached.DialTimeoutMS = cc.Memcached.DialTimeoutMS
+	c.Memcached.ReadTimeoutMS = cc.Memcached.ReadTimeoutMS
+	c.Memcached.WriteTimeoutMS = cc.Memcached.WriteTimeoutMS
+	c.Memcached.PoolSize = cc.Memcached.PoolSize
+	c.Memcached.MaxItemSizeBytes = cc.Memcached.MaxItemSizeBytes
+
+	c.Encryption.KeyID = cc.Encryption.KeyID
+	if cc.Encryption.KeyFiles != nil {
+		c.Encryption.KeyFiles = make(map[string]string, len(cc.Encryption.KeyFiles))
+		for k, v := range cc.Encryption.KeyFiles {
+			c.Encryption.KeyFiles[k] = v
+		}
+	}
+	if cc.Encryption.Keys != nil {
+		c.Encryption.Keys = make(map[string][]byte, len(cc.Encryption.Keys))
+		for k, v := range cc.Encryption.Keys {
+			c.Encryption.Keys[k] = v
+		}
+	}
+
+	c.Tiered.L1CacheName = cc.Tiered.L1CacheName
+	c.Tiered.L2CacheName = cc.Tiered.L2CacheName
+	c.Tiered.WriteMode = cc.Tiered.WriteMode
+	c.Tiered.WriteBehindQueueSize = cc.Tiered.WriteBehindQueueSize
+	c.Tiered.L1MaxTTLSecs = cc.Tiered.L1MaxTTLSecs
+	c.Tiered.L1MaxTTL = cc.Tiered.L1MaxTTL
+	c.Tiered.L2MaxTTLSecs = cc.Tiered.L2MaxTTLSecs
+	c.Tiered.L2MaxTTL = cc.Tiered.L2MaxTTL
+
 	return c
 
 }
@@ -33,13 +33,23 @@ const (
 
 	defaultCachePath = "/tmp/trickster"
 
-	defaultRedisClientType = "standard"
-	defaultRedisProtocol   = "tcp"
-	defaultRedisEndpoint   = "redis:6379"
+	defaultRedisClientType         = "standard"
+	defaultRedisProtocol           = "tcp"
+	defaultRedisEndpoint           = "redis:6379"
+	defaultRedisStaleRetentionSecs = 3600
 
 	defaultBBoltFile   = "trickster.db"
 	defaultBBoltBucket = "trickster"
 
+	defaultMemcachedServer           = "memcached:11211"
+	defaultMemcachedTimeoutMS        = 1000
+	defaultMemcachedPoolSize         = 8
+	defaultMemcachedMaxItemSizeBytes = 1048576
+
+	defaultTieredWriteMode            = "through"
+	defaultTieredWriteBehindQueueSize = 1024
+	defaultTieredL1MaxTTLSecs         = 300
+
 	defaultCacheIndexReap        = 3
 	defaultCacheIndexFlush       = 5
 	defaultCacheMaxSizeBytes     = 536870912
@@ -47,6 +57,9 @@ const (
 	defaultMaxSizeObjects        = 0
 	defaultMaxSizeBackoffObjects = 100
 	defaultMaxObjectSizeBytes    = 524288
+	defaultEvictionPolicy        = EvictionPolicyLRU
+	defaultEvictionPolicyName    = "lru"
+	defaultRefetchCostBytes      = 65536
 
 	defaultOriginTRF               = 1024
 	defaultOriginTEM               = EvictionMethodOldest
@@ -58,6 +71,27 @@ const (
 	defaultKeepAliveTimeoutSecs    = 300
 	defaultMaxIdleConns            = 20
 
+	defaultWarmerIntervalSecs = 60
+	defaultWarmerRangeSecs    = 21600
+	defaultWarmerStepSecs     = 60
+	defaultWarmerConcurrency  = 4
+
+	defaultRetryBackoffMS    = 100
+	defaultRetryMaxBackoffMS = 2000
+
+	defaultCircuitBreakerMinRequests = 20
+	defaultCircuitBreakerWindowSecs  = 30
+	defaultCircuitBreakerOpenSecs    = 30
+
+	defaultCompressionMinSizeBytes = 1024
+
+	defaultAuthenticatorRealm                 = "trickster"
+	defaultAuthenticatorAPIKeyHeader          = "X-API-Key"
+	defaultAuthenticatorJWKSRefreshSecs       = 3600
+	defaultAuthenticatorIdentityClaim         = "sub"
+	defaultAuthenticatorLeewaySecs            = 60
+	defaultAuthenticatorUpstreamAuthorization = "passthrough"
+
 	defaultHealthCheckPath  = "-"
 	defaultHealthCheckQuery = "-"
 	defaultHealthCheckVerb  = "-"
@@ -66,6 +100,10 @@ const (
 	defaultPingHandlerPath   = "/trickster/ping"
 )
 
+func defaultCompressionEncodings() []string {
+	return []string{"br", "zstd", "gzip"}
+}
+
 func defaultCompressableTypes() []string {
 	return []string{
 		"text/html",
@@ -0,0 +1,59 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import "strconv"
+
+// DownsampleMethod enumerates the methodologies for deriving a coarser-step
+// data point from a window of finer-step data points
+type DownsampleMethod int
+
+const (
+	// DownsampleMethodSample uses the finer-step value found exactly on the coarser step boundary
+	DownsampleMethodSample = DownsampleMethod(iota)
+	// DownsampleMethodMin uses the minimum of the finer-step values in the coarser step window
+	DownsampleMethodMin
+	// DownsampleMethodMax uses the maximum of the finer-step values in the coarser step window
+	DownsampleMethodMax
+	// DownsampleMethodMean uses the average of the finer-step values in the coarser step window
+	DownsampleMethodMean
+	// DownsampleMethodSum uses the sum of the finer-step values in the coarser step window
+	DownsampleMethodSum
+)
+
+// DownsampleMethodNames is a map of downsample methods keyed by name
+var DownsampleMethodNames = map[string]DownsampleMethod{
+	"sample": DownsampleMethodSample,
+	"min":    DownsampleMethodMin,
+	"max":    DownsampleMethodMax,
+	"mean":   DownsampleMethodMean,
+	"avg":    DownsampleMethodMean,
+	"sum":    DownsampleMethodSum,
+}
+
+// DownsampleMethodValues is a map of downsample methods keyed by internal id
+var DownsampleMethodValues = map[DownsampleMethod]string{
+	DownsampleMethodSample: "sample",
+	DownsampleMethodMin:    "min",
+	DownsampleMethodMax:    "max",
+	DownsampleMethodMean:   "mean",
+	DownsampleMethodSum:    "sum",
+}
+
+func (t DownsampleMethod) String() string {
+	if v, ok := DownsampleMethodValues[t]; ok {
+		return v
+	}
+	return strconv.Itoa(int(t))
+}
@@ -0,0 +1,42 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+func TestDownsampleMethodString(t *testing.T) {
+
+	t1 := DownsampleMethodSample
+	t2 := DownsampleMethodMax
+	var t3 DownsampleMethod = 10
+
+	if t1.String() != "sample" {
+		t.Errorf("expected %s got %s", "sample", t1.String())
+	}
+
+	if t2.String() != "max" {
+		t.Errorf("expected %s got %s", "max", t2.String())
+	}
+
+	if t3.String() != "10" {
+		t.Errorf("expected %s got %s", "10", t3.String())
+	}
+
+	if DownsampleMethodNames["avg"] != DownsampleMethodMean {
+		t.Errorf("expected %s got %s", DownsampleMethodMean, DownsampleMethodNames["avg"])
+	}
+
+}
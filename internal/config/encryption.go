@@ -0,0 +1,92 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+)
+
+// CacheEncryptionConfig is a collection of Configurations for encrypting cached objects at rest with AES-GCM
+type CacheEncryptionConfig struct {
+	// KeyID is the ID of the key in KeyFiles that is used to encrypt objects. Encryption is disabled when empty
+	KeyID string `toml:"key_id"`
+	// KeyFiles maps each key ID to the path of a file containing a 16, 24 or 32-byte AES key,
+	// in raw, hex or base64 form. Objects encrypted with any listed key can be decrypted, so a
+	// retired key should remain here until the objects encrypted with it have expired
+	KeyFiles map[string]string `toml:"key_files"`
+
+	// Keys is the parsed contents of KeyFiles
+	Keys map[string][]byte `toml:"-"`
+}
+
+// verifyEncryptionConfigs loads the keys for each cache that is configured for encryption
+func (c *TricksterConfig) verifyEncryptionConfigs() error {
+
+	for k, cc := range c.Caches {
+
+		if cc.Encryption.KeyID == "" {
+			continue
+		}
+
+		if cc.CacheTypeID == CacheTypeMemory || cc.CacheTypeID == CacheTypeTiered {
+			return fmt.Errorf("encryption is not supported for cache type [%s] in cache config [%s]", cc.CacheType, k)
+		}
+
+		if _, ok := cc.Encryption.KeyFiles[cc.Encryption.KeyID]; !ok {
+			return fmt.Errorf("invalid key id [%s] provided in encryption config for cache [%s]", cc.Encryption.KeyID, k)
+		}
+
+		cc.Encryption.Keys = make(map[string][]byte, len(cc.Encryption.KeyFiles))
+		for id, path := range cc.Encryption.KeyFiles {
+			if id == "" || len(id) > 255 {
+				return fmt.Errorf("invalid key id [%s] provided in encryption config for cache [%s]", id, k)
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			key, err := parseEncryptionKey(b)
+			if err != nil {
+				return fmt.Errorf("%s in key file [%s] for cache [%s]", err.Error(), path, k)
+			}
+			cc.Encryption.Keys[id] = key
+		}
+	}
+	return nil
+}
+
+// parseEncryptionKey returns the AES key found in the provided key file contents,
+// which may be the hex or base64 encoding of the key, or the raw key itself
+func parseEncryptionKey(b []byte) ([]byte, error) {
+
+	s := string(bytes.TrimSpace(b))
+	if key, err := hex.DecodeString(s); err == nil && isAESKeyLength(len(key)) {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && isAESKeyLength(len(key)) {
+		return key, nil
+	}
+	if isAESKeyLength(len(b)) {
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("invalid encryption key: must be a 16, 24 or 32-byte key in raw, hex or base64 form")
+}
+
+func isAESKeyLength(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
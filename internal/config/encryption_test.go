@@ -0,0 +1,94 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package config
+
+import (
+	"testing"
+)
+
+func TestParseEncryptionKey(t *testing.T) {
+
+	tests := []struct {
+		data     string
+		expected int
+		isErr    bool
+	}{
+		{"000102030405060708090a0b0c0d0e0f", 16, false},
+		{"000102030405060708090a0b0c0d0e0f1011121314151617\n", 24, false},
+		{"AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", 32, false},
+		{"0123456789abcdef!@#$%^&*()-_=+[]", 32, false},
+		{"0123456789abcdef", 16, false},
+		{"short", 0, true},
+		{"", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			key, err := parseEncryptionKey([]byte(test.data))
+			if test.isErr {
+				if err == nil {
+					t.Errorf("expected error for key %s", test.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+			}
+			if len(key) != test.expected {
+				t.Errorf("expected %d got %d", test.expected, len(key))
+			}
+		})
+	}
+}
+
+func TestVerifyEncryptionConfigs(t *testing.T) {
+
+	config := NewConfig()
+	cc := config.Caches["default"]
+
+	// the default cache is not encrypted
+	err := config.verifyEncryptionConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+
+	// memory caches can't be encrypted
+	cc.Encryption.KeyID = "test"
+	cc.Encryption.KeyFiles = map[string]string{"test": "../../testdata/test.cache-01.key"}
+	err = config.verifyEncryptionConfigs()
+	if err == nil {
+		t.Errorf("expected error for memory cache encryption")
+	}
+
+	cc.CacheType = "filesystem"
+	cc.CacheTypeID = CacheTypeFilesystem
+	err = config.verifyEncryptionConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(cc.Encryption.Keys["test"]) != 32 {
+		t.Errorf("expected 32 got %d", len(cc.Encryption.Keys["test"]))
+	}
+
+	cc.Encryption.KeyFiles["test"] = "../../testdata/test.cache-01.key.nonexistent"
+	err = config.verifyEncryptionConfigs()
+	if err == nil {
+		t.Errorf("expected error for missing key file")
+	}
+
+	cc.Encryption.KeyFiles["test"] = "../../testdata/test.rootca.pem"
+	err = config.verifyEncryptionConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid key file")
+	}
+}
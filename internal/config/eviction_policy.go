@@ -0,0 +1,54 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package config
+
+import "strconv"
+
+// EvictionPolicy enumerates the policies the Cache Index uses to select objects for eviction
+// when a cache exceeds its maximum size
+type EvictionPolicy int
+
+const (
+	// EvictionPolicyLRU evicts the least-recently-accessed objects first
+	EvictionPolicyLRU = EvictionPolicy(iota)
+	// EvictionPolicyLFU evicts the least-frequently-accessed objects first. Access counts are aged,
+	// so that objects which were popular long ago do not remain in the cache indefinitely
+	EvictionPolicyLFU
+	// EvictionPolicy2Q admits new objects to a probationary queue that is evicted first, so that
+	// objects accessed only once, as in a scan, do not displace those accessed repeatedly
+	EvictionPolicy2Q
+	// EvictionPolicyGDS (GreedyDual-Size) evicts the objects that are cheapest to refetch relative
+	// to their size, and least-frequently accessed, first
+	EvictionPolicyGDS
+)
+
+var evictionPolicyNames = map[string]EvictionPolicy{
+	"lru": EvictionPolicyLRU,
+	"lfu": EvictionPolicyLFU,
+	"2q":  EvictionPolicy2Q,
+	"gds": EvictionPolicyGDS,
+}
+
+var evictionPolicyValues = map[EvictionPolicy]string{
+	EvictionPolicyLRU: "lru",
+	EvictionPolicyLFU: "lfu",
+	EvictionPolicy2Q:  "2q",
+	EvictionPolicyGDS: "gds",
+}
+
+func (p EvictionPolicy) String() string {
+	if v, ok := evictionPolicyValues[p]; ok {
+		return v
+	}
+	return strconv.Itoa(int(p))
+}
@@ -0,0 +1,37 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package config
+
+import (
+	"testing"
+)
+
+func TestEvictionPolicyString(t *testing.T) {
+
+	tests := []struct {
+		p        EvictionPolicy
+		expected string
+	}{
+		{EvictionPolicyLRU, "lru"},
+		{EvictionPolicyLFU, "lfu"},
+		{EvictionPolicy2Q, "2q"},
+		{EvictionPolicyGDS, "gds"},
+		{EvictionPolicy(9), "9"},
+	}
+
+	for _, test := range tests {
+		if test.p.String() != test.expected {
+			t.Errorf("expected %s got %s", test.expected, test.p.String())
+		}
+	}
+}
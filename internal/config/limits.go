@@ -0,0 +1,87 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LimitConfig is a collection of limits on the rate and concurrency of requests, which are
+// tracked separately for each value of the limit's key
+type LimitConfig struct {
+	// KeyName is the part of the request by which the limit is tracked ('client_ip', 'header', 'origin' or 'path')
+	KeyName string `toml:"key"`
+	// KeyHeader is the name of the request header by which a 'header' limit is tracked
+	KeyHeader string `toml:"key_header"`
+	// RequestsPerSec is the rate at which requests are permitted for each key. 0 permits any rate
+	RequestsPerSec float64 `toml:"requests_per_sec"`
+	// Burst is the number of requests permitted for each key in excess of RequestsPerSec, at once
+	Burst int `toml:"burst"`
+	// MaxConcurrent is the maximum number of requests in flight for each key. 0 permits any number
+	MaxConcurrent int `toml:"max_concurrent"`
+}
+
+var limitKeyNames = map[string]bool{"client_ip": true, "header": true, "origin": true, "path": true}
+
+// Clone returns an exact copy of the subject LimitConfig
+func (lc *LimitConfig) Clone() *LimitConfig {
+	c := *lc
+	return &c
+}
+
+// verifyLimitConfigs validates each Limit and the Limit names referenced by each origin and path,
+// and sets the default burst of each rate limit
+func (c *TricksterConfig) verifyLimitConfigs() error {
+
+	for k, lc := range c.Limits {
+		lc.KeyName = strings.ToLower(lc.KeyName)
+		if !limitKeyNames[lc.KeyName] {
+			return fmt.Errorf("invalid key [%s] provided in limit config [%s]", lc.KeyName, k)
+		}
+		if lc.KeyName == "header" && lc.KeyHeader == "" {
+			return fmt.Errorf("missing key_header in limit config [%s]", k)
+		}
+		if lc.RequestsPerSec < 0 || lc.Burst < 0 || lc.MaxConcurrent < 0 {
+			return fmt.Errorf("negative value provided in limit config [%s]", k)
+		}
+		if lc.RequestsPerSec == 0 && lc.MaxConcurrent == 0 {
+			return fmt.Errorf("missing requests_per_sec or max_concurrent in limit config [%s]", k)
+		}
+		if lc.RequestsPerSec > 0 && lc.Burst == 0 {
+			lc.Burst = int(math.Max(1, math.Ceil(lc.RequestsPerSec)))
+		}
+	}
+
+	for k, oc := range c.Origins {
+		if oc.MaxUpstreamConcurrency < 0 {
+			return fmt.Errorf("invalid max_upstream_concurrency [%d] provided in origin config [%s]", oc.MaxUpstreamConcurrency, k)
+		}
+		for _, n := range oc.LimitNames {
+			if _, ok := c.Limits[n]; !ok {
+				return fmt.Errorf("invalid limit name [%s] provided in origin config [%s]", n, k)
+			}
+		}
+		for _, p := range oc.Paths {
+			for _, n := range p.LimitNames {
+				if _, ok := c.Limits[n]; !ok {
+					return fmt.Errorf("invalid limit name [%s] provided in path config [%s] for origin [%s]", n, p.Path, k)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import "testing"
+
+func TestVerifyLimitConfigs(t *testing.T) {
+
+	config := NewConfig()
+	lc := &LimitConfig{KeyName: "Client_IP", RequestsPerSec: 2.5}
+	config.Limits["test"] = lc
+	config.Origins["default"].LimitNames = []string{"test"}
+
+	err := config.verifyLimitConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if lc.KeyName != "client_ip" || lc.Burst != 3 {
+		t.Errorf("expected %s %d got %s %d", "client_ip", 3, lc.KeyName, lc.Burst)
+	}
+
+	tests := []struct {
+		cfg      LimitConfig
+		expected string
+	}{
+		{LimitConfig{KeyName: "user", MaxConcurrent: 1}, "invalid key [user] provided in limit config [test]"},
+		{LimitConfig{KeyName: "header", MaxConcurrent: 1}, "missing key_header in limit config [test]"},
+		{LimitConfig{KeyName: "origin", RequestsPerSec: -1}, "negative value provided in limit config [test]"},
+		{LimitConfig{KeyName: "origin"}, "missing requests_per_sec or max_concurrent in limit config [test]"},
+	}
+
+	for i, test := range tests {
+		*lc = test.cfg
+		err = config.verifyLimitConfigs()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("test %d: expected %s got %v", i, test.expected, err)
+		}
+	}
+
+	*lc = LimitConfig{KeyName: "origin", MaxConcurrent: 1}
+	config.Origins["default"].LimitNames = []string{"missing"}
+	err = config.verifyLimitConfigs()
+	expected := "invalid limit name [missing] provided in origin config [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+
+	config.Origins["default"].LimitNames = nil
+	config.Origins["default"].Paths["root"] = &PathConfig{Path: "/", LimitNames: []string{"missing"}}
+	err = config.verifyLimitConfigs()
+	expected = "invalid limit name [missing] provided in path config [/] for origin [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+
+	delete(config.Origins["default"].Paths, "root")
+	config.Origins["default"].MaxUpstreamConcurrency = -1
+	err = config.verifyLimitConfigs()
+	expected = "invalid max_upstream_concurrency [-1] provided in origin config [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+}
+
+func TestLimitConfigClone(t *testing.T) {
+	lc := &LimitConfig{KeyName: "header", KeyHeader: "X-Tenant", RequestsPerSec: 1, Burst: 2, MaxConcurrent: 3}
+	lc2 := lc.Clone()
+	if *lc2 != *lc {
+		t.Errorf("expected %v got %v", lc, lc2)
+	}
+	lc2.Burst = 4
+	if lc.Burst != 2 {
+		t.Errorf("clone shares state with the original config: %v", lc)
+	}
+}
@@ -16,6 +16,7 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -81,6 +82,26 @@ func Load(applicationName string, applicationVersion string, arguments []string)
 	Logging = c.Logging
 	Metrics = c.Metrics
 	NegativeCacheConfigs = c.NegativeCacheConfigs
+	Rules = c.Rules
+	Limits = c.Limits
+	Authenticators = c.Authenticators
+	Compression = c.Compression
+
+	if c.Frontend.HTTP2MaxConcurrentStreams < 0 {
+		return fmt.Errorf("invalid http2_max_concurrent_streams [%d] provided in frontend config",
+			c.Frontend.HTTP2MaxConcurrentStreams)
+	}
+
+	fm, err := parseFileMode(c.Frontend.UnixSocketMode)
+	if err != nil {
+		return fmt.Errorf("invalid unix_socket_mode [%s] provided in frontend config", c.Frontend.UnixSocketMode)
+	}
+	c.Frontend.UnixSocketFileMode = fm
+
+	if fm, err = parseFileMode(c.Metrics.UnixSocketMode); err != nil {
+		return fmt.Errorf("invalid unix_socket_mode [%s] provided in metrics config", c.Metrics.UnixSocketMode)
+	}
+	c.Metrics.UnixSocketFileMode = fm
 
 	for k, n := range NegativeCacheConfigs {
 		for c := range n {
@@ -109,6 +130,11 @@ func Load(applicationName string, applicationVersion string, arguments []string)
 			return fmt.Errorf(`missing origin-type for origin "%s"`, k)
 		}
 
+		o.UnixSocketPath, err = parseUnixSocketURL(url)
+		if err != nil {
+			return fmt.Errorf(`%s in origin-url for origin "%s"`, err.Error(), k)
+		}
+
 		if strings.HasSuffix(url.Path, "/") {
 			url.Path = url.Path[0 : len(url.Path)-1]
 		}
@@ -121,9 +147,31 @@ func Load(applicationName string, applicationVersion string, arguments []string)
 		o.BackfillTolerance = time.Duration(o.BackfillToleranceSecs) * time.Second
 		o.TimeseriesRetention = time.Duration(o.TimeseriesRetentionFactor)
 		o.TimeseriesTTL = time.Duration(o.TimeseriesTTLSecs) * time.Second
+		o.TimeseriesEmptyExtentTTL = time.Duration(o.TimeseriesEmptyExtentTTLSecs) * time.Second
 		o.FastForwardTTL = time.Duration(o.FastForwardTTLSecs) * time.Second
 		o.MaxTTL = time.Duration(o.MaxTTLSecs) * time.Second
 
+		if o.H2C && o.Scheme != "http" {
+			return fmt.Errorf(`h2c requires an http origin-url for origin "%s"`, k)
+		}
+
+		if len(o.BackfillRevalidationSecs) > 0 {
+			o.BackfillRevalidation, err = newBackfillRevalidationSchedule(o.BackfillRevalidationSecs)
+			if err != nil {
+				return fmt.Errorf(`%s for origin "%s"`, err.Error(), k)
+			}
+		}
+
+		if len(o.StepLadderSecs) > 0 {
+			o.StepLadder = make([]time.Duration, 0, len(o.StepLadderSecs))
+			for _, s := range o.StepLadderSecs {
+				if s > 0 {
+					o.StepLadder = append(o.StepLadder, time.Duration(s)*time.Second)
+				}
+			}
+			sort.Slice(o.StepLadder, func(i, j int) bool { return o.StepLadder[i] < o.StepLadder[j] })
+		}
+
 		if o.CompressableTypeList != nil {
 			o.CompressableTypes = make(map[string]bool)
 			for _, v := range o.CompressableTypeList {
@@ -133,6 +181,10 @@ func Load(applicationName string, applicationVersion string, arguments []string)
 
 		if o.CacheKeyPrefix == "" {
 			o.CacheKeyPrefix = o.Host
+			// origins reached through sockets share a Host, so are distinguished by their socket paths
+			if o.UnixSocketPath != "" {
+				o.CacheKeyPrefix = o.UnixSocketPath
+			}
 		}
 
 		nc, ok := NegativeCacheConfigs[o.NegativeCacheName]
@@ -165,6 +217,33 @@ func Load(applicationName string, applicationVersion string, arguments []string)
 	for _, c := range Caches {
 		c.Index.FlushInterval = time.Duration(c.Index.FlushIntervalSecs) * time.Second
 		c.Index.ReapInterval = time.Duration(c.Index.ReapIntervalSecs) * time.Second
+		c.Tiered.L1MaxTTL = time.Duration(c.Tiered.L1MaxTTLSecs) * time.Second
+		c.Tiered.L2MaxTTL = time.Duration(c.Tiered.L2MaxTTLSecs) * time.Second
+		c.Index.OriginKeyPrefixes = make(map[string]string)
+	}
+
+	// the objects an origin stores in a tiered cache are indexed by its tiers
+	for k, o := range Origins {
+		c, ok := Caches[o.CacheName]
+		if !ok {
+			continue
+		}
+		c.Index.OriginKeyPrefixes[k] = o.CacheKeyPrefix + "."
+		if c.CacheTypeID == CacheTypeTiered {
+			for _, t := range []string{c.Tiered.L1CacheName, c.Tiered.L2CacheName} {
+				if tc, ok := Caches[t]; ok {
+					tc.Index.OriginKeyPrefixes[k] = o.CacheKeyPrefix + "."
+				}
+			}
+		}
+	}
+
+	for k, c := range Caches {
+		for name := range c.Index.OriginQuotas {
+			if _, ok := c.Index.OriginKeyPrefixes[name]; !ok {
+				return fmt.Errorf("invalid origin name [%s] provided in quota for cache config [%s]", name, k)
+			}
+		}
 	}
 
 	return nil
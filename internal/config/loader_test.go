@@ -16,6 +16,7 @@ package config
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -42,6 +43,27 @@ func TestLoadConfiguration(t *testing.T) {
 
 }
 
+func TestLoadConfigurationUnixSocketOrigin(t *testing.T) {
+	a := []string{"-origin-type", "testing", "-origin-url", "http+unix:///var/run/prometheus.sock:/test/path/"}
+	err := Load("trickster-test", "0", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := Origins["default"]
+	if o.UnixSocketPath != "/var/run/prometheus.sock" {
+		t.Errorf("expected %s, got %s", "/var/run/prometheus.sock", o.UnixSocketPath)
+	}
+
+	if o.Scheme != "http" || o.Host != "localhost" || o.PathPrefix != "/test/path" {
+		t.Errorf("expected %s, got %s://%s%s", "http://localhost/test/path", o.Scheme, o.Host, o.PathPrefix)
+	}
+
+	if o.CacheKeyPrefix != "/var/run/prometheus.sock" {
+		t.Errorf("expected %s, got %s", "/var/run/prometheus.sock", o.CacheKeyPrefix)
+	}
+}
+
 func TestLoadConfigurationFileFailures(t *testing.T) {
 
 	tests := []struct {
@@ -76,6 +98,66 @@ func TestLoadConfigurationFileFailures(t *testing.T) {
 			"../../testdata/test.invalid-negative-cache-3.conf",
 			`invalid negative cache name: foo`,
 		},
+		{ // Case 7
+			"../../testdata/test.bad-tiered-cache.conf",
+			`invalid l1 cache name [fs] provided in tiered cache config [tiered]`,
+		},
+		{ // Case 8
+			"../../testdata/test.bad-encryption.conf",
+			`invalid key id [test_key_2] provided in encryption config for cache [fs]`,
+		},
+		{ // Case 9
+			"../../testdata/test.bad-eviction-policy.conf",
+			`invalid eviction policy [mru] provided in cache config [default]`,
+		},
+		{ // Case 10
+			"../../testdata/test.bad-warmer.conf",
+			`invalid concurrency [0] provided in warmer config for origin [test]`,
+		},
+		{ // Case 11
+			"../../testdata/test.bad-origin-quota.conf",
+			`invalid origin name [other] provided in quota for cache config [default]`,
+		},
+		{ // Case 12
+			"../../testdata/test.bad-rule.conf",
+			`invalid route origin name [other] in rule [0] of rule config [test]`,
+		},
+		{ // Case 13
+			"../../testdata/test.bad-path-template.conf",
+			`invalid template path [/api/v1/label/{name/values] provided in path config for origin [test]`,
+		},
+		{ // Case 14
+			"../../testdata/test.bad-limit.conf",
+			`missing key_header in limit config [tenant]`,
+		},
+		{ // Case 15
+			"../../testdata/test.bad-authenticator.conf",
+			`invalid authenticator name [missing] provided in origin config [test]`,
+		},
+		{ // Case 16
+			"../../testdata/test.bad-circuit-breaker.conf",
+			`invalid failure_ratio [1.5] provided in circuit breaker config for origin [test]`,
+		},
+		{ // Case 17
+			"../../testdata/test.bad-h2c.conf",
+			`h2c requires an http origin-url for origin "test"`,
+		},
+		{ // Case 18
+			"../../testdata/test.bad-unix-socket.conf",
+			`invalid unix socket path in origin-url for origin "test"`,
+		},
+		{ // Case 19
+			"../../testdata/test.bad-unix-socket-mode.conf",
+			`invalid unix_socket_mode [rw] provided in frontend config`,
+		},
+		{ // Case 20
+			"../../testdata/test.bad-compression.conf",
+			`invalid encoding [deflate] provided in compression config`,
+		},
+		{ // Case 21
+			"../../testdata/test.bad-cache-archive.conf",
+			`invalid cache_archive_authenticator [] provided in main config; an authenticator is required when cache_archive_handler_path is set`,
+		},
 	}
 
 	for i, test := range tests {
@@ -128,6 +210,18 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected 38821, got %d", Frontend.TLSListenPort)
 	}
 
+	if !Frontend.HTTP2 || !Frontend.H2C {
+		t.Errorf("expected http2 and h2c, got %t and %t", Frontend.HTTP2, Frontend.H2C)
+	}
+
+	if Frontend.HTTP2MaxConcurrentStreams != 100 {
+		t.Errorf("expected 100, got %d", Frontend.HTTP2MaxConcurrentStreams)
+	}
+
+	if Frontend.UnixSocketFileMode != 0660 {
+		t.Errorf("expected 660, got %o", Frontend.UnixSocketFileMode)
+	}
+
 	// Test Metrics Server
 	if Metrics.ListenPort != 57822 {
 		t.Errorf("expected 57821, got %d", Metrics.ListenPort)
@@ -137,6 +231,10 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected test, got %s", Metrics.ListenAddress)
 	}
 
+	if Metrics.UnixSocketFileMode != 0600 {
+		t.Errorf("expected 600, got %o", Metrics.UnixSocketFileMode)
+	}
+
 	// Test Logging
 	if Logging.LogLevel != "test_log_level" {
 		t.Errorf("expected test_log_level, got %s", Logging.LogLevel)
@@ -146,6 +244,19 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected test_file, got %s", Logging.LogFile)
 	}
 
+	// Test Compression
+	if strings.Join(Compression.EncodingList, ",") != "gzip,br" {
+		t.Errorf("expected gzip,br, got %s", strings.Join(Compression.EncodingList, ","))
+	}
+
+	if Compression.MinSizeBytes != 512 {
+		t.Errorf("expected 512, got %d", Compression.MinSizeBytes)
+	}
+
+	if len(Compression.ContentTypes) != 2 || !Compression.ContentTypes["text/csv"] {
+		t.Errorf("expected application/json and text/csv, got %v", Compression.ContentTypes)
+	}
+
 	// Test Origins
 
 	o, ok := Origins["test"]
@@ -190,6 +301,14 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected 301, got %d", o.BackfillToleranceSecs)
 	}
 
+	if len(o.StepLadder) != 4 || o.StepLadder[0] != 15*time.Second || o.StepLadder[3] != 300*time.Second {
+		t.Errorf("expected [15s 30s 1m0s 5m0s], got %v", o.StepLadder)
+	}
+
+	if !o.StepLadderResample {
+		t.Errorf("expected true got %t", o.StepLadderResample)
+	}
+
 	if o.TimeoutSecs != 37 {
 		t.Errorf("expected 37, got %d", o.TimeoutSecs)
 	}
@@ -202,6 +321,26 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected %d got %d", 23, o.MaxIdleConns)
 	}
 
+	if o.MaxUpstreamConcurrency != 17 {
+		t.Errorf("expected %d got %d", 17, o.MaxUpstreamConcurrency)
+	}
+
+	if o.MaxConnsPerHost != 4 {
+		t.Errorf("expected %d got %d", 4, o.MaxConnsPerHost)
+	}
+
+	if !o.HTTP2 || o.H2C {
+		t.Errorf("expected http2 and not h2c got %t and %t", o.HTTP2, o.H2C)
+	}
+
+	if len(o.LimitNames) != 1 || o.LimitNames[0] != "test" {
+		t.Errorf("expected %s got %v", "[test]", o.LimitNames)
+	}
+
+	if o.AuthenticatorName != "test" {
+		t.Errorf("expected %s got %s", "test", o.AuthenticatorName)
+	}
+
 	if o.KeepAliveTimeoutSecs != 7 {
 		t.Errorf("expected %d got %d", 7, o.KeepAliveTimeoutSecs)
 	}
@@ -211,6 +350,17 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected 300, got %d", o.TimeseriesTTLSecs)
 	}
 
+	if len(o.BackfillRevalidation) != 2 {
+		t.Errorf("expected %d got %d", 2, len(o.BackfillRevalidation))
+	} else if o.BackfillRevalidation[1].MaxAge != time.Hour ||
+		o.BackfillRevalidation[1].Interval != time.Duration(300)*time.Second {
+		t.Errorf("unexpected backfill revalidation rule %v", o.BackfillRevalidation[1])
+	}
+
+	if o.TimeseriesEmptyExtentTTL != time.Duration(120)*time.Second {
+		t.Errorf("expected %s, got %s", "2m0s", o.TimeseriesEmptyExtentTTL)
+	}
+
 	// MaxTTLSecs is 300, thus should override FastForwardTTLSecs = 382
 	if o.FastForwardTTLSecs != 300 {
 		t.Errorf("expected 300, got %d", o.FastForwardTTLSecs)
@@ -240,6 +390,190 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected test_client_key got %s", o.TLS.ClientKeyPath)
 	}
 
+	if len(o.Warmer.Requests) != 1 || len(o.Warmer.DashboardPaths) != 1 {
+		t.Errorf("expected 1 request and 1 dashboard got %v %v", o.Warmer.Requests, o.Warmer.DashboardPaths)
+	}
+
+	if o.Warmer.Interval != 31*time.Second {
+		t.Errorf("expected %s got %s", "31s", o.Warmer.Interval)
+	}
+
+	if o.Warmer.Range != 3601*time.Second {
+		t.Errorf("expected %s got %s", "1h0m1s", o.Warmer.Range)
+	}
+
+	if o.Warmer.Step != 16*time.Second {
+		t.Errorf("expected %s got %s", "16s", o.Warmer.Step)
+	}
+
+	if o.Warmer.Concurrency != 3 {
+		t.Errorf("expected %d got %d", 3, o.Warmer.Concurrency)
+	}
+
+	if o.Retries.MaxRetries != 3 || len(o.Retries.Statuses) != 1 || o.Retries.Statuses[0] != 503 {
+		t.Errorf("expected %d retries of %v got %d of %v", 3, []int{503}, o.Retries.MaxRetries, o.Retries.Statuses)
+	}
+
+	if o.Retries.Backoff != 50*time.Millisecond || o.Retries.MaxBackoff != 400*time.Millisecond {
+		t.Errorf("expected %s-%s got %s-%s", "50ms", "400ms", o.Retries.Backoff, o.Retries.MaxBackoff)
+	}
+
+	if o.Retries.HedgeDelay != 250*time.Millisecond {
+		t.Errorf("expected %s got %s", "250ms", o.Retries.HedgeDelay)
+	}
+
+	if o.CircuitBreaker.FailureRatio != 0.25 || o.CircuitBreaker.MinRequests != 40 {
+		t.Errorf("expected %g and %d got %g and %d", 0.25, 40, o.CircuitBreaker.FailureRatio, o.CircuitBreaker.MinRequests)
+	}
+
+	if o.CircuitBreaker.LatencyThreshold != 1500*time.Millisecond {
+		t.Errorf("expected %s got %s", "1.5s", o.CircuitBreaker.LatencyThreshold)
+	}
+
+	if o.CircuitBreaker.Window != 45*time.Second || o.CircuitBreaker.OpenDuration != 15*time.Second {
+		t.Errorf("expected %s and %s got %s and %s", "45s", "15s", o.CircuitBreaker.Window, o.CircuitBreaker.OpenDuration)
+	}
+
+	if !o.CircuitBreaker.ServeStale {
+		t.Errorf("expected %t got %t", true, o.CircuitBreaker.ServeStale)
+	}
+
+	if o.Warmer.Headers["Authorization"] != "Bearer test" {
+		t.Errorf("expected %s got %s", "Bearer test", o.Warmer.Headers["Authorization"])
+	}
+
+	p, ok := o.Paths["/series-GET-HEAD"]
+	if !ok {
+		t.Errorf("unable to find path config: %s", "/series")
+		return
+	}
+
+	if len(p.DownsampleMethods) != 2 {
+		t.Errorf("expected %d got %d", 2, len(p.DownsampleMethods))
+	}
+
+	if p.DownsampleMethods["max_over_time"] != DownsampleMethodMax {
+		t.Errorf("expected %s got %s", DownsampleMethodMax, p.DownsampleMethods["max_over_time"])
+	}
+
+	if len(p.DownsampleSourceSteps) != 2 || p.DownsampleSourceSteps[0] != time.Minute {
+		t.Errorf("expected %s got %v", "[1m0s 15s]", p.DownsampleSourceSteps)
+	}
+
+	if len(p.RuleNames) != 1 || p.RuleNames[0] != "test" {
+		t.Errorf("expected %s got %v", "[test]", p.RuleNames)
+	}
+
+	if len(p.LimitNames) != 1 || p.LimitNames[0] != "test-path" {
+		t.Errorf("expected %s got %v", "[test-path]", p.LimitNames)
+	}
+
+	if p.TimeoutSecs != 120 || p.Timeout != 120*time.Second {
+		t.Errorf("expected %d got %d", 120, p.TimeoutSecs)
+	}
+
+	// Test Main
+
+	if Main.CacheArchiveHandlerPath != "/trickster/cache-archive" || Main.CacheArchiveAuthenticatorName != "test" {
+		t.Errorf("expected %s got %s", "test", Main.CacheArchiveAuthenticatorName)
+	}
+
+	// Test Limits
+
+	l, ok := Limits["test"]
+	if !ok {
+		t.Errorf("unable to find limit config: %s", "test")
+		return
+	}
+
+	if l.KeyName != "header" || l.KeyHeader != "X-Tenant" {
+		t.Errorf("expected %s %s got %s %s", "header", "X-Tenant", l.KeyName, l.KeyHeader)
+	}
+
+	if l.RequestsPerSec != 2.5 || l.Burst != 3 || l.MaxConcurrent != 9 {
+		t.Errorf("expected %f %d %d got %f %d %d", 2.5, 3, 9, l.RequestsPerSec, l.Burst, l.MaxConcurrent)
+	}
+
+	if l, ok = Limits["test-path"]; !ok || l.Burst != 13 {
+		t.Errorf("unable to find limit config: %s", "test-path")
+	}
+
+	// Test Authenticators
+
+	ac, ok := Authenticators["test"]
+	if !ok {
+		t.Errorf("unable to find authenticator config: %s", "test")
+		return
+	}
+
+	if ac.AuthenticatorType != "jwt" || ac.Realm != "test-realm" || ac.JWKSURL != "https://test/keys" {
+		t.Errorf("expected %s %s %s got %s %s %s", "jwt", "test-realm", "https://test/keys",
+			ac.AuthenticatorType, ac.Realm, ac.JWKSURL)
+	}
+
+	if ac.Issuer != "test-issuer" || ac.Audience != "test-audience" || ac.IdentityClaim != "email" {
+		t.Errorf("expected %s %s %s got %s %s %s", "test-issuer", "test-audience", "email",
+			ac.Issuer, ac.Audience, ac.IdentityClaim)
+	}
+
+	if ac.JWKSRefreshSecs != 601 || ac.LeewaySecs != 31 {
+		t.Errorf("expected %d %d got %d %d", 601, 31, ac.JWKSRefreshSecs, ac.LeewaySecs)
+	}
+
+	if ac.UpstreamAuthorization != "replace" || ac.UpstreamAuthorizationValue != "Bearer test-upstream" {
+		t.Errorf("expected %s %s got %s %s", "replace", "Bearer test-upstream",
+			ac.UpstreamAuthorization, ac.UpstreamAuthorizationValue)
+	}
+
+	if v := ac.ClaimsHeaders["email"]; v != "X-User" {
+		t.Errorf("expected %s got %s", "X-User", v)
+	}
+
+	if ac, ok = Authenticators["test-keys"]; !ok || ac.APIKeys["test-key"] != "test-tenant" ||
+		ac.APIKeyHeader != "X-API-Key" {
+		t.Errorf("unable to find authenticator config: %s", "test-keys")
+	}
+
+	p, ok = o.Paths["/api/v1/label/(?P<name>[^/]+)/values-GET-HEAD"]
+	if !ok {
+		t.Errorf("unable to find path config: %s", "/api/v1/label/(?P<name>[^/]+)/values")
+		return
+	}
+
+	if p.MatchType != PathMatchTypeRegex || p.PathRegexp == nil {
+		t.Errorf("expected %s got %s", PathMatchTypeRegex, p.MatchType)
+	}
+
+	if len(p.CacheKeyParams) != 1 || p.CacheKeyParams[0] != "{name}" {
+		t.Errorf("expected %s got %v", "[{name}]", p.CacheKeyParams)
+	}
+
+	if p.AuthenticatorName != "test-keys" {
+		t.Errorf("expected %s got %s", "test-keys", p.AuthenticatorName)
+	}
+
+	// Test Rules
+
+	rs, ok := Rules["test"]
+	if !ok || len(rs) != 1 {
+		t.Errorf("unable to find rule config: %s", "test")
+		return
+	}
+
+	if rs[0].Name != "test-rule" || !rs[0].Stop {
+		t.Errorf("expected %s got %s", "test-rule", rs[0].Name)
+	}
+
+	if len(rs[0].Conditions) != 1 || rs[0].Conditions[0].Source != "header" ||
+		rs[0].Conditions[0].Operator != "eq" || !rs[0].Conditions[0].Negate {
+		t.Errorf("unexpected rule conditions %v", rs[0].Conditions)
+	}
+
+	if len(rs[0].Actions) != 2 || rs[0].Actions[0].Pattern != "^/series/(.*)$" ||
+		rs[0].Actions[1].Value != "test" {
+		t.Errorf("unexpected rule actions %v", rs[0].Actions)
+	}
+
 	// Test Caches
 
 	c, ok := Caches["test"]
@@ -276,6 +610,40 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected 20, got %d", c.Index.MaxSizeBackoffObjects)
 	}
 
+	if c.Index.EvictionPolicyName != "lfu" {
+		t.Errorf("expected lfu, got %s", c.Index.EvictionPolicyName)
+	}
+
+	if c.Index.EvictionPolicy != EvictionPolicyLFU {
+		t.Errorf("expected %s, got %s", EvictionPolicyLFU, c.Index.EvictionPolicy)
+	}
+
+	if c.Index.RefetchCostBytes != 4097 {
+		t.Errorf("expected 4097, got %d", c.Index.RefetchCostBytes)
+	}
+
+	q, ok := c.Index.OriginQuotas["test"]
+	if !ok {
+		t.Errorf("expected quota for origin %s", "test")
+	} else {
+		if q.MaxSizeBytes != 1048577 {
+			t.Errorf("expected 1048577, got %d", q.MaxSizeBytes)
+		}
+		if q.MaxSizeBackoffBytes != 65537 {
+			t.Errorf("expected 65537, got %d", q.MaxSizeBackoffBytes)
+		}
+		if q.MaxSizeObjects != 41 {
+			t.Errorf("expected 41, got %d", q.MaxSizeObjects)
+		}
+		if q.MaxSizeBackoffObjects != 11 {
+			t.Errorf("expected 11, got %d", q.MaxSizeBackoffObjects)
+		}
+	}
+
+	if c.Index.OriginKeyPrefixes["test"] != "test-prefix." {
+		t.Errorf("expected test-prefix., got %s", c.Index.OriginKeyPrefixes["test"])
+	}
+
 	if c.Index.ReapIntervalSecs != 4 {
 		t.Errorf("expected 4, got %d", c.Index.ReapIntervalSecs)
 	}
@@ -352,6 +720,18 @@ func TestFullLoadConfiguration(t *testing.T) {
 		t.Errorf("expected 60001, got %d", c.Redis.IdleCheckFrequencyMS)
 	}
 
+	if c.Redis.KeyNamespace != "test_namespace" {
+		t.Errorf("expected test_namespace, got %s", c.Redis.KeyNamespace)
+	}
+
+	if c.Redis.StaleRetentionSecs != 601 {
+		t.Errorf("expected 601, got %d", c.Redis.StaleRetentionSecs)
+	}
+
+	if c.Redis.ClientCacheMaxObjects != 1001 {
+		t.Errorf("expected 1001, got %d", c.Redis.ClientCacheMaxObjects)
+	}
+
 	if c.Filesystem.CachePath != "test_cache_path" {
 		t.Errorf("expected test_cache_path, got %s", c.Filesystem.CachePath)
 	}
@@ -371,6 +751,106 @@ func TestFullLoadConfiguration(t *testing.T) {
 	if c.Badger.ValueDirectory != "test_value_directory" {
 		t.Errorf("expected test_value_directory, got %s", c.Badger.ValueDirectory)
 	}
+
+	if len(c.Memcached.Servers) != 2 || c.Memcached.Servers[1] != "test_server_2" {
+		t.Errorf("expected [test_server_1 test_server_2], got %v", c.Memcached.Servers)
+	}
+
+	if c.Memcached.DialTimeoutMS != 1001 {
+		t.Errorf("expected 1001, got %d", c.Memcached.DialTimeoutMS)
+	}
+
+	if c.Memcached.ReadTimeoutMS != 1002 {
+		t.Errorf("expected 1002, got %d", c.Memcached.ReadTimeoutMS)
+	}
+
+	if c.Memcached.WriteTimeoutMS != 1003 {
+		t.Errorf("expected 1003, got %d", c.Memcached.WriteTimeoutMS)
+	}
+
+	if c.Memcached.PoolSize != 7 {
+		t.Errorf("expected 7, got %d", c.Memcached.PoolSize)
+	}
+
+	if c.Memcached.MaxItemSizeBytes != 2097152 {
+		t.Errorf("expected 2097152, got %d", c.Memcached.MaxItemSizeBytes)
+	}
+
+	if c.Encryption.KeyID != "test_key_2" {
+		t.Errorf("expected test_key_2, got %s", c.Encryption.KeyID)
+	}
+
+	if len(c.Encryption.KeyFiles) != 2 {
+		t.Errorf("expected 2, got %d", len(c.Encryption.KeyFiles))
+	}
+
+	if len(c.Encryption.Keys["test_key_1"]) != 32 {
+		t.Errorf("expected 32, got %d", len(c.Encryption.Keys["test_key_1"]))
+	}
+
+	if len(c.Encryption.Keys["test_key_2"]) != 16 {
+		t.Errorf("expected 16, got %d", len(c.Encryption.Keys["test_key_2"]))
+	}
+
+	if c.Tiered.L1CacheName != "test_l1" {
+		t.Errorf("expected test_l1, got %s", c.Tiered.L1CacheName)
+	}
+
+	if c.Tiered.L2CacheName != "test_l2" {
+		t.Errorf("expected test_l2, got %s", c.Tiered.L2CacheName)
+	}
+
+	if c.Tiered.WriteMode != "behind" {
+		t.Errorf("expected behind, got %s", c.Tiered.WriteMode)
+	}
+
+	if c.Tiered.WriteBehindQueueSize != 99 {
+		t.Errorf("expected 99, got %d", c.Tiered.WriteBehindQueueSize)
+	}
+
+	if c.Tiered.L1MaxTTL != time.Duration(31)*time.Second {
+		t.Errorf("expected 31s, got %s", c.Tiered.L1MaxTTL)
+	}
+
+	if c.Tiered.L2MaxTTL != time.Duration(3601)*time.Second {
+		t.Errorf("expected 1h0m1s, got %s", c.Tiered.L2MaxTTL)
+	}
+}
+
+func TestLoadConfigurationTieredCache(t *testing.T) {
+	a := []string{"-config", "../../testdata/test.tiered.conf"}
+	err := Load("trickster-test", "0", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tiers are retained though no origin uses them directly
+	for _, k := range []string{"tiered", "mem", "fs"} {
+		if _, ok := Caches[k]; !ok {
+			t.Errorf("unable to find cache config: %s", k)
+		}
+	}
+
+	if _, ok := Caches["unused"]; ok {
+		t.Errorf("expected cache config %s to be removed", "unused")
+	}
+
+	c := Caches["tiered"]
+	if c.CacheTypeID != CacheTypeTiered {
+		t.Errorf("expected %s got %s", CacheTypeTiered, c.CacheTypeID)
+	}
+
+	if c.Tiered.WriteMode != "through" {
+		t.Errorf("expected through, got %s", c.Tiered.WriteMode)
+	}
+
+	if c.Tiered.L1MaxTTL != time.Duration(defaultTieredL1MaxTTLSecs)*time.Second {
+		t.Errorf("expected %ds, got %s", defaultTieredL1MaxTTLSecs, c.Tiered.L1MaxTTL)
+	}
+
+	if c.Tiered.L2MaxTTL != 0 {
+		t.Errorf("expected 0s, got %s", c.Tiered.L2MaxTTL)
+	}
 }
 
 func TestEmptyLoadConfiguration(t *testing.T) {
@@ -414,6 +894,19 @@ func TestEmptyLoadConfiguration(t *testing.T) {
 		t.Errorf("expected '%s', got '%s'", defaultLogFile, Logging.LogFile)
 	}
 
+	// Test Compression
+	if strings.Join(Compression.EncodingList, ",") != "br,zstd,gzip" {
+		t.Errorf("expected br,zstd,gzip, got %s", strings.Join(Compression.EncodingList, ","))
+	}
+
+	if Compression.MinSizeBytes != defaultCompressionMinSizeBytes {
+		t.Errorf("expected %d, got %d", defaultCompressionMinSizeBytes, Compression.MinSizeBytes)
+	}
+
+	if !Compression.ContentTypes["application/json"] {
+		t.Errorf("expected application/json to be compressed")
+	}
+
 	// Test Origins
 
 	o, ok := Origins["test"]
@@ -500,6 +993,14 @@ func TestEmptyLoadConfiguration(t *testing.T) {
 		t.Errorf("expected %d, got %d", defaultMaxSizeBackoffObjects, c.Index.MaxSizeBackoffObjects)
 	}
 
+	if c.Index.EvictionPolicy != defaultEvictionPolicy {
+		t.Errorf("expected %s, got %s", defaultEvictionPolicy, c.Index.EvictionPolicy)
+	}
+
+	if c.Index.RefetchCostBytes != defaultRefetchCostBytes {
+		t.Errorf("expected %d, got %d", defaultRefetchCostBytes, c.Index.RefetchCostBytes)
+	}
+
 	if c.Index.ReapIntervalSecs != 3 {
 		t.Errorf("expected 3, got %d", c.Index.ReapIntervalSecs)
 	}
@@ -576,6 +1077,14 @@ func TestEmptyLoadConfiguration(t *testing.T) {
 		t.Errorf("expected 0, got %d", c.Redis.IdleCheckFrequencyMS)
 	}
 
+	if c.Redis.StaleRetentionSecs != defaultRedisStaleRetentionSecs {
+		t.Errorf("expected %d, got %d", defaultRedisStaleRetentionSecs, c.Redis.StaleRetentionSecs)
+	}
+
+	if c.Redis.ClientCacheMaxObjects != 0 {
+		t.Errorf("expected 0, got %d", c.Redis.ClientCacheMaxObjects)
+	}
+
 	if c.Filesystem.CachePath != "/tmp/trickster" {
 		t.Errorf("expected /tmp/trickster, got %s", c.Filesystem.CachePath)
 	}
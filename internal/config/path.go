@@ -14,10 +14,14 @@
 package config
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Comcast/trickster/internal/proxy/methods"
 	ts "github.com/Comcast/trickster/internal/util/strings"
@@ -34,16 +38,26 @@ const (
 	PathMatchTypeExact = PathMatchType(iota)
 	// PathMatchTypePrefix indicates the router will map the Path by prefix against incoming requests
 	PathMatchTypePrefix
+	// PathMatchTypeRegex indicates the router will map the Path as a regular expression that
+	// must match the entire path of incoming requests
+	PathMatchTypeRegex
+	// PathMatchTypeTemplate indicates the router will map the Path as a template whose {name}
+	// or {name:pattern} variables each match a segment of the path of incoming requests
+	PathMatchTypeTemplate
 )
 
 var pathMatchTypeNames = map[string]PathMatchType{
-	"exact":  PathMatchTypeExact,
-	"prefix": PathMatchTypePrefix,
+	"exact":    PathMatchTypeExact,
+	"prefix":   PathMatchTypePrefix,
+	"regex":    PathMatchTypeRegex,
+	"template": PathMatchTypeTemplate,
 }
 
 var pathMatchTypeValues = map[PathMatchType]string{
-	PathMatchTypeExact:  "exact",
-	PathMatchTypePrefix: "prefix",
+	PathMatchTypeExact:    "exact",
+	PathMatchTypePrefix:   "prefix",
+	PathMatchTypeRegex:    "regex",
+	PathMatchTypeTemplate: "template",
 }
 
 func (t PathMatchType) String() string {
@@ -57,13 +71,15 @@ func (t PathMatchType) String() string {
 type PathConfig struct {
 	// Path indicates the HTTP Request's URL PATH to which this configuration applies
 	Path string `toml:"path"`
-	// MatchTypeName indicates the type of path match the router will apply to the path ('exact' or 'prefix')
+	// MatchTypeName indicates the type of path match the router will apply to the path
+	// ('exact', 'prefix', 'regex' or 'template')
 	MatchTypeName string `toml:"match_type"`
 	// HandlerName provides the name of the HTTP handler to use
 	HandlerName string `toml:"handler"`
 	// Methods provides the list of permitted HTTP request methods for this Path
 	Methods []string `toml:"methods"`
-	// CacheKeyParams provides the list of http request query parameters to be included in the hash for each request's cache key
+	// CacheKeyParams provides the list of http request query parameters to be included in the hash for each request's cache key.
+	// A '{name}' entry includes the value of the path variable captured by a 'regex' or 'template' path match
 	CacheKeyParams []string `toml:"cache_key_params"`
 	// CacheKeyHeaders provides the list of http request headers to be included in the hash for each request's cache key
 	CacheKeyHeaders []string `toml:"cache_key_headers"`
@@ -83,6 +99,23 @@ type PathConfig struct {
 	NoMetrics bool `toml:"no_metrics"`
 	// CollapsedForwardingName indicates 'basic' or 'progressive' Collapsed Forwarding to be used by this path.
 	CollapsedForwardingName string `toml:"collapsed_forwarding"`
+	// DownsampleRules maps the name of a query's outermost function (or '*' for any query) to the
+	// method ('sample', 'min', 'max', 'mean' or 'sum') used to derive a coarser-step result from
+	// a cached finer-step result of the same query. Queries matching no rule are never downsampled.
+	DownsampleRules map[string]string `toml:"downsample_rules"`
+	// DownsampleSourceStepsSecs is the list of finer steps, in seconds, whose cached results
+	// may be used to fulfill coarser-step requests on this path
+	DownsampleSourceStepsSecs []int `toml:"downsample_source_steps_secs"`
+	// RuleNames is the list of names of the Rule Sets applied, in order, to requests for this path
+	RuleNames []string `toml:"rules"`
+	// LimitNames is the list of names of the Limits applied to requests for this path, in addition to the origin's
+	LimitNames []string `toml:"limits"`
+	// AuthenticatorName is the name of the Authenticator that authenticates requests for this path,
+	// which overrides the origin's. 'none' disables authentication for the path
+	AuthenticatorName string `toml:"authenticator"`
+	// TimeoutSecs defines how long requests to the upstream origin for this path will wait for a
+	// response before timing out, which overrides the origin's timeout_secs
+	TimeoutSecs int64 `toml:"timeout_secs"`
 
 	// Synthesized PathConfig Values
 	//
@@ -95,8 +128,17 @@ type PathConfig struct {
 	ResponseBodyBytes []byte `toml:"-"`
 	// MatchType is the PathMatchType representation of MatchTypeName
 	MatchType PathMatchType `toml:"-"`
+	// PathRegexp is the compiled representation of a 'regex' match type Path, anchored to the entire path
+	PathRegexp *regexp.Regexp `toml:"-"`
 	// CollapsedForwardingType is the typed representation of CollapsedForwardingName
 	CollapsedForwardingType CollapsedForwardingType `toml:"-"`
+	// DownsampleMethods is the typed representation of DownsampleRules
+	DownsampleMethods map[string]DownsampleMethod `toml:"-"`
+	// DownsampleSourceSteps is the time.Duration representation of DownsampleSourceStepsSecs,
+	// sorted from coarsest to finest
+	DownsampleSourceSteps []time.Duration `toml:"-"`
+	// Timeout is the time.Duration representation of TimeoutSecs
+	Timeout time.Duration `toml:"-"`
 	// OriginConfig is the reference to the PathConfig's parent Origin Config
 	OriginConfig *OriginConfig `toml:"-"`
 	// KeyHasher points to an optional function that hashes the cacheKey with a custom algorithm
@@ -135,6 +177,7 @@ func (p *PathConfig) Clone() *PathConfig {
 		OriginConfig:            p.OriginConfig,
 		MatchTypeName:           p.MatchTypeName,
 		MatchType:               p.MatchType,
+		PathRegexp:              p.PathRegexp,
 		HandlerName:             p.HandlerName,
 		Handler:                 p.Handler,
 		RequestHeaders:          ts.CloneMap(p.RequestHeaders),
@@ -152,6 +195,34 @@ func (p *PathConfig) Clone() *PathConfig {
 		CacheKeyFormFields:      make([]string, len(p.CacheKeyFormFields)),
 		custom:                  make([]string, len(p.custom)),
 		KeyHasher:               p.KeyHasher,
+		AuthenticatorName:       p.AuthenticatorName,
+		TimeoutSecs:             p.TimeoutSecs,
+		Timeout:                 p.Timeout,
+	}
+	if p.DownsampleRules != nil {
+		c.DownsampleRules = ts.CloneMap(p.DownsampleRules)
+	}
+	if p.DownsampleMethods != nil {
+		c.DownsampleMethods = make(map[string]DownsampleMethod, len(p.DownsampleMethods))
+		for k, v := range p.DownsampleMethods {
+			c.DownsampleMethods[k] = v
+		}
+	}
+	if p.DownsampleSourceStepsSecs != nil {
+		c.DownsampleSourceStepsSecs = make([]int, len(p.DownsampleSourceStepsSecs))
+		copy(c.DownsampleSourceStepsSecs, p.DownsampleSourceStepsSecs)
+	}
+	if p.DownsampleSourceSteps != nil {
+		c.DownsampleSourceSteps = make([]time.Duration, len(p.DownsampleSourceSteps))
+		copy(c.DownsampleSourceSteps, p.DownsampleSourceSteps)
+	}
+	if p.RuleNames != nil {
+		c.RuleNames = make([]string, len(p.RuleNames))
+		copy(c.RuleNames, p.RuleNames)
+	}
+	if p.LimitNames != nil {
+		c.LimitNames = make([]string, len(p.LimitNames))
+		copy(c.LimitNames, p.LimitNames)
 	}
 	copy(c.Methods, p.Methods)
 	copy(c.CacheKeyParams, p.CacheKeyParams)
@@ -176,6 +247,7 @@ func (p *PathConfig) Merge(p2 *PathConfig) {
 		case "match_type":
 			p.MatchType = p2.MatchType
 			p.MatchTypeName = p2.MatchTypeName
+			p.PathRegexp = p2.PathRegexp
 		case "handler":
 			p.HandlerName = p2.HandlerName
 			p.Handler = p2.Handler
@@ -204,6 +276,79 @@ func (p *PathConfig) Merge(p2 *PathConfig) {
 		case "collapsed_forwarding":
 			p.CollapsedForwardingName = p2.CollapsedForwardingName
 			p.CollapsedForwardingType = p2.CollapsedForwardingType
+		case "downsample_rules":
+			p.DownsampleRules = p2.DownsampleRules
+			p.DownsampleMethods = p2.DownsampleMethods
+		case "downsample_source_steps_secs":
+			p.DownsampleSourceStepsSecs = p2.DownsampleSourceStepsSecs
+			p.DownsampleSourceSteps = p2.DownsampleSourceSteps
+		case "rules":
+			p.RuleNames = p2.RuleNames
+		case "limits":
+			p.LimitNames = p2.LimitNames
+		case "authenticator":
+			p.AuthenticatorName = p2.AuthenticatorName
+		case "timeout_secs":
+			p.TimeoutSecs = p2.TimeoutSecs
+			p.Timeout = p2.Timeout
+		}
+	}
+}
+
+// verifyPathConfigs compiles the regular expressions of 'regex' match type paths, validates
+// the variables of 'template' match type paths, and validates the timeouts of all paths
+func (c *TricksterConfig) verifyPathConfigs() error {
+	for k, oc := range c.Origins {
+		for _, p := range oc.Paths {
+			if p.TimeoutSecs < 0 {
+				return fmt.Errorf("invalid timeout_secs [%d] provided in path config [%s] for origin [%s]",
+					p.TimeoutSecs, p.Path, k)
+			}
+			switch p.MatchType {
+			case PathMatchTypeRegex:
+				re, err := regexp.Compile("^(?:" + p.Path + ")$")
+				if err != nil {
+					return fmt.Errorf("invalid regex path [%s] provided in path config for origin [%s]", p.Path, k)
+				}
+				p.PathRegexp = re
+			case PathMatchTypeTemplate:
+				if !validPathTemplate(p.Path) {
+					return fmt.Errorf("invalid template path [%s] provided in path config for origin [%s]", p.Path, k)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validPathTemplate returns true if the path's variables are balanced, named and, when they
+// have a pattern, that the pattern is a valid regular expression
+func validPathTemplate(path string) bool {
+	var level, start int
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			if level == 0 {
+				start = i
+			}
+			level++
+		case '}':
+			level--
+			if level < 0 {
+				return false
+			}
+			if level == 0 {
+				parts := strings.SplitN(path[start+1:i], ":", 2)
+				if parts[0] == "" {
+					return false
+				}
+				if len(parts) == 2 {
+					if _, err := regexp.Compile(parts[1]); err != nil {
+						return false
+					}
+				}
+			}
 		}
 	}
+	return level == 0
 }
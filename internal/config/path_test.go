@@ -16,6 +16,7 @@ package config
 import (
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestPMTString(t *testing.T) {
@@ -23,7 +24,7 @@ func TestPMTString(t *testing.T) {
 	t1 := PathMatchTypeExact
 	t2 := PathMatchTypePrefix
 
-	var t3 PathMatchType = 3
+	var t3 PathMatchType = 4
 
 	if t1.String() != "exact" {
 		t.Errorf("expected %s got %s", "exact", t1.String())
@@ -33,8 +34,12 @@ func TestPMTString(t *testing.T) {
 		t.Errorf("expected %s got %s", "prefix", t2.String())
 	}
 
-	if t3.String() != "3" {
-		t.Errorf("expected %s got %s", "3", t3.String())
+	if t3.String() != "4" {
+		t.Errorf("expected %s got %s", "4", t3.String())
+	}
+
+	if PathMatchTypeRegex.String() != "regex" || PathMatchTypeTemplate.String() != "template" {
+		t.Errorf("expected %s %s got %s %s", "regex", "template", PathMatchTypeRegex, PathMatchTypeTemplate)
 	}
 
 }
@@ -76,7 +81,8 @@ func TestPathMerge(t *testing.T) {
 	pc2.OriginConfig = NewOriginConfig()
 
 	pc2.custom = []string{"path", "match_type", "handler", "methods", "cache_key_params", "cache_key_headers", "cache_key_form_fields",
-		"request_headers", "request_params", "response_headers", "response_code", "response_body", "no_metrics", "collapsed_forwarding"}
+		"request_headers", "request_params", "response_headers", "response_code", "response_body", "no_metrics", "collapsed_forwarding",
+		"downsample_rules", "downsample_source_steps_secs", "timeout_secs"}
 
 	expectedPath := "testPath"
 	expectedHandlerName := "testHandler"
@@ -96,6 +102,12 @@ func TestPathMerge(t *testing.T) {
 	pc2.NoMetrics = true
 	pc2.CollapsedForwardingName = "progressive"
 	pc2.CollapsedForwardingType = CFTypeProgressive
+	pc2.DownsampleRules = map[string]string{"max_over_time": "max"}
+	pc2.DownsampleMethods = map[string]DownsampleMethod{"max_over_time": DownsampleMethodMax}
+	pc2.DownsampleSourceStepsSecs = []int{15}
+	pc2.DownsampleSourceSteps = []time.Duration{15 * time.Second}
+	pc2.TimeoutSecs = 5
+	pc2.Timeout = 5 * time.Second
 
 	pc.Merge(pc2)
 
@@ -159,4 +171,72 @@ func TestPathMerge(t *testing.T) {
 		t.Errorf("expected %s got %s", "progressive", pc.CollapsedForwardingName)
 	}
 
+	if pc.DownsampleMethods["max_over_time"] != DownsampleMethodMax {
+		t.Errorf("expected %s got %s", DownsampleMethodMax, pc.DownsampleMethods["max_over_time"])
+	}
+
+	if len(pc.DownsampleSourceSteps) != 1 || pc.DownsampleSourceSteps[0] != 15*time.Second {
+		t.Errorf("expected %s got %v", "[15s]", pc.DownsampleSourceSteps)
+	}
+
+	if pc.TimeoutSecs != 5 || pc.Timeout != 5*time.Second {
+		t.Errorf("expected %s got %s", 5*time.Second, pc.Timeout)
+	}
+
+	pc3 := pc.Clone()
+	if pc3.Timeout != 5*time.Second {
+		t.Errorf("expected %s got %s", 5*time.Second, pc3.Timeout)
+	}
+	if len(pc3.DownsampleRules) != 1 || len(pc3.DownsampleMethods) != 1 ||
+		len(pc3.DownsampleSourceStepsSecs) != 1 || len(pc3.DownsampleSourceSteps) != 1 {
+		t.Errorf("expected cloned downsample settings, got %v %v", pc3.DownsampleMethods, pc3.DownsampleSourceSteps)
+	}
+
+}
+
+func TestVerifyPathConfigs(t *testing.T) {
+
+	config := NewConfig()
+	p := NewPathConfig()
+	p.Path = "/api/(?P<version>v[0-9]+)/series"
+	p.MatchType = PathMatchTypeRegex
+	config.Origins["default"].Paths["series"] = p
+
+	err := config.verifyPathConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if p.PathRegexp == nil || !p.PathRegexp.MatchString("/api/v1/series") || p.PathRegexp.MatchString("/api/v1/series/x") {
+		t.Errorf("expected an anchored regexp got %v", p.PathRegexp)
+	}
+
+	p.Path = "/api/(v[0-9]+/series"
+	err = config.verifyPathConfigs()
+	expected := "invalid regex path [/api/(v[0-9]+/series] provided in path config for origin [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+
+	p.MatchType = PathMatchTypeTemplate
+	for _, v := range []string{"/api/v1/label/{name}/values", "/api/{version:v[0-9]{1,2}}/query"} {
+		p.Path = v
+		if err = config.verifyPathConfigs(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for _, v := range []string{"/api/v1/label/{name/values", "/api/v1/label/}/values", "/api/{}", "/api/{version:v[0-9}"} {
+		p.Path = v
+		if err = config.verifyPathConfigs(); err == nil {
+			t.Errorf("expected error for template path %s", v)
+		}
+	}
+
+	p.Path = "/api/v1/labels"
+	p.TimeoutSecs = -1
+	err = config.verifyPathConfigs()
+	expected = "invalid timeout_secs [-1] provided in path config [/api/v1/labels] for origin [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
 }
@@ -0,0 +1,93 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RetryConfig is a collection of Configurations for retrying and hedging the idempotent requests
+// that Trickster makes to an origin
+type RetryConfig struct {
+	// MaxRetries is the maximum number of times a failed request is retried. 0 disables retries
+	MaxRetries int `toml:"max_retries"`
+	// BackoffMS is the base backoff, in milliseconds, before the first retry. The backoff doubles
+	// with each further retry, and the actual wait is a random duration up to the backoff
+	BackoffMS int `toml:"backoff_ms"`
+	// MaxBackoffMS is the maximum backoff, in milliseconds, before any retry
+	MaxBackoffMS int `toml:"max_backoff_ms"`
+	// Statuses is the list of origin response codes that cause a request to be retried
+	Statuses []int `toml:"statuses"`
+	// HedgeDelayMS is the time, in milliseconds, after which a second request is made to the origin
+	// if the first has not responded. The first response is used. 0 disables hedging
+	HedgeDelayMS int `toml:"hedge_delay_ms"`
+
+	// Backoff is the time.Duration representation of BackoffMS
+	Backoff time.Duration `toml:"-"`
+	// MaxBackoff is the time.Duration representation of MaxBackoffMS
+	MaxBackoff time.Duration `toml:"-"`
+	// HedgeDelay is the time.Duration representation of HedgeDelayMS
+	HedgeDelay time.Duration `toml:"-"`
+}
+
+// NewRetryConfig returns a RetryConfig with the default settings
+func NewRetryConfig() RetryConfig {
+	return RetryConfig{
+		BackoffMS:    defaultRetryBackoffMS,
+		MaxBackoffMS: defaultRetryMaxBackoffMS,
+		Statuses:     []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// Enabled returns true if the RetryConfig retries or hedges requests
+func (rc *RetryConfig) Enabled() bool {
+	return rc.MaxRetries > 0 || rc.HedgeDelayMS > 0
+}
+
+// Clone returns an exact copy of the subject *RetryConfig
+func (rc *RetryConfig) Clone() RetryConfig {
+	r := *rc
+	if rc.Statuses != nil {
+		r.Statuses = make([]int, len(rc.Statuses))
+		copy(r.Statuses, rc.Statuses)
+	}
+	return r
+}
+
+// verifyRetryConfigs validates the retries of each origin, and sets their durations
+func (c *TricksterConfig) verifyRetryConfigs() error {
+
+	for k, oc := range c.Origins {
+
+		rc := &oc.Retries
+
+		if rc.MaxRetries < 0 {
+			return fmt.Errorf("invalid max_retries [%d] provided in retries config for origin [%s]", rc.MaxRetries, k)
+		}
+		if rc.BackoffMS < 0 || rc.MaxBackoffMS < rc.BackoffMS {
+			return fmt.Errorf("invalid backoff [%d-%d] provided in retries config for origin [%s]",
+				rc.BackoffMS, rc.MaxBackoffMS, k)
+		}
+		if rc.HedgeDelayMS < 0 {
+			return fmt.Errorf("invalid hedge_delay_ms [%d] provided in retries config for origin [%s]", rc.HedgeDelayMS, k)
+		}
+
+		rc.Backoff = time.Duration(rc.BackoffMS) * time.Millisecond
+		rc.MaxBackoff = time.Duration(rc.MaxBackoffMS) * time.Millisecond
+		rc.HedgeDelay = time.Duration(rc.HedgeDelayMS) * time.Millisecond
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyRetryConfigs(t *testing.T) {
+
+	config := NewConfig()
+	rc := &config.Origins["default"].Retries
+
+	err := config.verifyRetryConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if rc.Backoff != defaultRetryBackoffMS*time.Millisecond {
+		t.Errorf("expected %d got %d", defaultRetryBackoffMS*time.Millisecond, rc.Backoff)
+	}
+
+	rc.MaxRetries = -1
+	err = config.verifyRetryConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid max_retries")
+	}
+
+	rc.MaxRetries = 1
+	rc.MaxBackoffMS = rc.BackoffMS - 1
+	err = config.verifyRetryConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid backoff")
+	}
+
+	rc.MaxBackoffMS = rc.BackoffMS
+	rc.HedgeDelayMS = -1
+	err = config.verifyRetryConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid hedge_delay_ms")
+	}
+}
+
+func TestRetryConfigClone(t *testing.T) {
+
+	rc := NewRetryConfig()
+	if rc.Enabled() {
+		t.Errorf("expected %t got %t", false, rc.Enabled())
+	}
+
+	rc.HedgeDelayMS = 100
+	if !rc.Enabled() {
+		t.Errorf("expected %t got %t", true, rc.Enabled())
+	}
+
+	r := rc.Clone()
+	r.Statuses[0] = 500
+	if rc.Statuses[0] == 500 {
+		t.Errorf("clone shares state with the original config: %v", rc)
+	}
+	if r.HedgeDelayMS != rc.HedgeDelayMS || r.BackoffMS != rc.BackoffMS {
+		t.Errorf("expected %v got %v", rc, r)
+	}
+}
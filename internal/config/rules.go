@@ -0,0 +1,195 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// RuleSetConfig is an ordered list of Rules that is applied to each request on the paths that
+// reference it by name. A request matching a Rule's Conditions has the Rule's Actions applied to it
+type RuleSetConfig []*RuleConfig
+
+// RuleConfig is a collection of Conditions, which must all match a request in order for
+// the Actions to be applied to it
+type RuleConfig struct {
+	// Name identifies the Rule in the logs
+	Name string `toml:"name"`
+	// Conditions is the list of Conditions that a request must match. A Rule without Conditions matches all requests
+	Conditions []*RuleConditionConfig `toml:"conditions"`
+	// Actions is the list of Actions applied, in order, to matching requests
+	Actions []*RuleActionConfig `toml:"actions"`
+	// Stop, when true, indicates no further Rules in the Rule Set are evaluated for a matching request
+	Stop bool `toml:"stop"`
+}
+
+// RuleConditionConfig describes a Condition that matches a part of a request against a value
+type RuleConditionConfig struct {
+	// Source is the part of the request that is matched ('header', 'param', 'host', 'method',
+	// 'client_ip', 'path' or 'statement')
+	Source string `toml:"source"`
+	// Key is the name of the header or query parameter that is matched
+	Key string `toml:"key"`
+	// Operator is the comparison of the source with the value ('eq', 'in', 'prefix', 'suffix',
+	// 'contains', 'regex', 'cidr' or 'exists'). The default is 'eq'
+	Operator string `toml:"operator"`
+	// Value is the value to which the source is compared. The 'in' and 'cidr' operators accept a comma-separated list
+	Value string `toml:"value"`
+	// Negate, when true, inverts the result of the comparison
+	Negate bool `toml:"negate"`
+}
+
+// RuleActionConfig describes an Action applied to a request that matches a Rule
+type RuleActionConfig struct {
+	// Action is the type of the Action ('set_header', 'append_header', 'delete_header', 'set_param',
+	// 'append_param', 'delete_param', 'rewrite_path', 'rewrite_host' or 'route')
+	Action string `toml:"action"`
+	// Key is the name of the header or query parameter that is modified
+	Key string `toml:"key"`
+	// Pattern is a regular expression matching the part of the path that 'rewrite_path' replaces.
+	// When it is not set, the entire path is replaced
+	Pattern string `toml:"pattern"`
+	// Value is the value of the header, query parameter, path or host, or the name of the origin
+	// to which the request is routed. The path replacement may reference the Pattern's capture groups
+	Value string `toml:"value"`
+}
+
+var ruleConditionSources = map[string]bool{"header": true, "param": true, "host": true, "method": true,
+	"client_ip": true, "path": true, "statement": true}
+
+var ruleConditionOperators = map[string]bool{"eq": true, "in": true, "prefix": true, "suffix": true,
+	"contains": true, "regex": true, "cidr": true, "exists": true}
+
+var ruleActions = map[string]bool{"set_header": true, "append_header": true, "delete_header": true,
+	"set_param": true, "append_param": true, "delete_param": true, "rewrite_path": true,
+	"rewrite_host": true, "route": true}
+
+// Clone returns an exact copy of the subject RuleSetConfig
+func (rs RuleSetConfig) Clone() RuleSetConfig {
+	rs2 := make(RuleSetConfig, len(rs))
+	for i, r := range rs {
+		r2 := &RuleConfig{Name: r.Name, Stop: r.Stop,
+			Conditions: make([]*RuleConditionConfig, len(r.Conditions)),
+			Actions:    make([]*RuleActionConfig, len(r.Actions)),
+		}
+		for j, c := range r.Conditions {
+			c2 := *c
+			r2.Conditions[j] = &c2
+		}
+		for j, a := range r.Actions {
+			a2 := *a
+			r2.Actions[j] = &a2
+		}
+		rs2[i] = r2
+	}
+	return rs2
+}
+
+// verifyRuleConfigs validates each Rule Set and the Rule Set names referenced by each path,
+// and normalizes the names of the Rules' sources, operators and actions
+func (c *TricksterConfig) verifyRuleConfigs() error {
+
+	for k, rs := range c.Rules {
+		for i, r := range rs {
+			for _, rc := range r.Conditions {
+				if err := rc.verify(); err != nil {
+					return fmt.Errorf("%s in rule [%d] of rule config [%s]", err.Error(), i, k)
+				}
+			}
+			for _, ra := range r.Actions {
+				if err := ra.verify(c.Origins); err != nil {
+					return fmt.Errorf("%s in rule [%d] of rule config [%s]", err.Error(), i, k)
+				}
+			}
+		}
+	}
+
+	for k, oc := range c.Origins {
+		for _, p := range oc.Paths {
+			for _, n := range p.RuleNames {
+				if _, ok := c.Rules[n]; !ok {
+					return fmt.Errorf("invalid rule name [%s] provided in path config [%s] for origin [%s]", n, p.Path, k)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rc *RuleConditionConfig) verify() error {
+
+	rc.Source = strings.ToLower(rc.Source)
+	if !ruleConditionSources[rc.Source] {
+		return fmt.Errorf("invalid condition source [%s]", rc.Source)
+	}
+	if (rc.Source == "header" || rc.Source == "param") && rc.Key == "" {
+		return fmt.Errorf("missing condition key for source [%s]", rc.Source)
+	}
+
+	rc.Operator = strings.ToLower(rc.Operator)
+	if rc.Operator == "" {
+		rc.Operator = "eq"
+	}
+	if !ruleConditionOperators[rc.Operator] {
+		return fmt.Errorf("invalid condition operator [%s]", rc.Operator)
+	}
+
+	switch rc.Operator {
+	case "regex":
+		if _, err := regexp.Compile(rc.Value); err != nil {
+			return fmt.Errorf("invalid condition regex [%s]", rc.Value)
+		}
+	case "cidr":
+		for _, v := range strings.Split(rc.Value, ",") {
+			if _, _, err := net.ParseCIDR(strings.TrimSpace(v)); err != nil {
+				return fmt.Errorf("invalid condition cidr [%s]", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ra *RuleActionConfig) verify(origins map[string]*OriginConfig) error {
+
+	ra.Action = strings.ToLower(ra.Action)
+	if !ruleActions[ra.Action] {
+		return fmt.Errorf("invalid action [%s]", ra.Action)
+	}
+
+	switch ra.Action {
+	case "set_header", "append_header", "delete_header", "set_param", "append_param", "delete_param":
+		if ra.Key == "" {
+			return fmt.Errorf("missing key for action [%s]", ra.Action)
+		}
+	case "rewrite_path":
+		if _, err := regexp.Compile(ra.Pattern); err != nil {
+			return fmt.Errorf("invalid rewrite_path pattern [%s]", ra.Pattern)
+		}
+	case "rewrite_host":
+		if ra.Value == "" {
+			return fmt.Errorf("missing value for action [%s]", ra.Action)
+		}
+	case "route":
+		if _, ok := origins[ra.Value]; !ok {
+			return fmt.Errorf("invalid route origin name [%s]", ra.Value)
+		}
+	}
+
+	return nil
+}
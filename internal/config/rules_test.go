@@ -0,0 +1,104 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import "testing"
+
+func TestVerifyRuleConfigs(t *testing.T) {
+
+	config := NewConfig()
+	rc := &RuleConditionConfig{Source: "Header", Key: "X-Tenant", Value: "acme"}
+	ra := &RuleActionConfig{Action: "route", Value: "default"}
+	config.Rules["test"] = RuleSetConfig{&RuleConfig{
+		Conditions: []*RuleConditionConfig{rc},
+		Actions:    []*RuleActionConfig{ra},
+	}}
+
+	err := config.verifyRuleConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if rc.Source != "header" || rc.Operator != "eq" {
+		t.Errorf("expected %s %s got %s %s", "header", "eq", rc.Source, rc.Operator)
+	}
+
+	tests := []struct {
+		source, key, operator, value            string
+		action, actionKey, pattern, actionValue string
+		expected                                string
+	}{
+		{"body", "", "", "", "route", "", "", "default",
+			"invalid condition source [body] in rule [0] of rule config [test]"},
+		{"param", "", "", "", "route", "", "", "default",
+			"missing condition key for source [param] in rule [0] of rule config [test]"},
+		{"path", "", "like", "", "route", "", "", "default",
+			"invalid condition operator [like] in rule [0] of rule config [test]"},
+		{"path", "", "regex", "[", "route", "", "", "default",
+			"invalid condition regex [[] in rule [0] of rule config [test]"},
+		{"client_ip", "", "cidr", "10.0.0.0/8,10.0.0.1", "route", "", "", "default",
+			"invalid condition cidr [10.0.0.1] in rule [0] of rule config [test]"},
+		{"path", "", "", "", "redirect", "", "", "default",
+			"invalid action [redirect] in rule [0] of rule config [test]"},
+		{"path", "", "", "", "set_header", "", "", "1",
+			"missing key for action [set_header] in rule [0] of rule config [test]"},
+		{"path", "", "", "", "rewrite_path", "", "[", "/",
+			"invalid rewrite_path pattern [[] in rule [0] of rule config [test]"},
+		{"path", "", "", "", "rewrite_host", "", "", "",
+			"missing value for action [rewrite_host] in rule [0] of rule config [test]"},
+		{"path", "", "", "", "route", "", "", "missing",
+			"invalid route origin name [missing] in rule [0] of rule config [test]"},
+	}
+
+	for i, test := range tests {
+		*rc = RuleConditionConfig{Source: test.source, Key: test.key, Operator: test.operator, Value: test.value}
+		*ra = RuleActionConfig{Action: test.action, Key: test.actionKey, Pattern: test.pattern, Value: test.actionValue}
+		err = config.verifyRuleConfigs()
+		if err == nil {
+			t.Errorf("test %d: expected error: %s", i, test.expected)
+		} else if err.Error() != test.expected {
+			t.Errorf("test %d: expected %s got %s", i, test.expected, err.Error())
+		}
+	}
+
+	*rc = RuleConditionConfig{Source: "path", Value: "/"}
+	*ra = RuleActionConfig{Action: "route", Value: "default"}
+	config.Origins["default"].Paths["root"] = &PathConfig{Path: "/", RuleNames: []string{"missing"}}
+	err = config.verifyRuleConfigs()
+	const expected = "invalid rule name [missing] provided in path config [/] for origin [default]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+}
+
+func TestRuleSetConfigClone(t *testing.T) {
+
+	rs := RuleSetConfig{&RuleConfig{
+		Name:       "test",
+		Conditions: []*RuleConditionConfig{{Source: "path", Value: "/"}},
+		Actions:    []*RuleActionConfig{{Action: "route", Value: "default"}},
+		Stop:       true,
+	}}
+
+	rs2 := rs.Clone()
+	rs2[0].Conditions[0].Value = "/changed"
+	rs2[0].Actions[0].Value = "changed"
+
+	if rs[0].Conditions[0].Value != "/" || rs[0].Actions[0].Value != "default" {
+		t.Errorf("clone shares state with the original config: %v", rs[0])
+	}
+
+	if rs2[0].Name != "test" || !rs2[0].Stop {
+		t.Errorf("expected %v got %v", rs[0], rs2[0])
+	}
+}
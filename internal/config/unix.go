@@ -0,0 +1,71 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unixSchemeSuffix is the suffix of the scheme of an origin URL that is reached through a
+// Unix domain socket (e.g., http+unix:///var/run/prometheus.sock:/path/prefix)
+const unixSchemeSuffix = "+unix"
+
+// unixSocketHost is the Host of the requests made to an origin through a Unix domain socket
+const unixSocketHost = "localhost"
+
+// parseUnixSocketURL returns the socket path of an origin URL that is reached through a Unix
+// domain socket, or an empty string if the URL is not. The URL is updated to the http or https
+// URL of requests made through the socket, with the path prefix that follows the socket path
+func parseUnixSocketURL(u *url.URL) (string, error) {
+
+	if !strings.HasSuffix(u.Scheme, unixSchemeSuffix) {
+		return "", nil
+	}
+
+	u.Scheme = strings.TrimSuffix(u.Scheme, unixSchemeSuffix)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.New("unsupported unix socket scheme")
+	}
+
+	path := u.Path
+	u.Path = ""
+	if i := strings.Index(path, ":"); i >= 0 {
+		path, u.Path = path[:i], path[i+1:]
+	}
+	// the socket path follows an empty host, as in a file:/// URL
+	if u.Host != "" || !filepath.IsAbs(path) {
+		return "", errors.New("invalid unix socket path")
+	}
+
+	u.Host = unixSocketHost
+	return path, nil
+}
+
+// parseFileMode returns the os.FileMode of an octal mode string (e.g., '0660'),
+// or 0 if the string is empty
+func parseFileMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || m > 0777 {
+		return 0, errors.New("invalid file mode")
+	}
+	return os.FileMode(m), nil
+}
@@ -0,0 +1,76 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseUnixSocketURL(t *testing.T) {
+
+	tests := []struct {
+		url, path, expected string
+		err                 bool
+	}{
+		{"http://127.0.0.1:9090/prefix", "", "http://127.0.0.1:9090/prefix", false},
+		{"http+unix:///var/run/prom.sock:/prefix", "/var/run/prom.sock", "http://localhost/prefix", false},
+		{"https+unix:///var/run/prom.sock", "/var/run/prom.sock", "https://localhost", false},
+		{"ftp+unix:///var/run/prom.sock", "", "", true},
+		{"http+unix://prom.sock:/prefix", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, _ := url.Parse(test.url)
+			path, err := parseUnixSocketURL(u)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected error for url %s", test.url)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != test.path {
+				t.Errorf("expected %s got %s", test.path, path)
+			}
+			if u.String() != test.expected {
+				t.Errorf("expected %s got %s", test.expected, u.String())
+			}
+		})
+	}
+}
+
+func TestParseFileMode(t *testing.T) {
+
+	m, err := parseFileMode("0660")
+	if err != nil {
+		t.Error(err)
+	}
+	if m != 0660 {
+		t.Errorf("expected %o got %o", 0660, m)
+	}
+
+	if m, _ = parseFileMode(""); m != 0 {
+		t.Errorf("expected %o got %o", 0, m)
+	}
+
+	for _, s := range []string{"0990", "rw", "01777"} {
+		if _, err = parseFileMode(s); err == nil {
+			t.Errorf("expected error for mode %s", s)
+		}
+	}
+}
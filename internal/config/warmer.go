@@ -0,0 +1,117 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// WarmerConfig is a collection of Configurations for periodically requesting an origin's
+// frequently-used queries through Trickster, so that they are cached before clients request them
+type WarmerConfig struct {
+	// Requests is a list of request paths, with query strings, to be requested from the origin.
+	// The placeholders {{start}}, {{end}} and {{step}} are replaced with the Unix time in seconds
+	// at the start and end of the warmed time range, and the step in seconds
+	Requests []string `toml:"requests"`
+	// DashboardPaths is a list of paths or glob patterns of Grafana dashboard JSON files,
+	// from whose panels the queries to be requested are extracted
+	DashboardPaths []string `toml:"dashboard_paths"`
+	// IntervalSecs is the number of seconds between each warming of the origin
+	IntervalSecs int `toml:"interval_secs"`
+	// RangeSecs is the duration of the warmed time range, which ends at the time of the warming
+	RangeSecs int `toml:"range_secs"`
+	// StepSecs is the step requested for the queries extracted from dashboards
+	StepSecs int `toml:"step_secs"`
+	// Concurrency is the maximum number of warming requests in flight at once
+	Concurrency int `toml:"concurrency"`
+	// Headers provides the HTTP Headers to apply to each warming request
+	Headers map[string]string `toml:"headers"`
+
+	// Interval is the time.Duration representation of IntervalSecs
+	Interval time.Duration `toml:"-"`
+	// Range is the time.Duration representation of RangeSecs
+	Range time.Duration `toml:"-"`
+	// Step is the time.Duration representation of StepSecs
+	Step time.Duration `toml:"-"`
+}
+
+// NewWarmerConfig returns a WarmerConfig with the default settings
+func NewWarmerConfig() WarmerConfig {
+	return WarmerConfig{
+		IntervalSecs: defaultWarmerIntervalSecs,
+		RangeSecs:    defaultWarmerRangeSecs,
+		StepSecs:     defaultWarmerStepSecs,
+		Concurrency:  defaultWarmerConcurrency,
+		Headers:      make(map[string]string),
+	}
+}
+
+// Enabled returns true if the WarmerConfig has any requests or dashboards to warm
+func (wc *WarmerConfig) Enabled() bool {
+	return len(wc.Requests) > 0 || len(wc.DashboardPaths) > 0
+}
+
+// Clone returns an exact copy of the subject *WarmerConfig
+func (wc *WarmerConfig) Clone() WarmerConfig {
+
+	w := *wc
+
+	if wc.Requests != nil {
+		w.Requests = make([]string, len(wc.Requests))
+		copy(w.Requests, wc.Requests)
+	}
+
+	if wc.DashboardPaths != nil {
+		w.DashboardPaths = make([]string, len(wc.DashboardPaths))
+		copy(w.DashboardPaths, wc.DashboardPaths)
+	}
+
+	w.Headers = make(map[string]string, len(wc.Headers))
+	for k, v := range wc.Headers {
+		w.Headers[k] = v
+	}
+
+	return w
+}
+
+// verifyWarmerConfigs validates the warmer of each origin that has one, and sets its durations
+func (c *TricksterConfig) verifyWarmerConfigs() error {
+
+	for k, oc := range c.Origins {
+
+		wc := &oc.Warmer
+		if !wc.Enabled() {
+			continue
+		}
+
+		if wc.IntervalSecs <= 0 {
+			return fmt.Errorf("invalid interval [%d] provided in warmer config for origin [%s]", wc.IntervalSecs, k)
+		}
+		if wc.RangeSecs <= 0 {
+			return fmt.Errorf("invalid range [%d] provided in warmer config for origin [%s]", wc.RangeSecs, k)
+		}
+		if wc.StepSecs <= 0 {
+			return fmt.Errorf("invalid step [%d] provided in warmer config for origin [%s]", wc.StepSecs, k)
+		}
+		if wc.Concurrency <= 0 {
+			return fmt.Errorf("invalid concurrency [%d] provided in warmer config for origin [%s]", wc.Concurrency, k)
+		}
+
+		wc.Interval = time.Duration(wc.IntervalSecs) * time.Second
+		wc.Range = time.Duration(wc.RangeSecs) * time.Second
+		wc.Step = time.Duration(wc.StepSecs) * time.Second
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyWarmerConfigs(t *testing.T) {
+
+	config := NewConfig()
+	wc := &config.Origins["default"].Warmer
+
+	// the default origin has nothing to warm, so its settings aren't checked
+	wc.Concurrency = 0
+	err := config.verifyWarmerConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+
+	wc.Requests = []string{"/api/v1/query?query=up"}
+	err = config.verifyWarmerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid concurrency")
+	}
+
+	wc.Concurrency = 1
+	err = config.verifyWarmerConfigs()
+	if err != nil {
+		t.Error(err)
+	}
+	if wc.Interval != defaultWarmerIntervalSecs*time.Second {
+		t.Errorf("expected %d got %d", defaultWarmerIntervalSecs*time.Second, wc.Interval)
+	}
+
+	wc.StepSecs = -1
+	err = config.verifyWarmerConfigs()
+	if err == nil {
+		t.Errorf("expected error for invalid step")
+	}
+}
+
+func TestWarmerConfigClone(t *testing.T) {
+
+	wc := NewWarmerConfig()
+	wc.Requests = []string{"/query"}
+	wc.DashboardPaths = []string{"/dashboards/*.json"}
+	wc.Headers["Authorization"] = "Bearer test"
+
+	w := wc.Clone()
+	w.Requests[0] = "/changed"
+	w.DashboardPaths[0] = "/changed"
+	w.Headers["Authorization"] = "changed"
+
+	if wc.Requests[0] != "/query" || wc.DashboardPaths[0] != "/dashboards/*.json" ||
+		wc.Headers["Authorization"] != "Bearer test" {
+		t.Errorf("clone shares state with the original config: %v", wc)
+	}
+
+	if w.Concurrency != wc.Concurrency || w.IntervalSecs != wc.IntervalSecs {
+		t.Errorf("expected %v got %v", wc, w)
+	}
+}
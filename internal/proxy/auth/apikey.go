@@ -0,0 +1,61 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// apiKeyVerifier verifies the static API key provided in a request header
+type apiKeyVerifier struct {
+	header string
+	// keys maps the SHA-256 sum of each key to its identity, so that keys are
+	// compared in constant time regardless of their length
+	keys map[[sha256.Size]byte]string
+}
+
+func newAPIKeyVerifier(keys map[string]string, header string) *apiKeyVerifier {
+	v := &apiKeyVerifier{header: header, keys: make(map[[sha256.Size]byte]string, len(keys))}
+	for k, id := range keys {
+		v.keys[sha256.Sum256([]byte(k))] = id
+	}
+	return v
+}
+
+func (v *apiKeyVerifier) scheme() string {
+	return "APIKey"
+}
+
+func (v *apiKeyVerifier) verify(r *http.Request) (*Identity, error) {
+
+	key := r.Header.Get(v.header)
+	if key == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	var name string
+	for k, id := range v.keys {
+		if subtle.ConstantTimeCompare(sum[:], k[:]) == 1 {
+			name = id
+		}
+	}
+	if name == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{Name: name}, nil
+}
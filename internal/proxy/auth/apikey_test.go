@@ -0,0 +1,49 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeyVerifier(t *testing.T) {
+
+	v := newAPIKeyVerifier(map[string]string{"key-1": "tenant-1", "key-2": "tenant-2"}, "X-API-Key")
+
+	tests := []struct {
+		key, expectedName string
+		expected          error
+	}{
+		{"key-1", "tenant-1", nil},
+		{"key-2", "tenant-2", nil},
+		{"key-3", "", ErrInvalidCredentials},
+		{"", "", ErrMissingCredentials},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+		if test.key != "" {
+			r.Header.Set("X-API-Key", test.key)
+		}
+		id, err := v.verify(r)
+		if err != test.expected {
+			t.Errorf("test %d: expected %v got %v", i, test.expected, err)
+		}
+		if err == nil && id.Name != test.expectedName {
+			t.Errorf("test %d: expected %s got %s", i, test.expectedName, id.Name)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package auth authenticates frontend requests with htpasswd Basic credentials,
+// static API keys or JSON Web Tokens, before they are proxied to the origin
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+)
+
+// ErrMissingCredentials indicates the request did not provide credentials to the Authenticator
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// ErrInvalidCredentials indicates the credentials provided by the request were not accepted
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Identity is the verified identity of an authenticated request
+type Identity struct {
+	// Name is the name of the authenticated user, API key or token subject
+	Name string
+	// Claims are the claims of the token that authenticated the request, if any
+	Claims map[string]interface{}
+}
+
+// Authenticator verifies the credentials of requests with the method of an Authenticator configuration
+type Authenticator struct {
+	// Name is the name of the Authenticator in the configuration
+	Name string
+
+	cfg      *config.AuthenticatorConfig
+	verifier verifier
+}
+
+// verifier verifies the credentials of a request with a particular method of authentication
+type verifier interface {
+	verify(r *http.Request) (*Identity, error)
+	// scheme is the authentication scheme challenged in the WWW-Authenticate header
+	scheme() string
+}
+
+// New returns a new Authenticator for the provided Authenticator configuration, which must
+// have been verified by the config loader
+func New(name string, cfg *config.AuthenticatorConfig) (*Authenticator, error) {
+
+	a := &Authenticator{Name: name, cfg: cfg}
+
+	var err error
+	switch cfg.AuthenticatorType {
+	case "basic":
+		a.verifier, err = newBasicVerifier(cfg.HtpasswdPath)
+	case "api_key":
+		a.verifier = newAPIKeyVerifier(cfg.APIKeys, cfg.APIKeyHeader)
+	case "jwt":
+		a.verifier, err = newJWTVerifier(name, cfg)
+	default:
+		err = fmt.Errorf("unknown authenticator type [%s]", cfg.AuthenticatorType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Authenticate returns the verified Identity of the request, or an error if it is not authenticated
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	return a.verifier.verify(r)
+}
+
+// challenge returns the value of the WWW-Authenticate header of responses to unauthenticated requests
+func (a *Authenticator) challenge() string {
+	return fmt.Sprintf(`%s realm="%s"`, a.verifier.scheme(), a.cfg.Realm)
+}
+
+// prepareUpstream sets the request headers mapped from the Identity's claims, and strips or
+// replaces the client's Authorization header, before the request is proxied to the origin
+func (a *Authenticator) prepareUpstream(r *http.Request, id *Identity) {
+
+	for claim, name := range a.cfg.ClaimsHeaders {
+		// any value provided by the client is removed, so that it cannot be spoofed
+		r.Header.Del(name)
+		if v, ok := id.Claims[claim]; ok {
+			if s := claimString(v); s != "" {
+				r.Header.Set(name, s)
+			}
+		}
+	}
+
+	switch a.cfg.UpstreamAuthorization {
+	case "strip":
+		r.Header.Del(headers.NameAuthorization)
+	case "replace":
+		r.Header.Set(headers.NameAuthorization, a.cfg.UpstreamAuthorizationValue)
+	}
+}
+
+// claimString returns the string representation of a claim value for use in a header
+func claimString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case []interface{}:
+		vals := make([]string, 0, len(t))
+		for _, v2 := range t {
+			if s := claimString(v2); s != "" {
+				vals = append(vals, s)
+			}
+		}
+		return strings.Join(vals, ",")
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,115 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+func TestNew(t *testing.T) {
+
+	path, cleanup := writeTestJWKS(t)
+	defer cleanup()
+
+	cfgs := []*config.AuthenticatorConfig{
+		{AuthenticatorType: "basic", HtpasswdPath: testHtpasswdPath},
+		{AuthenticatorType: "api_key", APIKeys: map[string]string{"key": "tenant"}, APIKeyHeader: "X-API-Key"},
+		{AuthenticatorType: "jwt", JWKSPath: path, JWKSRefreshSecs: 3600},
+	}
+	schemes := []string{"Basic", "APIKey", "Bearer"}
+
+	for i, cfg := range cfgs {
+		cfg.Realm = "test"
+		a, err := New("test", cfg)
+		if err != nil {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		if v, expected := a.challenge(), schemes[i]+` realm="test"`; v != expected {
+			t.Errorf("test %d: expected %s got %s", i, expected, v)
+		}
+	}
+
+	invalid := []*config.AuthenticatorConfig{
+		{AuthenticatorType: "basic", HtpasswdPath: "/invalid/path"},
+		{AuthenticatorType: "jwt", JWKSPath: "/invalid/path"},
+		{AuthenticatorType: "invalid"},
+	}
+	for i, cfg := range invalid {
+		if _, err := New("test", cfg); err == nil {
+			t.Errorf("test %d: expected error", i)
+		}
+	}
+}
+
+func TestPrepareUpstream(t *testing.T) {
+
+	a := &Authenticator{Name: "test", cfg: &config.AuthenticatorConfig{
+		ClaimsHeaders:         map[string]string{"sub": "X-User", "groups": "X-Groups", "tenant": "X-Tenant"},
+		UpstreamAuthorization: "strip",
+	}}
+	id := &Identity{Name: "user", Claims: map[string]interface{}{"sub": "user",
+		"groups": []interface{}{"a", "b"}}}
+
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.Header.Set("Authorization", "Bearer test")
+	r.Header.Set("X-Tenant", "spoofed")
+	a.prepareUpstream(r, id)
+
+	if v := r.Header.Get("X-User"); v != "user" {
+		t.Errorf("expected %s got %s", "user", v)
+	}
+	if v := r.Header.Get("X-Groups"); v != "a,b" {
+		t.Errorf("expected %s got %s", "a,b", v)
+	}
+	if v, ok := r.Header["X-Tenant"]; ok {
+		t.Errorf("expected no header got %v", v)
+	}
+	if v, ok := r.Header["Authorization"]; ok {
+		t.Errorf("expected no header got %v", v)
+	}
+
+	a.cfg.UpstreamAuthorization = "replace"
+	a.cfg.UpstreamAuthorizationValue = "Basic dGVzdDp0ZXN0"
+	a.prepareUpstream(r, id)
+	if v := r.Header.Get("Authorization"); v != "Basic dGVzdDp0ZXN0" {
+		t.Errorf("expected %s got %s", "Basic dGVzdDp0ZXN0", v)
+	}
+}
+
+func TestClaimString(t *testing.T) {
+
+	tests := []struct {
+		claim    interface{}
+		expected string
+	}{
+		{"test", "test"},
+		{float64(1577836800), "1577836800"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{[]interface{}{"a", 1.0, nil}, "a,1"},
+		{map[string]interface{}{"a": "b"}, `{"a":"b"}`},
+		{nil, ""},
+	}
+
+	for i, test := range tests {
+		if v := claimString(test.claim); v != test.expected {
+			t.Errorf("test %d: expected %s got %s", i, test.expected, v)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	apr1Prefix = "$apr1$"
+	sha1Prefix = "{SHA}"
+	apr1Itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// basicVerifier verifies Basic credentials against the users of an htpasswd file
+type basicVerifier struct {
+	users map[string]string
+}
+
+// newBasicVerifier returns a basicVerifier for the users of the htpasswd file at the provided path.
+// An error is returned if any user's password is hashed with an unsupported method
+func newBasicVerifier(path string) (*basicVerifier, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	v := &basicVerifier{users: make(map[string]string)}
+
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid entry on line %d of htpasswd file %s", n, path)
+		}
+		user, hash := line[:i], line[i+1:]
+		if !strings.HasPrefix(hash, apr1Prefix) && !strings.HasPrefix(hash, sha1Prefix) {
+			return nil, fmt.Errorf("unsupported password hash for user %s on line %d of htpasswd file %s: "+
+				"only apr1 (MD5) and SHA1 hashes are supported", user, n, path)
+		}
+		v.users[user] = hash
+	}
+	if err = s.Err(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (v *basicVerifier) scheme() string {
+	return "Basic"
+}
+
+func (v *basicVerifier) verify(r *http.Request) (*Identity, error) {
+
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrMissingCredentials
+	}
+
+	hash, ok := v.users[user]
+	if !ok || !checkPassword(password, hash) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{Name: user}, nil
+}
+
+// checkPassword returns true if the password matches the htpasswd hash
+func checkPassword(password, hash string) bool {
+	var h string
+	switch {
+	case strings.HasPrefix(hash, apr1Prefix):
+		salt := strings.TrimPrefix(hash, apr1Prefix)
+		if i := strings.Index(salt, "$"); i >= 0 {
+			salt = salt[:i]
+		}
+		h = apr1(password, salt)
+	case strings.HasPrefix(hash, sha1Prefix):
+		sum := sha1.Sum([]byte(password))
+		h = sha1Prefix + base64.StdEncoding.EncodeToString(sum[:])
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1
+}
+
+// apr1 returns the Apache variant of the MD5-based crypt hash of the password with the provided salt
+func apr1(password, salt string) string {
+
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw, s := []byte(password), []byte(salt)
+
+	alt := md5.New()
+	alt.Write(pw)
+	alt.Write(s)
+	alt.Write(pw)
+	altSum := alt.Sum(nil)
+
+	h := md5.New()
+	h.Write(pw)
+	h.Write([]byte(apr1Prefix))
+	h.Write(s)
+	for i := len(pw); i > 0; i -= 16 {
+		if i > 16 {
+			h.Write(altSum)
+		} else {
+			h.Write(altSum[:i])
+		}
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 == 1 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	sum := h.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		h = md5.New()
+		if i&1 == 1 {
+			h.Write(pw)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 == 1 {
+			h.Write(sum)
+		} else {
+			h.Write(pw)
+		}
+		sum = h.Sum(nil)
+	}
+
+	out := make([]byte, 0, 22)
+	encode := func(a, b, c byte, n int) {
+		v := uint(a)<<16 | uint(b)<<8 | uint(c)
+		for ; n > 0; n-- {
+			out = append(out, apr1Itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	encode(sum[0], sum[6], sum[12], 4)
+	encode(sum[1], sum[7], sum[13], 4)
+	encode(sum[2], sum[8], sum[14], 4)
+	encode(sum[3], sum[9], sum[15], 4)
+	encode(sum[4], sum[10], sum[5], 4)
+	encode(0, 0, sum[11], 2)
+
+	return apr1Prefix + salt + "$" + string(out)
+}
@@ -0,0 +1,118 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testHtpasswdPath = "../../../testdata/test.htpasswd"
+
+func TestApr1(t *testing.T) {
+	expected := "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/"
+	if v := apr1("myPassword", "r31....."); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+
+	tests := []struct {
+		password, hash string
+		expected       bool
+	}{
+		{"myPassword", "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/", true},
+		{"notMyPassword", "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/", false},
+		{"myPassword", "{SHA}VBPuJHI7uixaa6LQGWx4s+5GKNE=", true},
+		{"notMyPassword", "{SHA}VBPuJHI7uixaa6LQGWx4s+5GKNE=", false},
+		{"myPassword", "myPassword", false},
+	}
+
+	for i, test := range tests {
+		if v := checkPassword(test.password, test.hash); v != test.expected {
+			t.Errorf("test %d: expected %t got %t", i, test.expected, v)
+		}
+	}
+}
+
+func TestBasicVerifier(t *testing.T) {
+
+	v, err := newBasicVerifier(testHtpasswdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(v.users) != 2 {
+		t.Errorf("expected %d got %d", 2, len(v.users))
+	}
+
+	tests := []struct {
+		user, password string
+		expected       error
+	}{
+		{"test", "myPassword", nil},
+		{"test-sha", "myPassword", nil},
+		{"test", "notMyPassword", ErrInvalidCredentials},
+		{"test-unknown", "myPassword", ErrInvalidCredentials},
+		{"", "", ErrMissingCredentials},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+		if test.user != "" {
+			r.SetBasicAuth(test.user, test.password)
+		}
+		id, err := v.verify(r)
+		if err != test.expected {
+			t.Errorf("test %d: expected %v got %v", i, test.expected, err)
+		}
+		if err == nil && id.Name != test.user {
+			t.Errorf("test %d: expected %s got %s", i, test.user, id.Name)
+		}
+	}
+}
+
+func TestNewBasicVerifierInvalid(t *testing.T) {
+
+	if _, err := newBasicVerifier("/invalid/path"); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	dir, err := ioutil.TempDir("", "trickster-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "htpasswd")
+	if err = ioutil.WriteFile(path, []byte("invalid\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newBasicVerifier(path); err == nil {
+		t.Error("expected error for invalid entry")
+	}
+
+	// bcrypt (htpasswd -B) hashes are not supported, and must fail the load rather than lock the user out
+	if err = ioutil.WriteFile(path, []byte("test:$2y$05$n8JMk2cJ5Ikyu2ncWXazZuKpRgWPG/YwLqHFLUvT9xSJJqcIgVz8q\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newBasicVerifier(path); err == nil {
+		t.Error("expected error for unsupported hash")
+	}
+}
@@ -0,0 +1,55 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"net/http"
+
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+// Handler returns an HTTP Handler that passes each request authenticated by the Authenticator
+// to next, and otherwise responds with 401 Unauthorized
+func Handler(a *Authenticator, originName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// a request dispatched to another path by a rule is not authenticated again by the same
+		// Authenticator, since its credentials may have been stripped or replaced
+		name, identity := tctx.AuthIdentity(r.Context())
+		if name != a.Name {
+			id, err := a.Authenticate(r)
+			if err != nil {
+				log.Debug("request failed to authenticate", log.Pairs{"originName": originName,
+					"authenticatorName": a.Name, "detail": err.Error()})
+				metrics.FrontendUnauthorizedRequests.WithLabelValues(originName, a.Name).Inc()
+				w.Header().Set(headers.NameWWWAuthenticate, a.challenge())
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			identity = id.Name
+			a.prepareUpstream(r, id)
+			r = r.WithContext(tctx.WithAuthIdentity(r.Context(), a.Name, identity))
+		}
+
+		if rsc := request.GetResources(r); rsc != nil {
+			rsc.AuthIdentity = identity
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,96 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+func TestHandler(t *testing.T) {
+
+	a, err := New("test", &config.AuthenticatorConfig{AuthenticatorType: "api_key", Realm: "test",
+		APIKeys: map[string]string{"key": "tenant"}, APIKeyHeader: "X-API-Key",
+		UpstreamAuthorization: "replace", UpstreamAuthorizationValue: "Bearer upstream"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	var rsc *request.Resources
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if v := r.Header.Get("Authorization"); v != "Bearer upstream" {
+			t.Errorf("expected %s got %s", "Bearer upstream", v)
+		}
+		if name, id := tctx.AuthIdentity(r.Context()); name != "test" || id != "tenant" {
+			t.Errorf("expected %s %s got %s %s", "test", "tenant", name, id)
+		}
+		rsc = request.GetResources(r)
+	})
+	h := Handler(a, "test", next)
+
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected %d got %d", http.StatusUnauthorized, w.Code)
+	}
+	if v := w.Header().Get("WWW-Authenticate"); v != `APIKey realm="test"` {
+		t.Errorf("expected %s got %s", `APIKey realm="test"`, v)
+	}
+	if calls != 0 {
+		t.Errorf("expected %d got %d", 0, calls)
+	}
+
+	r = request.SetResources(r, &request.Resources{})
+	r.Header.Set("X-API-Key", "key")
+	r.Header.Set("Authorization", "Bearer client")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, w.Code)
+	}
+	if rsc == nil || rsc.AuthIdentity != "tenant" {
+		t.Errorf("expected identity %s got %v", "tenant", rsc)
+	}
+
+	// a request already authenticated by the Authenticator is not authenticated again
+	r = httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.Header.Set("Authorization", "Bearer upstream")
+	r = r.WithContext(tctx.WithAuthIdentity(r.Context(), "test", "tenant"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || calls != 2 {
+		t.Errorf("expected %d got %d", http.StatusOK, w.Code)
+	}
+
+	// but one authenticated by another Authenticator is
+	r = r.WithContext(tctx.WithAuthIdentity(r.Context(), "other", "tenant"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected %d got %d", http.StatusUnauthorized, w.Code)
+	}
+}
@@ -0,0 +1,296 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// minUnknownKeyRefreshInterval is the minimum time between reloads of a key set
+// that are caused by tokens signed with an unknown key
+const minUnknownKeyRefreshInterval = 10 * time.Second
+
+// oidcDiscoveryPath is the path, relative to an OpenID Connect issuer, of its discovery document
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// jwksClient is the HTTP client used to load key sets and discovery documents
+var jwksClient = &http.Client{Timeout: 10 * time.Second}
+
+// jwk is a public or symmetric key from a JSON Web Key Set
+type jwk struct {
+	kid string
+	alg string
+	key interface{}
+}
+
+// keySet is a JSON Web Key Set loaded from a file, a URL, or the URL discovered from an OpenID
+// Connect issuer, which is reloaded periodically and when a token's key is not found in it
+type keySet struct {
+	name    string
+	path    string
+	url     string
+	issuer  string
+	refresh time.Duration
+
+	mtx        sync.RWMutex
+	keys       []*jwk
+	lastLoad   time.Time
+	loading    bool
+	loadingMtx sync.Mutex
+}
+
+// jsonWebKey is the JSON representation of a key in a JSON Web Key Set
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	K         string `json:"k"`
+}
+
+// newKeySet returns a keySet loaded from the provided source. A key set that is loaded from a URL and is
+// unavailable at startup is logged and reloaded when needed, so that Trickster can start while the issuer is down
+func newKeySet(name, path, url, issuer string, refresh time.Duration) (*keySet, error) {
+	ks := &keySet{name: name, path: path, url: url, issuer: strings.TrimSuffix(issuer, "/"), refresh: refresh}
+	if err := ks.load(); err != nil {
+		if path != "" {
+			return nil, err
+		}
+		log.Warn("unable to load json web key set", log.Pairs{"authenticatorName": name, "detail": err.Error()})
+	}
+	return ks, nil
+}
+
+// find returns the keys that may have signed a token with the provided key id and algorithm.
+// The key set is reloaded when it is due, or when the key id is unknown
+func (ks *keySet) find(kid, alg string) []*jwk {
+
+	ks.mtx.RLock()
+	keys, age := ks.match(kid, alg), time.Since(ks.lastLoad)
+	ks.mtx.RUnlock()
+
+	if len(keys) == 0 && age > minUnknownKeyRefreshInterval {
+		ks.reload()
+		ks.mtx.RLock()
+		keys = ks.match(kid, alg)
+		ks.mtx.RUnlock()
+	} else if age > ks.refresh {
+		go ks.reload()
+	}
+
+	return keys
+}
+
+// match returns the keys that match the key id and algorithm. The caller must hold the read lock
+func (ks *keySet) match(kid, alg string) []*jwk {
+	var keys []*jwk
+	for _, k := range ks.keys {
+		if (kid != "" && k.kid != kid) || (k.alg != "" && k.alg != alg) || !keyMatchesAlg(k.key, alg) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// reload loads the key set, unless it is already being loaded
+func (ks *keySet) reload() {
+	ks.loadingMtx.Lock()
+	if ks.loading {
+		ks.loadingMtx.Unlock()
+		return
+	}
+	ks.loading = true
+	ks.loadingMtx.Unlock()
+
+	if err := ks.load(); err != nil {
+		log.Warn("unable to reload json web key set", log.Pairs{"authenticatorName": ks.name, "detail": err.Error()})
+	}
+
+	ks.loadingMtx.Lock()
+	ks.loading = false
+	ks.loadingMtx.Unlock()
+}
+
+// load loads the key set from its source, and replaces the current keys. The time of the
+// load is recorded even when it fails, so that an unavailable source is not retried constantly
+func (ks *keySet) load() error {
+
+	defer func() {
+		ks.mtx.Lock()
+		ks.lastLoad = time.Now()
+		ks.mtx.Unlock()
+	}()
+
+	var b []byte
+	var err error
+	if ks.path != "" {
+		b, err = ioutil.ReadFile(ks.path)
+	} else {
+		if ks.url == "" {
+			if ks.url, err = discoverJWKSURL(ks.issuer); err != nil {
+				return err
+			}
+		}
+		b, err = fetch(ks.url)
+	}
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return err
+	}
+
+	ks.mtx.Lock()
+	ks.keys = keys
+	ks.mtx.Unlock()
+
+	return nil
+}
+
+// discoverJWKSURL returns the jwks_uri of the OpenID Connect issuer's discovery document
+func discoverJWKSURL(issuer string) (string, error) {
+	b, err := fetch(issuer + oidcDiscoveryPath)
+	if err != nil {
+		return "", err
+	}
+	doc := struct {
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return "", err
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("missing jwks_uri in discovery document of issuer %s", issuer)
+	}
+	return doc.JWKSURI, nil
+}
+
+func fetch(url string) ([]byte, error) {
+	resp, err := jwksClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseJWKS returns the signing keys of a JSON Web Key Set. Keys of unsupported types are skipped
+func parseJWKS(b []byte) ([]*jwk, error) {
+
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*jwk, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key [%s]: %s", k.KeyID, err.Error())
+		}
+		if key == nil {
+			continue
+		}
+		keys = append(keys, &jwk{kid: k.KeyID, alg: k.Algorithm, key: key})
+	}
+
+	return keys, nil
+}
+
+// publicKey returns the key used to verify signatures, or nil if the key type is not supported
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("missing value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
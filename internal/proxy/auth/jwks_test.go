@@ -0,0 +1,112 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeySetOIDCDiscovery(t *testing.T) {
+
+	var jwksLoads int32
+	jwks := []byte(`{"keys":[]}`)
+
+	mux := http.NewServeMux()
+	var issuer string
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"issuer":"` + issuer + `","jwks_uri":"` + issuer + `/keys"}`))
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&jwksLoads, 1)
+		w.Write(jwks)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	issuer = s.URL
+
+	ks, err := newKeySet("test", "", "", issuer+"/", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ks.url != issuer+"/keys" {
+		t.Errorf("expected %s got %s", issuer+"/keys", ks.url)
+	}
+
+	// an unknown key is not reloaded until the minimum refresh interval has passed
+	jwks = testJWKS()
+	if keys := ks.find("rsa", "RS256"); len(keys) != 0 {
+		t.Errorf("expected %d got %d", 0, len(keys))
+	}
+
+	ks.lastLoad = ks.lastLoad.Add(-minUnknownKeyRefreshInterval)
+	if keys := ks.find("rsa", "RS256"); len(keys) != 1 {
+		t.Errorf("expected %d got %d", 1, len(keys))
+	}
+	if v := atomic.LoadInt32(&jwksLoads); v != 2 {
+		t.Errorf("expected %d got %d", 2, v)
+	}
+}
+
+func TestNewKeySetUnavailable(t *testing.T) {
+
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+
+	// a key set from an unavailable URL is loaded when it is needed
+	ks, err := newKeySet("test", "", s.URL+"/keys", "", time.Hour)
+	if err != nil {
+		t.Error(err)
+	}
+	if keys := ks.find("", "RS256"); len(keys) != 0 {
+		t.Errorf("expected %d got %d", 0, len(keys))
+	}
+
+	// but an unavailable file is an error
+	if _, err = newKeySet("test", "/invalid/path", "", "", time.Hour); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+
+	keys, err := parseJWKS(testJWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 {
+		t.Errorf("expected %d got %d", 3, len(keys))
+	}
+
+	tests := []string{
+		`invalid`,
+		`{"keys":[{"kty":"RSA","n":"invalid!","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"RSA","n":"AQAB","e":""}]}`,
+		`{"keys":[{"kty":"EC","crv":"P-256","x":"AQAB","y":"AQAB"}]}`,
+	}
+	for i, test := range tests {
+		if _, err := parseJWKS([]byte(test)); err == nil {
+			t.Errorf("test %d: expected error", i)
+		}
+	}
+
+	// unsupported key types and curves are skipped
+	keys, err = parseJWKS([]byte(`{"keys":[{"kty":"OKP"},{"kty":"EC","crv":"P-224"}]}`))
+	if err != nil || len(keys) != 0 {
+		t.Errorf("expected %d got %d %v", 0, len(keys), err)
+	}
+}
@@ -0,0 +1,254 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+
+	// registers the hash functions used by the supported algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// jwtVerifier verifies the signature and claims of JSON Web Tokens provided as Bearer credentials
+type jwtVerifier struct {
+	keys          *keySet
+	issuer        string
+	audience      string
+	identityClaim string
+	leeway        time.Duration
+}
+
+// jwtHeader is the JOSE header of a JSON Web Token
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// algHashes maps each supported signing algorithm to its hash function
+var algHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+}
+
+// ecdsaAlgs maps the size of each supported curve to the algorithm that signs with it
+var ecdsaAlgs = map[int]string{256: "ES256", 384: "ES384", 521: "ES512"}
+
+// now is the source of the current time used to validate tokens, which can be replaced in tests
+var now = time.Now
+
+func newJWTVerifier(name string, cfg *config.AuthenticatorConfig) (*jwtVerifier, error) {
+	ks, err := newKeySet(name, cfg.JWKSPath, cfg.JWKSURL, cfg.OIDCIssuer,
+		time.Duration(cfg.JWKSRefreshSecs)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &jwtVerifier{
+		keys:          ks,
+		issuer:        cfg.Issuer,
+		audience:      cfg.Audience,
+		identityClaim: cfg.IdentityClaim,
+		leeway:        time.Duration(cfg.LeewaySecs) * time.Second,
+	}, nil
+}
+
+func (v *jwtVerifier) scheme() string {
+	return "Bearer"
+}
+
+func (v *jwtVerifier) verify(r *http.Request) (*Identity, error) {
+
+	token := bearerToken(r)
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var h jwtHeader
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	hash, ok := algHashes[h.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported algorithm [%s]", ErrInvalidCredentials.Error(), h.Algorithm)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	var verified bool
+	for _, k := range v.keys.find(h.KeyID, h.Algorithm) {
+		if verifySignature(h.Algorithm, hash, k.key, signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("%s: signature not verified", ErrInvalidCredentials.Error())
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidCredentials.Error(), err.Error())
+	}
+
+	name := claimString(claims[v.identityClaim])
+	if name == "" {
+		return nil, fmt.Errorf("%s: missing %s claim", ErrInvalidCredentials.Error(), v.identityClaim)
+	}
+
+	return &Identity{Name: name, Claims: claims}, nil
+}
+
+// validateClaims validates the token's time, issuer and audience claims
+func (v *jwtVerifier) validateClaims(claims map[string]interface{}) error {
+
+	t := now()
+	if exp, ok := claims["exp"].(float64); ok && t.After(unixTime(exp).Add(v.leeway)) {
+		return errors.New("token is expired")
+	} else if !ok && claims["exp"] != nil {
+		return errors.New("invalid exp claim")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && t.Before(unixTime(nbf).Add(-v.leeway)) {
+		return errors.New("token is not yet valid")
+	} else if !ok && claims["nbf"] != nil {
+		return errors.New("invalid nbf claim")
+	}
+
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return fmt.Errorf("unexpected issuer [%s]", iss)
+		}
+	}
+
+	if v.audience != "" {
+		var ok bool
+		switch aud := claims["aud"].(type) {
+		case string:
+			ok = aud == v.audience
+		case []interface{}:
+			for _, a := range aud {
+				if s, _ := a.(string); s == v.audience {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			return errors.New("unexpected audience")
+		}
+	}
+
+	return nil
+}
+
+// verifySignature returns true if the signature of the signed content was made by the key with the algorithm
+func verifySignature(alg string, hash crypto.Hash, key interface{}, signed, sig []byte) bool {
+
+	if alg[0] == 'H' {
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signed)
+		return hmac.Equal(sig, mac.Sum(nil))
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[0] == 'P' {
+			return rsa.VerifyPSS(k, hash, digest, sig, nil) == nil
+		}
+		return alg[0] == 'R' && rsa.VerifyPKCS1v15(k, hash, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if alg[0] != 'E' || len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	}
+
+	return false
+}
+
+// keyMatchesAlg returns true if the key is of the type used by the algorithm, so that a
+// token cannot select an algorithm that treats a public key as a symmetric secret
+func keyMatchesAlg(key interface{}, alg string) bool {
+	if alg == "" {
+		return false
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return alg[0] == 'R' || alg[0] == 'P'
+	case *ecdsa.PublicKey:
+		return alg == ecdsaAlgs[k.Curve.Params().BitSize]
+	case []byte:
+		return alg[0] == 'H'
+	}
+	return false
+}
+
+// bearerToken returns the Bearer token of the request's Authorization header, if any
+func bearerToken(r *http.Request) string {
+	v := r.Header.Get(headers.NameAuthorization)
+	if len(v) > 7 && strings.EqualFold(v[:7], "bearer ") {
+		return strings.TrimSpace(v[7:])
+	}
+	return ""
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func unixTime(secs float64) time.Time {
+	return time.Unix(0, int64(secs*float64(time.Second)))
+}
@@ -0,0 +1,209 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+var testRSAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+var testECKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+var testSecret = []byte("test-secret")
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// testJWKS returns a JSON Web Key Set of the test keys
+func testJWKS() []byte {
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(testRSAKey.N.Bytes()),
+			"e": b64(big.NewInt(int64(testRSAKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(testECKey.X.Bytes()), "y": b64(testECKey.Y.Bytes())},
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": b64(testSecret)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "invalid", "e": "invalid"},
+	}}
+	b, _ := json.Marshal(set)
+	return b
+}
+
+// signToken returns a token with the provided claims, signed with the test key of the algorithm
+func signToken(alg, kid string, claims map[string]interface{}) string {
+
+	h, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	c, _ := json.Marshal(claims)
+	signed := b64(h) + "." + b64(c)
+
+	hash := algHashes[alg]
+	var sig []byte
+	switch alg[0] {
+	case 'H':
+		mac := hmac.New(hash.New, testSecret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case 'R', 'P', 'E':
+		d := hash.New()
+		d.Write([]byte(signed))
+		digest := d.Sum(nil)
+		switch alg[0] {
+		case 'R':
+			sig, _ = rsa.SignPKCS1v15(rand.Reader, testRSAKey, hash, digest)
+		case 'P':
+			sig, _ = rsa.SignPSS(rand.Reader, testRSAKey, hash, digest, nil)
+		default:
+			r, s, _ := ecdsa.Sign(rand.Reader, testECKey, digest)
+			sig = make([]byte, 64)
+			rb, sb := r.Bytes(), s.Bytes()
+			copy(sig[32-len(rb):32], rb)
+			copy(sig[64-len(sb):], sb)
+		}
+	}
+
+	return signed + "." + b64(sig)
+}
+
+func writeTestJWKS(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "trickster-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "jwks.json")
+	if err = ioutil.WriteFile(path, testJWKS(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestJWTVerifier(t *testing.T) {
+
+	path, cleanup := writeTestJWKS(t)
+	defer cleanup()
+
+	v, err := newJWTVerifier("test", &config.AuthenticatorConfig{JWKSPath: path, JWKSRefreshSecs: 3600,
+		Issuer: "test-issuer", Audience: "test-audience", IdentityClaim: "sub", LeewaySecs: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Unix(1577836800, 0)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"sub": "user", "iss": "test-issuer", "aud": []string{"other", "test-audience"},
+			"exp": ts.Unix() + 60, "nbf": ts.Unix() - 60}
+	}
+	with := func(k string, val interface{}) map[string]interface{} {
+		c := valid()
+		if val == nil {
+			delete(c, k)
+		} else {
+			c[k] = val
+		}
+		return c
+	}
+
+	tests := []struct {
+		token    string
+		expected bool
+	}{
+		{signToken("RS256", "rsa", valid()), true},
+		{signToken("RS512", "rsa", valid()), true},
+		{signToken("PS256", "rsa", valid()), true},
+		{signToken("ES256", "ec", valid()), true},
+		{signToken("HS256", "hmac", valid()), true},
+		// tokens without a key id are verified with each key of the algorithm's type
+		{signToken("RS256", "", valid()), true},
+		{signToken("ES256", "", valid()), true},
+		{signToken("RS256", "ec", valid()), false},
+		{signToken("RS256", "unknown", valid()), false},
+		// the HMAC key only signs with its declared algorithm
+		{signToken("HS512", "hmac", valid()), false},
+		{signToken("none", "", valid()), false},
+		{signToken("RS256", "rsa", with("exp", ts.Unix()-30)), true},
+		{signToken("RS256", "rsa", with("exp", ts.Unix()-61)), false},
+		{signToken("RS256", "rsa", with("exp", "invalid")), false},
+		{signToken("RS256", "rsa", with("nbf", ts.Unix()+61)), false},
+		{signToken("RS256", "rsa", with("iss", "other")), false},
+		{signToken("RS256", "rsa", with("aud", "test-audience")), true},
+		{signToken("RS256", "rsa", with("aud", "other")), false},
+		{signToken("RS256", "rsa", with("aud", nil)), false},
+		{signToken("RS256", "rsa", with("sub", nil)), false},
+		{signToken("RS256", "rsa", valid()) + "x", false},
+		{"invalid", false},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+		r.Header.Set("Authorization", "Bearer "+test.token)
+		id, err := v.verify(r)
+		if (err == nil) != test.expected {
+			t.Errorf("test %d: expected %t got %v", i, test.expected, err)
+			continue
+		}
+		if err == nil && (id.Name != "user" || id.Claims["iss"] != "test-issuer") {
+			t.Errorf("test %d: unexpected identity %v", i, id)
+		}
+	}
+
+	// a signature can't be moved to a token with different claims
+	parts := strings.Split(signToken("RS256", "rsa", valid()), ".")
+	parts[1] = strings.Split(signToken("RS256", "rsa", with("sub", "admin")), ".")[1]
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.Header.Set("Authorization", "Bearer "+strings.Join(parts, "."))
+	if _, err := v.verify(r); err == nil {
+		t.Error("expected error for tampered claims")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	if _, err := v.verify(r); err != ErrMissingCredentials {
+		t.Errorf("expected %v got %v", ErrMissingCredentials, err)
+	}
+	r.Header.Set("Authorization", "Basic dGVzdDp0ZXN0")
+	if _, err := v.verify(r); err != ErrMissingCredentials {
+		t.Errorf("expected %v got %v", ErrMissingCredentials, err)
+	}
+}
+
+func TestVerifySignatureKeyConfusion(t *testing.T) {
+	// a token signed with HS256 using the RSA public key as the secret is not accepted
+	secret := testRSAKey.N.Bytes()
+	mac := hmac.New(crypto.SHA256.New, secret)
+	mac.Write([]byte("test"))
+	if verifySignature("HS256", crypto.SHA256, &testRSAKey.PublicKey, []byte("test"), mac.Sum(nil)) {
+		t.Error("expected signature to fail verification")
+	}
+	if keyMatchesAlg(&testRSAKey.PublicKey, "HS256") || keyMatchesAlg(testSecret, "RS256") ||
+		keyMatchesAlg(&testECKey.PublicKey, "ES384") {
+		t.Error("expected key not to match algorithm")
+	}
+}
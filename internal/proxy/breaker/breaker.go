@@ -0,0 +1,218 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package breaker provides the circuit breakers that stop Trickster from making
+// requests to origins that are failing or slow, so that they can recover
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+// ErrOpen indicates a request was not made because the origin's circuit is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed indicates requests are made to the origin
+	StateClosed = State(iota)
+	// StateHalfOpen indicates a single request is permitted to test whether the origin has recovered
+	StateHalfOpen
+	// StateOpen indicates requests fail fast without being made to the origin
+	StateOpen
+)
+
+var stateNames = map[State]string{
+	StateClosed:   "closed",
+	StateHalfOpen: "half-open",
+	StateOpen:     "open",
+}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// Result is the result of a request permitted by a circuit breaker
+type Result int
+
+const (
+	// ResultSuccess indicates the origin responded successfully
+	ResultSuccess = Result(iota)
+	// ResultFailure indicates the request failed or the origin was too slow to respond
+	ResultFailure
+	// ResultCanceled indicates the request was canceled by Trickster, and is not counted
+	ResultCanceled
+)
+
+// numBuckets is the number of buckets over which a window of requests is counted
+const numBuckets = 10
+
+// now is the source of the current time used by the circuit breakers, which can be replaced in tests
+var now = time.Now
+
+// Breaker is a circuit breaker for an origin, which opens when the ratio of failed requests in its window
+// exceeds the configured ratio. After the circuit has been open for the configured duration, a single
+// request is permitted, which closes the circuit if it succeeds, or otherwise reopens it
+type Breaker struct {
+	// Name is the name of the origin protected by the Breaker
+	Name string
+
+	cfg        *config.CircuitBreakerConfig
+	bucketSize time.Duration
+
+	mtx      sync.Mutex
+	state    State
+	openedAt time.Time
+	probing  bool
+	buckets  [numBuckets]bucket
+}
+
+// bucket counts the requests and failures in a slice of the window
+type bucket struct {
+	index    int64
+	requests int
+	failures int
+}
+
+var breakers = make(map[string]*Breaker)
+var breakersMtx sync.Mutex
+
+// New returns a new, closed Breaker for the named origin with the provided configuration,
+// which must have been verified by the config loader, and registers it for the origin
+func New(originName string, cfg *config.CircuitBreakerConfig) *Breaker {
+
+	bs := cfg.Window / numBuckets
+	if bs <= 0 {
+		bs = time.Second
+	}
+	b := &Breaker{Name: originName, cfg: cfg, bucketSize: bs}
+	metrics.ProxyCircuitBreakerState.WithLabelValues(originName).Set(float64(StateClosed))
+
+	breakersMtx.Lock()
+	breakers[originName] = b
+	breakersMtx.Unlock()
+
+	return b
+}
+
+// Get returns the Breaker registered for the named origin, or nil if it does not have one
+func Get(originName string) *Breaker {
+	breakersMtx.Lock()
+	defer breakersMtx.Unlock()
+	return breakers[originName]
+}
+
+// State returns the current state of the Breaker. An open Breaker whose open duration
+// has elapsed is half-open, until its test request completes
+func (b *Breaker) State() State {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.state == StateOpen && !now().Before(b.openedAt.Add(b.cfg.OpenDuration)) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow returns ErrOpen if a request must fail fast without being made to the origin. Otherwise, the
+// request must report its Result to Record, and probe indicates that it is testing a half-open circuit
+func (b *Breaker) Allow() (probe bool, err error) {
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return false, nil
+	case StateOpen:
+		if now().Before(b.openedAt.Add(b.cfg.OpenDuration)) {
+			return false, ErrOpen
+		}
+		b.setState(StateHalfOpen)
+	}
+
+	// only one request tests a half-open circuit at once
+	if b.probing {
+		return false, ErrOpen
+	}
+	b.probing = true
+	return true, nil
+}
+
+// Record records the Result of a request permitted by Allow
+func (b *Breaker) Record(probe bool, result Result) {
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if probe {
+		b.probing = false
+		switch result {
+		case ResultSuccess:
+			b.buckets = [numBuckets]bucket{}
+			b.setState(StateClosed)
+		case ResultFailure:
+			b.open()
+		}
+		return
+	}
+
+	if result == ResultCanceled || b.state != StateClosed {
+		return
+	}
+
+	t := now()
+	index := t.UnixNano() / int64(b.bucketSize)
+	bk := &b.buckets[index%numBuckets]
+	if bk.index != index {
+		*bk = bucket{index: index}
+	}
+	bk.requests++
+	if result == ResultFailure {
+		bk.failures++
+	}
+
+	var requests, failures int
+	for _, v := range b.buckets {
+		if v.index > index-numBuckets {
+			requests += v.requests
+			failures += v.failures
+		}
+	}
+	if requests >= b.cfg.MinRequests && float64(failures)/float64(requests) >= b.cfg.FailureRatio {
+		b.open()
+	}
+}
+
+// open opens the circuit. The caller must hold the lock
+func (b *Breaker) open() {
+	b.openedAt = now()
+	b.setState(StateOpen)
+}
+
+// setState sets the state of the circuit. The caller must hold the lock
+func (b *Breaker) setState(s State) {
+	if s == b.state {
+		return
+	}
+	log.Info("circuit breaker state changed", log.Pairs{"originName": b.Name, "from": b.state.String(), "to": s.String()})
+	b.state = s
+	metrics.ProxyCircuitBreakerState.WithLabelValues(b.Name).Set(float64(s))
+}
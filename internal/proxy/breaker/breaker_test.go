@@ -0,0 +1,158 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+func testConfig() *config.CircuitBreakerConfig {
+	return &config.CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       10 * time.Second,
+		OpenDuration: 30 * time.Second,
+	}
+}
+
+func setNow(t time.Time) {
+	now = func() time.Time { return t }
+}
+
+func TestBreakerOpens(t *testing.T) {
+
+	defer func() { now = time.Now }()
+	start := time.Unix(1577836800, 0)
+	setNow(start)
+
+	b := New("test-opens", testConfig())
+	if Get("test-opens") != b {
+		t.Error("expected the breaker to be registered for its origin")
+	}
+
+	// failures below the minimum number of requests do not open the circuit
+	for i := 0; i < 3; i++ {
+		probe, err := b.Allow()
+		if err != nil || probe {
+			t.Fatalf("expected a permitted request, got %v %t", err, probe)
+		}
+		b.Record(probe, ResultFailure)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("expected %s got %s", StateClosed, b.State())
+	}
+
+	// canceled requests are not counted
+	b.Record(false, ResultCanceled)
+	if b.State() != StateClosed {
+		t.Errorf("expected %s got %s", StateClosed, b.State())
+	}
+
+	b.Record(false, ResultSuccess)
+	if b.State() != StateOpen {
+		t.Errorf("expected %s got %s", StateOpen, b.State())
+	}
+	if _, err := b.Allow(); err != ErrOpen {
+		t.Errorf("expected %v got %v", ErrOpen, err)
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+
+	defer func() { now = time.Now }()
+	start := time.Unix(1577836800, 0)
+	setNow(start)
+
+	b := New("test-window", testConfig())
+	for i := 0; i < 3; i++ {
+		b.Record(false, ResultFailure)
+	}
+
+	// failures that have left the window are no longer counted
+	setNow(start.Add(11 * time.Second))
+	for i := 0; i < 3; i++ {
+		b.Record(false, ResultSuccess)
+	}
+	b.Record(false, ResultFailure)
+	if b.State() != StateClosed {
+		t.Errorf("expected %s got %s", StateClosed, b.State())
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+
+	defer func() { now = time.Now }()
+	start := time.Unix(1577836800, 0)
+	setNow(start)
+
+	b := New("test-half-open", testConfig())
+	for i := 0; i < 4; i++ {
+		b.Record(false, ResultFailure)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("expected %s got %s", StateOpen, b.State())
+	}
+
+	setNow(start.Add(30 * time.Second))
+	if b.State() != StateHalfOpen {
+		t.Errorf("expected %s got %s", StateHalfOpen, b.State())
+	}
+
+	// a single request tests the origin
+	probe, err := b.Allow()
+	if err != nil || !probe {
+		t.Fatalf("expected a probe, got %v %t", err, probe)
+	}
+	if _, err := b.Allow(); err != ErrOpen {
+		t.Errorf("expected %v got %v", ErrOpen, err)
+	}
+
+	// a failed probe reopens the circuit
+	b.Record(probe, ResultFailure)
+	if b.State() != StateOpen {
+		t.Errorf("expected %s got %s", StateOpen, b.State())
+	}
+
+	// a canceled probe permits another
+	setNow(start.Add(60 * time.Second))
+	probe, _ = b.Allow()
+	b.Record(probe, ResultCanceled)
+	probe, err = b.Allow()
+	if err != nil || !probe {
+		t.Fatalf("expected a probe, got %v %t", err, probe)
+	}
+
+	// and a successful probe closes it
+	b.Record(probe, ResultSuccess)
+	if b.State() != StateClosed {
+		t.Errorf("expected %s got %s", StateClosed, b.State())
+	}
+	if probe, err := b.Allow(); err != nil || probe {
+		t.Errorf("expected a permitted request, got %v %t", err, probe)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	if StateHalfOpen.String() != "half-open" {
+		t.Errorf("expected %s got %s", "half-open", StateHalfOpen.String())
+	}
+}
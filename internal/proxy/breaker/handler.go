@@ -0,0 +1,31 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package breaker
+
+import (
+	"net/http"
+
+	"github.com/Comcast/trickster/internal/proxy/headers"
+)
+
+// StateHandler returns an HTTP Handler that reports the state of the named origin's circuit
+// breaker in a response header, if it has one, and passes each request to next
+func StateHandler(originName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b := Get(originName); b != nil {
+			w.Header().Set(headers.NameCircuitBreaker, b.State().String())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
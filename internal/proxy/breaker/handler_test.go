@@ -0,0 +1,40 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package breaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/proxy/headers"
+)
+
+func TestStateHandler(t *testing.T) {
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	StateHandler("test-handler-none", next).ServeHTTP(w, httptest.NewRequest("GET", "http://0/", nil))
+	if _, ok := w.Header()[headers.NameCircuitBreaker]; ok {
+		t.Errorf("unexpected header %s", headers.NameCircuitBreaker)
+	}
+
+	New("test-handler", testConfig())
+	w = httptest.NewRecorder()
+	StateHandler("test-handler", next).ServeHTTP(w, httptest.NewRequest("GET", "http://0/", nil))
+	if v := w.Header().Get(headers.NameCircuitBreaker); v != "closed" {
+		t.Errorf("expected %s got %s", "closed", v)
+	}
+}
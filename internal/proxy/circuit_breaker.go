@@ -0,0 +1,62 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Comcast/trickster/internal/proxy/breaker"
+)
+
+// circuitBreakerTransport is an http.RoundTripper that fails fast with breaker.ErrOpen while
+// the origin's circuit is open, and records the result of each request it makes to the origin.
+// A request fails if it errors, if the origin responds with a 5xx status code, or if the
+// response takes longer than the latency threshold
+type circuitBreakerTransport struct {
+	next             http.RoundTripper
+	breaker          *breaker.Breaker
+	latencyThreshold time.Duration
+}
+
+func newCircuitBreakerTransport(next http.RoundTripper, b *breaker.Breaker,
+	latencyThreshold time.Duration) *circuitBreakerTransport {
+	return &circuitBreakerTransport{next: next, breaker: b, latencyThreshold: latencyThreshold}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *circuitBreakerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	probe, err := t.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(r)
+
+	result := breaker.ResultSuccess
+	switch {
+	case err != nil && r.Context().Err() == context.Canceled:
+		// requests abandoned by the client or by a hedged request do not indicate the origin's health
+		result = breaker.ResultCanceled
+	case err != nil, resp.StatusCode >= http.StatusInternalServerError,
+		t.latencyThreshold > 0 && time.Since(start) > t.latencyThreshold:
+		result = breaker.ResultFailure
+	}
+	t.breaker.Record(probe, result)
+
+	return resp, err
+}
@@ -0,0 +1,72 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
+)
+
+func TestCircuitBreakerTransport(t *testing.T) {
+
+	b := breaker.New("test-cb-transport", &config.CircuitBreakerConfig{FailureRatio: 0.5, MinRequests: 2,
+		Window: time.Minute, OpenDuration: time.Minute})
+
+	// canceled requests are not counted as failures
+	rt := &sequenceRoundTripper{statuses: []int{http.StatusOK}, delays: []time.Duration{time.Second, time.Second}}
+	tr := newCircuitBreakerTransport(rt, b, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil).WithContext(ctx))
+	tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil).WithContext(ctx))
+	if b.State() != breaker.StateClosed {
+		t.Errorf("expected %s got %s", breaker.StateClosed, b.State())
+	}
+
+	rt = &sequenceRoundTripper{statuses: []int{http.StatusOK, http.StatusBadGateway}}
+	tr = newCircuitBreakerTransport(rt, b, 0)
+	tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if b.State() != breaker.StateOpen {
+		t.Errorf("expected %s got %s", breaker.StateOpen, b.State())
+	}
+
+	if _, err := tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil)); err != breaker.ErrOpen {
+		t.Errorf("expected %v got %v", breaker.ErrOpen, err)
+	}
+	if rt.count() != 2 {
+		t.Errorf("expected %d got %d", 2, rt.count())
+	}
+}
+
+func TestCircuitBreakerTransportLatency(t *testing.T) {
+
+	b := breaker.New("test-cb-latency", &config.CircuitBreakerConfig{FailureRatio: 1, MinRequests: 1,
+		Window: time.Minute, OpenDuration: time.Minute})
+
+	rt := &sequenceRoundTripper{statuses: []int{http.StatusOK}, delays: []time.Duration{20 * time.Millisecond}}
+	tr := newCircuitBreakerTransport(rt, b, 10*time.Millisecond)
+	if _, err := tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != breaker.StateOpen {
+		t.Errorf("expected %s got %s", breaker.StateOpen, b.State())
+	}
+}
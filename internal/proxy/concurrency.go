@@ -0,0 +1,85 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// concurrencyLimitedTransport is an http.RoundTripper that permits a maximum number of
+// requests in flight at once. Further requests wait for a request in flight to complete,
+// or until their context is done. A request is complete once its response body has been
+// read in full or closed, or its context is done
+type concurrencyLimitedTransport struct {
+	next http.RoundTripper
+	sem  chan struct{}
+}
+
+func newConcurrencyLimitedTransport(next http.RoundTripper, max int) *concurrencyLimitedTransport {
+	return &concurrencyLimitedTransport{next: next, sem: make(chan struct{}, max)}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *concurrencyLimitedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	select {
+	case t.sem <- struct{}{}:
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+
+	var once sync.Once
+	release := func() { once.Do(func() { <-t.sem }) }
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+
+	// the response body may never be read in full or closed if the client goes away
+	if done := r.Context().Done(); done != nil {
+		go func() {
+			<-done
+			release()
+		}()
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody is a response body that releases its request's slot in flight when it is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Read implements io.Reader
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.release()
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
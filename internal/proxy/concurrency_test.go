@@ -0,0 +1,95 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+type testRoundTripper struct{}
+
+func (testRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("test"))}, nil
+}
+
+func TestConcurrencyLimitedTransport(t *testing.T) {
+
+	tr := newConcurrencyLimitedTransport(testRoundTripper{}, 1)
+
+	r := httptest.NewRequest("GET", "http://0/", nil)
+	resp, err := tr.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second request must wait until the first response's body is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = tr.RoundTrip(r.WithContext(ctx))
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, err)
+	}
+
+	resp.Body.Close()
+	resp, err = tr.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reading the body in full also completes the request
+	b, _ := ioutil.ReadAll(resp.Body)
+	if string(b) != "test" {
+		t.Errorf("expected %s got %s", "test", string(b))
+	}
+	resp, err = tr.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// as does the end of the request's context
+	ctx, cancel = context.WithCancel(context.Background())
+	resp.Body.Close()
+	_, err = tr.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+	defer cancel2()
+	_, err = tr.RoundTrip(r.WithContext(ctx2))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewHTTPClientUpstreamConcurrency(t *testing.T) {
+	oc := config.NewOriginConfig()
+	oc.MaxUpstreamConcurrency = 2
+	c, err := NewHTTPClient(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr, ok := c.Transport.(*concurrencyLimitedTransport); !ok || cap(tr.sem) != 2 {
+		t.Errorf("expected a transport limited to %d requests", 2)
+	}
+}
@@ -0,0 +1,39 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+)
+
+// authIdentity is the identity of a request verified by a named Authenticator
+type authIdentity struct {
+	authenticator string
+	identity      string
+}
+
+// WithAuthIdentity returns a copy of the provided context that includes the identity
+// of the request, as verified by the named Authenticator
+func WithAuthIdentity(ctx context.Context, authenticator, identity string) context.Context {
+	return context.WithValue(ctx, authKey, authIdentity{authenticator: authenticator, identity: identity})
+}
+
+// AuthIdentity returns the name of the Authenticator that verified the request and the
+// identity it verified, or empty strings if the request has not been authenticated
+func AuthIdentity(ctx context.Context) (string, string) {
+	if v, ok := ctx.Value(authKey).(authIdentity); ok {
+		return v.authenticator, v.identity
+	}
+	return "", ""
+}
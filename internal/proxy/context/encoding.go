@@ -0,0 +1,33 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+)
+
+// WithContentEncoding returns a copy of the provided context that indicates the response
+// to the request is compressed in the content encoding negotiated with the client
+func WithContentEncoding(ctx context.Context, encoding string) context.Context {
+	return context.WithValue(ctx, contentEncodingKey, encoding)
+}
+
+// ContentEncoding returns the content encoding negotiated with the client, or an empty string
+// if the response is not compressed
+func ContentEncoding(ctx context.Context) string {
+	if v, ok := ctx.Value(contentEncodingKey).(string); ok {
+		return v
+	}
+	return ""
+}
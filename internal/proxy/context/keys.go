@@ -17,4 +17,8 @@ type contextKey int
 
 const (
 	resourcesKey contextKey = iota
+	ruleRouteKey
+	limitsKey
+	authKey
+	contentEncodingKey
 )
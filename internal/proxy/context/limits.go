@@ -0,0 +1,32 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+)
+
+// WithAppliedLimits returns a copy of the provided context that indicates the named Limits
+// have been applied to the request, so that they are not applied again
+func WithAppliedLimits(ctx context.Context, names map[string]bool) context.Context {
+	return context.WithValue(ctx, limitsKey, names)
+}
+
+// AppliedLimits returns the names of the Limits that have been applied to the request, if any
+func AppliedLimits(ctx context.Context) map[string]bool {
+	if v, ok := ctx.Value(limitsKey).(map[string]bool); ok {
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+)
+
+// WithRuleRoute returns a copy of the provided context that indicates the request was dispatched
+// by a Rule to the named origin, so that it is not dispatched again
+func WithRuleRoute(ctx context.Context, originName string) context.Context {
+	return context.WithValue(ctx, ruleRouteKey, originName)
+}
+
+// RuleRoute returns the name of the origin to which a Rule dispatched the request, if any
+func RuleRoute(ctx context.Context) string {
+	if v, ok := ctx.Value(ruleRouteKey).(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package encoding negotiates the content encoding of responses with clients, and compresses
+// response bodies in the negotiated encoding
+package encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Brotli is the content encoding of brotli-compressed bodies
+	Brotli = "br"
+	// Zstd is the content encoding of Zstandard-compressed bodies
+	Zstd = "zstd"
+	// Gzip is the content encoding of gzip-compressed bodies
+	Gzip = "gzip"
+)
+
+// encoder returns a WriteCloser that writes the compressed form of its input to w. Closing the
+// WriteCloser writes any remaining compressed data, but does not close w
+type encoder func(w io.Writer) (io.WriteCloser, error)
+
+var encoders = map[string]encoder{
+	Brotli: func(w io.Writer) (io.WriteCloser, error) {
+		return brotli.NewWriter(w), nil
+	},
+	Zstd: func(w io.Writer) (io.WriteCloser, error) {
+		// each response is compressed by a single goroutine, rather than one for each CPU
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	},
+	Gzip: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+}
+
+// NewWriter returns a WriteCloser that compresses its input, in the named content encoding, to w
+func NewWriter(encoding string, w io.Writer) (io.WriteCloser, error) {
+	e, ok := encoders[encoding]
+	if !ok {
+		return nil, fmt.Errorf("unsupported content encoding [%s]", encoding)
+	}
+	return e(w)
+}
+
+// Encode returns the body compressed in the named content encoding
+func Encode(encoding string, body []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(encoding, buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(body); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Compressible returns true if the response with the provided headers, whose body is size bytes,
+// is compressed under the compression config. A size less than 0 indicates the size is unknown.
+// A response that is already encoded, or that must not be transformed, is never compressed.
+func Compressible(cc *config.CompressionConfig, h http.Header, size int) bool {
+
+	if cc == nil || len(cc.EncodingList) == 0 || (size >= 0 && size < cc.MinSizeBytes) {
+		return false
+	}
+
+	if ce := h.Get(headers.NameContentEncoding); ce != "" && ce != "identity" {
+		return false
+	}
+
+	for _, v := range h[headers.NameCacheControl] {
+		if strings.Contains(strings.ToLower(v), headers.ValueNoTransform) {
+			return false
+		}
+	}
+
+	mt, _, err := mime.ParseMediaType(h.Get(headers.NameContentType))
+	if err != nil {
+		return false
+	}
+	return cc.ContentTypes[mt]
+}
+
+// AddVary adds Accept-Encoding to the Vary header, if it is not already listed, so that caches
+// downstream of Trickster store a variant of the response for each content encoding
+func AddVary(h http.Header) {
+	for _, v := range h[headers.NameVary] {
+		for _, n := range strings.Split(v, ",") {
+			if n = strings.TrimSpace(n); n == "*" || strings.EqualFold(n, headers.NameAcceptEncoding) {
+				return
+			}
+		}
+	}
+	h.Add(headers.NameVary, headers.NameAcceptEncoding)
+}
+
+// WeakenETag marks a strong ETag as weak, since a compressed body is not byte-for-byte
+// identical to the uncompressed body that the ETag was assigned to
+func WeakenETag(h http.Header) {
+	if etag := h.Get(headers.NameETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set(headers.NameETag, "W/"+etag)
+	}
+}
@@ -0,0 +1,171 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func testConfig() *config.CompressionConfig {
+	return &config.CompressionConfig{
+		EncodingList: []string{Brotli, Zstd, Gzip},
+		MinSizeBytes: 16,
+		ContentTypes: map[string]bool{"application/json": true, "text/plain": true},
+	}
+}
+
+// decode returns the body decompressed from the content encoding
+func decode(t *testing.T, encoding string, body []byte) string {
+	var r io.Reader
+	var err error
+	switch encoding {
+	case Brotli:
+		r = brotli.NewReader(bytes.NewReader(body))
+	case Zstd:
+		var d *zstd.Decoder
+		d, err = zstd.NewReader(bytes.NewReader(body))
+		if err == nil {
+			defer d.Close()
+		}
+		r = d
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	default:
+		return string(body)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestEncode(t *testing.T) {
+
+	body := strings.Repeat("trickster ", 100)
+
+	for _, enc := range []string{Brotli, Zstd, Gzip} {
+		b, err := Encode(enc, []byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) >= len(body) {
+			t.Errorf("expected %s to compress the body", enc)
+		}
+		if s := decode(t, enc, b); s != body {
+			t.Errorf("expected %s to decode to the body", enc)
+		}
+	}
+
+	if _, err := Encode("deflate", []byte(body)); err == nil {
+		t.Errorf("expected error for unsupported encoding")
+	}
+}
+
+func TestCompressible(t *testing.T) {
+
+	cc := testConfig()
+
+	tests := []struct {
+		h        http.Header
+		size     int
+		expected bool
+	}{
+		{http.Header{"Content-Type": {"application/json"}}, 100, true},
+		{http.Header{"Content-Type": {"text/plain; charset=utf-8"}}, -1, true},
+		{http.Header{"Content-Type": {"text/plain"}}, 15, false},
+		{http.Header{"Content-Type": {"image/png"}}, 100, false},
+		{http.Header{}, 100, false},
+		{http.Header{"Content-Type": {"text/plain"}, "Content-Encoding": {"gzip"}}, 100, false},
+		{http.Header{"Content-Type": {"text/plain"}, "Content-Encoding": {"identity"}}, 100, true},
+		{http.Header{"Content-Type": {"text/plain"}, "Cache-Control": {"max-age=60, No-Transform"}}, 100, false},
+	}
+
+	for i, test := range tests {
+		if v := Compressible(cc, test.h, test.size); v != test.expected {
+			t.Errorf("test %d expected %t got %t", i, test.expected, v)
+		}
+	}
+
+	if Compressible(nil, tests[0].h, 100) {
+		t.Errorf("expected nothing to be compressible without a config")
+	}
+
+	cc.EncodingList = nil
+	if Compressible(cc, tests[0].h, 100) {
+		t.Errorf("expected nothing to be compressible without encodings")
+	}
+}
+
+func TestAddVary(t *testing.T) {
+
+	tests := []struct {
+		vary     []string
+		expected string
+	}{
+		{nil, "Accept-Encoding"},
+		{[]string{"Accept"}, "Accept,Accept-Encoding"},
+		{[]string{"Accept, accept-encoding"}, "Accept, accept-encoding"},
+		{[]string{"*"}, "*"},
+	}
+
+	for i, test := range tests {
+		h := http.Header{}
+		for _, v := range test.vary {
+			h.Add("Vary", v)
+		}
+		AddVary(h)
+		if v := strings.Join(h["Vary"], ","); v != test.expected {
+			t.Errorf("test %d expected %s got %s", i, test.expected, v)
+		}
+	}
+}
+
+func TestWeakenETag(t *testing.T) {
+
+	tests := []struct {
+		etag     string
+		expected string
+	}{
+		{"", ""},
+		{`"abc"`, `W/"abc"`},
+		{`W/"abc"`, `W/"abc"`},
+	}
+
+	for i, test := range tests {
+		h := http.Header{}
+		if test.etag != "" {
+			h.Set(headers.NameETag, test.etag)
+		}
+		WeakenETag(h)
+		if v := h.Get(headers.NameETag); v != test.expected {
+			t.Errorf("test %d expected %s got %s", i, test.expected, v)
+		}
+	}
+}
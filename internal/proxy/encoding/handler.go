@@ -0,0 +1,181 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package encoding
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Comcast/trickster/internal/config"
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+)
+
+// Handler returns a handler that compresses the responses of the next handler in the content
+// encoding negotiated with each client, when they are compressible under the compression config.
+// The negotiated encoding is recorded in the request context, so that the Object Proxy Cache can
+// respond with a cached encoding of its object, which is passed through as already encoded.
+func Handler(cc *config.CompressionConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if cc == nil || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		enc := Negotiate(r.Header[headers.NameAcceptEncoding], cc.EncodingList)
+		if enc == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, config: cc, encoding: enc}
+		next.ServeHTTP(cw, r.WithContext(tctx.WithContentEncoding(r.Context(), enc)))
+		cw.Close()
+	})
+}
+
+// compressWriter is an http.ResponseWriter that compresses the response body. Until the body is
+// known to reach the minimum size, it is buffered, so that a smaller body is written as-is
+type compressWriter struct {
+	http.ResponseWriter
+	config   *config.CompressionConfig
+	encoding string
+	status   int
+	buf      []byte
+	// encoder is set once the body is being compressed
+	encoder io.WriteCloser
+	// passthrough indicates that the body is written as-is
+	passthrough bool
+}
+
+// WriteHeader determines whether the response is compressible from its status code and headers.
+// The header of a compressible response is written once the body is known to be large enough
+func (cw *compressWriter) WriteHeader(code int) {
+
+	if cw.status != 0 {
+		return
+	}
+	cw.status = code
+
+	h := cw.Header()
+	size := -1
+	if cl, err := strconv.Atoi(h.Get(headers.NameContentLength)); err == nil {
+		size = cl
+	}
+
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusPartialContent ||
+		code == http.StatusNotModified || h.Get(headers.NameContentRange) != "" ||
+		!Compressible(cw.config, h, size) {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if size >= 0 {
+		cw.startEncoding()
+	}
+}
+
+// startEncoding writes the header of the compressed response, and any buffered body to the encoder
+func (cw *compressWriter) startEncoding() error {
+
+	e, err := NewWriter(cw.encoding, cw.ResponseWriter)
+	if err != nil {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(cw.status)
+		_, err = cw.ResponseWriter.Write(cw.buf)
+		cw.buf = nil
+		return err
+	}
+	cw.encoder = e
+
+	h := cw.Header()
+	h.Set(headers.NameContentEncoding, cw.encoding)
+	h.Del(headers.NameContentLength)
+	AddVary(h)
+	WeakenETag(h)
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) > 0 {
+		_, err = e.Write(cw.buf)
+		cw.buf = nil
+	}
+	return err
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(b)
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.config.MinSizeBytes {
+		if err := cw.startEncoding(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes the body written so far to the client. A response that is flushed is being
+// streamed, so its body is compressed regardless of its size
+func (cw *compressWriter) Flush() {
+
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if !cw.passthrough && cw.encoder == nil {
+		cw.startEncoding()
+	}
+
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes the remainder of the body. A body that is still buffered never reached
+// the minimum size, and is written as-is
+func (cw *compressWriter) Close() error {
+
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+
+	if cw.passthrough || cw.status == 0 {
+		return nil
+	}
+
+	cw.passthrough = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) > 0 {
+		_, err := cw.ResponseWriter.Write(cw.buf)
+		cw.buf = nil
+		return err
+	}
+	return nil
+}
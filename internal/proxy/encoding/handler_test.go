@@ -0,0 +1,183 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package encoding
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+)
+
+var testBody = strings.Repeat(`{"status":"success"}`, 10)
+
+// serve responds to a request, with the provided Accept-Encoding, from the compression
+// handler, whose next handler writes the body with the provided headers
+func serve(t *testing.T, method, accept string, h map[string]string, code int, body string) *http.Response {
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range h {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(code)
+		// the body is written in parts, so that it's buffered until it reaches the minimum size
+		for i := 0; i < len(body); i += 8 {
+			end := i + 8
+			if end > len(body) {
+				end = len(body)
+			}
+			w.Write([]byte(body[i:end]))
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, "http://0/", nil)
+	if accept != "" {
+		r.Header.Set("Accept-Encoding", accept)
+	}
+	Handler(testConfig(), next).ServeHTTP(w, r)
+	return w.Result()
+}
+
+func TestHandler(t *testing.T) {
+
+	json := map[string]string{"Content-Type": "application/json"}
+
+	tests := []struct {
+		method   string
+		accept   string
+		h        map[string]string
+		code     int
+		body     string
+		encoding string
+	}{
+		{http.MethodGet, "gzip, deflate, br", json, http.StatusOK, testBody, Brotli},
+		{http.MethodGet, "zstd", json, http.StatusOK, testBody, Zstd},
+		{http.MethodGet, "gzip", json, http.StatusNotFound, testBody, Gzip},
+		{http.MethodGet, "gzip", map[string]string{"Content-Type": "application/json",
+			"Content-Length": strconv.Itoa(len(testBody))}, http.StatusOK, testBody, Gzip},
+		{http.MethodGet, "gzip", map[string]string{"Content-Type": "application/json",
+			"Etag": `"abc"`}, http.StatusOK, testBody, Gzip},
+		// a response that is not compressed
+		{http.MethodGet, "", json, http.StatusOK, testBody, ""},
+		{http.MethodGet, "deflate", json, http.StatusOK, testBody, ""},
+		{http.MethodHead, "gzip", json, http.StatusOK, "", ""},
+		{http.MethodGet, "gzip", json, http.StatusOK, "{}", ""},
+		{http.MethodGet, "gzip", map[string]string{"Content-Type": "application/json",
+			"Content-Length": "2"}, http.StatusOK, "{}", ""},
+		{http.MethodGet, "gzip", map[string]string{"Content-Type": "image/png"}, http.StatusOK, testBody, ""},
+		{http.MethodGet, "gzip", json, http.StatusNoContent, "", ""},
+		{http.MethodGet, "gzip", map[string]string{"Content-Type": "application/json",
+			"Content-Range": "bytes 0-199/400"}, http.StatusPartialContent, testBody, ""},
+	}
+
+	for i, test := range tests {
+		resp := serve(t, test.method, test.accept, test.h, test.code, test.body)
+		if resp.StatusCode != test.code {
+			t.Errorf("test %d expected %d got %d", i, test.code, resp.StatusCode)
+		}
+		if ce := resp.Header.Get("Content-Encoding"); ce != test.encoding {
+			t.Errorf("test %d expected encoding %s got %s", i, test.encoding, ce)
+		}
+		if test.encoding != "" {
+			if v := resp.Header.Get("Vary"); v != "Accept-Encoding" {
+				t.Errorf("test %d expected Vary %s got %s", i, "Accept-Encoding", v)
+			}
+			if v := resp.Header.Get("Content-Length"); v != "" {
+				t.Errorf("test %d expected no Content-Length got %s", i, v)
+			}
+			if v, ok := test.h["Etag"]; ok && resp.Header.Get("Etag") != "W/"+v {
+				t.Errorf("test %d expected Etag %s got %s", i, "W/"+v, resp.Header.Get("Etag"))
+			}
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		if s := decode(t, test.encoding, b); s != test.body {
+			t.Errorf("test %d expected body %s got %s", i, test.body, s)
+		}
+	}
+}
+
+func TestHandlerPassesThroughEncodedBody(t *testing.T) {
+
+	encoded, err := Encode(Gzip, []byte(testBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := serve(t, http.MethodGet, "br, gzip", map[string]string{"Content-Type": "application/json",
+		"Content-Encoding": "gzip"}, http.StatusOK, string(encoded))
+
+	if ce := resp.Header.Get("Content-Encoding"); ce != Gzip {
+		t.Errorf("expected encoding %s got %s", Gzip, ce)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	if s := decode(t, Gzip, b); s != testBody {
+		t.Errorf("expected the encoded body to be passed through")
+	}
+}
+
+func TestHandlerContentEncoding(t *testing.T) {
+
+	var enc string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc = tctx.ContentEncoding(r.Context())
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	Handler(testConfig(), next).ServeHTTP(httptest.NewRecorder(), r)
+
+	if enc != Gzip {
+		t.Errorf("expected %s got %s", Gzip, enc)
+	}
+
+	// without a config, the handler passes requests through
+	enc = ""
+	Handler(nil, next).ServeHTTP(httptest.NewRecorder(), r)
+	if enc != "" {
+		t.Errorf("expected no encoding got %s", enc)
+	}
+}
+
+func TestHandlerFlush(t *testing.T) {
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("a"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("b"))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	Handler(testConfig(), next).ServeHTTP(w, r)
+
+	// a flushed response is compressed, although it is smaller than the minimum size
+	resp := w.Result()
+	if !w.Flushed {
+		t.Errorf("expected the response to be flushed")
+	}
+	if ce := resp.Header.Get("Content-Encoding"); ce != Gzip {
+		t.Errorf("expected encoding %s got %s", Gzip, ce)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	if s := decode(t, Gzip, b); s != "ab" {
+		t.Errorf("expected %s got %s", "ab", s)
+	}
+}
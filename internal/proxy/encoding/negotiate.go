@@ -0,0 +1,83 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package encoding
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the content encoding, of those offered, that the client's Accept-Encoding
+// header values accept with the highest quality. Of encodings accepted with equal quality, the one
+// offered first is returned. It returns an empty string if the client accepts none of the offered
+// encodings, in which case the response is not compressed.
+func Negotiate(acceptEncoding []string, offered []string) string {
+
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, v := range acceptEncoding {
+		for _, part := range strings.Split(v, ",") {
+			name, q := parseCoding(part)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				wildcard = q
+				continue
+			}
+			qualities[name] = q
+		}
+	}
+
+	var best string
+	var bestQ float64
+	for _, e := range offered {
+		q, ok := qualities[e]
+		if !ok {
+			// an encoding not listed by the client is accepted only by a wildcard
+			q = wildcard
+		}
+		if q > bestQ {
+			best = e
+			bestQ = q
+		}
+	}
+
+	return best
+}
+
+// parseCoding returns the lower-case name of the content coding in an element of an
+// Accept-Encoding header, and its quality, which is 1 unless specified by a q parameter
+func parseCoding(s string) (string, float64) {
+
+	params := strings.Split(s, ";")
+	name := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+
+	for _, p := range params[1:] {
+		p = strings.TrimSpace(p)
+		if len(p) < 2 || (p[0] != 'q' && p[0] != 'Q') || p[1] != '=' {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(p[2:]), 64)
+		if err != nil || f < 0 || f > 1 {
+			// a malformed quality is treated as not acceptable
+			f = 0
+		}
+		q = f
+	}
+
+	return name, q
+}
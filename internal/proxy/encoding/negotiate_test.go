@@ -0,0 +1,53 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package encoding
+
+import (
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+
+	offered := []string{Brotli, Zstd, Gzip}
+
+	tests := []struct {
+		accept   []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{""}, ""},
+		{[]string{"identity"}, ""},
+		{[]string{"deflate"}, ""},
+		{[]string{"gzip"}, Gzip},
+		{[]string{"gzip, deflate, br"}, Brotli},
+		{[]string{"GZIP", "ZSTD"}, Zstd},
+		{[]string{"br;q=0.5, gzip;q=0.8"}, Gzip},
+		{[]string{"br;q=0, gzip"}, Gzip},
+		{[]string{"*"}, Brotli},
+		{[]string{"br;q=0, *;q=0.5"}, Zstd},
+		{[]string{"*;q=0"}, ""},
+		{[]string{"gzip;q=bad"}, ""},
+		{[]string{"gzip; level=1"}, Gzip},
+	}
+
+	for i, test := range tests {
+		if v := Negotiate(test.accept, offered); v != test.expected {
+			t.Errorf("test %d expected %s got %s", i, test.expected, v)
+		}
+	}
+
+	if v := Negotiate([]string{"br, gzip"}, []string{Gzip, Brotli}); v != Gzip {
+		t.Errorf("expected the first offered encoding %s got %s", Gzip, v)
+	}
+}
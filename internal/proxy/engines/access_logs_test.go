@@ -14,8 +14,10 @@
 package engines
 
 import (
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Comcast/trickster/internal/config"
@@ -23,8 +25,14 @@ import (
 )
 
 func TestLogUpstreamRequest(t *testing.T) {
-	fileName := "out.log"
-	// it should create a logger that outputs to a log file ("out.test.log")
+	dir, err := ioutil.TempDir("", "trickster-access-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer resetTestLogger()
+	fileName := filepath.Join(dir, "out.log")
+	// it should create a logger that outputs to a log file ("out.log")
 	config.Config = config.NewConfig()
 	config.Main = &config.MainConfig{InstanceID: 0}
 	config.Logging = &config.LoggingConfig{LogFile: fileName, LogLevel: "debug"}
@@ -34,12 +42,17 @@ func TestLogUpstreamRequest(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 	log.Logger.Close()
-	os.Remove(fileName)
 }
 
 func TestLogDownstreamRequest(t *testing.T) {
-	fileName := "out.log"
-	// it should create a logger that outputs to a log file ("out.test.log")
+	dir, err := ioutil.TempDir("", "trickster-access-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer resetTestLogger()
+	fileName := filepath.Join(dir, "out.log")
+	// it should create a logger that outputs to a log file ("out.log")
 	config.Config = config.NewConfig()
 	config.Main = &config.MainConfig{InstanceID: 0}
 	config.Logging = &config.LoggingConfig{LogFile: fileName, LogLevel: "debug"}
@@ -56,5 +69,11 @@ func TestLogDownstreamRequest(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 	log.Logger.Close()
-	os.Remove(fileName)
+}
+
+// resetTestLogger points the logger back at stdout, so that the log file is not
+// reopened by the tests that run after those writing to it
+func resetTestLogger() {
+	config.Logging = config.NewConfig().Logging
+	log.Init()
 }
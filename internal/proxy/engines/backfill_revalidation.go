@@ -0,0 +1,89 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+// backfillRevalidationExtents returns the portions of the cached extents that are due to be refetched from
+// the origin according to the revalidation schedule, based on when each portion was last fetched
+func backfillRevalidationExtents(sched config.BackfillRevalidationSchedule, cached, fetched timeseries.ExtentList,
+	step time.Duration, now time.Time) timeseries.ExtentList {
+
+	var due timeseries.ExtentList
+	var end time.Time // the zero time means the youngest rule's window is unbounded
+	for _, rule := range sched {
+		start := now.Add(-rule.MaxAge).Truncate(step)
+		if start.Before(now.Add(-rule.MaxAge)) {
+			start = start.Add(step)
+		}
+		window := intersectExtents(cached, start, end)
+		end = start.Add(-step)
+		if len(window) == 0 {
+			continue
+		}
+		var fresh timeseries.ExtentList
+		if rule.Interval > 0 {
+			cutoff := now.Add(-rule.Interval)
+			fresh = make(timeseries.ExtentList, 0, len(fetched))
+			for _, f := range fetched {
+				if !f.LastUsed.Before(cutoff) {
+					fresh = append(fresh, f)
+				}
+			}
+		}
+		due = append(due, window.Subtract(fresh, step)...)
+	}
+	return due.Compress(step)
+}
+
+// intersectExtents returns the portions of the ExtentList that fall between start and end, inclusive.
+// A zero end time is treated as unbounded.
+func intersectExtents(el timeseries.ExtentList, start, end time.Time) timeseries.ExtentList {
+	il := make(timeseries.ExtentList, 0, len(el))
+	for _, e := range el {
+		if e.Start.Before(start) {
+			e.Start = start
+		}
+		if !end.IsZero() && e.End.After(end) {
+			e.End = end
+		}
+		if !e.Start.After(e.End) {
+			il = append(il, e)
+		}
+	}
+	return il
+}
+
+// updateFetchedExtents returns the fetched extents updated with the newly-fetched extents, discarding
+// any extents that end before the oldest time subject to backfill revalidation
+func updateFetchedExtents(fetched, el timeseries.ExtentList, step time.Duration,
+	now, oldest time.Time) timeseries.ExtentList {
+	uf := fetched.Subtract(el, step)
+	for _, e := range el {
+		e.LastUsed = now
+		uf = append(uf, e)
+	}
+	kept := make(timeseries.ExtentList, 0, len(uf))
+	for _, e := range uf {
+		if !e.End.Before(oldest) {
+			kept = append(kept, e)
+		}
+	}
+	return kept.Compress(step)
+}
@@ -0,0 +1,163 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+func TestBackfillRevalidationExtents(t *testing.T) {
+
+	step := time.Duration(100) * time.Second
+	now := time.Unix(10000, 0)
+
+	sched := config.BackfillRevalidationSchedule{
+		{MaxAge: time.Duration(500) * time.Second, Interval: 0},
+		{MaxAge: time.Duration(2000) * time.Second, Interval: time.Duration(300) * time.Second},
+	}
+
+	cached := timeseries.ExtentList{{Start: time.Unix(7000, 0), End: time.Unix(10000, 0)}}
+
+	tests := []struct {
+		fetched  timeseries.ExtentList
+		expected string
+	}{
+		{ // 0 - nothing has been fetched recently, so the entire schedule window is due
+			expected: "8000-10000",
+		},
+		{ // 1 - the middle tier was fetched within its interval, so only the youngest tier is due
+			fetched:  timeseries.ExtentList{{Start: time.Unix(7000, 0), End: time.Unix(10000, 0), LastUsed: now.Add(-time.Minute)}},
+			expected: "9500-10000",
+		},
+		{ // 2 - part of the middle tier was fetched outside of its interval
+			fetched: timeseries.ExtentList{
+				{Start: time.Unix(8000, 0), End: time.Unix(8900, 0), LastUsed: now.Add(-time.Hour)},
+				{Start: time.Unix(9000, 0), End: time.Unix(10000, 0), LastUsed: now.Add(-time.Minute)},
+			},
+			expected: "8000-8900;9500-10000",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			el := backfillRevalidationExtents(sched, cached, test.fetched, step, now)
+			if el.String() != test.expected {
+				t.Errorf("expected %s got %s", test.expected, el.String())
+			}
+		})
+	}
+}
+
+func TestIntersectExtents(t *testing.T) {
+
+	el := timeseries.ExtentList{
+		{Start: time.Unix(100, 0), End: time.Unix(300, 0)},
+		{Start: time.Unix(500, 0), End: time.Unix(900, 0)},
+	}
+
+	expected := "200-300;500-600"
+	if v := intersectExtents(el, time.Unix(200, 0), time.Unix(600, 0)).String(); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+
+	expected = "600-900"
+	if v := intersectExtents(el, time.Unix(600, 0), time.Time{}).String(); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+}
+
+func TestUpdateFetchedExtents(t *testing.T) {
+
+	step := time.Duration(100) * time.Second
+	now := time.Unix(10000, 0)
+
+	fetched := timeseries.ExtentList{
+		{Start: time.Unix(1000, 0), End: time.Unix(2000, 0), LastUsed: now.Add(-time.Hour)},
+		{Start: time.Unix(8000, 0), End: time.Unix(9500, 0), LastUsed: now.Add(-time.Hour)},
+	}
+	el := timeseries.ExtentList{{Start: time.Unix(9000, 0), End: time.Unix(10000, 0)}}
+
+	uf := updateFetchedExtents(fetched, el, step, now, time.Unix(5000, 0))
+	expected := "8000-8900;9000-10000"
+	if uf.String() != expected {
+		t.Errorf("expected %s got %s", expected, uf.String())
+		return
+	}
+
+	if !uf[1].LastUsed.Equal(now) {
+		t.Errorf("expected %s got %s", now, uf[1].LastUsed)
+	}
+}
+
+func TestDeltaProxyCacheRequestBackfillRevalidation(t *testing.T) {
+
+	ts, _, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	pc := rsc.PathConfig
+
+	oc.FastForwardDisable = true
+	pc.CacheKeyParams = []string{"query", "step"}
+
+	step := time.Minute
+	end := time.Now().Truncate(step)
+	extr := timeseries.Extent{Start: end.Add(-time.Hour), End: end}
+
+	tests := []struct {
+		sched    config.BackfillRevalidationSchedule
+		expected string
+	}{
+		{config.BackfillRevalidationSchedule{{MaxAge: time.Duration(5) * time.Minute}}, "kmiss"},
+		{config.BackfillRevalidationSchedule{{MaxAge: time.Duration(5) * time.Minute}}, "rhit"}, // refetched on every request
+		{config.BackfillRevalidationSchedule{{MaxAge: time.Duration(5) * time.Minute, Interval: time.Hour}}, "hit"},
+		{nil, "hit"},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+
+			oc.BackfillRevalidation = test.sched
+
+			r.URL.Path = "/api/v1/query_range"
+			r.URL.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(step.Seconds()),
+				extr.Start.Unix(), extr.End.Unix(), queryDownsample)
+
+			w := httptest.NewRecorder()
+			client.QueryRangeHandler(w, r)
+			resp := w.Result()
+
+			err = testStatusCodeMatch(resp.StatusCode, http.StatusOK)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testResultHeaderPartMatch(resp.Header, map[string]string{"status": test.expected})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -28,18 +28,25 @@ import (
 	"github.com/Comcast/trickster/internal/util/log"
 )
 
-// QueryCache queries the cache for an HTTPDocument and returns it
+// QueryCache queries the cache for an HTTPDocument and returns it, even if it has expired
 func QueryCache(c cache.Cache, key string, ranges byterange.Ranges) (*HTTPDocument, status.LookupStatus, byterange.Ranges, error) {
+	return queryCache(c, key, ranges, true)
+}
+
+// queryCache queries the cache for an HTTPDocument and returns it. Expired documents that the
+// cache still holds are returned only when allowExpired is true, as when they will be revalidated
+func queryCache(c cache.Cache, key string, ranges byterange.Ranges,
+	allowExpired bool) (*HTTPDocument, status.LookupStatus, byterange.Ranges, error) {
 
 	d := &HTTPDocument{}
 	var lookupStatus status.LookupStatus
 	var bytes []byte
 	var err error
 
-	if c.Configuration().CacheType == "memory" {
+	if ct := c.Configuration().CacheType; ct == "memory" || ct == "tiered" {
 		mc := c.(cache.MemoryCache)
 		var ifc interface{}
-		ifc, lookupStatus, err = mc.RetrieveReference(key, true)
+		ifc, lookupStatus, err = mc.RetrieveReference(key, allowExpired)
 		// normalize any cache miss errors to cache.ErrKNF. We'll get all of them updated so we can remove this code
 		if err != nil && err != cache.ErrKNF && strings.HasSuffix(err.Error(), "not in cache") {
 			err = cache.ErrKNF
@@ -53,15 +60,24 @@ func QueryCache(c cache.Cache, key string, ranges byterange.Ranges) (*HTTPDocume
 			return d, lookupStatus, nr, err
 		}
 
-		if ifc != nil {
-			d, _ = ifc.(*HTTPDocument)
-		} else {
+		switch v := ifc.(type) {
+		case *HTTPDocument:
+			d = v
+		case []byte:
+			// a tiered cache found the document in its shared tier, so promote it into its memory tier
+			if err = unmarshalCachedDocument(key, v, d); err != nil {
+				return d, status.LookupStatusKeyMiss, ranges, err
+			}
+			if tc, ok := c.(cache.TieredCache); ok {
+				tc.PromoteReference(key, d)
+			}
+		default:
 			return d, status.LookupStatusKeyMiss, ranges, err
 		}
 
 	} else {
 
-		bytes, lookupStatus, err = c.Retrieve(key, true)
+		bytes, lookupStatus, err = c.Retrieve(key, allowExpired)
 		// normalize any cache miss errors to cache.ErrKNF. We'll get all of them updated so we can remove this code
 		if err != nil && err != cache.ErrKNF && strings.HasSuffix(err.Error(), "not in cache") {
 			err = cache.ErrKNF
@@ -76,24 +92,7 @@ func QueryCache(c cache.Cache, key string, ranges byterange.Ranges) (*HTTPDocume
 			return d, lookupStatus, nr, err
 		}
 
-		var inflate bool
-		// check and remove compression bit
-		if len(bytes) > 0 {
-			if bytes[0] == 1 {
-				inflate = true
-			}
-			bytes = bytes[1:]
-		}
-
-		if inflate {
-			log.Debug("decompressing cached data", log.Pairs{"cacheKey": key})
-			b, err := snappy.Decode(nil, bytes)
-			if err == nil {
-				bytes = b
-			}
-		}
-		_, err = d.UnmarshalMsg(bytes)
-		if err != nil {
+		if err = unmarshalCachedDocument(key, bytes, d); err != nil {
 			return d, status.LookupStatusKeyMiss, ranges, err
 		}
 
@@ -123,6 +122,29 @@ func QueryCache(c cache.Cache, key string, ranges byterange.Ranges) (*HTTPDocume
 	return d, lookupStatus, delta, nil
 }
 
+// unmarshalCachedDocument decodes a serialized HTTPDocument, as written to a non-memory cache by WriteCache
+func unmarshalCachedDocument(key string, bytes []byte, d *HTTPDocument) error {
+
+	var inflate bool
+	// check and remove compression bit
+	if len(bytes) > 0 {
+		if bytes[0] == 1 {
+			inflate = true
+		}
+		bytes = bytes[1:]
+	}
+
+	if inflate {
+		log.Debug("decompressing cached data", log.Pairs{"cacheKey": key})
+		b, err := snappy.Decode(nil, bytes)
+		if err == nil {
+			bytes = b
+		}
+	}
+	_, err := d.UnmarshalMsg(bytes)
+	return err
+}
+
 func stripConditionalHeaders(h http.Header) {
 	h.Del(headers.NameIfMatch)
 	h.Del(headers.NameIfUnmodifiedSince)
@@ -180,6 +202,26 @@ func WriteCache(c cache.Cache, key string, d *HTTPDocument, ttl time.Duration, c
 	} else {
 		bytes = append([]byte{0}, bytes...)
 	}
+
+	// for tiered, the memory tier also retains the document by reference. A timeseries document
+	// doesn't need its serialized body there, since the timeseries is retained by reference as well
+	if tc, ok := c.(cache.TieredCache); ok && c.Configuration().CacheType == "tiered" {
+		rd := d
+		if d.timeseries != nil {
+			rd = &HTTPDocument{}
+			*rd = *d
+			rd.Body = nil
+		}
+		rd.rangePartsLoaded = false
+		rd.isFulfillment = false
+		rd.isLoaded = false
+		rd.RangeParts = nil
+		if rd.CachingPolicy != nil {
+			rd.CachingPolicy.ResetClientConditionals()
+		}
+		return tc.StoreTiered(key, rd, bytes, ttl)
+	}
+
 	return c.Store(key, bytes, ttl)
 
 }
@@ -19,13 +19,18 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/encryption"
+	"github.com/Comcast/trickster/internal/cache/memory"
 	cr "github.com/Comcast/trickster/internal/cache/registration"
 	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/cache/tiered"
 	"github.com/Comcast/trickster/internal/config"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/ranges/byterange"
@@ -409,6 +414,148 @@ func TestQueryCache(t *testing.T) {
 
 }
 
+// newTestTieredCache returns a tiered cache fronting a new memory cache with l1
+func newTestTieredCache(l1 cache.MemoryCache) (*tiered.Cache, error) {
+	l2 := &memory.Cache{Name: "l2", Config: config.NewCacheConfig()}
+	if err := l2.Connect(); err != nil {
+		return nil, err
+	}
+	cfg := config.NewCacheConfig()
+	cfg.Name = "tiered"
+	cfg.CacheType = "tiered"
+	cfg.Tiered.L1MaxTTL = time.Minute
+	tc := &tiered.Cache{Name: cfg.Name, Config: cfg, L1: l1, L2: l2}
+	return tc, tc.Connect()
+}
+
+func TestQueryCacheTiered(t *testing.T) {
+
+	expected := "1234"
+
+	err := config.Load("trickster", "test", []string{"-origin-url", "http://1", "-origin-type", "test"})
+	if err != nil {
+		t.Errorf("Could not load configuration: %s", err.Error())
+	}
+
+	cr.LoadCachesFromConfig()
+	c, err := cr.GetCache("default")
+	if err != nil {
+		t.Error(err)
+	}
+
+	tc, err := newTestTieredCache(c.(cache.MemoryCache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &http.Response{}
+	resp.Header = make(http.Header)
+	resp.StatusCode = 200
+	resp.Header.Add(headers.NameContentLength, "4")
+	d := DocumentFromHTTPResponse(resp, []byte(expected), nil)
+	d.ContentType = "text/plain"
+
+	err = WriteCache(tc, "testKey", d, time.Duration(60)*time.Second, map[string]bool{"text/plain": true})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// served by reference from l1
+	d2, _, _, err := QueryCache(tc, "testKey", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if d2 != d {
+		t.Errorf("expected document to be retrieved by reference")
+	}
+
+	// served from l2 and promoted into l1
+	tc.L1.Remove("testKey")
+	d2, _, _, err = QueryCache(tc, "testKey", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if string(d2.Body) != string(expected) {
+		t.Errorf("expected %s got %s", string(expected), string(d2.Body))
+	}
+
+	ifc, _, err := tc.L1.RetrieveReference("testKey", false)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if ifc != d2 {
+		t.Errorf("expected document to be promoted by reference")
+	}
+
+	tc.Remove("testKey")
+	_, _, _, err = QueryCache(tc, "testKey", nil)
+	if err == nil {
+		t.Errorf("expected error")
+	}
+
+}
+
+func TestQueryCacheEncrypted(t *testing.T) {
+
+	expected := strings.Repeat("1234", 64)
+
+	dir, err := ioutil.TempDir("/tmp", "filesystem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.NewCacheConfig()
+	cfg.Name = "encrypted"
+	cfg.CacheType = "filesystem"
+	cfg.Filesystem.CachePath = dir
+	cfg.Encryption = config.CacheEncryptionConfig{KeyID: "test",
+		Keys: map[string][]byte{"test": []byte("0123456789abcdef")}}
+
+	c := cr.NewCache(cfg.Name, cfg)
+	if _, ok := c.(*encryption.Cache); !ok {
+		t.Fatalf("expected encrypted cache")
+	}
+
+	resp := &http.Response{}
+	resp.Header = make(http.Header)
+	resp.StatusCode = 200
+	d := DocumentFromHTTPResponse(resp, []byte(expected), nil)
+	d.ContentType = "text/plain"
+
+	// the document is compressed before it is encrypted
+	err = WriteCache(c, "testKey", d, time.Duration(60)*time.Second, map[string]bool{"text/plain": true})
+	if err != nil {
+		t.Error(err)
+	}
+
+	b, _, err := c.(*encryption.Cache).Cache.Retrieve("testKey", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !encryption.IsEncrypted(b) {
+		t.Errorf("expected encrypted object")
+	}
+
+	if strings.Contains(string(b), "1234") {
+		t.Errorf("expected object not to contain plaintext")
+	}
+
+	d2, _, _, err := QueryCache(c, "testKey", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if string(d2.Body) != expected {
+		t.Errorf("expected %s got %s", expected, string(d2.Body))
+	}
+
+}
+
 // Mock Cache for testing error conditions
 type testCache struct {
 	configuration *config.CachingConfig
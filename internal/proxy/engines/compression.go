@@ -0,0 +1,113 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/encoding"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// encodedBody returns the body of the document compressed in the content encoding negotiated
+// with the client, along with the encoding, if the document is compressible. isNew is true if
+// the body was compressed for the request, rather than retrieved from the document.
+func (pr *proxyRequest) encodedBody(d *HTTPDocument) (b []byte, enc string, isNew bool) {
+
+	enc = tctx.ContentEncoding(pr.Context())
+	if enc == "" || d == nil || d.StatusCode != http.StatusOK || len(d.Ranges) > 0 ||
+		(d.CachingPolicy != nil && d.CachingPolicy.NoTransform) {
+		return nil, "", false
+	}
+
+	// the content type of a document assembled from ranges is retained outside of its headers
+	h := http.Header(d.Headers)
+	if d.ContentType != "" && h.Get(headers.NameContentType) != d.ContentType {
+		h = h.Clone()
+		h.Set(headers.NameContentType, d.ContentType)
+	}
+	if !encoding.Compressible(config.Compression, h, len(d.Body)) {
+		return nil, "", false
+	}
+
+	if b, ok := d.EncodedBodies[enc]; ok {
+		return b, enc, false
+	}
+
+	b, err := encoding.Encode(enc, d.Body)
+	if err != nil {
+		log.Error("unable to compress cached object", log.Pairs{"cacheKey": pr.key, "detail": err.Error()})
+		return nil, "", false
+	}
+	return b, enc, true
+}
+
+// encodeResponseBody replaces the response body, when it is the body of the cached document,
+// with the body compressed in the content encoding negotiated with the client. The compressed
+// body is passed through to the client as one that is already encoded.
+func (pr *proxyRequest) encodeResponseBody() {
+
+	b, enc, isNew := pr.encodedBody(pr.cacheDocument)
+	if b == nil {
+		return
+	}
+
+	// a document that is about to be stored caches its compressed body then
+	if isNew && !pr.writeToCache {
+		pr.cacheEncodedBody(enc, b)
+	}
+
+	resp := pr.upstreamResponse
+	// the response headers of a cache hit are those of the cached document
+	h := resp.Header.Clone()
+	h.Set(headers.NameContentEncoding, enc)
+	encoding.AddVary(h)
+	encoding.WeakenETag(h)
+	resp.Header = h
+
+	pr.responseBody = b
+}
+
+// cacheEncodedBody caches the body of the cached document compressed in the content encoding
+// by replacing the document with a copy that includes it, for the remainder of its TTL
+func (pr *proxyRequest) cacheEncodedBody(enc string, b []byte) {
+
+	d := pr.cacheDocument
+	if d.CachingPolicy == nil {
+		return
+	}
+
+	ttl := pr.cacheTTL(d.CachingPolicy) - time.Since(d.CachingPolicy.LocalDate)
+	if ttl <= 0 {
+		return
+	}
+
+	// the document is copied, rather than updated, since a memory cache holds it by reference
+	nd := *d
+	nd.EncodedBodies = make(map[string][]byte, len(d.EncodedBodies)+1)
+	for k, v := range d.EncodedBodies {
+		nd.EncodedBodies[k] = v
+	}
+	nd.EncodedBodies[enc] = b
+
+	rsc := request.GetResources(pr.Request)
+	if err := WriteCache(rsc.CacheClient, pr.key, &nd, ttl, rsc.OriginConfig.CompressableTypes); err != nil {
+		log.Error("unable to cache compressed object", log.Pairs{"cacheKey": pr.key, "detail": err.Error()})
+	}
+}
@@ -0,0 +1,105 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/encoding"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+)
+
+func TestObjectProxyCacheEncodedBody(t *testing.T) {
+
+	hdrs := map[string]string{headers.NameCacheControl: headers.ValueMaxAge + "=60",
+		headers.NameContentType: "text/plain", headers.NameETag: `"test"`}
+	ts, _, r, rsc, err := setupTestHarnessOPC("", "test", http.StatusOK, hdrs)
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	mb := config.Compression.MinSizeBytes
+	config.Compression.MinSizeBytes = 0
+	defer func() { config.Compression.MinSizeBytes = mb }()
+
+	fetch := func(enc, expectedStatus, expectedEncoding string) []byte {
+		expectedETag := `"test"`
+		if expectedEncoding != "" {
+			expectedETag = `W/"test"`
+		}
+		w := httptest.NewRecorder()
+		ObjectProxyCacheRequest(w, r.WithContext(tctx.WithContentEncoding(r.Context(), enc)))
+		resp := w.Result()
+		if err := testResultHeaderPartMatch(resp.Header, map[string]string{"status": expectedStatus}); err != nil {
+			t.Error(err)
+		}
+		if ce := resp.Header.Get(headers.NameContentEncoding); ce != expectedEncoding {
+			t.Errorf("expected encoding %s got %s", expectedEncoding, ce)
+		}
+		// a compressed body is not identical to the body that the origin's strong ETag identifies
+		if etag := resp.Header.Get(headers.NameETag); etag != expectedETag {
+			t.Errorf("expected etag %s got %s", expectedETag, etag)
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		return b
+	}
+
+	key := rsc.OriginConfig.CacheKeyPrefix + "." + newProxyRequest(r, nil).DeriveCacheKey(nil, "")
+	encodedBodies := func() map[string][]byte {
+		d, _, _, err := QueryCache(rsc.CacheClient, key, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := http.Header(d.Headers).Get(headers.NameContentEncoding); v != "" {
+			t.Errorf("expected the cached headers to be unencoded got %s", v)
+		}
+		return d.EncodedBodies
+	}
+
+	// a body fetched from the origin is streamed as-is, to be compressed by the compression
+	// handler, and is cached along with its encoding in the content encoding negotiated
+	if b := fetch(encoding.Gzip, "kmiss", ""); string(b) != "test" {
+		t.Errorf("expected %s got %s", "test", string(b))
+	}
+	if eb := encodedBodies(); len(eb) != 1 || eb[encoding.Gzip] == nil {
+		t.Errorf("expected gzip encoded body got %v", eb)
+	}
+
+	b := fetch(encoding.Gzip, "hit", encoding.Gzip)
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = ioutil.ReadAll(gr); string(b) != "test" {
+		t.Errorf("expected %s got %s", "test", string(b))
+	}
+
+	// a further encoding is cached when it is first requested
+	fetch(encoding.Brotli, "hit", encoding.Brotli)
+	if eb := encodedBodies(); len(eb) != 2 {
+		t.Errorf("expected %d encoded bodies got %d", 2, len(eb))
+	}
+
+	if b := fetch("", "hit", ""); string(b) != "test" {
+		t.Errorf("expected %s got %s", "test", string(b))
+	}
+}
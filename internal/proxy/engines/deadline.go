@@ -0,0 +1,72 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// upstreamClient returns the HTTP Client used for requests to the origin for the path. The origin's
+// client times out after the origin's timeout, so a copy of it, sharing its transport, is returned
+// for any path with a timeout of its own
+func upstreamClient(oc *config.OriginConfig, pc *config.PathConfig) *http.Client {
+	if pc == nil || pc.Timeout <= 0 || oc.HTTPClient == nil || pc.Timeout == oc.HTTPClient.Timeout {
+		return oc.HTTPClient
+	}
+	c := *oc.HTTPClient
+	c.Timeout = pc.Timeout
+	return &c
+}
+
+// upstreamContext returns a context derived from that of the client's request, whose deadline is
+// the path's timeout (or the origin's). Upstream requests made concurrently on behalf of a single
+// client request share the context, so they are aborted together when the deadline passes or the
+// client goes away
+func upstreamContext(r *http.Request, oc *config.OriginConfig,
+	pc *config.PathConfig) (context.Context, context.CancelFunc) {
+	t := oc.Timeout
+	if pc != nil && pc.Timeout > 0 {
+		t = pc.Timeout
+	}
+	if t <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), t)
+}
+
+// sharedUpstreamContext returns a context for an upstream request that is shared by the clients
+// collapsed onto it. It carries the values of the context of the first client's request, but not
+// its cancelation or deadline, so that the request is not aborted when that client goes away;
+// only the path's timeout (or the origin's) applies
+func sharedUpstreamContext(r *http.Request, oc *config.OriginConfig,
+	pc *config.PathConfig) (context.Context, context.CancelFunc) {
+	return upstreamContext(r.WithContext(detachedContext{r.Context()}), oc, pc)
+}
+
+// detachedContext is a context with the values of its parent, which is never canceled
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,218 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+const queryReturnsOKSlowly = "some_query_here{latency_ms=1000,range_latency_ms=0}"
+
+func TestUpstreamClient(t *testing.T) {
+
+	oc := config.NewOriginConfig()
+	oc.HTTPClient = &http.Client{Timeout: oc.Timeout}
+
+	if c := upstreamClient(oc, nil); c != oc.HTTPClient {
+		t.Errorf("expected the origin's client got %v", c)
+	}
+
+	pc := config.NewPathConfig()
+	if c := upstreamClient(oc, pc); c != oc.HTTPClient {
+		t.Errorf("expected the origin's client got %v", c)
+	}
+
+	pc.Timeout = 5 * time.Second
+	c := upstreamClient(oc, pc)
+	if c == oc.HTTPClient || c.Timeout != pc.Timeout {
+		t.Errorf("expected %s got %s", pc.Timeout, c.Timeout)
+	}
+	if oc.HTTPClient.Timeout != oc.Timeout {
+		t.Errorf("expected %s got %s", oc.Timeout, oc.HTTPClient.Timeout)
+	}
+}
+
+func TestUpstreamContext(t *testing.T) {
+
+	oc := config.NewOriginConfig()
+	pc := config.NewPathConfig()
+	r, _ := http.NewRequest(http.MethodGet, "http://0/", nil)
+
+	ctx, cancel := upstreamContext(r, oc, pc)
+	d, ok := ctx.Deadline()
+	if !ok || time.Until(d) > oc.Timeout {
+		t.Errorf("expected deadline within %s got %v", oc.Timeout, d)
+	}
+	cancel()
+
+	pc.Timeout = 5 * time.Second
+	ctx, cancel = upstreamContext(r, oc, pc)
+	d, ok = ctx.Deadline()
+	if !ok || time.Until(d) > pc.Timeout {
+		t.Errorf("expected deadline within %s got %v", pc.Timeout, d)
+	}
+	cancel()
+
+	oc.Timeout = 0
+	ctx, cancel = upstreamContext(r, oc, nil)
+	if _, ok = ctx.Deadline(); ok {
+		t.Error("expected no deadline")
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, ctx.Err())
+	}
+
+	// the upstream context is canceled with the client's
+	cctx, ccancel := context.WithCancel(context.Background())
+	ctx, cancel = upstreamContext(r.WithContext(cctx), oc, pc)
+	defer cancel()
+	ccancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, ctx.Err())
+	}
+
+	// while a shared upstream context keeps the client's values, but not its cancelation
+	type testKey struct{}
+	cctx, ccancel = context.WithCancel(context.WithValue(context.Background(), testKey{}, "value"))
+	ctx, cancel = sharedUpstreamContext(r.WithContext(cctx), oc, pc)
+	defer cancel()
+	ccancel()
+	if ctx.Err() != nil {
+		t.Errorf("expected no error got %v", ctx.Err())
+	}
+	if v := ctx.Value(testKey{}); v != "value" {
+		t.Errorf("expected %s got %v", "value", v)
+	}
+	if d, ok = ctx.Deadline(); !ok || time.Until(d) > pc.Timeout {
+		t.Errorf("expected deadline within %s got %v", pc.Timeout, d)
+	}
+}
+
+func TestDeltaProxyCacheRequestPathTimeout(t *testing.T) {
+
+	ts, w, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	oc.FastForwardDisable = true
+	rsc.PathConfig.Timeout = 100 * time.Millisecond
+
+	step := time.Duration(300) * time.Second
+	end := time.Now().Add(-time.Duration(12) * time.Hour)
+	extr := timeseries.Extent{Start: end.Add(-time.Duration(18) * time.Hour), End: end}
+
+	u := r.URL
+	u.Path = "/api/v1/query_range"
+	u.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(step.Seconds()), extr.Start.Unix(), extr.End.Unix(), queryReturnsOKSlowly)
+
+	start := time.Now()
+	client.QueryRangeHandler(w, r)
+	if d := time.Since(start); d >= 900*time.Millisecond {
+		t.Errorf("expected the upstream request to time out after %s, took %s", rsc.PathConfig.Timeout, d)
+	}
+}
+
+func TestDeltaProxyCacheRequestClientCanceled(t *testing.T) {
+
+	ts, w, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	oc.FastForwardDisable = false
+
+	step := time.Duration(300) * time.Second
+	now := time.Now()
+	client.fftime = now.Truncate(oc.FastForwardTTL)
+
+	// cache the older part of the range, so that the newer part is fetched alongside the fast forward data
+	extr := timeseries.Extent{Start: now.Add(-time.Duration(12) * time.Hour), End: now.Add(-time.Duration(6) * time.Hour)}
+	u := r.URL
+	u.Path = "/api/v1/query_range"
+	u.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(step.Seconds()), extr.Start.Unix(), extr.End.Unix(), queryReturnsOKSlowly)
+	client.QueryRangeHandler(w, r)
+	err = testResultHeaderPartMatch(w.Result().Header, map[string]string{"status": "kmiss"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	extr.End = now
+	u = r.URL
+	u.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(step.Seconds()), extr.Start.Unix(), extr.End.Unix(), queryReturnsOKSlowly)
+	ctx, cancel := context.WithCancel(r.Context())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	w = httptest.NewRecorder()
+	start := time.Now()
+	client.QueryRangeHandler(w, r.WithContext(ctx))
+	if d := time.Since(start); d >= 900*time.Millisecond {
+		t.Errorf("expected the upstream requests to be aborted with the client's, took %s", d)
+	}
+	err = testResultHeaderPartMatch(w.Result().Header, map[string]string{"status": "phit", "ffstatus": "err"})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestObjectProxyCacheRequestClientCanceled(t *testing.T) {
+
+	hdrs := map[string]string{headers.NameCacheControl: headers.ValueMaxAge + "=60"}
+	ts, _, r, rsc, err := setupTestHarnessOPC("", "test", http.StatusOK, hdrs)
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	// the origin sends the first part of the body, and then stalls until the request is aborted
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.NameCacheControl, headers.ValueMaxAge+"=60")
+		w.Header().Set(headers.NameContentLength, "8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("test"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer origin.Close()
+	r.URL.Host = origin.Listener.Addr().String()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	w := httptest.NewRecorder()
+	ObjectProxyCacheRequest(w, r.WithContext(ctx))
+
+	// the truncated body is not cached
+	key := rsc.OriginConfig.CacheKeyPrefix + "." + newProxyRequest(r, nil).DeriveCacheKey(nil, "")
+	if _, _, _, err = QueryCache(rsc.CacheClient, key, nil); err != cache.ErrKNF {
+		t.Errorf("expected %v got %v", cache.ErrKNF, err)
+	}
+}
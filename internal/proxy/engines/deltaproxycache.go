@@ -14,7 +14,6 @@
 package engines
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -59,6 +58,18 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 
 	var cacheStatus status.LookupStatus
 
+	// rqe retains the extent and step as requested by the client, in case the step is
+	// snapped to the origin's step ladder and the response must be resampled back
+	rqe := trq.Clone()
+	rqe.NormalizeExtent()
+
+	if sc, ok := client.(origins.StepClient); ok &&
+		(len(oc.StepLadder) > 0 || (pc != nil && len(pc.DownsampleMethods) > 0)) {
+		// snap the step to the ladder and normalize its representation, so that requests
+		// at nearby steps share a cache key regardless of how the step was expressed
+		sc.SetStep(r, trq, snapStep(oc.StepLadder, trq.Step))
+	}
+
 	pr := newProxyRequest(r, w)
 	trq.FastForwardDisable = oc.FastForwardDisable || trq.FastForwardDisable
 	trq.NormalizeExtent()
@@ -102,6 +113,7 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 	var cts timeseries.Timeseries
 	var doc *HTTPDocument
 	var elapsed time.Duration
+	var downsampledFrom time.Duration
 
 	coReq := GetRequestCachingPolicy(r.Header)
 	if coReq.NoCache {
@@ -115,24 +127,33 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 			return // fetchTimeseries logs the error
 		}
 	} else {
-		doc, cacheStatus, _, err = QueryCache(cache, key, nil)
+		doc, cacheStatus, _, err = queryCache(cache, key, nil, false)
 		if cacheStatus == status.LookupStatusKeyMiss && err == tc.ErrKNF {
-			cts, doc, elapsed, err = fetchTimeseries(pr, trq, client)
-			if err != nil {
-				recordDPCResult(r, status.LookupStatusProxyError, doc.StatusCode, r.URL.Path, "", elapsed.Seconds(), nil, doc.Headers)
-				Respond(w, doc.StatusCode, doc.Headers, doc.Body)
-				locks.Release(key)
-				return // fetchTimeseries logs the error
+			// a coarser-step request may be partially or fully fulfilled from a finer step in the cache
+			if cts, doc, downsampledFrom = queryDownsampleSource(pr, trq, client); cts != nil {
+				cacheStatus = status.LookupStatusPartialHit
+			} else {
+				cts, doc, elapsed, err = fetchTimeseries(pr, trq, client)
+				if err != nil {
+					recordDPCResult(r, status.LookupStatusProxyError, doc.StatusCode, r.URL.Path, "", elapsed.Seconds(), nil, doc.Headers)
+					Respond(w, doc.StatusCode, doc.Headers, doc.Body)
+					locks.Release(key)
+					return // fetchTimeseries logs the error
+				}
 			}
 		} else {
 			// Load the Cached Timeseries
 			if doc == nil {
 				err = errors.New("empty document body")
 			} else {
-				if cc.CacheType == "memory" {
+				if doc.timeseries != nil {
 					cts = doc.timeseries
 				} else {
-					cts, err = client.UnmarshalTimeseries(doc.Body)
+					cts, err = unmarshalCachedTimeseries(client, doc.Body)
+					if err == nil && cc.CacheType == "tiered" {
+						// retain the decoded timeseries in the document held by the memory tier
+						doc.timeseries = cts
+					}
 				}
 			}
 			if err != nil {
@@ -165,21 +186,50 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 						}
 					}
 				}
+				if oc.TimeseriesEmptyExtentTTL > 0 {
+					expireEmptyExtents(doc, cts, trq.Step, now.Add(-oc.TimeseriesEmptyExtentTTL))
+				}
 				cacheStatus = status.LookupStatusPartialHit
 			}
 		}
 	}
 
+	// fetchedRanges tracks the ranges fetched from the origin, when subject to backfill revalidation
+	var fetchedRanges timeseries.ExtentList
+
+	if cacheStatus == status.LookupStatusKeyMiss || cacheStatus == status.LookupStatusPurge {
+		// the full extent was fetched from the origin, so track any portions of it having no data
+		if oc.TimeseriesEmptyExtentTTL > 0 {
+			doc.EmptyExtents = emptyExtents(cts, trq.Extent, trq.Step, now)
+		}
+		if len(oc.BackfillRevalidation) > 0 {
+			fetchedRanges = timeseries.ExtentList{trq.Extent}
+		}
+	}
+
 	// Find the ranges that we want, but which are not currently cached
 	var missRanges timeseries.ExtentList
 	if cacheStatus == status.LookupStatusPartialHit {
 		missRanges = trq.CalculateDeltas(cts.Extents())
 	}
 
+	// Find the recently-cached ranges that are due to be refetched, in order to correct any backfilled data
+	var revalidateRanges timeseries.ExtentList
+	if cacheStatus == status.LookupStatusPartialHit && len(oc.BackfillRevalidation) > 0 && !trq.IsOffset {
+		if _, ok := cts.(timeseries.Overwriter); ok {
+			cached := timeseries.ExtentList{trq.Extent}.Subtract(missRanges, trq.Step)
+			revalidateRanges = backfillRevalidationExtents(oc.BackfillRevalidation, cached,
+				doc.FetchedExtents, trq.Step, now)
+		}
+	}
+
 	if len(missRanges) == 0 && cacheStatus == status.LookupStatusPartialHit {
 		// on full cache hit, elapsed records the time taken to query the cache and definitively conclude that it is a full cache hit
 		elapsed = time.Since(now)
 		cacheStatus = status.LookupStatusHit
+		if len(revalidateRanges) > 0 {
+			cacheStatus = status.LookupStatusRevalidated
+		}
 	} else if len(missRanges) == 1 && missRanges[0].Start.Equal(trq.Extent.Start) && missRanges[0].End.Equal(trq.Extent.End) {
 		cacheStatus = status.LookupStatusRangeMiss
 	}
@@ -204,19 +254,33 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 	if len(missRanges) > 0 {
 		dpStatus["extentsFetched"] = timeseries.ExtentList(missRanges).String()
 	}
+	if len(revalidateRanges) > 0 {
+		dpStatus["extentsRevalidated"] = revalidateRanges.String()
+	}
+	if downsampledFrom > 0 {
+		dpStatus["downsampledFrom"] = downsampledFrom
+	}
 
 	// maintain a list of timeseries to merge into the main timeseries
 	mts := make([]timeseries.Timeseries, 0, len(missRanges))
 	wg := sync.WaitGroup{}
 	appendLock := sync.Mutex{}
 	uncachedValueCount := 0
-
-	// iterate each time range that the client needs and fetch from the upstream origin
-	for i := range missRanges {
+	var fetchedEmpty timeseries.ExtentList
+	// maintain a list of timeseries to overwrite into the main timeseries, by their extents
+	rvts := make(map[timeseries.Extent]timeseries.Timeseries)
+
+	// iterate each time range that the client needs, followed by each time range due for
+	// revalidation, and fetch from the upstream origin
+	fetchList := make(timeseries.ExtentList, 0, len(missRanges)+len(revalidateRanges))
+	fetchList = append(append(fetchList, missRanges...), revalidateRanges...)
+	// the fetches and the fast forward request share a deadline, and are aborted if the client goes away
+	ctx, cancel := upstreamContext(r, oc, pc)
+	for i := range fetchList {
 		wg.Add(1)
 		// This fetches the gaps from the origin and adds their datasets to the merge list
-		go func(e *timeseries.Extent, rq *proxyRequest) {
-			rq.Request = rq.WithContext(tctx.WithResources(r.Context(), request.NewResources(oc, pc, cc, cache, client)))
+		go func(e *timeseries.Extent, rq *proxyRequest, revalidate bool) {
+			rq.Request = rq.WithContext(tctx.WithResources(ctx, request.NewResources(oc, pc, cc, cache, client)))
 			client.SetExtent(rq.Request, trq, e)
 			body, resp, _ := rq.Fetch()
 			if resp.StatusCode == http.StatusOK && len(body) > 0 {
@@ -229,12 +293,24 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 				uncachedValueCount += nts.ValueCount()
 				nts.SetStep(trq.Step)
 				nts.SetExtents([]timeseries.Extent{*e})
+				var ee timeseries.ExtentList
+				if oc.TimeseriesEmptyExtentTTL > 0 && !revalidate {
+					ee = emptyExtents(nts, *e, trq.Step, now)
+				}
 				appendLock.Lock()
-				mts = append(mts, nts)
+				if revalidate {
+					rvts[*e] = nts
+				} else {
+					mts = append(mts, nts)
+				}
+				fetchedEmpty = append(fetchedEmpty, ee...)
+				if len(oc.BackfillRevalidation) > 0 {
+					fetchedRanges = append(fetchedRanges, *e)
+				}
 				appendLock.Unlock()
 			}
 			wg.Done()
-		}(&missRanges[i], pr.Clone())
+		}(&fetchList[i], pr.Clone(), i >= len(missRanges))
 	}
 
 	var hasFastForwardData bool
@@ -244,7 +320,7 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 		wg.Add(1)
 		rs := request.NewResources(oc, oc.FastForwardPath, cc, cache, client)
 		rs.AlternateCacheTTL = oc.FastForwardTTL
-		req := r.Clone(tctx.WithResources(context.Background(), rs))
+		req := r.Clone(tctx.WithResources(ctx, rs))
 		go func() {
 			// create a new context that uses the fast forward path config instead of the time series path config
 			req.URL = ffURL
@@ -273,6 +349,16 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	wg.Wait()
+	cancel()
+
+	if len(fetchedEmpty) > 0 {
+		doc.EmptyExtents = append(doc.EmptyExtents, fetchedEmpty...).Compress(trq.Step)
+	}
+
+	if len(fetchedRanges) > 0 {
+		doc.FetchedExtents = updateFetchedExtents(doc.FetchedExtents, fetchedRanges, trq.Step, now,
+			now.Add(-oc.BackfillRevalidation.MaxAge()))
+	}
 
 	// Merge the new delta timeseries into the cached timeseries
 	if len(mts) > 0 {
@@ -281,6 +367,20 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 		cts.Merge(true, mts...)
 	}
 
+	// Overwrite the revalidated ranges of the cached timeseries with their refetched data
+	if len(rvts) > 0 {
+		elapsed = time.Since(now)
+		ow := cts.(timeseries.Overwriter)
+		for e, nts := range rvts {
+			result := "unchanged"
+			if changes := ow.Overwrite(nts, timeseries.ExtentList{e}); changes > 0 {
+				result = "corrected"
+				metrics.ProxyBackfillCorrections.WithLabelValues(oc.Name, oc.OriginType, r.URL.Path).Add(float64(changes))
+			}
+			metrics.ProxyBackfillRevalidations.WithLabelValues(oc.Name, oc.OriginType, result, r.URL.Path).Inc()
+		}
+	}
+
 	// cts is the cacheable time series, rts is the user's response timeseries
 	rts := cts.Clone()
 
@@ -298,6 +398,13 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 		metrics.ProxyRequestElements.WithLabelValues(oc.Name, oc.OriginType, "cached", r.URL.Path).Add(float64(cachedValueCount))
 	}
 
+	// when the step was snapped to the ladder, optionally resample the response to the client's step
+	if oc.StepLadderResample && trq.Step != rqe.Step {
+		if rs, ok := rts.(timeseries.Resampler); ok {
+			rts = rs.Resample(rqe.Step, rqe.Extent)
+		}
+	}
+
 	// Merge Fast Forward data if present. This must be done after the Downstream Crop since
 	// the cropped extent was normalized to stepboundaries and would remove fast forward data
 	// If the fast forward data point is older (e.g. cached) than the last datapoint in the returned time series, it will not be merged
@@ -310,7 +417,8 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 	rh := http.Header(doc.Headers).Clone()
 
 	switch cacheStatus {
-	case status.LookupStatusKeyMiss, status.LookupStatusPartialHit, status.LookupStatusRangeMiss:
+	case status.LookupStatusKeyMiss, status.LookupStatusPartialHit, status.LookupStatusRangeMiss,
+		status.LookupStatusRevalidated:
 		wg.Add(1)
 		// Write the newly-merged object back to the cache
 		go func() {
@@ -322,17 +430,21 @@ func DeltaProxyCacheRequest(w http.ResponseWriter, r *http.Request) {
 			default:
 				cts.CropToRange(timeseries.Extent{End: bf.End, Start: OldestRetainedTimestamp})
 			}
+			doc.EmptyExtents = cropEmptyExtents(doc.EmptyExtents, cts.Extents())
 			// Don't cache datasets with empty extents (everything was cropped so there is nothing to cache)
 			if len(cts.Extents()) > 0 {
 				if cc.CacheType == "memory" {
 					doc.timeseries = cts
 				} else {
-					cdata, err := client.MarshalTimeseries(cts)
+					cdata, err := marshalCachedTimeseries(client, cts)
 					if err != nil {
 						locks.Release(key)
 						return
 					}
 					doc.Body = cdata
+					if cc.CacheType == "tiered" {
+						doc.timeseries = cts
+					}
 				}
 				WriteCache(cache, key, doc, oc.TimeseriesTTL, oc.CompressableTypes)
 			}
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Comcast/trickster/internal/cache"
 	cr "github.com/Comcast/trickster/internal/cache/registration"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/request"
@@ -1568,3 +1569,77 @@ func TestDeltaProxyCacheRequestFFTTLBiggerThanStep(t *testing.T) {
 	}
 
 }
+
+func TestDeltaProxyCacheRequestTiered(t *testing.T) {
+
+	ts, _, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	pc := rsc.PathConfig
+
+	oc.FastForwardDisable = true
+	pc.CacheKeyParams = []string{"query", "step"}
+
+	tc, err := newTestTieredCache(rsc.CacheClient.(cache.MemoryCache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsc.CacheClient = tc
+	rsc.CacheConfig = tc.Configuration()
+
+	step := time.Minute
+	end := time.Now().Add(-time.Duration(2) * time.Hour).Truncate(step)
+	extr := timeseries.Extent{Start: end.Add(-time.Hour), End: end}
+
+	r.URL.Path = "/api/v1/query_range"
+	r.URL.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(step.Seconds()),
+		extr.Start.Unix(), extr.End.Unix(), queryReturnsOKNoLatency)
+
+	key := oc.CacheKeyPrefix + "." + (&proxyRequest{Request: r}).DeriveCacheKey(nil, "")
+
+	tests := []struct {
+		evictL1  bool
+		expected string
+	}{
+		{false, "kmiss"},
+		{false, "hit"}, // from l1
+		{true, "hit"},  // from l2, and promoted into l1
+		{false, "hit"}, // from l1
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+
+			if test.evictL1 {
+				tc.L1.Remove(key)
+			}
+
+			w := httptest.NewRecorder()
+			client.QueryRangeHandler(w, r)
+			resp := w.Result()
+
+			err = testStatusCodeMatch(resp.StatusCode, http.StatusOK)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testResultHeaderPartMatch(resp.Header, map[string]string{"status": test.expected})
+			if err != nil {
+				t.Error(err)
+			}
+
+			ifc, _, err := tc.L1.RetrieveReference(key, false)
+			if err != nil {
+				t.Error(err)
+			}
+			if d, ok := ifc.(*HTTPDocument); !ok || d.timeseries == nil {
+				t.Errorf("expected timeseries to be retained by reference in l1")
+			}
+		})
+	}
+}
@@ -44,6 +44,21 @@ type HTTPDocument struct {
 	RangeParts byterange.MultipartByteRanges `msg:"-"`
 	// StoredRangeParts is a version of RangeParts that can be exported to MessagePack
 	StoredRangeParts map[string]*byterange.MultipartByteRange `msg:"range_parts"`
+	// EmptyExtents is the list of timeseries Extents that were queried from the origin but returned no data.
+	// The LastUsed time of each Extent records when it was queried
+	EmptyExtents timeseries.ExtentList `msg:"empty_extents"`
+	// FetchedExtents is the list of recent timeseries Extents subject to backfill revalidation.
+	// The LastUsed time of each Extent records when it was last fetched from the origin
+	FetchedExtents timeseries.ExtentList `msg:"fetched_extents"`
+	// VaryHeaders is set only on the variant index of an object whose responses Vary. It lists the
+	// request headers, named by the Vary header, whose values select the variant of the object
+	VaryHeaders []string `msg:"vary_headers"`
+	// Variants maps the hash of the VaryHeaders values of each of the index's cached variants
+	// to the Unix time at which the variant expires
+	Variants map[string]int64 `msg:"variants"`
+	// EncodedBodies maps the content encodings in which the Body has been compressed for clients
+	// to the compressed Body, so that it is compressed in each encoding only once
+	EncodedBodies map[string][]byte `msg:"encoded_bodies"`
 
 	rangePartsLoaded bool
 	isFulfillment    bool
@@ -52,11 +67,15 @@ type HTTPDocument struct {
 	isCompressed     bool
 }
 
-// Size returns the size of the HTTPDocument's headers, CachingPolicy, RangeParts, Body and timeseries data
+// Size returns the size of the HTTPDocument's headers, CachingPolicy, RangeParts, Body, EncodedBodies
+// and timeseries data
 func (d *HTTPDocument) Size() int {
 	var i int
 	i += len(headers.String(http.Header(d.Headers)))
 	i += len(d.Body)
+	for _, b := range d.EncodedBodies {
+		i += len(b)
+	}
 	if d.RangeParts != nil {
 		for _, p := range d.RangeParts {
 			i += p.Msgsize()
@@ -146,6 +146,87 @@ func (z *HTTPDocument) DecodeMsg(dc *msgp.Reader) (err error) {
 				}
 				z.StoredRangeParts[za0004] = za0005
 			}
+		case "empty_extents":
+			err = z.EmptyExtents.DecodeMsg(dc)
+			if err != nil {
+				return
+			}
+		case "fetched_extents":
+			err = z.FetchedExtents.DecodeMsg(dc)
+			if err != nil {
+				return
+			}
+		case "vary_headers":
+			var zb0005 uint32
+			zb0005, err = dc.ReadArrayHeader()
+			if err != nil {
+				return
+			}
+			if cap(z.VaryHeaders) >= int(zb0005) {
+				z.VaryHeaders = (z.VaryHeaders)[:zb0005]
+			} else {
+				z.VaryHeaders = make([]string, zb0005)
+			}
+			for za0006 := range z.VaryHeaders {
+				z.VaryHeaders[za0006], err = dc.ReadString()
+				if err != nil {
+					return
+				}
+			}
+		case "variants":
+			var zb0006 uint32
+			zb0006, err = dc.ReadMapHeader()
+			if err != nil {
+				return
+			}
+			if z.Variants == nil {
+				z.Variants = make(map[string]int64, zb0006)
+			} else if len(z.Variants) > 0 {
+				for key := range z.Variants {
+					delete(z.Variants, key)
+				}
+			}
+			for zb0006 > 0 {
+				zb0006--
+				var za0007 string
+				var za0008 int64
+				za0007, err = dc.ReadString()
+				if err != nil {
+					return
+				}
+				za0008, err = dc.ReadInt64()
+				if err != nil {
+					return
+				}
+				z.Variants[za0007] = za0008
+			}
+		case "encoded_bodies":
+			var zb0007 uint32
+			zb0007, err = dc.ReadMapHeader()
+			if err != nil {
+				return
+			}
+			if z.EncodedBodies == nil {
+				z.EncodedBodies = make(map[string][]byte, zb0007)
+			} else if len(z.EncodedBodies) > 0 {
+				for key := range z.EncodedBodies {
+					delete(z.EncodedBodies, key)
+				}
+			}
+			for zb0007 > 0 {
+				zb0007--
+				var za0009 string
+				var za0010 []byte
+				za0009, err = dc.ReadString()
+				if err != nil {
+					return
+				}
+				za0010, err = dc.ReadBytes(za0010)
+				if err != nil {
+					return
+				}
+				z.EncodedBodies[za0009] = za0010
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -158,9 +239,9 @@ func (z *HTTPDocument) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *HTTPDocument) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 9
+	// map header, size 14
 	// write "status_code"
-	err = en.Append(0x89, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	err = en.Append(0x8e, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
 	if err != nil {
 		return
 	}
@@ -280,15 +361,86 @@ func (z *HTTPDocument) EncodeMsg(en *msgp.Writer) (err error) {
 			}
 		}
 	}
+	// write "empty_extents"
+	err = en.Append(0xad, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = z.EmptyExtents.EncodeMsg(en)
+	if err != nil {
+		return
+	}
+	// write "fetched_extents"
+	err = en.Append(0xaf, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = z.FetchedExtents.EncodeMsg(en)
+	if err != nil {
+		return
+	}
+	// write "vary_headers"
+	err = en.Append(0xac, 0x76, 0x61, 0x72, 0x79, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.VaryHeaders)))
+	if err != nil {
+		return
+	}
+	for za0006 := range z.VaryHeaders {
+		err = en.WriteString(z.VaryHeaders[za0006])
+		if err != nil {
+			return
+		}
+	}
+	// write "variants"
+	err = en.Append(0xa8, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.Variants)))
+	if err != nil {
+		return
+	}
+	for za0007, za0008 := range z.Variants {
+		err = en.WriteString(za0007)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt64(za0008)
+		if err != nil {
+			return
+		}
+	}
+	// write "encoded_bodies"
+	err = en.Append(0xae, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x5f, 0x62, 0x6f, 0x64, 0x69, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.EncodedBodies)))
+	if err != nil {
+		return
+	}
+	for za0009, za0010 := range z.EncodedBodies {
+		err = en.WriteString(za0009)
+		if err != nil {
+			return
+		}
+		err = en.WriteBytes(za0010)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *HTTPDocument) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 9
+	// map header, size 14
 	// string "status_code"
-	o = append(o, 0x89, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	o = append(o, 0x8e, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
 	o = msgp.AppendInt(o, z.StatusCode)
 	// string "status"
 	o = append(o, 0xa6, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73)
@@ -342,6 +494,38 @@ func (z *HTTPDocument) MarshalMsg(b []byte) (o []byte, err error) {
 			}
 		}
 	}
+	// string "empty_extents"
+	o = append(o, 0xad, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x74, 0x73)
+	o, err = z.EmptyExtents.MarshalMsg(o)
+	if err != nil {
+		return
+	}
+	// string "fetched_extents"
+	o = append(o, 0xaf, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x74, 0x73)
+	o, err = z.FetchedExtents.MarshalMsg(o)
+	if err != nil {
+		return
+	}
+	// string "vary_headers"
+	o = append(o, 0xac, 0x76, 0x61, 0x72, 0x79, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.VaryHeaders)))
+	for za0006 := range z.VaryHeaders {
+		o = msgp.AppendString(o, z.VaryHeaders[za0006])
+	}
+	// string "variants"
+	o = append(o, 0xa8, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73)
+	o = msgp.AppendMapHeader(o, uint32(len(z.Variants)))
+	for za0007, za0008 := range z.Variants {
+		o = msgp.AppendString(o, za0007)
+		o = msgp.AppendInt64(o, za0008)
+	}
+	// string "encoded_bodies"
+	o = append(o, 0xae, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x5f, 0x62, 0x6f, 0x64, 0x69, 0x65, 0x73)
+	o = msgp.AppendMapHeader(o, uint32(len(z.EncodedBodies)))
+	for za0009, za0010 := range z.EncodedBodies {
+		o = msgp.AppendString(o, za0009)
+		o = msgp.AppendBytes(o, za0010)
+	}
 	return
 }
 
@@ -484,6 +668,87 @@ func (z *HTTPDocument) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 				z.StoredRangeParts[za0004] = za0005
 			}
+		case "empty_extents":
+			bts, err = z.EmptyExtents.UnmarshalMsg(bts)
+			if err != nil {
+				return
+			}
+		case "fetched_extents":
+			bts, err = z.FetchedExtents.UnmarshalMsg(bts)
+			if err != nil {
+				return
+			}
+		case "vary_headers":
+			var zb0005 uint32
+			zb0005, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if cap(z.VaryHeaders) >= int(zb0005) {
+				z.VaryHeaders = (z.VaryHeaders)[:zb0005]
+			} else {
+				z.VaryHeaders = make([]string, zb0005)
+			}
+			for za0006 := range z.VaryHeaders {
+				z.VaryHeaders[za0006], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "variants":
+			var zb0006 uint32
+			zb0006, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if z.Variants == nil {
+				z.Variants = make(map[string]int64, zb0006)
+			} else if len(z.Variants) > 0 {
+				for key := range z.Variants {
+					delete(z.Variants, key)
+				}
+			}
+			for zb0006 > 0 {
+				var za0007 string
+				var za0008 int64
+				zb0006--
+				za0007, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					return
+				}
+				za0008, bts, err = msgp.ReadInt64Bytes(bts)
+				if err != nil {
+					return
+				}
+				z.Variants[za0007] = za0008
+			}
+		case "encoded_bodies":
+			var zb0007 uint32
+			zb0007, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if z.EncodedBodies == nil {
+				z.EncodedBodies = make(map[string][]byte, zb0007)
+			} else if len(z.EncodedBodies) > 0 {
+				for key := range z.EncodedBodies {
+					delete(z.EncodedBodies, key)
+				}
+			}
+			for zb0007 > 0 {
+				var za0009 string
+				var za0010 []byte
+				zb0007--
+				za0009, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					return
+				}
+				za0010, bts, err = msgp.ReadBytesBytes(bts, za0010)
+				if err != nil {
+					return
+				}
+				z.EncodedBodies[za0009] = za0010
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -525,5 +790,23 @@ func (z *HTTPDocument) Msgsize() (s int) {
 			}
 		}
 	}
+	s += 14 + z.EmptyExtents.Msgsize() + 16 + z.FetchedExtents.Msgsize() + 13 + msgp.ArrayHeaderSize
+	for za0006 := range z.VaryHeaders {
+		s += msgp.StringPrefixSize + len(z.VaryHeaders[za0006])
+	}
+	s += 9 + msgp.MapHeaderSize
+	if z.Variants != nil {
+		for za0007, za0008 := range z.Variants {
+			_ = za0008
+			s += msgp.StringPrefixSize + len(za0007) + msgp.Int64Size
+		}
+	}
+	s += 15 + msgp.MapHeaderSize
+	if z.EncodedBodies != nil {
+		for za0009, za0010 := range z.EncodedBodies {
+			_ = za0010
+			s += msgp.StringPrefixSize + len(za0009) + msgp.BytesPrefixSize + len(za0010)
+		}
+	}
 	return
 }
@@ -15,12 +15,15 @@ package engines
 
 import (
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	txe "github.com/Comcast/trickster/internal/proxy/errors"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/ranges/byterange"
+	"github.com/Comcast/trickster/internal/timeseries"
 )
 
 func TestDocumentFromHTTPResponse(t *testing.T) {
@@ -44,6 +47,50 @@ func TestDocumentFromHTTPResponse(t *testing.T) {
 
 }
 
+// TestDocumentMarshalRoundTrip ensures the generated msgp code covers every field of the HTTPDocument
+func TestDocumentMarshalRoundTrip(t *testing.T) {
+
+	now := time.Unix(1577836800, 0)
+	extents := timeseries.ExtentList{timeseries.Extent{Start: now, End: now.Add(time.Hour)}}
+
+	d := &HTTPDocument{
+		StatusCode:     200,
+		Headers:        map[string][]string{headers.NameContentType: {"text/plain"}},
+		Body:           []byte("test"),
+		EmptyExtents:   extents,
+		FetchedExtents: extents,
+		VaryHeaders:    []string{headers.NameAcceptEncoding},
+		Variants:       map[string]int64{"gzip": 1},
+		EncodedBodies:  map[string][]byte{"gzip": []byte("encoded")},
+	}
+
+	b, err := d.MarshalMsg(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2 := &HTTPDocument{}
+	if _, err = d2.UnmarshalMsg(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d2.EmptyExtents) != 1 || !d2.EmptyExtents[0].End.Equal(extents[0].End) {
+		t.Errorf("expected empty extents %s got %s", extents, d2.EmptyExtents)
+	}
+	if len(d2.FetchedExtents) != 1 || !d2.FetchedExtents[0].Start.Equal(extents[0].Start) {
+		t.Errorf("expected fetched extents %s got %s", extents, d2.FetchedExtents)
+	}
+	if !reflect.DeepEqual(d2.VaryHeaders, d.VaryHeaders) {
+		t.Errorf("expected %v got %v", d.VaryHeaders, d2.VaryHeaders)
+	}
+	if !reflect.DeepEqual(d2.Variants, d.Variants) {
+		t.Errorf("expected %v got %v", d.Variants, d2.Variants)
+	}
+	if !reflect.DeepEqual(d2.EncodedBodies, d.EncodedBodies) {
+		t.Errorf("expected %v got %v", d.EncodedBodies, d2.EncodedBodies)
+	}
+
+}
+
 func TestCachingPolicyString(t *testing.T) {
 
 	cp := &CachingPolicy{NoTransform: true}
@@ -0,0 +1,143 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/pkg/locks"
+)
+
+// downsampleAggregators maps each DownsampleMethod to its Aggregator. The nil Aggregator
+// for DownsampleMethodSample uses the value found exactly on the coarser step boundary.
+var downsampleAggregators = map[config.DownsampleMethod]timeseries.Aggregator{
+	config.DownsampleMethodSample: nil,
+	config.DownsampleMethodMin:    timeseries.AggregateMin,
+	config.DownsampleMethodMax:    timeseries.AggregateMax,
+	config.DownsampleMethodMean:   timeseries.AggregateMean,
+	config.DownsampleMethodSum:    timeseries.AggregateSum,
+}
+
+// downsampleAggregator returns the Aggregator that the PathConfig's downsample rules assign to
+// the statement, and false if the statement's results may not be derived from a finer step
+func downsampleAggregator(pc *config.PathConfig, dc origins.DownsamplingClient,
+	statement string) (timeseries.Aggregator, bool) {
+
+	if pc == nil || len(pc.DownsampleMethods) == 0 || len(pc.DownsampleSourceSteps) == 0 {
+		return nil, false
+	}
+
+	dm, ok := pc.DownsampleMethods["*"]
+	if fn := dc.OuterFunction(statement); fn != "" {
+		if m, ok2 := pc.DownsampleMethods[fn]; ok2 {
+			dm, ok = m, true
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+
+	agg, ok := downsampleAggregators[dm]
+	return agg, ok
+}
+
+// queryDownsampleSource looks in the cache for the request's timeseries at each of the path's
+// finer source steps that evenly divide the requested step. The first one found is downsampled to
+// the requested step and returned with a copy of its document and the step it was cached at.
+func queryDownsampleSource(pr *proxyRequest, trq *timeseries.TimeRangeQuery,
+	client origins.TimeseriesClient) (timeseries.Timeseries, *HTTPDocument, time.Duration) {
+
+	dc, ok := client.(origins.DownsamplingClient)
+	if !ok {
+		return nil, nil, 0
+	}
+
+	rsc := request.GetResources(pr.Request)
+	oc := rsc.OriginConfig
+	pc := rsc.PathConfig
+	cache := rsc.CacheClient
+
+	agg, ok := downsampleAggregator(pc, dc, trq.Statement)
+	if !ok {
+		return nil, nil, 0
+	}
+
+	r := pr.Request
+	if pr.upstreamRequest != nil {
+		r = pr.upstreamRequest
+	}
+
+	for _, step := range pc.DownsampleSourceSteps {
+
+		if step >= trq.Step || trq.Step%step != 0 {
+			continue
+		}
+
+		strq := trq.Clone()
+		spr := &proxyRequest{Request: r.Clone(r.Context())}
+		dc.SetStep(spr.Request, strq, step)
+		key := oc.CacheKeyPrefix + "." + spr.DeriveCacheKey(strq.TemplateURL, "")
+
+		// the source key is locked while it is read, since a memory cache returns a reference
+		// to the same timeseries that concurrent requests for the source step will merge into
+		locks.Acquire(key)
+		doc, lookupStatus, _, err := queryCache(cache, key, nil, false)
+		if err != nil || lookupStatus != status.LookupStatusHit || doc == nil {
+			locks.Release(key)
+			continue
+		}
+
+		var sts timeseries.Timeseries
+		if doc.timeseries != nil {
+			sts = doc.timeseries
+		} else {
+			sts, err = unmarshalCachedTimeseries(client, doc.Body)
+		}
+		if err != nil || sts == nil || sts.Step() != step {
+			locks.Release(key)
+			continue
+		}
+
+		ds, ok := sts.(timeseries.Downsampler)
+		if !ok {
+			locks.Release(key)
+			return nil, nil, 0
+		}
+
+		dts := ds.Downsample(trq.Step, agg)
+		d := &HTTPDocument{
+			Status:      doc.Status,
+			StatusCode:  doc.StatusCode,
+			Headers:     http.Header(doc.Headers).Clone(),
+			ContentType: doc.ContentType,
+		}
+		locks.Release(key)
+
+		if len(dts.Extents()) == 0 {
+			continue
+		}
+
+		log.Debug("using downsampled timeseries from finer step", log.Pairs{"sourceKey": key, "sourceStep": step, "step": trq.Step})
+		return dts, d, step
+	}
+
+	return nil, nil, 0
+}
@@ -0,0 +1,127 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/Comcast/trickster/pkg/promsim"
+)
+
+const queryDownsample = "some_query_here{latency_ms=0,range_latency_ms=0,series_count=2}"
+
+func TestDownsampleAggregator(t *testing.T) {
+
+	client := &TestClient{}
+
+	pc := config.NewPathConfig()
+	if _, ok := downsampleAggregator(pc, client, "max_over_time(up[5m])"); ok {
+		t.Errorf("expected %t got %t", false, ok)
+	}
+
+	pc.DownsampleSourceSteps = []time.Duration{time.Minute}
+	pc.DownsampleMethods = map[string]config.DownsampleMethod{"max_over_time": config.DownsampleMethodMax}
+
+	if agg, ok := downsampleAggregator(pc, client, "max_over_time(up[5m])"); !ok || agg == nil {
+		t.Errorf("expected %t got %t", true, ok)
+	}
+
+	if _, ok := downsampleAggregator(pc, client, "rate(up[5m])"); ok {
+		t.Errorf("expected %t got %t", false, ok)
+	}
+
+	pc.DownsampleMethods["*"] = config.DownsampleMethodSample
+	if agg, ok := downsampleAggregator(pc, client, "rate(up[5m])"); !ok || agg != nil {
+		t.Errorf("expected %t got %t", true, ok)
+	}
+
+}
+
+func TestDeltaProxyCacheRequestDownsample(t *testing.T) {
+
+	ts, _, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	pc := rsc.PathConfig
+
+	oc.FastForwardDisable = true
+	pc.CacheKeyParams = []string{"query", "step"}
+	pc.DownsampleMethods = map[string]config.DownsampleMethod{"*": config.DownsampleMethodSample}
+	pc.DownsampleSourceSteps = []time.Duration{time.Duration(60) * time.Second}
+
+	fine := time.Duration(60) * time.Second
+	coarse := time.Duration(300) * time.Second
+
+	end := time.Now().Add(-time.Duration(2) * time.Hour).Truncate(coarse)
+	extr := timeseries.Extent{Start: end.Add(-time.Duration(6) * time.Hour), End: end}
+
+	tests := []struct {
+		step     time.Duration
+		extent   timeseries.Extent
+		expected string
+	}{
+		{fine, extr, "kmiss"}, // populates the finer step
+		{coarse, extr, "hit"}, // fully derived from the finer step
+		{coarse, timeseries.Extent{Start: extr.Start.Add(-time.Hour), End: extr.End}, "phit"}, // partially derived
+		{time.Duration(90) * time.Second, extr, "kmiss"},                                      // not a multiple of the finer step
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+
+			expected, _, _ := promsim.GetTimeSeriesData(queryDownsample, test.extent.Start.Truncate(test.step),
+				test.extent.End.Truncate(test.step), test.step)
+
+			r.URL.Path = "/api/v1/query_range"
+			r.URL.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(test.step.Seconds()),
+				test.extent.Start.Unix(), test.extent.End.Unix(), queryDownsample)
+
+			w := httptest.NewRecorder()
+			client.QueryRangeHandler(w, r)
+			resp := w.Result()
+
+			bodyBytes, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testStringMatch(string(bodyBytes), expected)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testStatusCodeMatch(resp.StatusCode, http.StatusOK)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testResultHeaderPartMatch(resp.Header, map[string]string{"status": test.expected})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"time"
+
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+// emptyExtents returns the portions of the queried Extent for which the Timeseries has no data,
+// marked as having been queried at the provided time
+func emptyExtents(ts timeseries.Timeseries, e timeseries.Extent, step time.Duration,
+	now time.Time) timeseries.ExtentList {
+	var el timeseries.ExtentList
+	if ve, ok := ts.(timeseries.ValueExtenter); ok {
+		el = timeseries.ExtentList{e}.Subtract(ve.ValueExtents(step), step)
+	} else if ts.ValueCount() == 0 {
+		// without knowledge of which timestamps have values, only a wholly-empty response is tracked
+		el = timeseries.ExtentList{e}
+	}
+	for i := range el {
+		el[i].LastUsed = now
+	}
+	return el
+}
+
+// expireEmptyExtents removes the document's empty extents that were queried before the cutoff time, and
+// removes them from the Timeseries's extents as well, so they will be queried from the origin again
+func expireEmptyExtents(doc *HTTPDocument, ts timeseries.Timeseries, step time.Duration, cutoff time.Time) {
+	if len(doc.EmptyExtents) == 0 {
+		return
+	}
+	live := make(timeseries.ExtentList, 0, len(doc.EmptyExtents))
+	expired := make(timeseries.ExtentList, 0, len(doc.EmptyExtents))
+	for _, e := range doc.EmptyExtents {
+		if e.LastUsed.Before(cutoff) {
+			expired = append(expired, e)
+			continue
+		}
+		live = append(live, e)
+	}
+	if len(expired) == 0 {
+		return
+	}
+	ts.SetExtents(ts.Extents().Subtract(expired, step))
+	doc.EmptyExtents = live
+}
+
+// cropEmptyExtents returns the empty extents that overlap any of the provided extents, so that
+// empty extents are discarded along with the timeseries data that has been cropped from the cache
+func cropEmptyExtents(el, have timeseries.ExtentList) timeseries.ExtentList {
+	if len(el) == 0 {
+		return el
+	}
+	cropped := make(timeseries.ExtentList, 0, len(el))
+	for _, e := range el {
+		for _, h := range have {
+			if !e.End.Before(h.Start) && !e.Start.After(h.End) {
+				cropped = append(cropped, e)
+				break
+			}
+		}
+	}
+	return cropped
+}
@@ -0,0 +1,173 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/prometheus/common/model"
+)
+
+func TestEmptyExtents(t *testing.T) {
+
+	step := time.Duration(100) * time.Second
+	now := time.Now()
+	e := timeseries.Extent{Start: time.Unix(100, 0), End: time.Unix(1000, 0)}
+
+	// the series starts midway through the queried extent
+	me := &MatrixEnvelope{
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a"},
+					Values: []model.SamplePair{
+						{Timestamp: 600000, Value: 1.5},
+						{Timestamp: 700000, Value: 1.5},
+						{Timestamp: 1000000, Value: 1.5},
+					},
+				},
+			},
+		},
+	}
+
+	el := emptyExtents(me, e, step, now)
+	expected := "100-500;800-900"
+	if el.String() != expected {
+		t.Errorf("expected %s got %s", expected, el.String())
+	}
+	if !el[0].LastUsed.Equal(now) {
+		t.Errorf("expected %s got %s", now, el[0].LastUsed)
+	}
+
+	el = emptyExtents(&MatrixEnvelope{}, e, step, now)
+	expected = "100-1000"
+	if el.String() != expected {
+		t.Errorf("expected %s got %s", expected, el.String())
+	}
+}
+
+func TestExpireEmptyExtents(t *testing.T) {
+
+	step := time.Duration(100) * time.Second
+	now := time.Now()
+
+	me := &MatrixEnvelope{ExtentList: timeseries.ExtentList{{Start: time.Unix(100, 0), End: time.Unix(1000, 0)}}}
+	doc := &HTTPDocument{EmptyExtents: timeseries.ExtentList{
+		{Start: time.Unix(100, 0), End: time.Unix(300, 0), LastUsed: now.Add(-time.Hour)},
+		{Start: time.Unix(800, 0), End: time.Unix(800, 0), LastUsed: now},
+	}}
+
+	expireEmptyExtents(doc, me, step, now.Add(-time.Minute))
+
+	expected := "400-1000"
+	if me.Extents().String() != expected {
+		t.Errorf("expected %s got %s", expected, me.Extents().String())
+	}
+
+	expected = "800-800"
+	if doc.EmptyExtents.String() != expected {
+		t.Errorf("expected %s got %s", expected, doc.EmptyExtents.String())
+	}
+
+	// nothing is expired
+	expireEmptyExtents(doc, me, step, now.Add(-time.Minute))
+	if doc.EmptyExtents.String() != expected {
+		t.Errorf("expected %s got %s", expected, doc.EmptyExtents.String())
+	}
+}
+
+func TestCropEmptyExtents(t *testing.T) {
+
+	el := timeseries.ExtentList{
+		{Start: time.Unix(100, 0), End: time.Unix(300, 0)},
+		{Start: time.Unix(800, 0), End: time.Unix(900, 0)},
+	}
+	have := timeseries.ExtentList{{Start: time.Unix(300, 0), End: time.Unix(600, 0)}}
+
+	expected := "100-300"
+	if v := cropEmptyExtents(el, have).String(); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+
+	if v := cropEmptyExtents(nil, have); len(v) != 0 {
+		t.Errorf("expected %d got %d", 0, len(v))
+	}
+}
+
+func TestDeltaProxyCacheRequestEmptyExtents(t *testing.T) {
+
+	ts, _, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	pc := rsc.PathConfig
+
+	oc.FastForwardDisable = true
+	pc.CacheKeyParams = []string{"query", "step"}
+
+	step := time.Minute
+	end := time.Now().Add(-time.Duration(2) * time.Hour).Truncate(step)
+	extr := timeseries.Extent{Start: end.Add(-time.Hour), End: end}
+
+	// the origin returns no series for these queries
+	query1 := "some_query_here{latency_ms=0,range_latency_ms=0,series_count=0}"
+	query2 := "other_query_here{latency_ms=0,range_latency_ms=0,series_count=0}"
+
+	tests := []struct {
+		query    string
+		ttl      time.Duration
+		expected string
+	}{
+		{query1, time.Hour, "kmiss"},
+		{query1, time.Hour, "hit"},         // the empty extent is considered covered
+		{query1, time.Nanosecond, "rmiss"}, // the empty extent has expired, and is queried again
+		{query2, time.Duration(0), "kmiss"},
+		{query2, time.Nanosecond, "hit"}, // empty extents were not tracked without a ttl, so are never expired
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+
+			oc.TimeseriesEmptyExtentTTL = test.ttl
+
+			r.URL.Path = "/api/v1/query_range"
+			r.URL.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(step.Seconds()),
+				extr.Start.Unix(), extr.End.Unix(), test.query)
+
+			w := httptest.NewRecorder()
+			client.QueryRangeHandler(w, r)
+			resp := w.Result()
+
+			err = testStatusCodeMatch(resp.StatusCode, http.StatusOK)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testResultHeaderPartMatch(resp.Header, map[string]string{"status": test.expected})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ package engines
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 	"math"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/Comcast/trickster/internal/cache/status"
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/params"
 	"github.com/Comcast/trickster/internal/proxy/request"
@@ -64,8 +66,11 @@ func DoProxy(w io.Writer, r *http.Request) *http.Response {
 		key := oc.CacheKeyPrefix + "." + pr.DeriveCacheKey(nil, "")
 		result, ok := Reqs.Load(key)
 		if !ok {
+			// the upstream request is shared by every client collapsed onto it, and so must
+			// not be aborted when the client that made it goes away
+			ctx, cancel := sharedUpstreamContext(r, oc, pc)
 			var contentLength int64
-			reader, resp, contentLength = PrepareFetchReader(r)
+			reader, resp, contentLength = PrepareFetchReader(r.WithContext(ctx))
 			cacheStatusCode = setStatusHeader(resp.StatusCode, resp.Header)
 			writer := PrepareResponseWriter(w, resp.StatusCode, resp.Header)
 			// Check if we know the content length and if it is less than our max object size.
@@ -77,8 +82,11 @@ func DoProxy(w io.Writer, r *http.Request) *http.Response {
 					io.Copy(pcf, reader)
 					pcf.Close()
 					Reqs.Delete(key)
+					cancel()
 				}()
 				pcf.AddClient(writer)
+			} else {
+				cancel()
 			}
 		} else {
 			pcf, _ := result.(ProgressiveCollapseForwarder)
@@ -123,20 +131,27 @@ func PrepareFetchReader(r *http.Request) (io.ReadCloser, *http.Response, int64)
 	headers.RemoveClientHeaders(r.Header)
 
 	if pc != nil {
-		headers.UpdateHeaders(r.Header, pc.RequestHeaders)
+		headers.UpdateHeaders(r.Header, headers.ExpandPathVars(pc.RequestHeaders, rsc.PathVars))
 		params.UpdateParams(r.URL.Query(), pc.RequestParams)
 	}
 
 	r.RequestURI = ""
-	resp, err := oc.HTTPClient.Do(r)
+	resp, err := upstreamClient(oc, pc).Do(r)
 	if err != nil {
-		log.Error("error downloading url", log.Pairs{"url": r.URL.String(), "detail": err.Error()})
+		code := http.StatusBadGateway
+		if errors.Is(err, breaker.ErrOpen) {
+			// the request was not made, as the origin's circuit breaker is open
+			log.Debug("circuit breaker is open", log.Pairs{"url": r.URL.String(), "originName": oc.Name})
+			code = http.StatusServiceUnavailable
+		} else {
+			log.Error("error downloading url", log.Pairs{"url": r.URL.String(), "detail": err.Error()})
+		}
 		// if there is an err and the response is nil, the server could not be reached; make a 502 for the downstream response
 		if resp == nil {
-			resp = &http.Response{StatusCode: http.StatusBadGateway, Request: r, Header: make(http.Header)}
+			resp = &http.Response{StatusCode: code, Request: r, Header: make(http.Header)}
 		}
 		if pc != nil {
-			headers.UpdateHeaders(resp.Header, pc.ResponseHeaders)
+			headers.UpdateHeaders(resp.Header, headers.ExpandPathVars(pc.ResponseHeaders, rsc.PathVars))
 		}
 		return nil, resp, 0
 	}
@@ -172,7 +187,7 @@ func PrepareFetchReader(r *http.Request) (io.ReadCloser, *http.Response, int64)
 	resp.Header.Del(headers.NameContentLength)
 
 	if pc != nil {
-		headers.UpdateHeaders(resp.Header, pc.ResponseHeaders)
+		headers.UpdateHeaders(resp.Header, headers.ExpandPathVars(pc.ResponseHeaders, rsc.PathVars))
 		hasCustomResponseBody = pc.HasCustomResponseBody
 	}
 
@@ -0,0 +1,137 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package engines
+
+import (
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/Comcast/trickster/pkg/locks"
+)
+
+// CachedTimeseriesInfo describes the timeseries held in a Delta Proxy Cache document
+type CachedTimeseriesInfo struct {
+	Step        time.Duration
+	Extents     timeseries.ExtentList
+	SeriesCount int
+	ValueCount  int
+}
+
+// InspectCachedTimeseries retrieves the document cached under key and, if it holds a timeseries
+// that the client is able to decode, returns a description of the timeseries. It returns nil if
+// the object is not a Delta Proxy Cache document, such as one cached by the Object Proxy Cache.
+func InspectCachedTimeseries(c cache.Cache, key string,
+	client origins.TimeseriesClient) (*CachedTimeseriesInfo, error) {
+
+	// the key is locked while it is read, since a memory cache returns a reference
+	// to the same timeseries that concurrent requests for the key will merge into
+	locks.Acquire(key)
+	defer locks.Release(key)
+
+	doc, err := peekCache(c, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := doc.timeseries
+	if ts == nil {
+		if len(doc.Body) == 0 {
+			return nil, nil
+		}
+		if ts, err = unmarshalCachedTimeseries(client, doc.Body); err != nil {
+			return nil, nil
+		}
+	}
+
+	// every timeseries cached by the Delta Proxy Cache has extents, so a body that merely
+	// decodes as a timeseries, such as an instantaneous query result, is not reported as one
+	el := ts.Extents()
+	if len(el) == 0 {
+		return nil, nil
+	}
+
+	return &CachedTimeseriesInfo{
+		Step:        ts.Step(),
+		Extents:     el.Clone(),
+		SeriesCount: ts.SeriesCount(),
+		ValueCount:  ts.ValueCount(),
+	}, nil
+}
+
+// CachedVariantsInfo describes the variant index of an Object Proxy Cache object whose responses Vary
+type CachedVariantsInfo struct {
+	VaryHeaders  []string
+	VariantCount int
+}
+
+// InspectCachedVariants retrieves the document cached under key and, if it is the index of the
+// variants of an object, returns the headers the object varies on and the number of its variants
+// that have not expired. It returns nil if the object is not a variant index.
+func InspectCachedVariants(c cache.Cache, key string) (*CachedVariantsInfo, error) {
+
+	locks.Acquire(key)
+	defer locks.Release(key)
+
+	doc, err := peekCache(c, key)
+	if err != nil {
+		return nil, err
+	}
+	if !doc.isVariantIndex() {
+		return nil, nil
+	}
+
+	return &CachedVariantsInfo{
+		VaryHeaders:  append([]string(nil), doc.VaryHeaders...),
+		VariantCount: doc.liveVariants(time.Now()),
+	}, nil
+}
+
+// peekCache returns the document cached under key for inspection, without the side effects of
+// a lookup, so that inspecting an object does not count as an access to it when its cache
+// decides what to evict. Caches unable to peek are queried as usual.
+func peekCache(c cache.Cache, key string) (*HTTPDocument, error) {
+
+	var ifc interface{}
+	var lookupStatus status.LookupStatus
+	var err error
+
+	if rp, ok := c.(cache.ReferencePeeker); ok {
+		ifc, lookupStatus, err = rp.PeekReference(key)
+	} else if p, ok := c.(cache.ObjectPeeker); ok {
+		ifc, lookupStatus, err = p.Peek(key)
+	} else {
+		var doc *HTTPDocument
+		doc, lookupStatus, _, err = QueryCache(c, key, nil)
+		ifc = doc
+	}
+	if err != nil || lookupStatus != status.LookupStatusHit {
+		return nil, cache.ErrKNF
+	}
+
+	switch v := ifc.(type) {
+	case *HTTPDocument:
+		if v != nil {
+			return v, nil
+		}
+	case []byte:
+		d := &HTTPDocument{}
+		if err = unmarshalCachedDocument(key, v, d); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+	return nil, cache.ErrKNF
+}
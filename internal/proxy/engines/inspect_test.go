@@ -0,0 +1,145 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package engines
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+func TestInspectCachedTimeseries(t *testing.T) {
+
+	mc := &memory.Cache{Name: "test", Config: config.NewCacheConfig()}
+	if err := mc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &TestClient{}
+	ts := testCodecTimeseries()
+	body, _ := tc.MarshalTimeseries(ts)
+
+	store := func(key string, body []byte, ts timeseries.Timeseries) {
+		resp := &http.Response{StatusCode: 200, Header: make(http.Header)}
+		d := DocumentFromHTTPResponse(resp, body, nil)
+		d.timeseries = ts
+		if err := WriteCache(mc, key, d, time.Minute, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store("reference", nil, ts)
+	store("body", body, nil)
+	store("object", []byte("object body"), nil)
+	store("empty", nil, nil)
+	store("no-extents", []byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`), nil)
+
+	accessed := lastAccessTimes(mc)
+
+	for _, key := range []string{"reference", "body"} {
+		info, err := InspectCachedTimeseries(mc, key, tc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info == nil {
+			t.Fatalf("expected timeseries info for %s", key)
+		}
+		if info.Step != time.Minute {
+			t.Errorf("expected %s got %s", time.Minute, info.Step)
+		}
+		if info.Extents.String() != ts.Extents().String() {
+			t.Errorf("expected %s got %s", ts.Extents().String(), info.Extents.String())
+		}
+		if info.SeriesCount != 0 || info.ValueCount != 0 {
+			t.Errorf("expected empty timeseries got %d series, %d values", info.SeriesCount, info.ValueCount)
+		}
+	}
+
+	for _, key := range []string{"object", "empty", "no-extents"} {
+		info, err := InspectCachedTimeseries(mc, key, tc)
+		if err != nil {
+			t.Error(err)
+		}
+		if info != nil {
+			t.Errorf("expected no timeseries info for %s", key)
+		}
+	}
+
+	_, err := InspectCachedTimeseries(mc, "missing", tc)
+	if err == nil {
+		t.Errorf("expected error for missing key")
+	}
+
+	// inspecting the objects does not count as accessing them
+	for k, v := range lastAccessTimes(mc) {
+		if !v.Equal(accessed[k]) {
+			t.Errorf("expected %s to be last accessed at %s got %s", k, accessed[k], v)
+		}
+	}
+}
+
+func lastAccessTimes(mc *memory.Cache) map[string]time.Time {
+	m := make(map[string]time.Time)
+	for _, o := range mc.Index.ListObjects() {
+		m[o.Key] = o.LastAccess
+	}
+	return m
+}
+
+func TestInspectCachedVariants(t *testing.T) {
+
+	mc := &memory.Cache{Name: "test", Config: config.NewCacheConfig()}
+	if err := mc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &http.Response{StatusCode: 200, Header: make(http.Header)}
+	if err := WriteCache(mc, "object", DocumentFromHTTPResponse(resp, []byte("body"), nil), time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	idx := &HTTPDocument{VaryHeaders: []string{"Accept"}, Variants: map[string]int64{
+		"a": now.Add(time.Minute).Unix(), "b": now.Add(time.Minute).Unix(), "c": now.Add(-time.Minute).Unix()}}
+	if err := WriteCache(mc, "index", idx, time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := InspectCachedVariants(mc, "index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil {
+		t.Fatal("expected variants info")
+	}
+	if info.VariantCount != 2 {
+		t.Errorf("expected %d got %d", 2, info.VariantCount)
+	}
+
+	info, err = InspectCachedVariants(mc, "object")
+	if err != nil {
+		t.Error(err)
+	}
+	if info != nil {
+		t.Errorf("expected no variants info")
+	}
+
+	_, err = InspectCachedVariants(mc, "missing")
+	if err == nil {
+		t.Errorf("expected error for missing key")
+	}
+}
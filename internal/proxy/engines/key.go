@@ -62,7 +62,11 @@ func (pr *proxyRequest) DeriveCacheKey(templateURL *url.URL, extra string) strin
 
 	vals := make([]string, 0, (len(pc.CacheKeyParams) + len(pc.CacheKeyHeaders) + len(pc.CacheKeyFormFields)*2))
 
-	if v := pr.Header.Get(headers.NameAuthorization); v != "" {
+	// the identity verified by an Authenticator is used in place of the client's credentials,
+	// which may differ between requests, or be stripped or replaced before proxying
+	if rsc.AuthIdentity != "" {
+		vals = append(vals, fmt.Sprintf("%s.%s.", "identity", rsc.AuthIdentity))
+	} else if v := pr.Header.Get(headers.NameAuthorization); v != "" {
 		vals = append(vals, fmt.Sprintf("%s.%s.", headers.NameAuthorization, v))
 	}
 
@@ -75,6 +79,13 @@ func (pr *proxyRequest) DeriveCacheKey(templateURL *url.URL, extra string) strin
 		}
 	} else {
 		for _, p := range pc.CacheKeyParams {
+			// {name} references the value of a variable captured from the path
+			if len(p) > 2 && p[0] == '{' && p[len(p)-1] == '}' {
+				if v := rsc.PathVars[p[1:len(p)-1]]; v != "" {
+					vals = append(vals, fmt.Sprintf("%s.%s.", p, v))
+				}
+				continue
+			}
 			if v := params.Get(p); v != "" {
 				vals = append(vals, fmt.Sprintf("%s.%s.", p, v))
 			}
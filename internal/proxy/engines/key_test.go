@@ -182,6 +182,62 @@ func exampleKeyHasher(path string, params url.Values, headers http.Header, body
 	return "test-key"
 }
 
+func TestDeriveCacheKeyPathVars(t *testing.T) {
+
+	cfg := &config.OriginConfig{
+		Paths: map[string]*config.PathConfig{
+			"root": {Path: "/", CacheKeyParams: []string{"{tenant}", "query"}},
+		},
+	}
+
+	tr := httptest.NewRequest("GET", "http://127.0.0.1/?query=12345", nil)
+	rsc := request.NewResources(cfg, cfg.Paths["root"], nil, nil, nil)
+	tr = tr.WithContext(ct.WithResources(context.Background(), rsc))
+
+	pr := newProxyRequest(tr, nil)
+	key1 := pr.DeriveCacheKey(nil, "")
+
+	rsc.PathVars = map[string]string{"tenant": "acme"}
+	key2 := pr.DeriveCacheKey(nil, "")
+	if key1 == key2 {
+		t.Errorf("expected the path variable to change the key %s", key1)
+	}
+
+	rsc.PathVars["tenant"] = "other"
+	if key3 := pr.DeriveCacheKey(nil, ""); key3 == key2 {
+		t.Errorf("expected the path variable to change the key %s", key2)
+	}
+}
+
+func TestDeriveCacheKeyAuthIdentity(t *testing.T) {
+
+	cfg := &config.OriginConfig{
+		Paths: map[string]*config.PathConfig{
+			"root": {Path: "/", CacheKeyParams: []string{"query"}},
+		},
+	}
+
+	tr := httptest.NewRequest("GET", "http://127.0.0.1/?query=12345", nil)
+	tr.Header.Set("Authorization", "Bearer token-1")
+	rsc := request.NewResources(cfg, cfg.Paths["root"], nil, nil, nil)
+	rsc.AuthIdentity = "user"
+	tr = tr.WithContext(ct.WithResources(context.Background(), rsc))
+
+	pr := newProxyRequest(tr, nil)
+	key1 := pr.DeriveCacheKey(nil, "")
+
+	// a verified identity's key does not depend on the credentials that identified it
+	tr.Header.Set("Authorization", "Bearer token-2")
+	if key2 := pr.DeriveCacheKey(nil, ""); key1 != key2 {
+		t.Errorf("expected %s got %s", key1, key2)
+	}
+
+	rsc.AuthIdentity = "other"
+	if key3 := pr.DeriveCacheKey(nil, ""); key1 == key3 {
+		t.Errorf("expected the identity to change the key %s", key1)
+	}
+}
+
 func TestDeriveCacheKeyAuthHeader(t *testing.T) {
 
 	client := &TestClient{
@@ -23,6 +23,7 @@ import (
 
 	"github.com/Comcast/trickster/internal/cache"
 	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/request"
 	"github.com/Comcast/trickster/internal/util/log"
@@ -110,7 +111,15 @@ func confirmTrueCacheHit(pr *proxyRequest) (bool, error) {
 
 	pr.cachingPolicy.Merge(pr.cacheDocument.CachingPolicy)
 
-	if (!pr.checkCacheFreshness()) && (pr.cachingPolicy.CanRevalidate) {
+	fresh := pr.checkCacheFreshness()
+
+	// while the origin's circuit is open, an expired object is served rather than failing fast
+	if !fresh && pr.cacheStatus == status.LookupStatusHit && pr.servesStale(breaker.StateOpen) {
+		pr.cacheStatus = status.LookupStatusStale
+		return true, nil
+	}
+
+	if (!fresh) && (pr.cachingPolicy.CanRevalidate) {
 		return false, handleCacheRevalidation(pr)
 	}
 	if !pr.cachingPolicy.IsFresh {
@@ -176,6 +185,15 @@ func handleCacheRevalidationResponse(pr *proxyRequest) error {
 	}
 
 	pr.revalidation = RevalStatusFailed
+
+	// a revalidation that failed, or that tested the origin's half-open circuit and reopened
+	// it, is answered with the expired object, when configured
+	if pr.upstreamResponse.StatusCode >= http.StatusInternalServerError &&
+		pr.cacheStatus == status.LookupStatusHit && pr.servesStale(breaker.StateHalfOpen) {
+		pr.cacheStatus = status.LookupStatusStale
+		return handleTrueCacheHit(pr)
+	}
+
 	pr.cacheStatus = status.LookupStatusKeyMiss
 	return handleAllWrites(pr)
 }
@@ -268,6 +286,7 @@ func fetchViaObjectProxyCache(w io.Writer, r *http.Request) (*http.Response, sta
 	pr.cachingPolicy = GetRequestCachingPolicy(pr.Header)
 
 	pr.key = oc.CacheKeyPrefix + "." + pr.DeriveCacheKey(nil, "")
+	pr.baseKey = pr.key
 	pcfResult, pcfExists := Reqs.Load(pr.key)
 	if (!pr.wantsRanges && pcfExists) || pr.cachingPolicy.NoCache {
 		if pr.cachingPolicy.NoCache {
@@ -284,12 +303,18 @@ func fetchViaObjectProxyCache(w io.Writer, r *http.Request) (*http.Response, sta
 
 	pr.cachingPolicy.ParseClientConditionals()
 
+	// the base key remains locked while any variant of the object is read or written
 	if !rsc.NoLock {
-		locks.Acquire(pr.key)
+		locks.Acquire(pr.baseKey)
 	}
 
 	var err error
 	pr.cacheDocument, pr.cacheStatus, pr.neededRanges, err = QueryCache(cc, pr.key, pr.wantedRanges)
+	if err == nil && pr.cacheDocument.isVariantIndex() {
+		pr.variants = pr.cacheDocument
+		pr.key = variantKey(pr.baseKey, variantHash(pr.Header, pr.variants.VaryHeaders))
+		pr.cacheDocument, pr.cacheStatus, pr.neededRanges, err = QueryCache(cc, pr.key, pr.wantedRanges)
+	}
 	if err == nil || err == cache.ErrKNF {
 		if f, ok := cacheResponseHandlers[pr.cacheStatus]; ok {
 			f(pr)
@@ -305,7 +330,7 @@ func fetchViaObjectProxyCache(w io.Writer, r *http.Request) (*http.Response, sta
 	}
 
 	if !rsc.NoLock {
-		locks.Release(pr.key)
+		locks.Release(pr.baseKey)
 	}
 
 	// newProxyRequest sets pr.started to time.Now()
@@ -26,6 +26,7 @@ import (
 
 	"github.com/Comcast/trickster/internal/cache/status"
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
 	tc "github.com/Comcast/trickster/internal/proxy/context"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/request"
@@ -749,6 +750,97 @@ func TestObjectProxyCacheNoRevalidate(t *testing.T) {
 	}
 }
 
+func TestObjectProxyCacheServeStale(t *testing.T) {
+
+	headers := map[string]string{headers.NameCacheControl: headers.ValueMaxAge + "=1"}
+	ts, _, r, rsc, err := setupTestHarnessOPC("", "test", http.StatusOK, headers)
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	p := rsc.PathConfig
+	p.ResponseHeaders = headers
+
+	oc := rsc.OriginConfig
+	oc.Name = "test-serve-stale"
+	oc.CircuitBreaker.ServeStale = true
+
+	_, e := testFetchOPC(r, http.StatusOK, "test", map[string]string{"status": "kmiss"})
+	for _, err = range e {
+		t.Error(err)
+	}
+
+	time.Sleep(1010 * time.Millisecond)
+
+	// the expired object is served while the origin's circuit is open
+	b := breaker.New(oc.Name, &config.CircuitBreakerConfig{FailureRatio: 1, MinRequests: 1,
+		Window: time.Second, OpenDuration: time.Minute})
+	b.Record(false, breaker.ResultFailure)
+
+	_, e = testFetchOPC(r, http.StatusOK, "test", map[string]string{"status": "stale"})
+	for _, err = range e {
+		t.Error(err)
+	}
+}
+
+func TestObjectProxyCacheVary(t *testing.T) {
+
+	hdrs := map[string]string{headers.NameCacheControl: headers.ValueMaxAge + "=60", headers.NameVary: "Accept"}
+	ts, _, r, rsc, err := setupTestHarnessOPC("", "test", http.StatusOK, hdrs)
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	fetch := func(accept, expected string) {
+		r.Header.Set("Accept", accept)
+		_, e := testFetchOPC(r, http.StatusOK, "test", map[string]string{"status": expected})
+		for _, err = range e {
+			t.Error(err)
+		}
+	}
+
+	// each variant is fetched once from the origin, then served from the cache
+	fetch("text/plain", "kmiss")
+	fetch("application/json", "kmiss")
+	fetch("text/plain", "hit")
+	fetch("application/json", "hit")
+
+	key := rsc.OriginConfig.CacheKeyPrefix + "." + newProxyRequest(r, nil).DeriveCacheKey(nil, "")
+	info, err := InspectCachedVariants(rsc.CacheClient, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil {
+		t.Fatal("expected variant index")
+	}
+	if info.VariantCount != 2 {
+		t.Errorf("expected %d variants got %d", 2, info.VariantCount)
+	}
+	if len(info.VaryHeaders) != 1 || info.VaryHeaders[0] != "Accept" {
+		t.Errorf("unexpected vary headers %v", info.VaryHeaders)
+	}
+}
+
+func TestObjectProxyCacheVaryAll(t *testing.T) {
+
+	hdrs := map[string]string{headers.NameCacheControl: headers.ValueMaxAge + "=60", headers.NameVary: "*"}
+	ts, _, r, _, err := setupTestHarnessOPC("", "test", http.StatusOK, hdrs)
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	// a response that varies on '*' is never cached
+	for i := 0; i < 2; i++ {
+		_, e := testFetchOPC(r, http.StatusOK, "test", map[string]string{"status": "kmiss"})
+		for _, err = range e {
+			t.Error(err)
+		}
+	}
+}
+
 func TestObjectProxyCacheCanRevalidate(t *testing.T) {
 
 	headers := map[string]string{
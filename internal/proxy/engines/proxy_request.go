@@ -15,7 +15,6 @@ package engines
 
 import (
 	"bytes"
-	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -24,6 +23,7 @@ import (
 
 	"github.com/Comcast/trickster/internal/cache/status"
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
 	tctx "github.com/Comcast/trickster/internal/proxy/context"
 	"github.com/Comcast/trickster/internal/proxy/headers"
 	"github.com/Comcast/trickster/internal/proxy/ranges/byterange"
@@ -51,6 +51,10 @@ type proxyRequest struct {
 
 	cacheDocument *HTTPDocument
 	cacheBuffer   *bytes.Buffer
+	// variants is the index of the object's variants when its cached responses Vary, in which
+	// case the index is cached under baseKey, and key is that of the variant the request selects
+	variants *HTTPDocument
+	baseKey  string
 
 	key          string
 	started      time.Time
@@ -79,7 +83,7 @@ func newProxyRequest(r *http.Request, w io.Writer) *proxyRequest {
 
 	pr := &proxyRequest{
 		Request:         r,
-		upstreamRequest: r.Clone(context.Background()),
+		upstreamRequest: r.Clone(r.Context()),
 		contentLength:   -1,
 		responseWriter:  w,
 		started:         time.Now(),
@@ -93,9 +97,11 @@ func newProxyRequest(r *http.Request, w io.Writer) *proxyRequest {
 
 func (pr *proxyRequest) Clone() *proxyRequest {
 	return &proxyRequest{
-		Request:            pr.Request.Clone(context.Background()),
+		Request:            pr.Request.Clone(pr.Request.Context()),
 		cacheDocument:      pr.cacheDocument,
 		key:                pr.key,
+		baseKey:            pr.baseKey,
+		variants:           pr.variants,
 		cacheStatus:        pr.cacheStatus,
 		writeToCache:       pr.writeToCache,
 		wantsRanges:        pr.wantsRanges,
@@ -149,7 +155,7 @@ func (pr *proxyRequest) Fetch() ([]byte, *http.Response, time.Duration) {
 func (pr *proxyRequest) prepareRevalidationRequest() {
 
 	pr.revalidation = RevalStatusInProgress
-	pr.revalidationRequest = request.SetResources(pr.upstreamRequest.Clone(context.Background()), request.GetResources(pr.Request))
+	pr.revalidationRequest = request.SetResources(pr.upstreamRequest.Clone(pr.upstreamRequest.Context()), request.GetResources(pr.Request))
 
 	if pr.cacheStatus == status.LookupStatusPartialHit {
 		var rh string
@@ -220,7 +226,7 @@ func (pr *proxyRequest) prepareUpstreamRequests() {
 	// if we are articulating the origin range requests, break those out here
 	if pr.neededRanges != nil && len(pr.neededRanges) > 0 && rsc.OriginConfig.DearticulateUpstreamRanges {
 		for _, r := range pr.neededRanges {
-			req := request.SetResources(pr.upstreamRequest.Clone(context.Background()), rsc)
+			req := request.SetResources(pr.upstreamRequest.Clone(pr.upstreamRequest.Context()), rsc)
 			req.Header.Set(headers.NameRange, "bytes="+r.String())
 			pr.originRequests = append(pr.originRequests, req)
 		}
@@ -267,6 +273,17 @@ func (pr *proxyRequest) checkCacheFreshness() bool {
 	return cp.IsFresh
 }
 
+// servesStale returns true if the origin serves expired objects from the cache while its circuit
+// breaker is at least in the provided state, and the circuit breaker is in that state
+func (pr *proxyRequest) servesStale(min breaker.State) bool {
+	oc := request.GetResources(pr.Request).OriginConfig
+	if oc == nil || !oc.CircuitBreaker.ServeStale {
+		return false
+	}
+	b := breaker.Get(oc.Name)
+	return b != nil && b.State() >= min
+}
+
 func (pr *proxyRequest) parseRequestRanges() bool {
 	// handle byte range requests
 	var out byterange.Ranges
@@ -324,8 +341,17 @@ func (pr *proxyRequest) writeResponseBody() {
 	if pr.upstreamReader == nil || pr.responseWriter == nil {
 		return
 	}
-	io.Copy(pr.responseWriter, pr.upstreamReader)
-
+	_, err := io.Copy(pr.responseWriter, pr.upstreamReader)
+	// a body that was cut short, by the origin or by the client going away or its deadline
+	// passing, must not be cached as though it were complete
+	if err == nil && pr.upstreamRequest != nil {
+		err = pr.upstreamRequest.Context().Err()
+	}
+	if err != nil && pr.writeToCache {
+		log.Debug("response body was not fully read, and will not be cached",
+			log.Pairs{"cacheKey": pr.key, "detail": err.Error()})
+		pr.writeToCache = false
+	}
 }
 
 func (pr *proxyRequest) determineCacheability() {
@@ -333,6 +359,14 @@ func (pr *proxyRequest) determineCacheability() {
 	rsc := request.GetResources(pr.Request)
 	resp := pr.upstreamResponse
 
+	// a response that varies on more than the request headers can't be selected from the cache
+	if resp != nil {
+		if _, varyAll := varyHeaders(resp.Header); varyAll {
+			pr.writeToCache = false
+			return
+		}
+	}
+
 	if resp != nil && resp.StatusCode >= 400 {
 		pr.writeToCache = pr.cachingPolicy.IsNegativeCache
 		resp.Header.Del(headers.NameCacheControl)
@@ -393,19 +427,57 @@ func (pr *proxyRequest) store() error {
 	rsc := request.GetResources(pr.Request)
 	oc := rsc.OriginConfig
 
-	rf := oc.RevalidationFactor
-	if rsc.AlternateCacheTTL > 0 {
-		rf = 1
+	d.CachingPolicy = pr.cachingPolicy
+	ttl := pr.cacheTTL(pr.cachingPolicy)
+
+	// a revalidated document's body is unchanged, so its compressed bodies remain valid. Otherwise,
+	// the body is compressed only in the encoding negotiated with the client
+	if pr.cacheStatus != status.LookupStatusRevalidated {
+		d.EncodedBodies = nil
+	}
+	if b, enc, isNew := pr.encodedBody(d); isNew {
+		eb := make(map[string][]byte, len(d.EncodedBodies)+1)
+		for k, v := range d.EncodedBodies {
+			eb[k] = v
+		}
+		eb[enc] = b
+		d.EncodedBodies = eb
 	}
 
-	d.CachingPolicy = pr.cachingPolicy
-	err := WriteCache(rsc.CacheClient, pr.key, d, pr.cachingPolicy.TTL(rf, oc.MaxTTL), oc.CompressableTypes)
+	baseKey := pr.baseKey
+	if baseKey == "" {
+		baseKey = pr.key
+	}
+
+	// a response that Varies is cached as a variant, which is selected through the object's
+	// variant index, while any other response replaces whatever is cached under the base key
+	key := baseKey
+	if names, _ := varyHeaders(http.Header(d.Headers)); len(names) > 0 {
+		hash := variantHash(pr.Header, names)
+		key = variantKey(baseKey, hash)
+		if err := storeVariantIndex(rsc.CacheClient, baseKey, pr.variants, names, hash, ttl); err != nil {
+			return err
+		}
+	}
+
+	err := WriteCache(rsc.CacheClient, key, d, ttl, oc.CompressableTypes)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// cacheTTL returns the TTL with which a document with the caching policy is cached
+func (pr *proxyRequest) cacheTTL(cp *CachingPolicy) time.Duration {
+	rsc := request.GetResources(pr.Request)
+	oc := rsc.OriginConfig
+	rf := oc.RevalidationFactor
+	if rsc.AlternateCacheTTL > 0 {
+		rf = 1
+	}
+	return cp.TTL(rf, oc.MaxTTL)
+}
+
 func (pr *proxyRequest) updateContentLength() {
 
 	resp := pr.upstreamResponse
@@ -483,6 +555,7 @@ func (pr *proxyRequest) prepareResponse() {
 		resp.Header.Del(headers.NameContentRange)
 		if pr.cacheStatus == status.LookupStatusHit || pr.cacheStatus == status.LookupStatusRevalidated || pr.cacheStatus == status.LookupStatusPartialHit {
 			pr.responseBody = d.Body
+			pr.encodeResponseBody()
 		}
 	}
 
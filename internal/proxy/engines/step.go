@@ -0,0 +1,28 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import "time"
+
+// snapStep returns the smallest rung of the step ladder that is at least as coarse as the
+// provided step. The ladder must be sorted finest to coarsest. If the step is coarser than
+// every rung, or the ladder is empty, the step is returned unchanged.
+func snapStep(ladder []time.Duration, step time.Duration) time.Duration {
+	for _, s := range ladder {
+		if s >= step {
+			return s
+		}
+	}
+	return step
+}
@@ -0,0 +1,143 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/Comcast/trickster/pkg/promsim"
+)
+
+func TestSnapStep(t *testing.T) {
+
+	ladder := []time.Duration{time.Duration(15) * time.Second, time.Minute, time.Duration(5) * time.Minute}
+
+	tests := []struct {
+		ladder   []time.Duration
+		step     time.Duration
+		expected time.Duration
+	}{
+		{ladder, time.Duration(10) * time.Second, time.Duration(15) * time.Second},
+		{ladder, time.Duration(15) * time.Second, time.Duration(15) * time.Second},
+		{ladder, time.Duration(17) * time.Second, time.Minute},
+		{ladder, time.Duration(90) * time.Second, time.Duration(5) * time.Minute},
+		{ladder, time.Hour, time.Hour},
+		{nil, time.Duration(17) * time.Second, time.Duration(17) * time.Second},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if v := snapStep(test.ladder, test.step); v != test.expected {
+				t.Errorf("expected %s got %s", test.expected, v)
+			}
+		})
+	}
+}
+
+func TestDeltaProxyCacheRequestStepLadder(t *testing.T) {
+
+	ts, _, r, rsc, err := setupTestHarnessDPC()
+	if err != nil {
+		t.Error(err)
+	}
+	defer ts.Close()
+
+	client := rsc.OriginClient.(*TestClient)
+	oc := rsc.OriginConfig
+	pc := rsc.PathConfig
+
+	oc.FastForwardDisable = true
+	oc.StepLadder = []time.Duration{time.Duration(15) * time.Second, time.Minute}
+	pc.CacheKeyParams = []string{"query", "step"}
+
+	snapped := time.Minute
+	end := time.Now().Add(-time.Duration(2) * time.Hour).Truncate(snapped)
+	extr := timeseries.Extent{Start: end.Add(-time.Duration(6) * time.Hour), End: end}
+
+	tests := []struct {
+		step     time.Duration
+		resample bool
+		expected string
+	}{
+		{time.Duration(20) * time.Second, false, "kmiss"}, // snapped to 60s
+		{time.Duration(45) * time.Second, false, "hit"},   // snapped to 60s, shares the cache key
+		{time.Duration(30) * time.Second, true, "hit"},    // snapped to 60s and resampled to 30s
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+
+			oc.StepLadderResample = test.resample
+
+			r.URL.Path = "/api/v1/query_range"
+			r.URL.RawQuery = fmt.Sprintf("step=%d&start=%d&end=%d&query=%s", int(test.step.Seconds()),
+				extr.Start.Unix(), extr.End.Unix(), queryDownsample)
+
+			w := httptest.NewRecorder()
+			client.QueryRangeHandler(w, r)
+			resp := w.Result()
+
+			bodyBytes, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testStatusCodeMatch(resp.StatusCode, http.StatusOK)
+			if err != nil {
+				t.Error(err)
+			}
+
+			err = testResultHeaderPartMatch(resp.Header, map[string]string{"status": test.expected})
+			if err != nil {
+				t.Error(err)
+			}
+
+			if !test.resample {
+				expected, _, _ := promsim.GetTimeSeriesData(queryDownsample, extr.Start, extr.End, snapped)
+				err = testStringMatch(string(bodyBytes), expected)
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+
+			rts, err := client.UnmarshalTimeseries(bodyBytes)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			me := rts.(*MatrixEnvelope)
+			if len(me.Data.Result) == 0 {
+				t.Errorf("expected series in response")
+				return
+			}
+			values := me.Data.Result[0].Values
+			if n := int(extr.End.Sub(extr.Start)/test.step) + 1; len(values) != n {
+				t.Errorf("expected %d values got %d", n, len(values))
+				return
+			}
+			for j := 1; j < len(values); j += 2 {
+				if values[j].Value != values[j-1].Value {
+					t.Errorf("expected %v got %v at %s", values[j-1].Value, values[j].Value, values[j].Timestamp)
+				}
+			}
+		})
+	}
+}
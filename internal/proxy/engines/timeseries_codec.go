@@ -0,0 +1,61 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"bytes"
+
+	"github.com/Comcast/trickster/internal/proxy/errors"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+// timeseriesCodecVersion is the version of the binary encoding of cached timeseries. It must be
+// incremented whenever a BinaryTimeseriesClient changes its encoding in an incompatible way, so
+// that entries cached in the prior encoding are discarded rather than misread
+const timeseriesCodecVersion = 1
+
+// timeseriesCodecHeader prefixes binary-encoded cached timeseries, and is followed by the version.
+// Since it cannot begin a valid JSON document, cached timeseries without the header are decoded
+// using the origin's wire format, as they were cached prior to the binary encoding
+var timeseriesCodecHeader = []byte{0, 't', 's'}
+
+// marshalCachedTimeseries encodes a timeseries for storage in a non-memory cache, using the
+// client's binary encoding if it has one, and its wire format otherwise
+func marshalCachedTimeseries(client origins.TimeseriesClient, ts timeseries.Timeseries) ([]byte, error) {
+	bc, ok := client.(origins.BinaryTimeseriesClient)
+	if !ok {
+		return client.MarshalTimeseries(ts)
+	}
+	b, err := bc.MarshalTimeseriesBinary(ts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(timeseriesCodecHeader)+1+len(b))
+	out = append(append(out, timeseriesCodecHeader...), timeseriesCodecVersion)
+	return append(out, b...), nil
+}
+
+// unmarshalCachedTimeseries decodes a timeseries stored by marshalCachedTimeseries, or
+// stored in the client's wire format
+func unmarshalCachedTimeseries(client origins.TimeseriesClient, data []byte) (timeseries.Timeseries, error) {
+	if !bytes.HasPrefix(data, timeseriesCodecHeader) {
+		return client.UnmarshalTimeseries(data)
+	}
+	bc, ok := client.(origins.BinaryTimeseriesClient)
+	if !ok || len(data) <= len(timeseriesCodecHeader) || data[len(timeseriesCodecHeader)] != timeseriesCodecVersion {
+		return nil, errors.ErrInvalidTimeseriesEncoding
+	}
+	return bc.UnmarshalTimeseriesBinary(data[len(timeseriesCodecHeader)+1:])
+}
@@ -0,0 +1,115 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/proxy/errors"
+	"github.com/Comcast/trickster/internal/timeseries"
+)
+
+// binaryTestClient is a TestClient that implements origins.BinaryTimeseriesClient by
+// reversing its JSON encoding, so that results in the wrong encoding fail to decode
+type binaryTestClient struct {
+	*TestClient
+}
+
+func reverseBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+
+func (c *binaryTestClient) MarshalTimeseriesBinary(ts timeseries.Timeseries) ([]byte, error) {
+	b, err := c.MarshalTimeseries(ts)
+	return reverseBytes(b), err
+}
+
+func (c *binaryTestClient) UnmarshalTimeseriesBinary(data []byte) (timeseries.Timeseries, error) {
+	return c.UnmarshalTimeseries(reverseBytes(data))
+}
+
+func testCodecTimeseries() timeseries.Timeseries {
+	return &MatrixEnvelope{
+		Status:       "success",
+		Data:         MatrixData{ResultType: "matrix"},
+		StepDuration: time.Minute,
+		ExtentList:   timeseries.ExtentList{timeseries.Extent{Start: time.Unix(60, 0), End: time.Unix(600, 0)}},
+	}
+}
+
+func TestMarshalCachedTimeseries(t *testing.T) {
+
+	tc := &TestClient{}
+	bc := &binaryTestClient{TestClient: tc}
+	ts := testCodecTimeseries()
+
+	// a client without a binary encoding stores its wire format
+	b, err := marshalCachedTimeseries(tc, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.HasPrefix(b, timeseriesCodecHeader) {
+		t.Errorf("unexpected binary header in %s", string(b))
+	}
+
+	// which remains readable once the client gains a binary encoding
+	ts2, err := unmarshalCachedTimeseries(bc, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts2.Extents().String() != ts.Extents().String() {
+		t.Errorf("expected %s got %s", ts.Extents().String(), ts2.Extents().String())
+	}
+
+	b, err = marshalCachedTimeseries(bc, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(b, timeseriesCodecHeader) || b[len(timeseriesCodecHeader)] != timeseriesCodecVersion {
+		t.Errorf("expected binary header and version in %v", b)
+	}
+
+	ts2, err = unmarshalCachedTimeseries(bc, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts2.Step() != ts.Step() {
+		t.Errorf("expected %s got %s", ts.Step(), ts2.Step())
+	}
+
+	// a client that lost its binary encoding can't read it
+	_, err = unmarshalCachedTimeseries(tc, b)
+	if err != errors.ErrInvalidTimeseriesEncoding {
+		t.Errorf("expected %v got %v", errors.ErrInvalidTimeseriesEncoding, err)
+	}
+
+	// nor can any client read a different version of it
+	b[len(timeseriesCodecHeader)] = timeseriesCodecVersion + 1
+	_, err = unmarshalCachedTimeseries(bc, b)
+	if err != errors.ErrInvalidTimeseriesEncoding {
+		t.Errorf("expected %v got %v", errors.ErrInvalidTimeseriesEncoding, err)
+	}
+
+	_, err = unmarshalCachedTimeseries(bc, timeseriesCodecHeader)
+	if err != errors.ErrInvalidTimeseriesEncoding {
+		t.Errorf("expected %v got %v", errors.ErrInvalidTimeseriesEncoding, err)
+	}
+
+}
@@ -0,0 +1,109 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/util/md5"
+)
+
+// varyHeaders returns the sorted, canonical names of the request headers listed by the Vary
+// header of the response. varyAll is true when the response varies on '*', so that no request
+// can be known to select it.
+func varyHeaders(h http.Header) (names []string, varyAll bool) {
+	seen := make(map[string]bool)
+	for _, v := range h[headers.NameVary] {
+		for _, n := range strings.Split(v, ",") {
+			n = strings.TrimSpace(n)
+			if n == "" {
+				continue
+			}
+			if n == "*" {
+				return nil, true
+			}
+			n = http.CanonicalHeaderKey(n)
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, false
+}
+
+// variantHash returns the hash of the request's values of the named headers, which identifies the
+// variant of the object that the request selects
+func variantHash(h http.Header, names []string) string {
+	vals := make([]string, len(names))
+	for i, n := range names {
+		vals[i] = n + ":" + strings.Join(h[n], ",")
+	}
+	return md5.Checksum(strings.Join(vals, "\n"))
+}
+
+// variantKey returns the cache key of the variant with the provided hash
+func variantKey(baseKey, hash string) string {
+	return baseKey + ".variant." + hash
+}
+
+// isVariantIndex returns true if the document is the index of the variants of an object,
+// rather than a cached response
+func (d *HTTPDocument) isVariantIndex() bool {
+	return d != nil && len(d.VaryHeaders) > 0
+}
+
+// liveVariants returns the number of the index's variants that have not expired
+func (d *HTTPDocument) liveVariants(now time.Time) int {
+	var n int
+	for _, exp := range d.Variants {
+		if exp > now.Unix() {
+			n++
+		}
+	}
+	return n
+}
+
+// storeVariantIndex writes the index of the object's variants to the base key, adding the variant
+// with the provided hash, which expires after ttl. The variant index that was retrieved with the
+// request is copied rather than updated, since a memory cache holds it by reference. If the origin
+// now varies the object on different headers, the previous variants are no longer selectable, and
+// the index is replaced.
+func storeVariantIndex(c cache.Cache, baseKey string, prev *HTTPDocument, names []string,
+	hash string, ttl time.Duration) error {
+
+	now := time.Now()
+	idx := &HTTPDocument{VaryHeaders: names, Variants: map[string]int64{hash: now.Add(ttl).Unix()}}
+
+	if prev.isVariantIndex() && strings.Join(prev.VaryHeaders, ",") == strings.Join(names, ",") {
+		for h, exp := range prev.Variants {
+			if _, ok := idx.Variants[h]; ok || exp <= now.Unix() {
+				continue
+			}
+			idx.Variants[h] = exp
+			// the index is retained for as long as its longest-lived variant
+			if d := time.Unix(exp, 0).Sub(now); d > ttl {
+				ttl = d
+			}
+		}
+	}
+
+	return WriteCache(c, baseKey, idx, ttl, nil)
+}
@@ -0,0 +1,128 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package engines
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+)
+
+func TestVaryHeaders(t *testing.T) {
+
+	tests := []struct {
+		vary     []string
+		expected string
+		varyAll  bool
+	}{
+		{nil, "", false},
+		{[]string{""}, "", false},
+		{[]string{"accept-encoding"}, "Accept-Encoding", false},
+		{[]string{"Accept, accept-encoding", "Accept"}, "Accept,Accept-Encoding", false},
+		{[]string{"User-Agent , ,Accept"}, "Accept,User-Agent", false},
+		{[]string{"Accept", "*"}, "", true},
+	}
+
+	for i, test := range tests {
+		h := http.Header{}
+		for _, v := range test.vary {
+			h.Add("Vary", v)
+		}
+		names, varyAll := varyHeaders(h)
+		if varyAll != test.varyAll {
+			t.Errorf("test %d expected %t got %t", i, test.varyAll, varyAll)
+		}
+		if s := strings.Join(names, ","); s != test.expected {
+			t.Errorf("test %d expected %s got %s", i, test.expected, s)
+		}
+	}
+}
+
+func TestVariantHash(t *testing.T) {
+
+	names := []string{"Accept", "Accept-Encoding"}
+
+	h1 := http.Header{"Accept": {"text/plain"}, "Accept-Encoding": {"gzip"}, "User-Agent": {"a"}}
+	h2 := http.Header{"Accept": {"text/plain"}, "Accept-Encoding": {"gzip"}, "User-Agent": {"b"}}
+	h3 := http.Header{"Accept": {"text/plain"}}
+
+	if variantHash(h1, names) != variantHash(h2, names) {
+		t.Errorf("expected headers not named by Vary to select the same variant")
+	}
+
+	if variantHash(h1, names) == variantHash(h3, names) {
+		t.Errorf("expected headers named by Vary to select different variants")
+	}
+
+	if k := variantKey("base", "hash"); k != "base.variant.hash" {
+		t.Errorf("expected %s got %s", "base.variant.hash", k)
+	}
+}
+
+func TestStoreVariantIndex(t *testing.T) {
+
+	mc := &memory.Cache{Name: "test", Config: config.NewCacheConfig()}
+	if err := mc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"Accept"}
+
+	query := func() *HTTPDocument {
+		d, lookupStatus, _, err := QueryCache(mc, "base", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lookupStatus != status.LookupStatusHit {
+			t.Fatalf("expected %s got %s", status.LookupStatusHit, lookupStatus)
+		}
+		return d
+	}
+
+	if err := storeVariantIndex(mc, "base", nil, names, "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	idx := query()
+	if !idx.isVariantIndex() || idx.liveVariants(time.Now()) != 1 {
+		t.Fatalf("expected variant index with %d variants got %v", 1, idx.Variants)
+	}
+
+	// an expired variant is dropped from the index
+	idx.Variants["expired"] = time.Now().Add(-time.Minute).Unix()
+
+	if err := storeVariantIndex(mc, "base", idx, names, "b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	idx2 := query()
+	if len(idx2.Variants) != 2 {
+		t.Errorf("expected %d variants got %d", 2, len(idx2.Variants))
+	}
+	if _, ok := idx.Variants["b"]; ok {
+		t.Errorf("expected the previous index to be unchanged")
+	}
+
+	// a change in the headers the object varies on replaces the index
+	if err := storeVariantIndex(mc, "base", idx2, []string{"Accept-Encoding"}, "c", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	idx3 := query()
+	if len(idx3.Variants) != 1 || idx3.VaryHeaders[0] != "Accept-Encoding" {
+		t.Errorf("expected replaced index got %v %v", idx3.VaryHeaders, idx3.Variants)
+	}
+}
@@ -31,6 +31,12 @@ var ErrNotTimeRangeQuery = errors.New("not a time range query")
 // ErrNoRanges indicates an error that the range request does not contain any usable ranges
 var ErrNoRanges = errors.New("no usable ranges")
 
+// ErrUnsupportedTimeseries indicates an error that the timeseries is not of a type supported by the operation
+var ErrUnsupportedTimeseries = errors.New("unsupported timeseries type")
+
+// ErrInvalidTimeseriesEncoding indicates an error that a cached timeseries could not be decoded
+var ErrInvalidTimeseriesEncoding = errors.New("invalid timeseries encoding")
+
 // MissingURLParam returns a Formatted Error
 func MissingURLParam(param string) error {
 	return fmt.Errorf("missing URL parameter: [%s]", param)
@@ -0,0 +1,124 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/archive"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/auth"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/routing"
+	"github.com/Comcast/trickster/internal/util/log"
+
+	"github.com/gorilla/mux"
+)
+
+// cacheArchiver exports the objects in a cache to an archive, and imports an archive into a cache
+type cacheArchiver struct {
+	caches map[string]cache.Cache
+}
+
+type cacheImportResult struct {
+	Cache    string `json:"cache"`
+	Imported int    `json:"imported"`
+}
+
+// RegisterCacheArchiveHandler registers the application's cache archive handlers, which export
+// the objects in a cache with GET /path/{cacheName}, and import an archive with PUT or POST.
+// Both are authenticated by the configured cache archive Authenticator, without which the
+// handlers are not registered
+func RegisterCacheArchiveHandler(caches map[string]cache.Cache) {
+	if config.Main.CacheArchiveHandlerPath == "" {
+		return
+	}
+	name := config.Main.CacheArchiveAuthenticatorName
+	ac, ok := config.Authenticators[name]
+	if !ok {
+		log.Error("cache archive handler requires an authenticator", log.Pairs{"authenticatorName": name})
+		return
+	}
+	a, err := auth.New(name, ac)
+	if err != nil {
+		log.Error("cache archive handler authenticator setup failed", log.Pairs{"authenticatorName": name, "detail": err.Error()})
+		return
+	}
+	ca := &cacheArchiver{caches: caches}
+	routing.Router.Handle(config.Main.CacheArchiveHandlerPath+"/{cache}",
+		auth.Handler(a, "", http.HandlerFunc(ca.exportCache))).Methods("GET")
+	routing.Router.Handle(config.Main.CacheArchiveHandlerPath+"/{cache}",
+		auth.Handler(a, "", http.HandlerFunc(ca.importCache))).Methods("PUT", "POST")
+}
+
+// exportCache responds with an archive of the objects in the requested cache
+func (ca *cacheArchiver) exportCache(w http.ResponseWriter, r *http.Request) {
+
+	name := mux.Vars(r)["cache"]
+	c, ok := ca.caches[name]
+	if !ok {
+		http.Error(w, "unknown cache: "+name, http.StatusNotFound)
+		return
+	}
+
+	e, err := archive.NewExporter(c)
+	if err == cache.ErrListUnsupported {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	h := w.Header()
+	h.Set(headers.NameContentType, "application/octet-stream")
+	h.Set("Content-Disposition", `attachment; filename="`+name+`.trkcache"`)
+	h.Set(headers.NameCacheControl, headers.ValueNoCache)
+	w.WriteHeader(http.StatusOK)
+
+	// the status has been sent, so a failure partway through can only be logged
+	n, err := e.Export(w)
+	if err != nil {
+		log.Error("cache export failed", log.Pairs{"cacheName": name, "exported": n, "detail": err.Error()})
+		return
+	}
+	log.Info("cache exported", log.Pairs{"cacheName": name, "exported": n})
+}
+
+// importCache stores the objects in the archive provided in the request body into the requested cache
+func (ca *cacheArchiver) importCache(w http.ResponseWriter, r *http.Request) {
+
+	name := mux.Vars(r)["cache"]
+	c, ok := ca.caches[name]
+	if !ok {
+		http.Error(w, "unknown cache: "+name, http.StatusNotFound)
+		return
+	}
+
+	n, err := archive.Import(c, r.Body)
+	if err != nil {
+		log.Error("cache import failed", log.Pairs{"cacheName": name, "imported": n, "detail": err.Error()})
+		code := http.StatusBadGateway
+		if errors.Is(err, archive.ErrInvalidArchive) {
+			code = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	log.Info("cache imported", log.Pairs{"cacheName": name, "imported": n})
+	writeJSON(w, &cacheImportResult{Cache: name, Imported: n})
+}
@@ -0,0 +1,161 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/routing"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestCacheArchiver(t *testing.T) (*cacheArchiver, *memory.Cache) {
+
+	err := config.Load("trickster-test", "test", []string{"-origin-url", "http://1.2.3.4", "-origin-type", "prometheus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := &memory.Cache{Name: "default", Config: config.Caches["default"]}
+	if err = mc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &cacheArchiver{
+		caches: map[string]cache.Cache{"default": mc, "unlistable": &unlistableCache{mc}},
+	}, mc
+}
+
+func archiveRequest(ca *cacheArchiver, method, cacheName string, body []byte) *http.Response {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, "http://0/trickster/archive/"+cacheName, bytes.NewReader(body))
+	r = mux.SetURLVars(r, map[string]string{"cache": cacheName})
+	if method == http.MethodGet {
+		ca.exportCache(w, r)
+	} else {
+		ca.importCache(w, r)
+	}
+	return w.Result()
+}
+
+func TestRegisterCacheArchiveHandler(t *testing.T) {
+
+	ca, _ := newTestCacheArchiver(t)
+	defer func() { config.Main.CacheArchiveHandlerPath = "" }()
+
+	routeRequest := func(method, path, apiKey string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, "http://0"+path, nil)
+		if apiKey != "" {
+			r.Header.Set("X-API-Key", apiKey)
+		}
+		routing.Router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// the handler is not registered without an authenticator
+	config.Main.CacheArchiveHandlerPath = "/trickster/archive-unauthenticated"
+	RegisterCacheArchiveHandler(ca.caches)
+	if code := routeRequest(http.MethodGet, "/trickster/archive-unauthenticated/default", ""); code != http.StatusNotFound {
+		t.Errorf("expected %d got %d", http.StatusNotFound, code)
+	}
+
+	config.Authenticators["archive"] = &config.AuthenticatorConfig{AuthenticatorType: "api_key",
+		APIKeyHeader: "X-API-Key", APIKeys: map[string]string{"secret": "admin"}}
+	config.Main.CacheArchiveHandlerPath = "/trickster/archive"
+	config.Main.CacheArchiveAuthenticatorName = "archive"
+	RegisterCacheArchiveHandler(ca.caches)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPost} {
+		if code := routeRequest(method, "/trickster/archive/default", ""); code != http.StatusUnauthorized {
+			t.Errorf("expected %d got %d for %s", http.StatusUnauthorized, code, method)
+		}
+	}
+	if code := routeRequest(http.MethodGet, "/trickster/archive/default", "secret"); code != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, code)
+	}
+}
+
+func TestCacheArchiveExportImport(t *testing.T) {
+
+	ca, mc := newTestCacheArchiver(t)
+	mc.Store("key1", []byte("value1"), time.Minute)
+	mc.Store("key2", []byte("value2"), time.Minute)
+
+	resp := archiveRequest(ca, http.MethodGet, "default", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d got %d", http.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/octet-stream" {
+		t.Errorf("expected %s got %s", "application/octet-stream", ct)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename="default.trkcache"` {
+		t.Errorf("unexpected Content-Disposition %s", cd)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+
+	ca2, mc2 := newTestCacheArchiver(t)
+	resp = archiveRequest(ca2, http.MethodPut, "default", b)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	ir := &cacheImportResult{}
+	b, _ = ioutil.ReadAll(resp.Body)
+	if err := json.Unmarshal(b, ir); err != nil {
+		t.Fatal(err)
+	}
+	if ir.Cache != "default" || ir.Imported != 2 {
+		t.Errorf("unexpected import result %v", ir)
+	}
+
+	for _, k := range []string{"key1", "key2"} {
+		if _, _, err := mc2.Retrieve(k, false); err != nil {
+			t.Errorf("expected %s to be imported: %v", k, err)
+		}
+	}
+}
+
+func TestCacheArchiveErrors(t *testing.T) {
+
+	ca, _ := newTestCacheArchiver(t)
+
+	tests := []struct {
+		method    string
+		cacheName string
+		body      []byte
+		code      int
+	}{
+		{http.MethodGet, "missing", nil, http.StatusNotFound},
+		{http.MethodPut, "missing", nil, http.StatusNotFound},
+		{http.MethodGet, "unlistable", nil, http.StatusNotImplemented},
+		{http.MethodPost, "default", []byte("not an archive"), http.StatusBadRequest},
+	}
+
+	for i, test := range tests {
+		resp := archiveRequest(ca, test.method, test.cacheName, test.body)
+		if resp.StatusCode != test.code {
+			t.Errorf("test %d: expected %d got %d", i, test.code, resp.StatusCode)
+		}
+	}
+}
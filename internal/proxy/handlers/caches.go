@@ -0,0 +1,305 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/engines"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/routing"
+	"github.com/Comcast/trickster/internal/timeseries"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultCacheBrowserLimit = 100
+	maxCacheBrowserLimit     = 1000
+)
+
+// cacheBrowser lists the caches and the objects they hold. clients are the origin clients, whose
+// configurations map cache keys to origins, and which decode the timeseries cached for them
+type cacheBrowser struct {
+	caches  map[string]cache.Cache
+	clients map[string]origins.Client
+}
+
+type cacheSummary struct {
+	Name      string `json:"name"`
+	CacheType string `json:"cache_type"`
+	Listable  bool   `json:"listable"`
+}
+
+type cacheObjectList struct {
+	Cache     string              `json:"cache"`
+	CacheType string              `json:"cache_type"`
+	Total     int                 `json:"total"`
+	Offset    int                 `json:"offset"`
+	Limit     int                 `json:"limit"`
+	Objects   []*cacheObjectEntry `json:"objects"`
+}
+
+type cacheObjectEntry struct {
+	Key        string                 `json:"key"`
+	Size       int64                  `json:"size"`
+	Expiration *time.Time             `json:"expiration,omitempty"`
+	LastWrite  *time.Time             `json:"last_write,omitempty"`
+	LastAccess *time.Time             `json:"last_access,omitempty"`
+	Origin     string                 `json:"origin,omitempty"`
+	Timeseries *cacheTimeseriesDetail `json:"timeseries,omitempty"`
+	Variants   *cacheVariantsDetail   `json:"variants,omitempty"`
+}
+
+type cacheTimeseriesDetail struct {
+	StepSecs    float64               `json:"step_secs"`
+	Extents     timeseries.ExtentList `json:"extents"`
+	SeriesCount int                   `json:"series_count"`
+	ValueCount  int                   `json:"value_count"`
+}
+
+type cacheVariantsDetail struct {
+	VaryHeaders []string `json:"vary_headers"`
+	Count       int      `json:"count"`
+}
+
+// RegisterCacheBrowserHandler registers the application's cache browser handlers, which list the
+// configured caches at the handler path, and the objects in each cache at /path/{cacheName}
+func RegisterCacheBrowserHandler(caches map[string]cache.Cache, clients map[string]origins.Client) {
+	if config.Main.CacheBrowserHandlerPath == "" {
+		return
+	}
+	cb := &cacheBrowser{caches: caches, clients: clients}
+	routing.Router.HandleFunc(config.Main.CacheBrowserHandlerPath, cb.listCaches).Methods("GET")
+	routing.Router.HandleFunc(config.Main.CacheBrowserHandlerPath+"/{cache}", cb.listObjects).Methods("GET")
+}
+
+// listCaches responds with the name and type of each cache, and whether its objects can be listed
+func (cb *cacheBrowser) listCaches(w http.ResponseWriter, r *http.Request) {
+	l := make([]cacheSummary, 0, len(cb.caches))
+	for k, c := range cb.caches {
+		_, ok := c.(cache.ObjectLister)
+		l = append(l, cacheSummary{Name: k, CacheType: c.Configuration().CacheType, Listable: ok})
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	writeJSON(w, l)
+}
+
+// listObjects responds with a page of the objects in the requested cache. The page is selected
+// with the offset and limit query parameters, after sorting by the sort parameter, which is one of
+// key (the default), size, age or access, in the order of the order parameter, asc or desc
+func (cb *cacheBrowser) listObjects(w http.ResponseWriter, r *http.Request) {
+
+	name := mux.Vars(r)["cache"]
+	c, ok := cb.caches[name]
+	if !ok {
+		http.Error(w, "unknown cache: "+name, http.StatusNotFound)
+		return
+	}
+
+	l, ok := c.(cache.ObjectLister)
+	if !ok {
+		http.Error(w, cache.ErrListUnsupported.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	qp := r.URL.Query()
+
+	offset, err := intParam(qp.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "invalid offset: "+qp.Get("offset"), http.StatusBadRequest)
+		return
+	}
+
+	limit, err := intParam(qp.Get("limit"), defaultCacheBrowserLimit)
+	if err != nil || limit < 1 {
+		http.Error(w, "invalid limit: "+qp.Get("limit"), http.StatusBadRequest)
+		return
+	}
+	if limit > maxCacheBrowserLimit {
+		limit = maxCacheBrowserLimit
+	}
+
+	objects, err := l.ListObjects()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if err = sortObjects(objects, qp.Get("sort"), qp.Get("order")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ol := &cacheObjectList{Cache: name, CacheType: c.Configuration().CacheType,
+		Total: len(objects), Offset: offset, Limit: limit, Objects: []*cacheObjectEntry{}}
+
+	if offset < len(objects) {
+		end := offset + limit
+		if end > len(objects) {
+			end = len(objects)
+		}
+		prefixes := cb.cacheKeyPrefixes(name)
+		for _, o := range objects[offset:end] {
+			ol.Objects = append(ol.Objects, cb.objectEntry(c, o, prefixes))
+		}
+	}
+
+	writeJSON(w, ol)
+}
+
+// objectEntry returns the metadata of the object, along with its origin and, for Delta
+// Proxy Cache objects, a description of the cached timeseries. For the variant index of an
+// Object Proxy Cache object whose responses Vary, it includes the number of cached variants
+func (cb *cacheBrowser) objectEntry(c cache.Cache, o cache.ObjectMetadata,
+	prefixes map[string]origins.Client) *cacheObjectEntry {
+
+	e := &cacheObjectEntry{Key: o.Key, Size: o.Size}
+	if !o.Expiration.IsZero() {
+		e.Expiration = &o.Expiration
+	}
+	if !o.LastWrite.IsZero() {
+		e.LastWrite = &o.LastWrite
+	}
+	if !o.LastAccess.IsZero() {
+		e.LastAccess = &o.LastAccess
+	}
+
+	// the origin is the one with the longest cache key prefix matching the key
+	var client origins.Client
+	var pl int
+	for p, oc := range prefixes {
+		if len(p) > pl && strings.HasPrefix(o.Key, p) {
+			client = oc
+			pl = len(p)
+		}
+	}
+	if client == nil {
+		return e
+	}
+	e.Origin = client.Name()
+
+	if tsc, ok := client.(origins.TimeseriesClient); ok {
+		if info, err := engines.InspectCachedTimeseries(c, o.Key, tsc); err == nil && info != nil {
+			e.Timeseries = &cacheTimeseriesDetail{
+				StepSecs:    info.Step.Seconds(),
+				Extents:     info.Extents,
+				SeriesCount: info.SeriesCount,
+				ValueCount:  info.ValueCount,
+			}
+			return e
+		}
+	}
+
+	if info, err := engines.InspectCachedVariants(c, o.Key); err == nil && info != nil {
+		e.Variants = &cacheVariantsDetail{VaryHeaders: info.VaryHeaders, Count: info.VariantCount}
+	}
+
+	return e
+}
+
+// cacheKeyPrefixes maps the cache key prefix of each origin that caches objects in the named cache,
+// either directly or as a tier of a tiered cache, to the origin's client
+func (cb *cacheBrowser) cacheKeyPrefixes(cacheName string) map[string]origins.Client {
+	prefixes := make(map[string]origins.Client)
+	for _, client := range cb.clients {
+		oc := client.Configuration()
+		if oc == nil {
+			continue
+		}
+		if oc.CacheName != cacheName {
+			cc, ok := config.Caches[oc.CacheName]
+			if !ok || cc.CacheType != "tiered" ||
+				(cc.Tiered.L1CacheName != cacheName && cc.Tiered.L2CacheName != cacheName) {
+				continue
+			}
+		}
+		prefixes[oc.CacheKeyPrefix+"."] = client
+	}
+	return prefixes
+}
+
+// sortObjects sorts the objects by the named field, in the provided order. Objects with equal
+// values are sorted by key, so that pages are consistent across requests
+func sortObjects(objects []cache.ObjectMetadata, field, order string) error {
+
+	var less func(i, j int) bool
+	desc := true
+
+	switch field {
+	case "", "key":
+		desc = false
+	case "size":
+		less = func(i, j int) bool { return objects[i].Size < objects[j].Size }
+	case "age":
+		less = func(i, j int) bool { return objects[i].LastWrite.After(objects[j].LastWrite) }
+	case "access":
+		less = func(i, j int) bool { return objects[i].LastAccess.Before(objects[j].LastAccess) }
+	default:
+		return fmt.Errorf("invalid sort: %s", field)
+	}
+
+	switch order {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return fmt.Errorf("invalid order: %s", order)
+	}
+
+	if desc && less == nil {
+		sort.Slice(objects, func(i, j int) bool { return objects[i].Key > objects[j].Key })
+		return nil
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	if less == nil {
+		return nil
+	}
+	if desc {
+		sort.SliceStable(objects, func(i, j int) bool { return less(j, i) })
+	} else {
+		sort.SliceStable(objects, less)
+	}
+	return nil
+}
+
+func intParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(headers.NameContentType, headers.ValueApplicationJSON)
+	w.Header().Set(headers.NameCacheControl, headers.ValueNoCache)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
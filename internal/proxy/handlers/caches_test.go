@@ -0,0 +1,325 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/engines"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/proxy/origins/prometheus"
+	"github.com/Comcast/trickster/internal/util/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+func init() {
+	metrics.Init()
+}
+
+const testMatrixBody = `{"status":"success","data":{"resultType":"matrix","result":[` +
+	`{"metric":{"__name__":"a"},"values":[[1577836800,"1"],[1577836860,"2"]]},` +
+	`{"metric":{"__name__":"b"},"values":[[1577836800,"3"]]}]},` +
+	`"extents":[{"start":"2020-01-01T00:00:00Z","end":"2020-01-01T00:01:00Z"}],"step":60000000000}`
+
+func newTestCacheBrowser(t *testing.T) (*cacheBrowser, *memory.Cache) {
+
+	err := config.Load("trickster-test", "test", []string{"-origin-url", "http://1.2.3.4", "-origin-type", "prometheus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := &memory.Cache{Name: "default", Config: config.Caches["default"]}
+	if err = mc.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	oc := config.Origins["default"]
+	oc.CacheKeyPrefix = "test"
+	client, err := prometheus.NewClient("default", oc, mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := func(key, body string) {
+		resp := &http.Response{StatusCode: 200, Header: make(http.Header)}
+		d := engines.DocumentFromHTTPResponse(resp, []byte(body), nil)
+		if err := engines.WriteCache(mc, key, d, time.Minute, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store("test.dpc", testMatrixBody)
+	store("test.opc", "object body")
+	store("other.key", "a longer object body")
+
+	return &cacheBrowser{
+		caches:  map[string]cache.Cache{"default": mc, "unlistable": &unlistableCache{mc}},
+		clients: map[string]origins.Client{"default": client},
+	}, mc
+}
+
+// unlistableCache hides the ListObjects method of the cache it wraps
+type unlistableCache struct {
+	cache.Cache
+}
+
+func listObjects(t *testing.T, cb *cacheBrowser, cacheName, query string) (*http.Response, *cacheObjectList) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://0/trickster/caches/"+cacheName+query, nil)
+	r = mux.SetURLVars(r, map[string]string{"cache": cacheName})
+	cb.listObjects(w, r)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ol := &cacheObjectList{}
+	if err = json.Unmarshal(b, ol); err != nil {
+		t.Fatal(err)
+	}
+	return resp, ol
+}
+
+func TestRegisterCacheBrowserHandler(t *testing.T) {
+	config.Load("trickster-test", "test", []string{"-origin-url", "http://1.2.3.4", "-origin-type", "prometheus"})
+	config.Main.CacheBrowserHandlerPath = "/trickster/caches"
+	RegisterCacheBrowserHandler(map[string]cache.Cache{}, map[string]origins.Client{})
+	config.Main.CacheBrowserHandlerPath = ""
+}
+
+func TestCacheBrowserListCaches(t *testing.T) {
+
+	cb, _ := newTestCacheBrowser(t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://0/trickster/caches", nil)
+	cb.listCaches(w, r)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	b, _ := ioutil.ReadAll(resp.Body)
+	var l []cacheSummary
+	if err := json.Unmarshal(b, &l); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(l) != 2 {
+		t.Fatalf("expected %d got %d", 2, len(l))
+	}
+
+	if l[0].Name != "default" || l[0].CacheType != "memory" || !l[0].Listable {
+		t.Errorf("unexpected cache summary %v", l[0])
+	}
+
+	if l[1].Name != "unlistable" || l[1].Listable {
+		t.Errorf("unexpected cache summary %v", l[1])
+	}
+}
+
+func TestCacheBrowserListObjects(t *testing.T) {
+
+	cb, _ := newTestCacheBrowser(t)
+
+	_, ol := listObjects(t, cb, "default", "")
+	if ol == nil {
+		t.Fatal("expected object list")
+	}
+
+	if ol.Total != 3 || len(ol.Objects) != 3 {
+		t.Fatalf("expected %d objects got %d", 3, len(ol.Objects))
+	}
+
+	// sorted by key by default
+	if ol.Objects[0].Key != "other.key" || ol.Objects[1].Key != "test.dpc" || ol.Objects[2].Key != "test.opc" {
+		t.Errorf("unexpected order %s, %s, %s", ol.Objects[0].Key, ol.Objects[1].Key, ol.Objects[2].Key)
+	}
+
+	o := ol.Objects[0]
+	if o.Origin != "" {
+		t.Errorf("expected no origin got %s", o.Origin)
+	}
+	if o.Size == 0 || o.Expiration == nil || o.LastWrite == nil || o.LastAccess == nil {
+		t.Errorf("expected object metadata got %v", o)
+	}
+
+	o = ol.Objects[1]
+	if o.Origin != "default" {
+		t.Errorf("expected origin %s got %s", "default", o.Origin)
+	}
+	if o.Timeseries == nil {
+		t.Fatal("expected timeseries detail")
+	}
+	if o.Timeseries.StepSecs != 60 {
+		t.Errorf("expected step %d got %f", 60, o.Timeseries.StepSecs)
+	}
+	if o.Timeseries.SeriesCount != 2 {
+		t.Errorf("expected series count %d got %d", 2, o.Timeseries.SeriesCount)
+	}
+	if o.Timeseries.ValueCount != 3 {
+		t.Errorf("expected value count %d got %d", 3, o.Timeseries.ValueCount)
+	}
+	if len(o.Timeseries.Extents) != 1 || o.Timeseries.Extents[0].Start.Unix() != 1577836800 {
+		t.Errorf("unexpected extents %s", o.Timeseries.Extents)
+	}
+
+	o = ol.Objects[2]
+	if o.Origin != "default" {
+		t.Errorf("expected origin %s got %s", "default", o.Origin)
+	}
+	if o.Timeseries != nil {
+		t.Errorf("expected no timeseries detail")
+	}
+}
+
+func TestCacheBrowserListObjectsVariants(t *testing.T) {
+
+	cb, mc := newTestCacheBrowser(t)
+
+	exp := time.Now().Add(time.Minute).Unix()
+	idx := &engines.HTTPDocument{VaryHeaders: []string{"Accept"}, Variants: map[string]int64{"a": exp, "b": exp}}
+	if err := engines.WriteCache(mc, "test.vary", idx, time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, ol := listObjects(t, cb, "default", "")
+	if ol == nil {
+		t.Fatal("expected object list")
+	}
+
+	for _, o := range ol.Objects {
+		if o.Key != "test.vary" {
+			if o.Variants != nil {
+				t.Errorf("expected no variants detail for %s", o.Key)
+			}
+			continue
+		}
+		if o.Variants == nil {
+			t.Fatal("expected variants detail")
+		}
+		if o.Variants.Count != 2 {
+			t.Errorf("expected variant count %d got %d", 2, o.Variants.Count)
+		}
+		if len(o.Variants.VaryHeaders) != 1 || o.Variants.VaryHeaders[0] != "Accept" {
+			t.Errorf("unexpected vary headers %v", o.Variants.VaryHeaders)
+		}
+		return
+	}
+	t.Error("expected variant index in object list")
+}
+
+func TestCacheBrowserListObjectsPaging(t *testing.T) {
+
+	cb, _ := newTestCacheBrowser(t)
+
+	_, ol := listObjects(t, cb, "default", "?sort=key&order=desc&offset=1&limit=1")
+	if ol == nil {
+		t.Fatal("expected object list")
+	}
+	if ol.Total != 3 || ol.Offset != 1 || ol.Limit != 1 || len(ol.Objects) != 1 {
+		t.Fatalf("unexpected page %d/%d/%d/%d", ol.Total, ol.Offset, ol.Limit, len(ol.Objects))
+	}
+	if ol.Objects[0].Key != "test.dpc" {
+		t.Errorf("expected %s got %s", "test.dpc", ol.Objects[0].Key)
+	}
+
+	_, ol = listObjects(t, cb, "default", "?offset=5&limit=5000")
+	if ol == nil {
+		t.Fatal("expected object list")
+	}
+	if len(ol.Objects) != 0 || ol.Limit != maxCacheBrowserLimit {
+		t.Errorf("unexpected page %d/%d", ol.Limit, len(ol.Objects))
+	}
+}
+
+func TestCacheBrowserListObjectsErrors(t *testing.T) {
+
+	cb, _ := newTestCacheBrowser(t)
+
+	tests := []struct {
+		cacheName string
+		query     string
+		expected  int
+	}{
+		{"missing", "", http.StatusNotFound},
+		{"unlistable", "", http.StatusNotImplemented},
+		{"default", "?offset=-1", http.StatusBadRequest},
+		{"default", "?offset=a", http.StatusBadRequest},
+		{"default", "?limit=0", http.StatusBadRequest},
+		{"default", "?sort=color", http.StatusBadRequest},
+		{"default", "?order=sideways", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cacheName+test.query, func(t *testing.T) {
+			resp, _ := listObjects(t, cb, test.cacheName, test.query)
+			if resp.StatusCode != test.expected {
+				t.Errorf("expected %d got %d", test.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestSortObjects(t *testing.T) {
+
+	now := time.Now()
+	objects := []cache.ObjectMetadata{
+		{Key: "a", Size: 2, LastWrite: now.Add(-time.Minute), LastAccess: now},
+		{Key: "b", Size: 3, LastWrite: now, LastAccess: now.Add(-time.Hour)},
+		{Key: "c", Size: 2, LastWrite: now.Add(-time.Hour), LastAccess: now.Add(-time.Minute)},
+	}
+
+	tests := []struct {
+		field    string
+		order    string
+		expected string
+	}{
+		{"", "", "abc"},
+		{"key", "desc", "cba"},
+		{"size", "", "bac"},
+		{"size", "asc", "acb"},
+		{"age", "", "cab"},
+		{"age", "asc", "bac"},
+		{"access", "", "acb"},
+		{"access", "asc", "bca"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.field+"."+test.order, func(t *testing.T) {
+			if err := sortObjects(objects, test.field, test.order); err != nil {
+				t.Fatal(err)
+			}
+			var keys string
+			for _, o := range objects {
+				keys += o.Key
+			}
+			if keys != test.expected {
+				t.Errorf("expected %s got %s", test.expected, keys)
+			}
+		})
+	}
+}
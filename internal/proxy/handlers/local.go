@@ -28,7 +28,7 @@ func HandleLocalResponse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if len(p.ResponseHeaders) > 0 {
-		headers.UpdateHeaders(w.Header(), p.ResponseHeaders)
+		headers.UpdateHeaders(w.Header(), headers.ExpandPathVars(p.ResponseHeaders, rsc.PathVars))
 	}
 	if p.ResponseCode > 0 {
 		w.WriteHeader(p.ResponseCode)
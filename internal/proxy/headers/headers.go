@@ -73,8 +73,12 @@ const (
 	NameContentRange = "Content-Range"
 	// NameTricksterResult represents the HTTP Header Name of "X-Trickster-Result"
 	NameTricksterResult = "X-Trickster-Result"
+	// NameCircuitBreaker represents the HTTP Header Name of "X-Trickster-Circuit-Breaker"
+	NameCircuitBreaker = "X-Trickster-Circuit-Breaker"
 	// NameVia represents the HTTP Header Name of "Via"
 	NameVia = "Via"
+	// NameVary represents the HTTP Header Name of "Vary"
+	NameVary = "Vary"
 	// NameXForwardedFor represents the HTTP Header Name of "X-Forwarded-For"
 	NameXForwardedFor = "X-Forwarded-For"
 	// NameAcceptEncoding represents the HTTP Header Name of "Accept-Encoding"
@@ -103,6 +107,10 @@ const (
 	NameExpires = "Expires"
 	// NameETag represents the HTTP Header Name of "etag"
 	NameETag = "Etag"
+	// NameRetryAfter represents the HTTP Header Name of "Retry-After"
+	NameRetryAfter = "Retry-After"
+	// NameWWWAuthenticate represents the HTTP Header Name of "WWW-Authenticate"
+	NameWWWAuthenticate = "Www-Authenticate"
 )
 
 // Merge merges the source http.Header map into destination map.
@@ -143,6 +151,25 @@ func UpdateHeaders(headers http.Header, updates map[string]string) {
 	}
 }
 
+// ExpandPathVars returns a copy of the provided header updates, with any {name} references in
+// their values replaced by the value of the named path variable. The updates are returned as-is
+// when there are no path variables
+func ExpandPathVars(updates map[string]string, vars map[string]string) map[string]string {
+	if len(updates) == 0 || len(vars) == 0 {
+		return updates
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	r := strings.NewReplacer(pairs...)
+	expanded := make(map[string]string, len(updates))
+	for k, v := range updates {
+		expanded[k] = r.Replace(v)
+	}
+	return expanded
+}
+
 // AddProxyHeaders injects standard Trickster headers into proxied upstream HTTP requests
 func AddProxyHeaders(remoteAddr string, headers http.Header) {
 	if remoteAddr != "" {
@@ -95,6 +95,25 @@ func TestUpdateHeaders(t *testing.T) {
 
 }
 
+func TestExpandPathVars(t *testing.T) {
+
+	updates := map[string]string{"X-Tenant": "{tenant}", "X-Scope": "{tenant}/{name}", "X-Literal": "{missing}"}
+
+	if u := ExpandPathVars(updates, nil); !reflect.DeepEqual(u, updates) {
+		t.Errorf("expected %v got %v", updates, u)
+	}
+
+	expected := map[string]string{"X-Tenant": "acme", "X-Scope": "acme/up", "X-Literal": "{missing}"}
+	u := ExpandPathVars(updates, map[string]string{"tenant": "acme", "name": "up"})
+	if !reflect.DeepEqual(u, expected) {
+		t.Errorf("expected %v got %v", expected, u)
+	}
+
+	if updates["X-Tenant"] != "{tenant}" {
+		t.Errorf("expected the updates to be unmodified, got %v", updates)
+	}
+}
+
 func TestRemoveClientHeaders(t *testing.T) {
 
 	headers := http.Header{}
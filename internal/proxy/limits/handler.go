@@ -0,0 +1,90 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package limits
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/headers"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+// concurrencyRetryAfter is the Retry-After duration of requests rejected by a concurrency limit
+const concurrencyRetryAfter = time.Second
+
+// Handler returns an HTTP Handler that passes each request to next, unless it exceeds any of
+// the Limiters, in which case it is rejected with a 429 Too Many Requests response
+func Handler(limiters []*Limiter, originName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var path string
+		if rsc := request.GetResources(r); rsc != nil && rsc.PathConfig != nil {
+			path = rsc.PathConfig.Path
+		}
+
+		// a request dispatched to another path by a rule is not limited again by the same Limit
+		previous := tctx.AppliedLimits(r.Context())
+		applied := make(map[string]bool, len(previous)+len(limiters))
+		for k := range previous {
+			applied[k] = true
+		}
+
+		acquired := make([]*Limiter, 0, len(limiters))
+		keys := make([]string, 0, len(limiters))
+		release := func() {
+			for i, l := range acquired {
+				l.release(keys[i])
+			}
+		}
+
+		for _, l := range limiters {
+			if applied[l.Name] {
+				continue
+			}
+			key := l.key(r, originName, path)
+			if d := l.allow(key); d > 0 {
+				release()
+				reject(w, originName, l.Name, "rate", d)
+				return
+			}
+			if !l.acquire(key) {
+				release()
+				reject(w, originName, l.Name, "concurrency", concurrencyRetryAfter)
+				return
+			}
+			acquired = append(acquired, l)
+			keys = append(keys, key)
+			applied[l.Name] = true
+		}
+
+		defer release()
+		if len(acquired) > 0 {
+			r = r.WithContext(tctx.WithAppliedLimits(r.Context(), applied))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func reject(w http.ResponseWriter, originName, limitName, reason string, retryAfter time.Duration) {
+	log.Debug("request rejected by limit", log.Pairs{"originName": originName, "limitName": limitName, "reason": reason})
+	metrics.FrontendRejectedRequests.WithLabelValues(originName, limitName, reason).Inc()
+	w.Header().Set(headers.NameRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+}
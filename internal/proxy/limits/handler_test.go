@@ -0,0 +1,87 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package limits
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+func TestHandler(t *testing.T) {
+
+	rate := New("rate", &config.LimitConfig{KeyName: "header", KeyHeader: "X-Tenant", RequestsPerSec: 0.5, Burst: 1})
+	concurrency := New("concurrency", &config.LimitConfig{KeyName: "origin", MaxConcurrent: 1})
+
+	var inner http.Handler
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inner != nil {
+			inner.ServeHTTP(w, r)
+		}
+	})
+	h := Handler([]*Limiter{concurrency, rate}, "test", next)
+
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.Header.Set("X-Tenant", "acme")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected %d got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if v := w.Header().Get("Retry-After"); v != "2" {
+		t.Errorf("expected %s got %s", "2", v)
+	}
+
+	// the concurrency slot was released when the rate limit rejected the request
+	if len(concurrency.inflight) != 0 {
+		t.Errorf("expected %d got %d", 0, len(concurrency.inflight))
+	}
+
+	// a request in flight causes the next request to exceed the concurrency limit
+	r.Header.Set("X-Tenant", "other")
+	inner = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w2 := httptest.NewRecorder()
+		h.ServeHTTP(w2, r.WithContext(tctx.WithAppliedLimits(r.Context(), nil)))
+		if w2.Code != http.StatusTooManyRequests || w2.Header().Get("Retry-After") != "1" {
+			t.Errorf("expected %d got %d", http.StatusTooManyRequests, w2.Code)
+		}
+		// but a request that the limits were already applied to is not limited again
+		w3 := httptest.NewRecorder()
+		inner = nil
+		h.ServeHTTP(w3, r)
+		if w3.Code != http.StatusOK {
+			t.Errorf("expected %d got %d", http.StatusOK, w3.Code)
+		}
+	})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, w.Code)
+	}
+}
@@ -0,0 +1,159 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package limits provides the rate and concurrency limits applied to frontend requests,
+// which are tracked separately for each client, header value, origin or path
+package limits
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// sweepInterval is how often the rate limit buckets that have been idle long enough to refill are removed
+const sweepInterval = time.Minute
+
+// now is the source of the current time used by the rate limits, which can be replaced in tests
+var now = time.Now
+
+// Limiter enforces a Limit's rate and concurrency limits, for each value of its key
+type Limiter struct {
+	// Name is the name of the Limit in the configuration
+	Name string
+
+	cfg       *config.LimitConfig
+	mtx       sync.Mutex
+	buckets   map[string]*bucket
+	inflight  map[string]int
+	lastSweep time.Time
+}
+
+// bucket is a token bucket, which is refilled at the Limit's rate, up to its burst
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a new Limiter for the provided Limit configuration
+func New(name string, cfg *config.LimitConfig) *Limiter {
+	return &Limiter{
+		Name:      name,
+		cfg:       cfg,
+		buckets:   make(map[string]*bucket),
+		inflight:  make(map[string]int),
+		lastSweep: now(),
+	}
+}
+
+// key returns the value by which the request is tracked by the Limiter
+func (l *Limiter) key(r *http.Request, originName, path string) string {
+	switch l.cfg.KeyName {
+	case "client_ip":
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			return host
+		}
+		return r.RemoteAddr
+	case "header":
+		return r.Header.Get(l.cfg.KeyHeader)
+	case "origin":
+		return originName
+	}
+	return originName + path
+}
+
+// allow takes a token from the key's bucket, and returns 0 if the request is permitted by the
+// rate limit, or otherwise the time until a token will be available
+func (l *Limiter) allow(key string) time.Duration {
+
+	if l.cfg.RequestsPerSec <= 0 {
+		return 0
+	}
+
+	burst := float64(l.cfg.Burst)
+	t := now()
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.sweep(t)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: t}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(burst, b.tokens+t.Sub(b.last).Seconds()*l.cfg.RequestsPerSec)
+		b.last = t
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / l.cfg.RequestsPerSec * float64(time.Second))
+}
+
+// sweep removes the buckets that would be full by now, since they are no different from new buckets
+func (l *Limiter) sweep(t time.Time) {
+	if t.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = t
+	burst := float64(l.cfg.Burst)
+	for k, b := range l.buckets {
+		if b.tokens+t.Sub(b.last).Seconds()*l.cfg.RequestsPerSec >= burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// acquire returns true if the request is permitted by the concurrency limit, in which case
+// it is counted as in flight until it is released
+func (l *Limiter) acquire(key string) bool {
+
+	if l.cfg.MaxConcurrent <= 0 {
+		return true
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.inflight[key] >= l.cfg.MaxConcurrent {
+		return false
+	}
+	l.inflight[key]++
+	return true
+}
+
+// release removes an acquired request from the key's requests in flight
+func (l *Limiter) release(key string) {
+
+	if l.cfg.MaxConcurrent <= 0 {
+		return
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.inflight[key] <= 1 {
+		delete(l.inflight, key)
+		return
+	}
+	l.inflight[key]--
+}
@@ -0,0 +1,114 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package limits
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+func TestAllow(t *testing.T) {
+
+	t0 := time.Unix(1577836800, 0)
+	clock := t0
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	l := New("test", &config.LimitConfig{KeyName: "client_ip", RequestsPerSec: 2, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		if d := l.allow("a"); d != 0 {
+			t.Errorf("expected request %d to be allowed, got %s", i, d)
+		}
+	}
+	if d := l.allow("a"); d != 500*time.Millisecond {
+		t.Errorf("expected %s got %s", 500*time.Millisecond, d)
+	}
+
+	// each key has its own bucket
+	if d := l.allow("b"); d != 0 {
+		t.Errorf("expected request to be allowed, got %s", d)
+	}
+
+	clock = clock.Add(500 * time.Millisecond)
+	if d := l.allow("a"); d != 0 {
+		t.Errorf("expected request to be allowed, got %s", d)
+	}
+
+	// buckets that have refilled are removed by the next sweep
+	clock = clock.Add(sweepInterval)
+	l.allow("c")
+	if len(l.buckets) != 1 {
+		t.Errorf("expected %d got %d", 1, len(l.buckets))
+	}
+
+	l = New("test", &config.LimitConfig{KeyName: "client_ip", MaxConcurrent: 1})
+	if d := l.allow("a"); d != 0 {
+		t.Errorf("expected request to be allowed, got %s", d)
+	}
+}
+
+func TestAcquire(t *testing.T) {
+
+	l := New("test", &config.LimitConfig{KeyName: "origin", MaxConcurrent: 2})
+	if !l.acquire("a") || !l.acquire("a") || !l.acquire("b") {
+		t.Errorf("expected requests to be acquired")
+	}
+	if l.acquire("a") {
+		t.Errorf("expected request to be rejected")
+	}
+	l.release("a")
+	if !l.acquire("a") {
+		t.Errorf("expected request to be acquired")
+	}
+	l.release("a")
+	l.release("a")
+	l.release("b")
+	if len(l.inflight) != 0 {
+		t.Errorf("expected %d got %d", 0, len(l.inflight))
+	}
+
+	l = New("test", &config.LimitConfig{KeyName: "origin", RequestsPerSec: 1})
+	if !l.acquire("a") || !l.acquire("a") {
+		t.Errorf("expected requests to be acquired")
+	}
+}
+
+func TestKey(t *testing.T) {
+
+	r := httptest.NewRequest(http.MethodGet, "http://0/", nil)
+	r.RemoteAddr = "10.0.0.1:12345"
+	r.Header.Set("X-Tenant", "acme")
+
+	tests := []struct {
+		cfg      config.LimitConfig
+		expected string
+	}{
+		{config.LimitConfig{KeyName: "client_ip"}, "10.0.0.1"},
+		{config.LimitConfig{KeyName: "header", KeyHeader: "X-Tenant"}, "acme"},
+		{config.LimitConfig{KeyName: "origin"}, "test"},
+		{config.LimitConfig{KeyName: "path"}, "test/api/v1/query"},
+	}
+
+	for i, test := range tests {
+		l := New("test", &test.cfg)
+		if k := l.key(r, "test", "/api/v1/query"); k != test.expected {
+			t.Errorf("test %d: expected %s got %s", i, test.expected, k)
+		}
+	}
+}
@@ -33,10 +33,11 @@ const (
 	tkTimestamp2 = "<$TIMESTAMP2$>"
 )
 
-var reTimeFieldAndStep, reTimeClauseAlt *regexp.Regexp
+var reTimeFieldAndStep, reTimeClauseAlt, reStepClause *regexp.Regexp
 
 func init() {
 	reTimeFieldAndStep = regexp.MustCompile(`(?i)select\s+\(\s*intdiv\s*\(\s*touint32\s*\(\s*(?P<timeField>[a-zA-Z0-9\._-]+)\s*\)\s*,\s*(?P<step>[0-9]+)\s*\)\s*\*\s*[0-9]+\s*\)`)
+	reStepClause = regexp.MustCompile(`(?i)(intdiv\s*\(\s*touint32\s*\(\s*[a-zA-Z0-9\._-]+\s*\)\s*,\s*)[0-9]+(\s*\)\s*\*\s*)[0-9]+`)
 	reTimeClauseAlt = regexp.MustCompile(`(?i)\s+(?P<expression>(?P<operator>>=|>|=|between)\s+(?P<modifier>toDate(Time)?)\((?P<ts1>[0-9]+)\)(?P<timeExpr2>\s+and\s+toDate(Time)?\((?P<ts2>[0-9]+)\))?)`)
 }
 
@@ -44,6 +45,12 @@ func interpolateTimeQuery(template, timeField string, extent *timeseries.Extent)
 	return strings.Replace(strings.Replace(template, tkTimestamp1, strconv.Itoa(int(extent.Start.Unix())), -1), tkTimestamp2, strconv.Itoa(int(extent.End.Unix())), -1)
 }
 
+// setQueryStep replaces the bucket size of the query's intDiv() time field clause with the provided step
+func setQueryStep(query string, step time.Duration) string {
+	secs := strconv.FormatInt(int64(step/time.Second), 10)
+	return reStepClause.ReplaceAllString(query, "${1}"+secs+"${2}"+secs)
+}
+
 var compiledRe = make(map[string]*regexp.Regexp)
 
 const timeClauseRe = `(?i)(?P<conjunction>where|and)\s+#TIME_FIELD#\s+(?P<timeExpr1>(?P<operator>>=|>|=|between)\s+(?P<modifier>toDate(Time)?)\((?P<ts1>[0-9]+)\))(?P<timeExpr2>\s+and\s+toDate(Time)?\((?P<ts2>[0-9]+)\))?`
@@ -15,6 +15,7 @@ package clickhouse
 
 import (
 	"testing"
+	"time"
 )
 
 func TestGetQueryPartsFailure(t *testing.T) {
@@ -54,3 +55,19 @@ func TestParseQueryExtents(t *testing.T) {
 	}
 
 }
+
+func TestSetQueryStep(t *testing.T) {
+
+	query := "SELECT (intDiv(toUInt32(t), 60) * 60) * 1000 AS t, count() FROM m WHERE t BETWEEN toDateTime(<$TIMESTAMP1$>) AND toDateTime(<$TIMESTAMP2$>) GROUP BY t"
+	expected := "SELECT (intDiv(toUInt32(t), 300) * 300) * 1000 AS t, count() FROM m WHERE t BETWEEN toDateTime(<$TIMESTAMP1$>) AND toDateTime(<$TIMESTAMP2$>) GROUP BY t"
+
+	if out := setQueryStep(query, time.Duration(300)*time.Second); out != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+
+	query = "SELECT count() FROM m"
+	if out := setQueryStep(query, time.Minute); out != query {
+		t.Errorf("expected %s, got %s", query, out)
+	}
+
+}
@@ -17,6 +17,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/Comcast/trickster/internal/timeseries"
 )
@@ -68,3 +69,29 @@ func (c *Client) SetExtent(r *http.Request, trq *timeseries.TimeRangeQuery, exte
 
 	r.URL.RawQuery = p.Encode()
 }
+
+// SetStep will change the upstream request query, and the TimeRangeQuery's Statement and TemplateURL,
+// to bucket timestamps by the provided step
+func (c *Client) SetStep(r *http.Request, trq *timeseries.TimeRangeQuery, step time.Duration) {
+
+	if r == nil || trq == nil {
+		return
+	}
+
+	trq.Statement = setQueryStep(trq.Statement, step)
+	trq.Step = step
+
+	if trq.TemplateURL != nil {
+		t := trq.TemplateURL.Query()
+		if q := t.Get(upQuery); q != "" {
+			t.Set(upQuery, setQueryStep(q, step))
+			trq.TemplateURL.RawQuery = t.Encode()
+		}
+	}
+
+	p := r.URL.Query()
+	if q := p.Get(upQuery); q != "" {
+		p.Set(upQuery, setQueryStep(q, step))
+		r.URL.RawQuery = p.Encode()
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/urls"
 	"github.com/Comcast/trickster/internal/timeseries"
 )
 
@@ -50,6 +51,38 @@ func TestSetExtent(t *testing.T) {
 
 }
 
+func TestSetStepParam(t *testing.T) {
+
+	client := &Client{}
+
+	tu := &url.URL{RawQuery: "query=select (intdiv(touint32(myTimeField), 60) * 60) * where myTimeField BETWEEN toDateTime(<$TIMESTAMP1$>) AND toDateTime(<$TIMESTAMP2$>) end"}
+	r, _ := http.NewRequest(http.MethodGet, tu.String(), nil)
+	trq := &timeseries.TimeRangeQuery{TimestampFieldName: "myTimeField", TemplateURL: urls.Clone(tu),
+		Statement: tu.Query().Get(upQuery), Step: time.Minute}
+
+	client.SetStep(r, trq, time.Duration(300)*time.Second)
+
+	if trq.Step != time.Duration(300)*time.Second {
+		t.Errorf("expected %s got %s", "5m0s", trq.Step)
+	}
+
+	expected := "select (intdiv(touint32(myTimeField), 300) * 300) * where myTimeField BETWEEN toDateTime(<$TIMESTAMP1$>) AND toDateTime(<$TIMESTAMP2$>) end"
+	if trq.Statement != expected {
+		t.Errorf("expected %s got %s", expected, trq.Statement)
+	}
+
+	if v := trq.TemplateURL.Query().Get(upQuery); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+
+	if v := r.URL.Query().Get(upQuery); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+
+	// nil requests are ignored
+	client.SetStep(nil, trq, time.Minute)
+}
+
 func TestBuildUpstreamURL(t *testing.T) {
 
 	cfg := config.NewConfig()
@@ -52,6 +52,28 @@ func interpolateTimeQuery(template string, extent *timeseries.Extent) string {
 	return strings.Replace(template, tkTime, fmt.Sprintf("time >= %dms AND time <= %dms", extent.Start.Unix()*1000, extent.End.Unix()*1000), -1)
 }
 
+// setQueryStep replaces the duration in the query's group by time() clause with the provided step
+func setQueryStep(query string, step time.Duration) string {
+	m := reStep.FindStringSubmatchIndex(query)
+	if len(m) < 4 || m[2] < 0 {
+		return query
+	}
+	return query[:m[2]] + formatStep(step) + query[m[3]:]
+}
+
+// formatStep returns the provided step as an InfluxQL duration literal in its largest whole unit
+func formatStep(step time.Duration) string {
+	switch {
+	case step%time.Hour == 0:
+		return fmt.Sprintf("%dh", step/time.Hour)
+	case step%time.Minute == 0:
+		return fmt.Sprintf("%dm", step/time.Minute)
+	case step%time.Second == 0:
+		return fmt.Sprintf("%ds", step/time.Second)
+	}
+	return fmt.Sprintf("%dms", step/time.Millisecond)
+}
+
 func getQueryParts(query string) (string, timeseries.Extent) {
 	m := matching.GetNamedMatches(reTime1, query, nil)
 	if _, ok := m["now1"]; !ok {
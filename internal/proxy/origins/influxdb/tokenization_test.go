@@ -63,3 +63,29 @@ func TestTimeFromParts(t *testing.T) {
 	}
 
 }
+
+func TestSetQueryStep(t *testing.T) {
+
+	tests := []struct {
+		query    string
+		step     time.Duration
+		expected string
+	}{
+		{"select mean(v) from m where <$TIME_TOKEN$> group by time(14s)", time.Duration(15) * time.Second,
+			"select mean(v) from m where <$TIME_TOKEN$> group by time(15s)"},
+		{"select mean(v) from m where <$TIME_TOKEN$> GROUP BY host, time(1m) fill(null)", time.Duration(300) * time.Second,
+			"select mean(v) from m where <$TIME_TOKEN$> GROUP BY host, time(5m) fill(null)"},
+		{"select mean(v) from m group by time(1m)", time.Duration(2) * time.Hour,
+			"select mean(v) from m group by time(2h)"},
+		{"select mean(v) from m group by time(1s)", time.Duration(1500) * time.Millisecond,
+			"select mean(v) from m group by time(1500ms)"},
+		{"select v from m", time.Minute, "select v from m"},
+	}
+
+	for _, test := range tests {
+		if out := setQueryStep(test.query, test.step); out != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, out)
+		}
+	}
+
+}
@@ -50,6 +50,38 @@ func TestSetExtent(t *testing.T) {
 	}
 }
 
+func TestSetStepParam(t *testing.T) {
+
+	client := Client{}
+
+	u := &url.URL{RawQuery: "q=select+mean(v)+from+m+where+time+>%3D+now()+-+1h+group+by+time(14s)"}
+	r, _ := http.NewRequest(http.MethodGet, u.String(), nil)
+
+	tu := &url.URL{RawQuery: "q=select+mean(v)+from+m+where+<%24TIME_TOKEN%24>+group+by+time(14s)"}
+	trq := &timeseries.TimeRangeQuery{Statement: "select mean(v) from m where <$TIME_TOKEN$> group by time(14s)",
+		TemplateURL: tu, Step: time.Duration(14) * time.Second}
+
+	client.SetStep(r, trq, time.Duration(15)*time.Second)
+
+	if trq.Step != time.Duration(15)*time.Second {
+		t.Errorf("expected %s got %s", "15s", trq.Step)
+	}
+
+	expected := "select mean(v) from m where <$TIME_TOKEN$> group by time(15s)"
+	if trq.Statement != expected {
+		t.Errorf("expected %s got %s", expected, trq.Statement)
+	}
+
+	if v := trq.TemplateURL.Query().Get(upQuery); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+
+	expected = "select mean(v) from m where time >= now() - 1h group by time(15s)"
+	if v := r.URL.Query().Get(upQuery); v != expected {
+		t.Errorf("expected %s got %s", expected, v)
+	}
+}
+
 func TestBuildUpstreamURL(t *testing.T) {
 
 	cfg := config.NewConfig()
@@ -0,0 +1,148 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"time"
+
+	"github.com/Comcast/trickster/internal/proxy/errors"
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/prometheus/common/model"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// MarshalMsg implements msgp.Marshaler, appending a compact binary encoding of the MatrixEnvelope
+// to b. Each series' timestamps are delta-encoded, so that regularly-stepped timestamps are stored
+// in only a byte or two apiece.
+func (me *MatrixEnvelope) MarshalMsg(b []byte) ([]byte, error) {
+	o := msgp.AppendArrayHeader(b, 5)
+	o = msgp.AppendString(o, me.Status)
+	o = msgp.AppendString(o, me.Data.ResultType)
+	o = msgp.AppendInt64(o, int64(me.StepDuration))
+	o, err := me.ExtentList.MarshalMsg(o)
+	if err != nil {
+		return o, err
+	}
+	o = msgp.AppendArrayHeader(o, uint32(len(me.Data.Result)))
+	for _, ss := range me.Data.Result {
+		o = msgp.AppendArrayHeader(o, 3)
+		o = msgp.AppendMapHeader(o, uint32(len(ss.Metric)))
+		for k, v := range ss.Metric {
+			o = msgp.AppendString(o, string(k))
+			o = msgp.AppendString(o, string(v))
+		}
+		o = msgp.AppendArrayHeader(o, uint32(len(ss.Values)))
+		var prev model.Time
+		for _, v := range ss.Values {
+			o = msgp.AppendInt64(o, int64(v.Timestamp-prev))
+			prev = v.Timestamp
+		}
+		o = msgp.AppendArrayHeader(o, uint32(len(ss.Values)))
+		for _, v := range ss.Values {
+			o = msgp.AppendFloat64(o, float64(v.Value))
+		}
+	}
+	return o, nil
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler, decoding a MatrixEnvelope encoded by MarshalMsg
+func (me *MatrixEnvelope) UnmarshalMsg(bts []byte) ([]byte, error) {
+	var err error
+	var sz uint32
+	if sz, bts, err = msgp.ReadArrayHeaderBytes(bts); err != nil {
+		return bts, err
+	}
+	if sz != 5 {
+		return bts, msgp.ArrayError{Wanted: 5, Got: sz}
+	}
+	if me.Status, bts, err = msgp.ReadStringBytes(bts); err != nil {
+		return bts, err
+	}
+	if me.Data.ResultType, bts, err = msgp.ReadStringBytes(bts); err != nil {
+		return bts, err
+	}
+	var step int64
+	if step, bts, err = msgp.ReadInt64Bytes(bts); err != nil {
+		return bts, err
+	}
+	me.StepDuration = time.Duration(step)
+	me.ExtentList = timeseries.ExtentList{}
+	if bts, err = me.ExtentList.UnmarshalMsg(bts); err != nil {
+		return bts, err
+	}
+	if sz, bts, err = msgp.ReadArrayHeaderBytes(bts); err != nil {
+		return bts, err
+	}
+	me.Data.Result = make(model.Matrix, sz)
+	for i := range me.Data.Result {
+		if bts, err = unmarshalSampleStream(bts, &me.Data.Result[i]); err != nil {
+			return bts, err
+		}
+	}
+	me.isSorted = false
+	me.isCounted = false
+	return bts, nil
+}
+
+func unmarshalSampleStream(bts []byte, ssp **model.SampleStream) ([]byte, error) {
+	var err error
+	var sz uint32
+	if sz, bts, err = msgp.ReadArrayHeaderBytes(bts); err != nil {
+		return bts, err
+	}
+	if sz != 3 {
+		return bts, msgp.ArrayError{Wanted: 3, Got: sz}
+	}
+	if sz, bts, err = msgp.ReadMapHeaderBytes(bts); err != nil {
+		return bts, err
+	}
+	ss := &model.SampleStream{Metric: make(model.Metric, sz)}
+	var k, v string
+	for ; sz > 0; sz-- {
+		if k, bts, err = msgp.ReadStringBytes(bts); err != nil {
+			return bts, err
+		}
+		if v, bts, err = msgp.ReadStringBytes(bts); err != nil {
+			return bts, err
+		}
+		ss.Metric[model.LabelName(k)] = model.LabelValue(v)
+	}
+	if sz, bts, err = msgp.ReadArrayHeaderBytes(bts); err != nil {
+		return bts, err
+	}
+	ss.Values = make([]model.SamplePair, sz)
+	var prev, d int64
+	for i := range ss.Values {
+		if d, bts, err = msgp.ReadInt64Bytes(bts); err != nil {
+			return bts, err
+		}
+		prev += d
+		ss.Values[i].Timestamp = model.Time(prev)
+	}
+	if sz, bts, err = msgp.ReadArrayHeaderBytes(bts); err != nil {
+		return bts, err
+	}
+	if int(sz) != len(ss.Values) {
+		return bts, errors.ErrInvalidTimeseriesEncoding
+	}
+	var f float64
+	for i := range ss.Values {
+		if f, bts, err = msgp.ReadFloat64Bytes(bts); err != nil {
+			return bts, err
+		}
+		ss.Values[i].Value = model.SampleValue(f)
+	}
+	*ssp = ss
+	return bts, nil
+}
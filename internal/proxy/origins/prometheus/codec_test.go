@@ -0,0 +1,179 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/proxy/errors"
+	"github.com/Comcast/trickster/internal/timeseries"
+	"github.com/prometheus/common/model"
+)
+
+// unsupportedTimeseries is a Timeseries that is not a MatrixEnvelope
+type unsupportedTimeseries struct {
+	timeseries.Timeseries
+}
+
+func testCodecMatrix() *MatrixEnvelope {
+	return &MatrixEnvelope{
+		Status: "success",
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a", "instance": "host1"},
+					Values: []model.SamplePair{
+						{Timestamp: 99000, Value: 1.5},
+						{Timestamp: 199000, Value: -2},
+						{Timestamp: 299000, Value: 1e300},
+					},
+				},
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "b"},
+					Values: []model.SamplePair{},
+				},
+			},
+		},
+		StepDuration: 100 * time.Second,
+		ExtentList: timeseries.ExtentList{
+			timeseries.Extent{Start: time.Unix(99, 0), End: time.Unix(299, 0)},
+		},
+	}
+}
+
+func TestMarshalUnmarshalMsg(t *testing.T) {
+
+	me := testCodecMatrix()
+	b, err := me.MarshalMsg(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	me2 := &MatrixEnvelope{}
+	left, err := me2.UnmarshalMsg(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("expected %d got %d", 0, len(left))
+	}
+
+	if me2.StepDuration != me.StepDuration {
+		t.Errorf("expected %s got %s", me.StepDuration, me2.StepDuration)
+	}
+
+	if me2.ExtentList.String() != me.ExtentList.String() {
+		t.Errorf("expected %s got %s", me.ExtentList.String(), me2.ExtentList.String())
+	}
+
+	expected, _ := json.Marshal(me)
+	j, _ := json.Marshal(me2)
+	if string(j) != string(expected) {
+		t.Errorf("expected %s got %s", string(expected), string(j))
+	}
+
+}
+
+func TestUnmarshalMsgInvalid(t *testing.T) {
+
+	me := testCodecMatrix()
+	b, err := me.MarshalMsg(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	me2 := &MatrixEnvelope{}
+	_, err = me2.UnmarshalMsg(b[:len(b)-4])
+	if err == nil {
+		t.Errorf("expected error for truncated input")
+	}
+
+	_, err = me2.UnmarshalMsg([]byte("{}"))
+	if err == nil {
+		t.Errorf("expected error for non-binary input")
+	}
+
+}
+
+func TestMarshalTimeseriesBinary(t *testing.T) {
+
+	client := &Client{}
+
+	_, err := client.MarshalTimeseriesBinary(&unsupportedTimeseries{})
+	if err != errors.ErrUnsupportedTimeseries {
+		t.Errorf("expected %v got %v", errors.ErrUnsupportedTimeseries, err)
+	}
+
+	b, err := client.MarshalTimeseriesBinary(testCodecMatrix())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := client.UnmarshalTimeseriesBinary(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ts.SeriesCount() != 2 {
+		t.Errorf("expected %d got %d", 2, ts.SeriesCount())
+	}
+
+	if ts.ValueCount() != 3 {
+		t.Errorf("expected %d got %d", 3, ts.ValueCount())
+	}
+
+}
+
+func benchmarkCodecMatrix() *MatrixEnvelope {
+	me := &MatrixEnvelope{
+		Status:       "success",
+		Data:         MatrixData{ResultType: "matrix"},
+		StepDuration: 15 * time.Second,
+	}
+	for i := 0; i < 20; i++ {
+		ss := &model.SampleStream{
+			Metric: model.Metric{"__name__": "up", "instance": model.LabelValue(string(rune('a' + i)))},
+			Values: make([]model.SamplePair, 0, 1440),
+		}
+		for j := 0; j < 1440; j++ {
+			ss.Values = append(ss.Values, model.SamplePair{Timestamp: model.Time(j * 15000), Value: model.SampleValue(j)})
+		}
+		me.Data.Result = append(me.Data.Result, ss)
+	}
+	me.ExtentList = timeseries.ExtentList{
+		timeseries.Extent{Start: time.Unix(0, 0), End: time.Unix(1439*15, 0)},
+	}
+	return me
+}
+
+func BenchmarkUnmarshalTimeseries(b *testing.B) {
+	client := &Client{}
+	data, _ := client.MarshalTimeseries(benchmarkCodecMatrix())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client.UnmarshalTimeseries(data)
+	}
+}
+
+func BenchmarkUnmarshalTimeseriesBinary(b *testing.B) {
+	client := &Client{}
+	data, _ := client.MarshalTimeseriesBinary(benchmarkCodecMatrix())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client.UnmarshalTimeseriesBinary(data)
+	}
+}
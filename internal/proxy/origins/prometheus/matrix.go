@@ -33,6 +33,221 @@ func (me *MatrixEnvelope) SetStep(step time.Duration) {
 	me.StepDuration = step
 }
 
+// Downsample returns a new Timeseries at the provided (coarser) step, with each data point
+// derived from the subject's data points by the provided Aggregator
+func (me *MatrixEnvelope) Downsample(step time.Duration, agg timeseries.Aggregator) timeseries.Timeseries {
+
+	me.Sort()
+
+	el := me.ExtentList.Downsample(me.StepDuration, step, agg != nil)
+	resMe := &MatrixEnvelope{
+		Status: me.Status,
+		Data: MatrixData{
+			ResultType: me.Data.ResultType,
+			Result:     make(model.Matrix, 0, len(me.Data.Result)),
+		},
+		StepDuration: step,
+		ExtentList:   el,
+	}
+
+	if len(el) == 0 {
+		return resMe
+	}
+
+	included := func(t time.Time) bool {
+		for i := range el {
+			if el[i].Includes(t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, ss := range me.Data.Result {
+		values := make([]model.SamplePair, 0, len(ss.Values))
+		window := make([]float64, 0, 16)
+		var closesAt time.Time
+		flush := func() {
+			if len(window) == 0 || !included(closesAt) {
+				return
+			}
+			v := window[len(window)-1]
+			if agg != nil {
+				v = agg(window)
+			}
+			values = append(values, model.SamplePair{Timestamp: model.TimeFromUnixNano(closesAt.UnixNano()),
+				Value: model.SampleValue(v)})
+		}
+		for _, sp := range ss.Values {
+			t := sp.Timestamp.Time()
+			c := t.Truncate(step)
+			if c.Before(t) {
+				c = c.Add(step)
+			}
+			if !c.Equal(closesAt) {
+				flush()
+				closesAt = c
+				window = window[:0]
+			}
+			if agg != nil || t.Equal(c) {
+				window = append(window, float64(sp.Value))
+			}
+		}
+		flush()
+		if len(values) > 0 {
+			resMe.Data.Result = append(resMe.Data.Result, &model.SampleStream{Metric: ss.Metric, Values: values})
+		}
+	}
+
+	return resMe
+}
+
+// Resample returns a new Timeseries with data points at each multiple of the provided step
+// within the Extent, carrying forward each of the subject's data points for up to its own step
+func (me *MatrixEnvelope) Resample(step time.Duration, e timeseries.Extent) timeseries.Timeseries {
+
+	me.Sort()
+
+	resMe := &MatrixEnvelope{
+		Status: me.Status,
+		Data: MatrixData{
+			ResultType: me.Data.ResultType,
+			Result:     make(model.Matrix, 0, len(me.Data.Result)),
+		},
+		StepDuration: step,
+		ExtentList:   timeseries.ExtentList{e},
+	}
+
+	if step <= 0 {
+		return resMe
+	}
+
+	start := e.Start.Truncate(step)
+	if start.Before(e.Start) {
+		start = start.Add(step)
+	}
+
+	for _, ss := range me.Data.Result {
+		values := make([]model.SamplePair, 0, int(e.End.Sub(start)/step)+1)
+		j := -1
+		for t := start; !t.After(e.End); t = t.Add(step) {
+			for j+1 < len(ss.Values) && !ss.Values[j+1].Timestamp.Time().After(t) {
+				j++
+			}
+			if j < 0 {
+				continue
+			}
+			if t.Sub(ss.Values[j].Timestamp.Time()) >= me.StepDuration && me.StepDuration > 0 {
+				continue
+			}
+			values = append(values, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()),
+				Value: ss.Values[j].Value})
+		}
+		if len(values) > 0 {
+			resMe.Data.Result = append(resMe.Data.Result, &model.SampleStream{Metric: ss.Metric, Values: values})
+		}
+	}
+
+	return resMe
+}
+
+// Overwrite replaces the data within the provided Extents with the provided Timeseries's data
+// in those Extents, and returns the number of values that were added, changed or removed
+func (me *MatrixEnvelope) Overwrite(ts timeseries.Timeseries, el timeseries.ExtentList) int {
+
+	me2, ok := ts.(*MatrixEnvelope)
+	if !ok || len(el) == 0 {
+		return 0
+	}
+
+	included := func(t time.Time) bool {
+		for i := range el {
+			if el[i].Includes(t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// index the replacement values by series and timestamp
+	rv := make(map[string]map[time.Time]model.SampleValue)
+	rm := make(map[string]model.Metric)
+	for _, ss := range me2.Data.Result {
+		k := ss.Metric.String()
+		m, ok := rv[k]
+		if !ok {
+			m = make(map[time.Time]model.SampleValue)
+			rv[k] = m
+			rm[k] = ss.Metric
+		}
+		for _, v := range ss.Values {
+			if t := v.Timestamp.Time(); included(t) {
+				m[t] = v.Value
+			}
+		}
+	}
+
+	changes := 0
+	result := make(model.Matrix, 0, len(me.Data.Result))
+	for _, ss := range me.Data.Result {
+		k := ss.Metric.String()
+		m := rv[k]
+		delete(rv, k)
+		values := make([]model.SamplePair, 0, len(ss.Values)+len(m))
+		for _, v := range ss.Values {
+			t := v.Timestamp.Time()
+			if !included(t) {
+				values = append(values, v)
+				continue
+			}
+			nv, ok := m[t]
+			if !ok {
+				changes++ // removed
+				continue
+			}
+			if !nv.Equal(v.Value) {
+				changes++ // changed
+			}
+			values = append(values, model.SamplePair{Timestamp: v.Timestamp, Value: nv})
+			delete(m, t)
+		}
+		for t, nv := range m {
+			changes++ // added
+			values = append(values, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: nv})
+		}
+		if len(values) == 0 {
+			continue
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i].Timestamp < values[j].Timestamp })
+		result = append(result, &model.SampleStream{Metric: ss.Metric, Values: values})
+	}
+
+	// any remaining replacement series did not previously exist
+	keys := make([]string, 0, len(rv))
+	for k := range rv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		m := rv[k]
+		if len(m) == 0 {
+			continue
+		}
+		values := make([]model.SamplePair, 0, len(m))
+		for t, nv := range m {
+			values = append(values, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: nv})
+		}
+		changes += len(values)
+		sort.Slice(values, func(i, j int) bool { return values[i].Timestamp < values[j].Timestamp })
+		result = append(result, &model.SampleStream{Metric: rm[k], Values: values})
+	}
+
+	me.Data.Result = result
+	me.isCounted = false
+	me.isSorted = false
+	return changes
+}
+
 // Merge merges the provided Timeseries list into the base Timeseries (in the order provided) and optionally sorts the merged Timeseries
 func (me *MatrixEnvelope) Merge(sort bool, collection ...timeseries.Timeseries) {
 	meMetrics := make(map[string]*model.SampleStream)
@@ -365,6 +580,21 @@ func (me *MatrixEnvelope) Extents() timeseries.ExtentList {
 	return me.ExtentList
 }
 
+// ValueExtents returns the ExtentList of timestamps in the MatrixEnvelope having at least one value,
+// where timestamps one step apart are part of the same Extent
+func (me *MatrixEnvelope) ValueExtents(step time.Duration) timeseries.ExtentList {
+	me.updateTimestamps()
+	el := make(timeseries.ExtentList, 0, 1)
+	for _, t := range me.tslist {
+		if n := len(el); n > 0 && !t.After(el[n-1].End.Add(step)) {
+			el[n-1].End = t
+			continue
+		}
+		el = append(el, timeseries.Extent{Start: t, End: t})
+	}
+	return el
+}
+
 // TimestampCount returns the number of unique timestamps across the timeseries
 func (me *MatrixEnvelope) TimestampCount() int {
 	me.updateTimestamps()
@@ -14,6 +14,7 @@
 package prometheus
 
 import (
+	"encoding/json"
 	"reflect"
 	"strconv"
 	"testing"
@@ -1674,6 +1675,114 @@ func TestTimestampCount(t *testing.T) {
 	}
 }
 
+func TestValueExtents(t *testing.T) {
+
+	me := &MatrixEnvelope{
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a"},
+					Values: []model.SamplePair{
+						{Timestamp: 300000, Value: 1.5},
+						{Timestamp: 400000, Value: 1.5},
+					},
+				},
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "b"},
+					Values: []model.SamplePair{
+						{Timestamp: 400000, Value: 1.5},
+						{Timestamp: 500000, Value: 1.5},
+						{Timestamp: 900000, Value: 1.5},
+					},
+				},
+			},
+		},
+	}
+
+	expected := "300-500;900-900"
+	if el := me.ValueExtents(time.Duration(100) * time.Second); el.String() != expected {
+		t.Errorf("expected %s got %s", expected, el.String())
+	}
+
+	me = &MatrixEnvelope{}
+	if el := me.ValueExtents(time.Duration(100) * time.Second); len(el) != 0 {
+		t.Errorf("expected %d got %d", 0, len(el))
+	}
+}
+
+func TestOverwrite(t *testing.T) {
+
+	me := &MatrixEnvelope{
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a"},
+					Values: []model.SamplePair{
+						{Timestamp: 100000, Value: 1},
+						{Timestamp: 200000, Value: 2},
+						{Timestamp: 300000, Value: 3},
+						{Timestamp: 400000, Value: 4},
+					},
+				},
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "b"},
+					Values: []model.SamplePair{
+						{Timestamp: 400000, Value: 4},
+					},
+				},
+			},
+		},
+	}
+
+	me2 := &MatrixEnvelope{
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a"},
+					Values: []model.SamplePair{
+						{Timestamp: 100000, Value: 9}, // outside of the overwritten extent
+						{Timestamp: 300000, Value: 3},
+						{Timestamp: 400000, Value: 5},
+					},
+				},
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "c"},
+					Values: []model.SamplePair{
+						{Timestamp: 300000, Value: 3},
+					},
+				},
+			},
+		},
+	}
+
+	el := timeseries.ExtentList{timeseries.Extent{Start: time.Unix(200, 0), End: time.Unix(400, 0)}}
+
+	// a:200 removed, a:400 changed, b:400 removed, c:300 added
+	if changes := me.Overwrite(me2, el); changes != 4 {
+		t.Errorf("expected %d got %d", 4, changes)
+	}
+
+	expected := `{"status":"","data":{"resultType":"matrix","result":[` +
+		`{"metric":{"__name__":"a"},"values":[[100,"1"],[300,"3"],[400,"5"]]},` +
+		`{"metric":{"__name__":"c"},"values":[[300,"3"]]}]}}`
+	b, _ := json.Marshal(me)
+	if string(b) != expected {
+		t.Errorf("expected %s got %s", expected, string(b))
+	}
+
+	// overwriting with identical data makes no changes
+	if changes := me.Overwrite(me.Clone(), el); changes != 0 {
+		t.Errorf("expected %d got %d", 0, changes)
+	}
+
+	if changes := me.Overwrite(me2, nil); changes != 0 {
+		t.Errorf("expected %d got %d", 0, changes)
+	}
+}
+
 func TestSize(t *testing.T) {
 	m := &MatrixEnvelope{
 		Status: rvSuccess,
@@ -1700,3 +1809,144 @@ func TestSize(t *testing.T) {
 		t.Errorf("expected %d got %d", expected, i)
 	}
 }
+
+func TestDownsample(t *testing.T) {
+
+	me := &MatrixEnvelope{
+		Status: rvSuccess,
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a"},
+					Values: []model.SamplePair{
+						{Timestamp: 0, Value: 9},
+						{Timestamp: 100000, Value: 1},
+						{Timestamp: 200000, Value: 5},
+						{Timestamp: 300000, Value: 3},
+						{Timestamp: 400000, Value: 2},
+						{Timestamp: 500000, Value: 7},
+						{Timestamp: 600000, Value: 4},
+					},
+				},
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "b"},
+					Values: []model.SamplePair{
+						{Timestamp: 100000, Value: 1},
+						{Timestamp: 200000, Value: 1},
+					},
+				},
+			},
+		},
+		ExtentList:   timeseries.ExtentList{timeseries.Extent{Start: time.Unix(0, 0), End: time.Unix(600, 0)}},
+		StepDuration: time.Duration(100) * time.Second,
+	}
+
+	tests := []struct {
+		agg            timeseries.Aggregator
+		expectedA      []model.SamplePair
+		expectedSeries int
+		expectedExtent string
+	}{
+		{ // Run 0 - sample the boundary values
+			agg:            nil,
+			expectedA:      []model.SamplePair{{Timestamp: 0, Value: 9}, {Timestamp: 300000, Value: 3}, {Timestamp: 600000, Value: 4}},
+			expectedSeries: 1,
+			expectedExtent: "0-600",
+		},
+		{ // Run 1 - max of each full window
+			agg:            timeseries.AggregateMax,
+			expectedA:      []model.SamplePair{{Timestamp: 300000, Value: 5}, {Timestamp: 600000, Value: 7}},
+			expectedSeries: 2,
+			expectedExtent: "300-600",
+		},
+		{ // Run 2 - sum of each full window
+			agg:            timeseries.AggregateSum,
+			expectedA:      []model.SamplePair{{Timestamp: 300000, Value: 9}, {Timestamp: 600000, Value: 13}},
+			expectedSeries: 2,
+			expectedExtent: "300-600",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ds := me.Downsample(time.Duration(300)*time.Second, test.agg).(*MatrixEnvelope)
+			if ds.StepDuration != time.Duration(300)*time.Second {
+				t.Errorf("expected %s got %s", "5m0s", ds.StepDuration)
+			}
+			if ds.ExtentList.String() != test.expectedExtent {
+				t.Errorf("expected %s got %s", test.expectedExtent, ds.ExtentList.String())
+			}
+			if len(ds.Data.Result) != test.expectedSeries {
+				t.Errorf("expected %d got %d", test.expectedSeries, len(ds.Data.Result))
+				return
+			}
+			if !reflect.DeepEqual(ds.Data.Result[0].Values, test.expectedA) {
+				t.Errorf("mismatch\nexpected %v\ngot      %v", test.expectedA, ds.Data.Result[0].Values)
+			}
+		})
+	}
+
+	// the source timeseries is unmodified
+	if len(me.Data.Result[0].Values) != 7 {
+		t.Errorf("expected %d got %d", 7, len(me.Data.Result[0].Values))
+	}
+
+	// no usable extents
+	me.ExtentList = timeseries.ExtentList{timeseries.Extent{Start: time.Unix(100, 0), End: time.Unix(200, 0)}}
+	ds := me.Downsample(time.Duration(300)*time.Second, nil).(*MatrixEnvelope)
+	if len(ds.Data.Result) != 0 {
+		t.Errorf("expected %d got %d", 0, len(ds.Data.Result))
+	}
+}
+
+func TestResample(t *testing.T) {
+
+	me := &MatrixEnvelope{
+		Status: rvSuccess,
+		Data: MatrixData{
+			ResultType: "matrix",
+			Result: model.Matrix{
+				&model.SampleStream{
+					Metric: model.Metric{"__name__": "a"},
+					Values: []model.SamplePair{
+						{Timestamp: 0, Value: 1},
+						{Timestamp: 30000, Value: 2},
+						// gap at 60000
+						{Timestamp: 90000, Value: 4},
+					},
+				},
+			},
+		},
+		ExtentList:   timeseries.ExtentList{timeseries.Extent{Start: time.Unix(0, 0), End: time.Unix(90, 0)}},
+		StepDuration: time.Duration(30) * time.Second,
+	}
+
+	e := timeseries.Extent{Start: time.Unix(0, 0), End: time.Unix(100, 0)}
+	rs := me.Resample(time.Duration(20)*time.Second, e).(*MatrixEnvelope)
+
+	expected := []model.SamplePair{
+		{Timestamp: 0, Value: 1},
+		{Timestamp: 20000, Value: 1},
+		{Timestamp: 40000, Value: 2},
+		{Timestamp: 100000, Value: 4},
+	}
+
+	if rs.StepDuration != time.Duration(20)*time.Second {
+		t.Errorf("expected %s got %s", "20s", rs.StepDuration)
+	}
+
+	if len(rs.Data.Result) != 1 {
+		t.Errorf("expected %d got %d", 1, len(rs.Data.Result))
+		return
+	}
+
+	if !reflect.DeepEqual(rs.Data.Result[0].Values, expected) {
+		t.Errorf("mismatch\nexpected %v\ngot      %v", expected, rs.Data.Result[0].Values)
+	}
+
+	rs = me.Resample(0, e).(*MatrixEnvelope)
+	if len(rs.Data.Result) != 0 {
+		t.Errorf("expected %d got %d", 0, len(rs.Data.Result))
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/Comcast/trickster/pkg/sort/times"
 
+	"github.com/Comcast/trickster/internal/proxy/errors"
 	"github.com/Comcast/trickster/internal/timeseries"
 	"github.com/prometheus/common/model"
 )
@@ -67,6 +68,22 @@ func (c *Client) UnmarshalTimeseries(data []byte) (timeseries.Timeseries, error)
 	return me, err
 }
 
+// MarshalTimeseriesBinary converts a Timeseries into a compact binary blob for Cache Storage
+func (c *Client) MarshalTimeseriesBinary(ts timeseries.Timeseries) ([]byte, error) {
+	me, ok := ts.(*MatrixEnvelope)
+	if !ok {
+		return nil, errors.ErrUnsupportedTimeseries
+	}
+	return me.MarshalMsg(nil)
+}
+
+// UnmarshalTimeseriesBinary converts a binary blob produced by MarshalTimeseriesBinary into a Timeseries
+func (c *Client) UnmarshalTimeseriesBinary(data []byte) (timeseries.Timeseries, error) {
+	me := &MatrixEnvelope{}
+	_, err := me.UnmarshalMsg(data)
+	return me, err
+}
+
 // UnmarshalInstantaneous converts a JSON blob into an Instantaneous Data Point
 func (c *Client) UnmarshalInstantaneous(data []byte) (timeseries.Timeseries, error) {
 	ve := &VectorEnvelope{}
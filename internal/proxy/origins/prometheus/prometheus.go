@@ -18,6 +18,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -56,6 +57,13 @@ const (
 	upMatch = "match[]"
 )
 
+// reOuterFunction matches a function or aggregation operator at the head of a statement,
+// including any grouping clause that precedes its parameters
+var reOuterFunction = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:(?:by|without)\s*\([^)]*\)\s*)?\(`)
+
+// reGroupingClause matches a grouping clause that follows an aggregation's parameters
+var reGroupingClause = regexp.MustCompile(`^(?:by|without)\s*\([^)]*\)$`)
+
 // Client Implements Proxy Client Interface
 type Client struct {
 	name               string
@@ -174,3 +182,43 @@ func (c *Client) ParseTimeRangeQuery(r *http.Request) (*timeseries.TimeRangeQuer
 
 	return trq, nil
 }
+
+// OuterFunction returns the name of the function or aggregation operator that encloses the
+// entire statement (e.g., 'max_over_time' for 'max_over_time(metric[5m])'). An empty string is
+// returned for statements like 'max(a) - min(b)' that are not wholly enclosed by one function.
+func (c *Client) OuterFunction(statement string) string {
+	s := strings.TrimSpace(statement)
+	m := reOuterFunction.FindStringSubmatch(s)
+	if len(m) != 2 {
+		return ""
+	}
+	i := len(m[0]) - 1
+	var depth int
+	var quote byte
+	for j := i; j < len(s); j++ {
+		if quote != 0 {
+			if s[j] == '\\' {
+				j++
+			} else if s[j] == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch s[j] {
+		case '"', '\'', '`':
+			quote = s[j]
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				rest := strings.TrimSpace(s[j+1:])
+				if rest == "" || reGroupingClause.MatchString(rest) {
+					return m[1]
+				}
+				return ""
+			}
+		}
+	}
+	return ""
+}
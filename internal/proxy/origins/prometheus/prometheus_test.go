@@ -25,6 +25,7 @@ import (
 	"github.com/Comcast/trickster/internal/config"
 	"github.com/Comcast/trickster/internal/proxy/errors"
 	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/timeseries"
 )
 
 func TestPrometheusClientInterfacing(t *testing.T) {
@@ -35,6 +36,11 @@ func TestPrometheusClientInterfacing(t *testing.T) {
 	c := &Client{name: "test"}
 	var oc origins.Client = c
 	var tc origins.TimeseriesClient = c
+	var dc origins.DownsamplingClient = c
+	var bc origins.BinaryTimeseriesClient = c
+	var ds timeseries.Downsampler = &MatrixEnvelope{}
+	var ve timeseries.ValueExtenter = &MatrixEnvelope{}
+	var ow timeseries.Overwriter = &MatrixEnvelope{}
 
 	if oc.Name() != "test" {
 		t.Errorf("expected %s got %s", "test", oc.Name())
@@ -43,6 +49,26 @@ func TestPrometheusClientInterfacing(t *testing.T) {
 	if tc.Name() != "test" {
 		t.Errorf("expected %s got %s", "test", tc.Name())
 	}
+
+	if dc.OuterFunction("max(up)") != "max" {
+		t.Errorf("expected %s got %s", "max", dc.OuterFunction("max(up)"))
+	}
+
+	if bc == nil {
+		t.Errorf("expected non-nil value for %s", "BinaryTimeseriesClient")
+	}
+
+	if ds == nil {
+		t.Errorf("expected non-nil value for %s", "Downsampler")
+	}
+
+	if ve == nil {
+		t.Errorf("expected non-nil value for %s", "ValueExtenter")
+	}
+
+	if ow == nil {
+		t.Errorf("expected non-nil value for %s", "Overwriter")
+	}
 }
 
 func TestNewClient(t *testing.T) {
@@ -391,3 +417,30 @@ func TestSetCache(t *testing.T) {
 		t.Errorf("expected nil cache for client named %s", "test")
 	}
 }
+
+func TestOuterFunction(t *testing.T) {
+
+	client := &Client{}
+
+	tests := []struct {
+		statement, expected string
+	}{
+		{"up", ""},
+		{"max_over_time(up[5m])", "max_over_time"},
+		{" max_over_time (up[5m]) ", "max_over_time"},
+		{"sum by (job) (rate(http_requests_total[5m]))", "sum"},
+		{"sum(rate(http_requests_total[5m])) without (instance)", "sum"},
+		{"max(a) - min(b)", ""},
+		{`max(up{job="a)b"})`, "max"},
+		{"max(up", ""},
+		{"(max(up))", ""},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if f := client.OuterFunction(test.statement); f != test.expected {
+				t.Errorf("expected %s got %s", test.expected, f)
+			}
+		})
+	}
+}
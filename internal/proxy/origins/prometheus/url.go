@@ -18,6 +18,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Comcast/trickster/internal/proxy/urls"
 	"github.com/Comcast/trickster/internal/timeseries"
@@ -56,6 +57,21 @@ func (c *Client) SetExtent(r *http.Request, trq *timeseries.TimeRangeQuery, exte
 	r.URL.RawQuery = params.Encode()
 }
 
+// SetStep will change the upstream request query, and the TimeRangeQuery's TemplateURL if it has one,
+// to use the provided step
+func (c *Client) SetStep(r *http.Request, trq *timeseries.TimeRangeQuery, step time.Duration) {
+	s := strconv.FormatFloat(step.Seconds(), 'f', -1, 64)
+	params := r.URL.Query()
+	params.Set(upStep, s)
+	r.URL.RawQuery = params.Encode()
+	if trq.TemplateURL != nil {
+		t := trq.TemplateURL.Query()
+		t.Set(upStep, s)
+		trq.TemplateURL.RawQuery = t.Encode()
+	}
+	trq.Step = step
+}
+
 // FastForwardURL returns the url to fetch the Fast Forward value based on a timerange url
 func (c *Client) FastForwardURL(r *http.Request) (*url.URL, error) {
 
@@ -52,6 +52,33 @@ func TestSetExtent(t *testing.T) {
 	}
 }
 
+func TestSetStepParam(t *testing.T) {
+
+	client := &Client{}
+
+	u := &url.URL{RawQuery: "q=up&step=1m"}
+	r, _ := http.NewRequest(http.MethodGet, u.String(), nil)
+	trq := &timeseries.TimeRangeQuery{Step: time.Minute}
+	client.SetStep(r, trq, time.Duration(15)*time.Second)
+
+	expected := "q=up&step=15"
+	if expected != r.URL.RawQuery {
+		t.Errorf("\nexpected [%s]\ngot [%s]", expected, r.URL.RawQuery)
+	}
+
+	if trq.Step != time.Duration(15)*time.Second {
+		t.Errorf("expected %s got %s", "15s", trq.Step)
+	}
+
+	trq.TemplateURL = &url.URL{RawQuery: "q=up&step=1m"}
+	client.SetStep(r, trq, time.Duration(30)*time.Second)
+
+	expected = "q=up&step=30"
+	if expected != trq.TemplateURL.RawQuery {
+		t.Errorf("\nexpected [%s]\ngot [%s]", expected, trq.TemplateURL.RawQuery)
+	}
+}
+
 func TestFastForwardURL(t *testing.T) {
 
 	expected := "q=up"
@@ -16,6 +16,7 @@ package origins
 import (
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/Comcast/trickster/internal/cache"
 	"github.com/Comcast/trickster/internal/config"
@@ -49,3 +50,31 @@ type TimeseriesClient interface {
 	// SetCache sets the Cache object the client will use when caching origin content
 	SetCache(cache.Cache)
 }
+
+// StepClient is an optional interface for TimeseriesClients that are able to rewrite
+// the step of an upstream timeseries request
+type StepClient interface {
+	// SetStep will update an upstream request's step parameter, and the TimeRangeQuery's
+	// Step and TemplateURL (if it has one), to the provided duration. Cache keys are derived
+	// from the TemplateURL when it is set, and otherwise from the upstream request
+	SetStep(*http.Request, *timeseries.TimeRangeQuery, time.Duration)
+}
+
+// DownsamplingClient is an optional interface for TimeseriesClients that are able to
+// fulfill coarser-step requests from cached finer-step timeseries
+type DownsamplingClient interface {
+	StepClient
+	// OuterFunction returns the name of the function or aggregation that encloses the
+	// entire statement, or an empty string if there is none
+	OuterFunction(string) string
+}
+
+// BinaryTimeseriesClient is an optional interface for TimeseriesClients that are able to encode
+// their Timeseries in a compact binary format for storage in the cache, so that the origin's
+// wire format is only produced when responding to the client
+type BinaryTimeseriesClient interface {
+	// MarshalTimeseriesBinary encodes the Timeseries in the client's binary format
+	MarshalTimeseriesBinary(timeseries.Timeseries) ([]byte, error)
+	// UnmarshalTimeseriesBinary decodes a Timeseries from the client's binary format
+	UnmarshalTimeseriesBinary([]byte) (timeseries.Timeseries, error)
+}
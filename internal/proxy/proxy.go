@@ -14,19 +14,24 @@
 package proxy
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
+	"golang.org/x/net/http2"
 	"golang.org/x/net/netutil"
 
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
 	"github.com/Comcast/trickster/internal/util/log"
 	"github.com/Comcast/trickster/internal/util/metrics"
+	"github.com/Comcast/trickster/internal/util/socket"
 )
 
 // NewHTTPClient returns an HTTP client configured to the specifications of the
@@ -77,17 +82,69 @@ func NewHTTPClient(oc *config.OriginConfig) (*http.Client, error) {
 		}
 	}
 
+	dialer := &net.Dialer{KeepAlive: time.Duration(oc.KeepAliveTimeoutSecs) * time.Second}
+	dialContext := dialer.DialContext
+	if oc.UnixSocketPath != "" {
+		// every request to the origin is made through its socket, whatever the address of its host
+		dialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", oc.UnixSocketPath)
+		}
+	}
+
+	var transport http.RoundTripper
+	if oc.H2C {
+		// h2c requests are made with prior knowledge, so the "TLS" connection is a plaintext one.
+		// http2.Transport has no connection limits, so MaxIdleConns and MaxConnsPerHost do not apply,
+		// and it does not pass the request's context to DialTLS, so a dial cannot be canceled with
+		// its request. Instead, each dial is bounded by the origin's timeout
+		transport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				ctx := context.Background()
+				if oc.Timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, oc.Timeout)
+					defer cancel()
+				}
+				return dialContext(ctx, network, addr)
+			},
+		}
+	} else {
+		t := &http.Transport{
+			DialContext:         dialContext,
+			MaxIdleConns:        oc.MaxIdleConns,
+			MaxIdleConnsPerHost: oc.MaxIdleConns,
+			MaxConnsPerHost:     oc.MaxConnsPerHost,
+			TLSClientConfig:     TLSConfig,
+		}
+		if oc.HTTP2 {
+			if err := http2.ConfigureTransport(t); err != nil {
+				return nil, err
+			}
+		}
+		transport = t
+	}
+
+	if oc.MaxUpstreamConcurrency > 0 {
+		transport = newConcurrencyLimitedTransport(transport, oc.MaxUpstreamConcurrency)
+	}
+
+	if oc.CircuitBreaker.Enabled() {
+		transport = newCircuitBreakerTransport(transport, breaker.New(oc.Name, &oc.CircuitBreaker),
+			oc.CircuitBreaker.LatencyThreshold)
+	}
+
+	// each retried or hedged request is subject to the circuit breaker and the concurrency limit
+	if oc.Retries.Enabled() {
+		transport = newRetryTransport(transport, oc.Name, &oc.Retries)
+	}
+
 	return &http.Client{
 		Timeout: oc.Timeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
-		Transport: &http.Transport{
-			Dial:                (&net.Dialer{KeepAlive: time.Duration(oc.KeepAliveTimeoutSecs) * time.Second}).Dial,
-			MaxIdleConns:        oc.MaxIdleConns,
-			MaxIdleConnsPerHost: oc.MaxIdleConns,
-			TLSClientConfig:     TLSConfig,
-		},
+		Transport: transport,
 	}, nil
 
 }
@@ -105,7 +162,11 @@ func NewHTTPClient(oc *config.OriginConfig) (*http.Client, error) {
 // which observes the connections to set a gauge with the current number of
 // connections (with operates with sampling through scrapes), and a set of
 // counter metrics for connections accepted, rejected and closed.
-func NewListener(listenAddress string, listenPort, connectionsLimit int, tlsConfig *tls.Config) (net.Listener, error) {
+//
+// If the listen address is the path of a Unix domain socket (unix:///path/to/socket), the
+// listener is created on the socket, whose permissions are set to the socketMode, if not 0.
+func NewListener(listenAddress string, listenPort, connectionsLimit int, tlsConfig *tls.Config,
+	socketMode os.FileMode) (net.Listener, error) {
 
 	var listener net.Listener
 	var err error
@@ -114,10 +175,9 @@ func NewListener(listenAddress string, listenPort, connectionsLimit int, tlsConf
 
 	if tlsConfig != nil {
 		listenerType = "https"
-		listener, err = tls.Listen("tcp", fmt.Sprintf("%s:%d", listenAddress, listenPort), tlsConfig)
-	} else {
-		listener, err = net.Listen("tcp", fmt.Sprintf("%s:%d", listenAddress, listenPort))
 	}
+
+	listener, err = socket.Listen(listenAddress, listenPort, socketMode)
 	if err != nil {
 		// so we can exit one level above, this usually means that the port is in use
 		return nil, err
@@ -135,9 +195,17 @@ func NewListener(listenAddress string, listenPort, connectionsLimit int, tlsConf
 		metrics.ProxyMaxConnections.Set(float64(connectionsLimit))
 	}
 
-	return &connectionsLimitObProxy{
+	listener = &connectionsLimitObProxy{
 		listener,
-	}, nil
+	}
+
+	// TLS is layered over the observed connections, rather than beneath them, so that the server
+	// is handed the *tls.Conn it needs to negotiate HTTP/2, and closing it closes the observed one
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	return listener, nil
 }
 
 type connectionsLimitObProxy struct {
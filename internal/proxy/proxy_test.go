@@ -93,7 +93,7 @@ func TestNewHTTPClient(t *testing.T) {
 
 func TestNewListenerErr(t *testing.T) {
 	config.NewConfig()
-	l, err := NewListener("-", 0, 0, nil)
+	l, err := NewListener("-", 0, 0, nil, 0)
 	if err == nil {
 		l.Close()
 		t.Errorf("expected error: %s", `listen tcp: lookup -: no such host`)
@@ -116,7 +116,7 @@ func TestNewListenerTLS(t *testing.T) {
 		t.Error(err)
 	}
 
-	l, err := NewListener("", 0, 0, tlsConfig)
+	l, err := NewListener("", 0, 0, tlsConfig, 0)
 	defer l.Close()
 	if err != nil {
 		t.Error(err)
@@ -172,7 +172,7 @@ func TestListenerConnectionLimitWorks(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.Name, func(t *testing.T) {
-			l, err := NewListener("", tc.ListenPort, tc.ConnectionsLimit, nil)
+			l, err := NewListener("", tc.ListenPort, tc.ConnectionsLimit, nil, 0)
 			defer l.Close()
 
 			go func() {
@@ -35,6 +35,8 @@ type Resources struct {
 	OriginClient      origins.Client
 	AlternateCacheTTL time.Duration
 	TimeRangeQuery    *timeseries.TimeRangeQuery
+	PathVars          map[string]string
+	AuthIdentity      string
 }
 
 // Clone returns an exact copy of the subject Resources collection
@@ -48,6 +50,8 @@ func (r Resources) Clone() *Resources {
 		OriginClient:      r.OriginClient,
 		AlternateCacheTTL: r.AlternateCacheTTL,
 		TimeRangeQuery:    r.TimeRangeQuery,
+		PathVars:          r.PathVars,
+		AuthIdentity:      r.AuthIdentity,
 	}
 }
 
@@ -0,0 +1,227 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+// idempotentMethods are the methods of the requests that may safely be made more than once
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// jitter returns a random duration in [0, d), which can be replaced in tests
+var jitter = func(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// retryTransport is an http.RoundTripper that retries idempotent requests that fail, or that receive
+// a retryable status code, after a jittered exponential backoff. When a hedge delay is configured, a
+// second request is also made if the first has not responded within the delay, and the first
+// response of the two is used
+type retryTransport struct {
+	next       http.RoundTripper
+	originName string
+	cfg        *config.RetryConfig
+	statuses   map[int]bool
+}
+
+func newRetryTransport(next http.RoundTripper, originName string, cfg *config.RetryConfig) *retryTransport {
+	t := &retryTransport{next: next, originName: originName, cfg: cfg, statuses: make(map[int]bool)}
+	for _, s := range cfg.Statuses {
+		t.statuses[s] = true
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	if !idempotentMethods[r.Method] || !replayable(r) {
+		return t.next.RoundTrip(r)
+	}
+
+	for attempt := 0; ; attempt++ {
+
+		resp, err := t.hedge(r)
+
+		var reason string
+		switch {
+		case err == breaker.ErrOpen || r.Context().Err() != nil:
+		case err != nil:
+			reason = "error"
+		case t.statuses[resp.StatusCode]:
+			reason = "status"
+		}
+		if reason == "" || attempt >= t.cfg.MaxRetries {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		metrics.ProxyUpstreamRetries.WithLabelValues(t.originName, reason).Inc()
+		log.Debug("retrying upstream request", log.Pairs{"originName": t.originName, "url": r.URL.String(),
+			"attempt": attempt + 1, "reason": reason})
+
+		backoff := t.cfg.Backoff << uint(attempt)
+		if backoff > t.cfg.MaxBackoff || backoff <= 0 {
+			backoff = t.cfg.MaxBackoff
+		}
+		timer := time.NewTimer(jitter(backoff))
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		}
+
+		if r, err = rewind(r); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// hedgeResult is the result of one of the requests made by hedge
+type hedgeResult struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// hedge makes the request, and a second request if the first has not responded within the hedge delay.
+// The first response is returned, and the other request is canceled
+func (t *retryTransport) hedge(r *http.Request) (*http.Response, error) {
+
+	if t.cfg.HedgeDelay <= 0 {
+		return t.next.RoundTrip(r)
+	}
+
+	results := make(chan hedgeResult, 2)
+	send := func(req *http.Request) {
+		ctx, cancel := context.WithCancel(req.Context())
+		go func() {
+			resp, err := t.next.RoundTrip(req.WithContext(ctx))
+			results <- hedgeResult{resp: resp, err: err, cancel: cancel}
+		}()
+	}
+
+	send(r)
+	pending := 1
+	timer := time.NewTimer(t.cfg.HedgeDelay)
+	defer timer.Stop()
+	hedged := false
+
+	for {
+		select {
+		case <-timer.C:
+			r2, err := rewind(r)
+			if err != nil {
+				continue
+			}
+			metrics.ProxyUpstreamHedges.WithLabelValues(t.originName).Inc()
+			send(r2)
+			pending++
+			hedged = true
+		case res := <-results:
+			pending--
+			if res.err != nil && pending > 0 {
+				// the other request may yet succeed
+				res.cancel()
+				continue
+			}
+			if res.err != nil && !hedged {
+				res.cancel()
+				return nil, res.err
+			}
+			if pending > 0 {
+				// the slower request is canceled, and its response is discarded if it arrives
+				go func(n int) {
+					for ; n > 0; n-- {
+						other := <-results
+						other.cancel()
+						if other.resp != nil {
+							other.resp.Body.Close()
+						}
+					}
+				}(pending)
+			}
+			if res.err != nil {
+				res.cancel()
+				return nil, res.err
+			}
+			res.resp.Body = &cancelingBody{ReadCloser: res.resp.Body, cancel: res.cancel}
+			return res.resp, nil
+		}
+	}
+}
+
+// cancelingBody is a response body that cancels its request's context once it is read in full or closed
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Read implements io.Reader
+func (b *cancelingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.cancel()
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// replayable returns true if the request's body, if any, can be provided again to a retried request
+func replayable(r *http.Request) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// rewind returns a copy of the request with a new copy of its body, if it has one
+func rewind(r *http.Request) (*http.Request, error) {
+	r2 := r.WithContext(r.Context())
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r2.Body = body
+	}
+	return r2, nil
+}
@@ -0,0 +1,265 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
+)
+
+// sequenceRoundTripper responds to each request with the next of its statuses, or
+// fails the request when the status is 0, after the delay for that request, if any
+type sequenceRoundTripper struct {
+	mtx      sync.Mutex
+	statuses []int
+	delays   []time.Duration
+	bodies   []string
+	calls    int
+}
+
+func (rt *sequenceRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	rt.mtx.Lock()
+	i := rt.calls
+	rt.calls++
+	rt.mtx.Unlock()
+
+	if r.Body != nil {
+		b, _ := ioutil.ReadAll(r.Body)
+		rt.mtx.Lock()
+		rt.bodies = append(rt.bodies, string(b))
+		rt.mtx.Unlock()
+	}
+
+	if i < len(rt.delays) && rt.delays[i] > 0 {
+		select {
+		case <-time.After(rt.delays[i]):
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+	}
+
+	status := rt.statuses[len(rt.statuses)-1]
+	if i < len(rt.statuses) {
+		status = rt.statuses[i]
+	}
+	if status == 0 {
+		return nil, errors.New("test error")
+	}
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(http.StatusText(status)))}, nil
+}
+
+func (rt *sequenceRoundTripper) count() int {
+	rt.mtx.Lock()
+	defer rt.mtx.Unlock()
+	return rt.calls
+}
+
+func testRetryConfig(maxRetries int, hedgeDelay time.Duration) *config.RetryConfig {
+	rc := config.NewRetryConfig()
+	rc.MaxRetries = maxRetries
+	rc.Backoff = time.Millisecond
+	rc.MaxBackoff = 2 * time.Millisecond
+	rc.HedgeDelay = hedgeDelay
+	return &rc
+}
+
+func TestRetryTransport(t *testing.T) {
+
+	rt := &sequenceRoundTripper{statuses: []int{0, http.StatusBadGateway, http.StatusOK}}
+	tr := newRetryTransport(rt, "test", testRetryConfig(2, 0))
+
+	resp, err := tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, resp.StatusCode)
+	}
+	if rt.count() != 3 {
+		t.Errorf("expected %d got %d", 3, rt.count())
+	}
+
+	// the last response is returned when the retries are exhausted
+	rt = &sequenceRoundTripper{statuses: []int{http.StatusServiceUnavailable}}
+	tr = newRetryTransport(rt, "test", testRetryConfig(2, 0))
+	resp, err = tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected %d got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if rt.count() != 3 {
+		t.Errorf("expected %d got %d", 3, rt.count())
+	}
+
+	// statuses that are not configured are not retried
+	rt = &sequenceRoundTripper{statuses: []int{http.StatusInternalServerError}}
+	tr = newRetryTransport(rt, "test", testRetryConfig(2, 0))
+	tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if rt.count() != 1 {
+		t.Errorf("expected %d got %d", 1, rt.count())
+	}
+
+	// nor are requests that are not idempotent
+	rt = &sequenceRoundTripper{statuses: []int{0}}
+	tr = newRetryTransport(rt, "test", testRetryConfig(2, 0))
+	tr.RoundTrip(httptest.NewRequest("POST", "http://0/", strings.NewReader("test")))
+	if rt.count() != 1 {
+		t.Errorf("expected %d got %d", 1, rt.count())
+	}
+}
+
+func TestRetryTransportBody(t *testing.T) {
+
+	rt := &sequenceRoundTripper{statuses: []int{0, http.StatusOK}}
+	tr := newRetryTransport(rt, "test", testRetryConfig(1, 0))
+
+	r, _ := http.NewRequest("PUT", "http://0/", bytes.NewReader([]byte("test")))
+	if _, err := tr.RoundTrip(r); err != nil {
+		t.Fatal(err)
+	}
+	if len(rt.bodies) != 2 || rt.bodies[1] != "test" {
+		t.Errorf("expected the body to be sent with each request, got %v", rt.bodies)
+	}
+
+	// a body that cannot be sent again is not retried
+	rt = &sequenceRoundTripper{statuses: []int{0, http.StatusOK}}
+	tr = newRetryTransport(rt, "test", testRetryConfig(1, 0))
+	r, _ = http.NewRequest("PUT", "http://0/", ioutil.NopCloser(strings.NewReader("test")))
+	if _, err := tr.RoundTrip(r); err == nil {
+		t.Error("expected error for unreplayable request")
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+
+	defer func(f func(time.Duration) time.Duration) { jitter = f }(jitter)
+	var waits []time.Duration
+	jitter = func(d time.Duration) time.Duration {
+		waits = append(waits, d)
+		return 0
+	}
+
+	rc := testRetryConfig(4, 0)
+	rc.MaxBackoff = 5 * time.Millisecond
+	tr := newRetryTransport(&sequenceRoundTripper{statuses: []int{0}}, "test", rc)
+	tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond}
+	if len(waits) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, waits)
+	}
+	for i := range expected {
+		if waits[i] != expected[i] {
+			t.Errorf("expected %v got %v", expected, waits)
+			break
+		}
+	}
+}
+
+func TestRetryTransportOpenCircuit(t *testing.T) {
+
+	b := breaker.New("test-retry-open", &config.CircuitBreakerConfig{FailureRatio: 0.5, MinRequests: 1,
+		Window: time.Second, OpenDuration: time.Minute})
+	b.Record(false, breaker.ResultFailure)
+
+	rt := &sequenceRoundTripper{statuses: []int{http.StatusOK}}
+	tr := newRetryTransport(newCircuitBreakerTransport(rt, b, 0), "test", testRetryConfig(2, 0))
+	if _, err := tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil)); err != breaker.ErrOpen {
+		t.Errorf("expected %v got %v", breaker.ErrOpen, err)
+	}
+	if rt.count() != 0 {
+		t.Errorf("expected %d got %d", 0, rt.count())
+	}
+}
+
+func TestRetryTransportHedge(t *testing.T) {
+
+	// the first request is slow, so the hedged request's response is used
+	rt := &sequenceRoundTripper{statuses: []int{http.StatusNoContent, http.StatusOK},
+		delays: []time.Duration{time.Second}}
+	tr := newRetryTransport(rt, "test", testRetryConfig(0, 10*time.Millisecond))
+
+	start := time.Now()
+	resp, err := tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, resp.StatusCode)
+	}
+	if time.Since(start) >= time.Second {
+		t.Error("expected the hedged response before the first")
+	}
+	resp.Body.Close()
+
+	// a fast response is used without hedging
+	rt = &sequenceRoundTripper{statuses: []int{http.StatusNoContent, http.StatusOK}}
+	tr = newRetryTransport(rt, "test", testRetryConfig(0, time.Second))
+	resp, err = tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %d got %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if rt.count() != 1 {
+		t.Errorf("expected %d got %d", 1, rt.count())
+	}
+
+	// the hedged request's error waits for the first response
+	rt = &sequenceRoundTripper{statuses: []int{http.StatusNoContent, 0},
+		delays: []time.Duration{50 * time.Millisecond}}
+	tr = newRetryTransport(rt, "test", testRetryConfig(0, 10*time.Millisecond))
+	resp, err = tr.RoundTrip(httptest.NewRequest("GET", "http://0/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %d got %d", http.StatusNoContent, resp.StatusCode)
+	}
+}
+
+func TestNewHTTPClientRetries(t *testing.T) {
+	oc := config.NewOriginConfig()
+	oc.Name = "test-client-retries"
+	oc.Retries.MaxRetries = 2
+	oc.CircuitBreaker.FailureRatio = 0.5
+	c, err := NewHTTPClient(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := c.Transport.(*retryTransport)
+	if !ok {
+		t.Fatal("expected a retrying transport")
+	}
+	if _, ok := tr.next.(*circuitBreakerTransport); !ok {
+		t.Error("expected a circuit breaking transport")
+	}
+	if breaker.Get(oc.Name) == nil {
+		t.Error("expected a circuit breaker for the origin")
+	}
+}
@@ -0,0 +1,113 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rules
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// Handler returns an HTTP Handler that applies the Rule Sets, in order, to each request before
+// passing it to next. A request that a Rule routes to another origin, or whose path a Rule rewrites,
+// is instead dispatched by the router, so that it is handled by the path that now matches it.
+// A request is dispatched by a Rule only once, so any routes applied to it thereafter are ignored
+func Handler(sets []*RuleSet, router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var originName string
+		var client origins.Client
+		if rsc := request.GetResources(r); rsc != nil {
+			if rsc.OriginConfig != nil {
+				originName = rsc.OriginConfig.Name
+			}
+			client = rsc.OriginClient
+		}
+
+		// requests routed by the origin name in their path are matched by the path relative to the origin
+		var prefix string
+		path := r.URL.Path
+		if originName != "" && strings.HasPrefix(path, "/"+originName+"/") {
+			prefix = "/" + originName
+			path = path[len(prefix):]
+		}
+
+		req := &ruleRequest{Request: r, path: path, client: client}
+		for _, rs := range sets {
+			rs.apply(req)
+		}
+
+		if req.rewritten {
+			r.URL.Path = prefix + req.path
+			r.URL.RawPath = ""
+		}
+
+		if req.route == "" && !req.rewritten {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if routed := tctx.RuleRoute(r.Context()); routed != "" {
+			log.Debug("request has already been dispatched by a rule", log.Pairs{"originName": routed, "route": req.route})
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		target := req.route
+		if target == "" {
+			target = originName
+		}
+		dispatch(w, r, router, target, req.path)
+	})
+}
+
+// dispatch serves the request with the router's handler for the path on the target origin,
+// which is matched as if the request was routed by the origin's name in its Host header
+func dispatch(w http.ResponseWriter, r *http.Request, router *mux.Router, target, path string) {
+
+	r2 := r.WithContext(tctx.WithRuleRoute(r.Context(), target))
+	u := *r.URL
+	u.Path = path
+	u.RawPath = ""
+	r2.URL = &u
+
+	// the router matches the host of absolute URLs, rather than the Host header
+	rm := *r2
+	rm.Host = target
+	if u.IsAbs() {
+		um := u
+		um.Host = target
+		rm.URL = &um
+	}
+	var m mux.RouteMatch
+	ok := router.Match(&rm, &m)
+
+	if !ok || m.Handler == nil {
+		log.Debug("no route found for rule-dispatched request", log.Pairs{"originName": target, "path": path})
+		http.NotFound(w, r2)
+		return
+	}
+
+	// the router only sets the variables of template paths on the requests that it serves itself
+	if len(m.Vars) > 0 {
+		r2 = mux.SetURLVars(r2, m.Vars)
+	}
+	m.Handler.ServeHTTP(w, r2)
+}
@@ -0,0 +1,126 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Comcast/trickster/internal/config"
+	tctx "github.com/Comcast/trickster/internal/proxy/context"
+	"github.com/Comcast/trickster/internal/proxy/request"
+)
+
+func namedHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", name)
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Route", tctx.RuleRoute(r.Context()))
+	})
+}
+
+func TestHandler(t *testing.T) {
+
+	router := mux.NewRouter()
+	router.Handle("/api/v1/query", namedHandler("other")).Host("other")
+	router.Handle("/api/v1/query", namedHandler("test-query")).Host("test")
+
+	rs := testRuleSet(t, config.RuleSetConfig{
+		&config.RuleConfig{
+			Conditions: []*config.RuleConditionConfig{{Source: "header", Key: "X-Tenant", Value: "acme"}},
+			Actions:    []*config.RuleActionConfig{{Action: "route", Value: "other"}},
+		},
+		&config.RuleConfig{
+			Conditions: []*config.RuleConditionConfig{{Source: "path", Operator: "eq", Value: "/legacy"}},
+			Actions:    []*config.RuleActionConfig{{Action: "rewrite_path", Value: "/api/v1/query"}},
+		},
+		&config.RuleConfig{
+			Actions: []*config.RuleActionConfig{{Action: "set_header", Key: "X-Rules", Value: "1"}},
+		},
+	})
+
+	h := Handler([]*RuleSet{rs}, router, namedHandler("next"))
+	oc := &config.OriginConfig{Name: "test"}
+
+	tests := []struct {
+		url, tenant, handler, path string
+	}{
+		// no route or rewrite is applied, so the request is passed to next
+		{"http://trickster/test/api/v1/label", "", "next", "/test/api/v1/label"},
+		// the request is routed to the other origin by the relative path
+		{"http://trickster/test/api/v1/query", "acme", "other", "/api/v1/query"},
+		// the rewritten path is dispatched to its handler on the same origin
+		{"http://trickster/test/legacy", "", "test-query", "/api/v1/query"},
+		// the route does not match any path on the other origin
+		{"http://trickster/test/legacy/x", "acme", "", ""},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest("GET", test.url, nil)
+		r = request.SetResources(r, request.NewResources(oc, nil, nil, nil, nil))
+		if test.tenant != "" {
+			r.Header.Set("X-Tenant", test.tenant)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if test.handler == "" {
+			if w.Code != http.StatusNotFound {
+				t.Errorf("test %d: expected %d got %d", i, http.StatusNotFound, w.Code)
+			}
+			continue
+		}
+		if v := w.Header().Get("X-Handler"); v != test.handler {
+			t.Errorf("test %d: expected handler %s got %s", i, test.handler, v)
+		}
+		if v := w.Header().Get("X-Path"); v != test.path {
+			t.Errorf("test %d: expected path %s got %s", i, test.path, v)
+		}
+		if r.Header.Get("X-Rules") != "1" {
+			t.Errorf("test %d: expected header %s to be set", i, "X-Rules")
+		}
+	}
+}
+
+func TestHandlerAlreadyRouted(t *testing.T) {
+
+	router := mux.NewRouter()
+	router.Handle("/", namedHandler("other")).Host("other")
+
+	rs := testRuleSet(t, config.RuleSetConfig{&config.RuleConfig{
+		Actions: []*config.RuleActionConfig{{Action: "route", Value: "other"}},
+	}})
+	h := Handler([]*RuleSet{rs}, router, namedHandler("next"))
+
+	r := httptest.NewRequest("GET", "http://trickster/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if v := w.Header().Get("X-Handler"); v != "other" {
+		t.Errorf("expected handler %s got %s", "other", v)
+	}
+	if v := w.Header().Get("X-Route"); v != "other" {
+		t.Errorf("expected route %s got %s", "other", v)
+	}
+
+	// a request that was already dispatched by a rule is not dispatched again
+	r = r.WithContext(tctx.WithRuleRoute(r.Context(), "test"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if v := w.Header().Get("X-Handler"); v != "next" {
+		t.Errorf("expected handler %s got %s", "next", v)
+	}
+}
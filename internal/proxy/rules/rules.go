@@ -0,0 +1,295 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package rules applies configurable Rules to requests, which modify the requests'
+// headers, query parameters, paths and hosts, and route them to other origins
+package rules
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/origins"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// RuleSet is an ordered list of Rules compiled from a RuleSetConfig
+type RuleSet struct {
+	Name  string
+	rules []*rule
+}
+
+type rule struct {
+	name       string
+	conditions []*condition
+	actions    []*action
+	stop       bool
+}
+
+type condition struct {
+	source   string
+	key      string
+	operator string
+	value    string
+	values   []string
+	re       *regexp.Regexp
+	nets     []*net.IPNet
+	negate   bool
+}
+
+type action struct {
+	action string
+	key    string
+	value  string
+	re     *regexp.Regexp
+}
+
+// ruleRequest is a request to which Rules are applied, and the results of the Actions applied to it
+type ruleRequest struct {
+	*http.Request
+	// path is the request's path, relative to the origin when it was routed by the origin name
+	path      string
+	client    origins.Client
+	statement *string
+	// route is the name of the origin to which the request is routed
+	route string
+	// rewritten indicates the request's path was rewritten
+	rewritten bool
+}
+
+// New returns a RuleSet compiled from the provided RuleSetConfig, which must have been verified by the config loader
+func New(name string, cfg config.RuleSetConfig) (*RuleSet, error) {
+
+	rs := &RuleSet{Name: name, rules: make([]*rule, len(cfg))}
+
+	for i, rc := range cfg {
+
+		r := &rule{name: rc.Name, stop: rc.Stop,
+			conditions: make([]*condition, len(rc.Conditions)),
+			actions:    make([]*action, len(rc.Actions)),
+		}
+		if r.name == "" {
+			r.name = strconv.Itoa(i)
+		}
+
+		for j, cc := range rc.Conditions {
+			c := &condition{source: cc.Source, key: cc.Key, operator: cc.Operator, value: cc.Value, negate: cc.Negate}
+			switch c.operator {
+			case "in":
+				c.values = strings.Split(c.value, ",")
+				for k := range c.values {
+					c.values[k] = strings.TrimSpace(c.values[k])
+				}
+			case "regex":
+				re, err := regexp.Compile(c.value)
+				if err != nil {
+					return nil, err
+				}
+				c.re = re
+			case "cidr":
+				for _, v := range strings.Split(c.value, ",") {
+					_, n, err := net.ParseCIDR(strings.TrimSpace(v))
+					if err != nil {
+						return nil, err
+					}
+					c.nets = append(c.nets, n)
+				}
+			}
+			r.conditions[j] = c
+		}
+
+		for j, ac := range rc.Actions {
+			a := &action{action: ac.Action, key: ac.Key, value: ac.Value}
+			if ac.Pattern != "" {
+				re, err := regexp.Compile(ac.Pattern)
+				if err != nil {
+					return nil, err
+				}
+				a.re = re
+			}
+			r.actions[j] = a
+		}
+
+		rs.rules[i] = r
+	}
+
+	return rs, nil
+}
+
+// apply applies the Actions of each Rule that the request matches
+func (rs *RuleSet) apply(req *ruleRequest) {
+	for _, r := range rs.rules {
+		if !r.matches(req) {
+			continue
+		}
+		log.Debug("request matched rule", log.Pairs{"ruleSet": rs.Name, "rule": r.name, "path": req.path})
+		for _, a := range r.actions {
+			a.apply(req)
+		}
+		if r.stop {
+			return
+		}
+	}
+}
+
+func (r *rule) matches(req *ruleRequest) bool {
+	for _, c := range r.conditions {
+		if !c.matches(req) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *condition) matches(req *ruleRequest) bool {
+
+	values := req.input(c.source, c.key)
+
+	var ok bool
+	if c.operator == "exists" {
+		ok = len(values) > 0
+	} else {
+		for _, v := range values {
+			if ok = c.compare(v); ok {
+				break
+			}
+		}
+	}
+
+	if c.negate {
+		return !ok
+	}
+	return ok
+}
+
+func (c *condition) compare(v string) bool {
+	switch c.operator {
+	case "in":
+		for _, v2 := range c.values {
+			if v == v2 {
+				return true
+			}
+		}
+		return false
+	case "prefix":
+		return strings.HasPrefix(v, c.value)
+	case "suffix":
+		return strings.HasSuffix(v, c.value)
+	case "contains":
+		return strings.Contains(v, c.value)
+	case "regex":
+		return c.re.MatchString(v)
+	case "cidr":
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return false
+		}
+		for _, n := range c.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return v == c.value
+}
+
+// input returns the values of the part of the request that is matched by a condition,
+// or nil if the request does not have it
+func (req *ruleRequest) input(source, key string) []string {
+	var v string
+	switch source {
+	case "header":
+		return req.Header[http.CanonicalHeaderKey(key)]
+	case "param":
+		return req.URL.Query()[key]
+	case "host":
+		v = stripPort(req.Host)
+	case "method":
+		v = req.Method
+	case "client_ip":
+		v = stripPort(req.RemoteAddr)
+	case "path":
+		v = req.path
+	case "statement":
+		v = req.parseStatement()
+	}
+	if v == "" {
+		return nil
+	}
+	return []string{v}
+}
+
+// parseStatement returns the query statement of a time series request, or an empty string
+// if it is not a time range query. Only requests without a body are parsed
+func (req *ruleRequest) parseStatement() string {
+	if req.statement != nil {
+		return *req.statement
+	}
+	var s string
+	if tc, ok := req.client.(origins.TimeseriesClient); ok &&
+		(req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		if trq, err := tc.ParseTimeRangeQuery(req.Request); err == nil && trq != nil {
+			s = trq.Statement
+		}
+	}
+	req.statement = &s
+	return s
+}
+
+func (a *action) apply(req *ruleRequest) {
+	switch a.action {
+	case "set_header":
+		req.Header.Set(a.key, a.value)
+	case "append_header":
+		req.Header.Add(a.key, a.value)
+	case "delete_header":
+		req.Header.Del(a.key)
+	case "set_param", "append_param", "delete_param":
+		q := req.URL.Query()
+		switch a.action {
+		case "set_param":
+			q.Set(a.key, a.value)
+		case "append_param":
+			q.Add(a.key, a.value)
+		default:
+			q.Del(a.key)
+		}
+		req.URL.RawQuery = q.Encode()
+		// a statement parsed from the previous parameters may no longer be valid
+		req.statement = nil
+	case "rewrite_path":
+		if a.re != nil {
+			req.path = a.re.ReplaceAllString(req.path, a.value)
+		} else {
+			req.path = a.value
+		}
+		req.rewritten = true
+	case "rewrite_host":
+		req.Host = a.value
+	case "route":
+		req.route = a.value
+	}
+}
+
+// stripPort returns the host portion of a host:port address, or the address if it has no port
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
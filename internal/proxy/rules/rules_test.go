@@ -0,0 +1,149 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rules
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+func testRuleSet(t *testing.T, cfg config.RuleSetConfig) *RuleSet {
+	rs, err := New("test", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+func TestConditions(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "http://trickster:8480/api/v1/query?query=up&tenant=acme", nil)
+	r.Header.Set("X-Tenant", "acme")
+	r.RemoteAddr = "10.1.2.3:12345"
+
+	tests := []struct {
+		cond     config.RuleConditionConfig
+		expected bool
+	}{
+		{config.RuleConditionConfig{Source: "header", Key: "x-tenant", Operator: "eq", Value: "acme"}, true},
+		{config.RuleConditionConfig{Source: "header", Key: "X-Tenant", Operator: "eq", Value: "other"}, false},
+		{config.RuleConditionConfig{Source: "header", Key: "X-Tenant", Operator: "eq", Value: "acme", Negate: true}, false},
+		{config.RuleConditionConfig{Source: "header", Key: "X-Missing", Operator: "exists"}, false},
+		{config.RuleConditionConfig{Source: "header", Key: "X-Missing", Operator: "exists", Negate: true}, true},
+		{config.RuleConditionConfig{Source: "param", Key: "tenant", Operator: "in", Value: "other, acme"}, true},
+		{config.RuleConditionConfig{Source: "param", Key: "query", Operator: "exists"}, true},
+		{config.RuleConditionConfig{Source: "host", Operator: "eq", Value: "trickster"}, true},
+		{config.RuleConditionConfig{Source: "method", Operator: "eq", Value: "POST"}, false},
+		{config.RuleConditionConfig{Source: "client_ip", Operator: "cidr", Value: "192.168.0.0/16, 10.0.0.0/8"}, true},
+		{config.RuleConditionConfig{Source: "client_ip", Operator: "cidr", Value: "192.168.0.0/16"}, false},
+		{config.RuleConditionConfig{Source: "path", Operator: "prefix", Value: "/api/v1"}, true},
+		{config.RuleConditionConfig{Source: "path", Operator: "suffix", Value: "/query"}, true},
+		{config.RuleConditionConfig{Source: "path", Operator: "contains", Value: "v2"}, false},
+		{config.RuleConditionConfig{Source: "path", Operator: "regex", Value: "^/api/v[0-9]+/"}, true},
+		// the request is not a time range query, so it has no statement
+		{config.RuleConditionConfig{Source: "statement", Operator: "exists"}, false},
+	}
+
+	for i, test := range tests {
+		c := test.cond
+		rs := testRuleSet(t, config.RuleSetConfig{&config.RuleConfig{Conditions: []*config.RuleConditionConfig{&c}}})
+		req := &ruleRequest{Request: r, path: r.URL.Path}
+		if m := rs.rules[0].matches(req); m != test.expected {
+			t.Errorf("test %d: expected %t got %t", i, test.expected, m)
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "http://trickster:8480/legacy/query?query=up&debug=1", nil)
+	r.Header.Set("X-Remove", "1")
+
+	rs := testRuleSet(t, config.RuleSetConfig{
+		&config.RuleConfig{
+			Conditions: []*config.RuleConditionConfig{{Source: "path", Operator: "prefix", Value: "/legacy/"}},
+			Actions: []*config.RuleActionConfig{
+				{Action: "set_header", Key: "X-Scope-OrgID", Value: "acme"},
+				{Action: "append_header", Key: "X-Scope-OrgID", Value: "other"},
+				{Action: "delete_header", Key: "X-Remove"},
+				{Action: "set_param", Key: "tenant", Value: "acme"},
+				{Action: "append_param", Key: "tenant", Value: "other"},
+				{Action: "delete_param", Key: "debug"},
+				{Action: "rewrite_path", Pattern: "^/legacy/(.*)$", Value: "/api/v1/$1"},
+				{Action: "rewrite_host", Value: "upstream"},
+			},
+			Stop: true,
+		},
+		&config.RuleConfig{
+			Actions: []*config.RuleActionConfig{{Action: "route", Value: "other"}},
+		},
+	})
+
+	req := &ruleRequest{Request: r, path: r.URL.Path}
+	rs.apply(req)
+
+	if v := r.Header["X-Scope-Orgid"]; len(v) != 2 || v[0] != "acme" || v[1] != "other" {
+		t.Errorf("unexpected header values %v", v)
+	}
+	if _, ok := r.Header["X-Remove"]; ok {
+		t.Errorf("expected header %s to be deleted", "X-Remove")
+	}
+	const expectedQuery = "query=up&tenant=acme&tenant=other"
+	if r.URL.RawQuery != expectedQuery {
+		t.Errorf("expected %s got %s", expectedQuery, r.URL.RawQuery)
+	}
+	if req.path != "/api/v1/query" || !req.rewritten {
+		t.Errorf("expected rewritten path %s got %s", "/api/v1/query", req.path)
+	}
+	if r.Host != "upstream" {
+		t.Errorf("expected %s got %s", "upstream", r.Host)
+	}
+	// the first rule stops the evaluation of the rule set before the route is applied
+	if req.route != "" {
+		t.Errorf("expected no route got %s", req.route)
+	}
+
+	// the path is replaced entirely when the rewrite has no pattern
+	rs = testRuleSet(t, config.RuleSetConfig{&config.RuleConfig{
+		Actions: []*config.RuleActionConfig{{Action: "rewrite_path", Value: "/new"}, {Action: "route", Value: "other"}},
+	}})
+	req = &ruleRequest{Request: r, path: "/old"}
+	rs.apply(req)
+	if req.path != "/new" || req.route != "other" {
+		t.Errorf("unexpected path %s and route %s", req.path, req.route)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+
+	if _, err := New("test", config.RuleSetConfig{&config.RuleConfig{
+		Conditions: []*config.RuleConditionConfig{{Source: "path", Operator: "regex", Value: "["}},
+	}}); err == nil {
+		t.Errorf("expected error for invalid regex")
+	}
+
+	if _, err := New("test", config.RuleSetConfig{&config.RuleConfig{
+		Conditions: []*config.RuleConditionConfig{{Source: "client_ip", Operator: "cidr", Value: "10.0.0.0"}},
+	}}); err == nil {
+		t.Errorf("expected error for invalid cidr")
+	}
+
+	if _, err := New("test", config.RuleSetConfig{&config.RuleConfig{
+		Actions: []*config.RuleActionConfig{{Action: "rewrite_path", Pattern: "["}},
+	}}); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
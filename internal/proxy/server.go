@@ -0,0 +1,48 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// NewHTTPServer returns an HTTP Server that serves the handler to the clients of a frontend listener.
+// When a TLS config is provided, the server negotiates HTTP/2 with the clients that support it, if the
+// frontend config enables it. Otherwise, it accepts cleartext HTTP/2 (h2c) connections if enabled.
+// The TLS config must be the one provided to NewListener for the server's listener
+func NewHTTPServer(fc *config.FrontendConfig, handler http.Handler, tlsConfig *tls.Config) (*http.Server, error) {
+
+	srv := &http.Server{Handler: handler, TLSConfig: tlsConfig}
+	h2s := &http2.Server{MaxConcurrentStreams: uint32(fc.HTTP2MaxConcurrentStreams)}
+
+	if tlsConfig != nil {
+		if !fc.HTTP2 {
+			return srv, nil
+		}
+		// adds h2 to the TLS config's protocols, so it is offered by the listener
+		return srv, http2.ConfigureServer(srv, h2s)
+	}
+
+	if fc.H2C {
+		srv.Handler = h2c.NewHandler(handler, h2s)
+	}
+
+	return srv, nil
+}
@@ -0,0 +1,204 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package proxy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/http2"
+
+	"github.com/Comcast/trickster/internal/config"
+)
+
+var testProtoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Proto))
+})
+
+// serveTest serves the handler on a new listener with the provided frontend and
+// tls configs, and returns the listener's base URL and a func to stop the server
+func serveTest(t *testing.T, fc *config.FrontendConfig, tlsConfig *tls.Config) (string, func()) {
+	srv, err := NewHTTPServer(fc, testProtoHandler, tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewListener("127.0.0.1", 0, 0, tlsConfig, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/", scheme, l.Addr().String()), func() { srv.Close() }
+}
+
+func testTLSConfig(t *testing.T) *tls.Config {
+	c := config.NewConfig()
+	oc := c.Origins["default"]
+	c.Frontend.ServeTLS = true
+	oc.TLS.ServeTLS = true
+	oc.TLS.FullChainCertPath = "../../testdata/test.01.cert.pem"
+	oc.TLS.PrivateKeyPath = "../../testdata/test.01.key.pem"
+	tlsConfig, err := c.TLSCertConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tlsConfig
+}
+
+func TestNewHTTPServerHTTP2(t *testing.T) {
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+
+	tests := []struct {
+		http2    bool
+		expected int
+	}{
+		{false, 1},
+		{true, 2},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			u, stop := serveTest(t, &config.FrontendConfig{HTTP2: test.http2}, testTLSConfig(t))
+			defer stop()
+			resp, err := client.Get(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.ProtoMajor != test.expected {
+				t.Errorf("expected %d got %d", test.expected, resp.ProtoMajor)
+			}
+		})
+	}
+}
+
+func TestNewHTTPServerH2C(t *testing.T) {
+
+	u, stop := serveTest(t, &config.FrontendConfig{H2C: true}, nil)
+	defer stop()
+
+	// an h2c origin is requested with prior knowledge
+	oc := config.NewOriginConfig()
+	oc.H2C = true
+	c, err := NewHTTPClient(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Transport.(*http2.Transport); !ok {
+		t.Errorf("expected an http2 transport")
+	}
+
+	resp, err := c.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected %d got %d", 2, resp.ProtoMajor)
+	}
+
+	// while HTTP/1.1 clients are still served
+	resp, err = http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 1 {
+		t.Errorf("expected %d got %d", 1, resp.ProtoMajor)
+	}
+}
+
+func TestNewHTTPClientHTTP2(t *testing.T) {
+
+	u, stop := serveTest(t, &config.FrontendConfig{HTTP2: true}, testTLSConfig(t))
+	defer stop()
+
+	oc := config.NewOriginConfig()
+	oc.HTTP2 = true
+	oc.MaxConnsPerHost = 1
+	oc.TLS = &config.TLSConfig{InsecureSkipVerify: true}
+	c, err := NewHTTPClient(oc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr, ok := c.Transport.(*http.Transport); !ok || tr.MaxConnsPerHost != 1 {
+		t.Errorf("expected a transport with %d connections per host", 1)
+	}
+
+	resp, err := c.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected %d got %d", 2, resp.ProtoMajor)
+	}
+}
+
+func TestUnixSocketListenerAndOrigin(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "trickster-proxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.sock")
+
+	srv, err := NewHTTPServer(&config.FrontendConfig{H2C: true}, testProtoHandler, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewListener("unix://"+path, 0, 0, nil, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	// requests to the origin are made through its socket, over HTTP/1.1 and h2c
+	for _, h2c := range []bool{false, true} {
+		oc := config.NewOriginConfig()
+		oc.UnixSocketPath = path
+		oc.H2C = h2c
+		c, err := NewHTTPClient(oc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := c.Get("http://localhost/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		expected := "HTTP/1.1"
+		if h2c {
+			expected = "HTTP/2.0"
+		}
+		if string(b) != expected {
+			t.Errorf("expected %s got %s", expected, string(b))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package warmer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// rangeRequestFormatter returns the path and query string of a request for the query over the range
+type rangeRequestFormatter func(query string, start, end time.Time, step time.Duration) string
+
+// rangeRequestFormatters maps each origin type whose dashboard queries can be warmed to its rangeRequestFormatter
+var rangeRequestFormatters = map[string]rangeRequestFormatter{
+	"prometheus": prometheusRangeRequest,
+}
+
+func prometheusRangeRequest(query string, start, end time.Time, step time.Duration) string {
+	v := url.Values{}
+	v.Set("query", query)
+	v.Set("start", strconv.FormatInt(start.Unix(), 10))
+	v.Set("end", strconv.FormatInt(end.Unix(), 10))
+	v.Set("step", strconv.FormatInt(int64(step.Seconds()), 10))
+	return "/api/v1/query_range?" + v.Encode()
+}
+
+// dashboard is the subset of a Grafana dashboard model that holds its panels' queries. Dashboards
+// saved from the Grafana API wrap the model in a "dashboard" field, and older versions group their
+// panels into rows, while newer versions nest the panels of a collapsed row within the row panel
+type dashboard struct {
+	Dashboard *dashboard        `json:"dashboard"`
+	Panels    []*dashboardPanel `json:"panels"`
+	Rows      []*dashboardPanel `json:"rows"`
+}
+
+type dashboardPanel struct {
+	Panels  []*dashboardPanel  `json:"panels"`
+	Targets []*dashboardTarget `json:"targets"`
+}
+
+type dashboardTarget struct {
+	Expr    string `json:"expr"`
+	Hide    bool   `json:"hide"`
+	Instant bool   `json:"instant"`
+}
+
+// reDashboardVariable matches a reference to a dashboard template variable, in any of Grafana's syntaxes
+var reDashboardVariable = regexp.MustCompile(`\$\{?[A-Za-z_]\w*|\[\[\w+\]\]`)
+
+// dashboardQueries returns the distinct range queries of the visible panels in the Grafana dashboard
+// files matching the patterns, with Grafana's built-in interval and range variables replaced.
+// Queries that reference other template variables are skipped, since their values are chosen
+// by the dashboard's user
+func dashboardQueries(patterns []string, rng, step time.Duration) []string {
+
+	// $__rate_interval is approximated as four steps, which is Grafana's value when the step
+	// is the scrape interval. Longer variable names precede their prefixes, so they match first
+	r := strings.NewReplacer(
+		"$__interval_ms", strconv.FormatInt(int64(step/time.Millisecond), 10),
+		"${__interval_ms}", strconv.FormatInt(int64(step/time.Millisecond), 10),
+		"$__interval", durationString(step),
+		"${__interval}", durationString(step),
+		"$__rate_interval", durationString(4*step),
+		"${__rate_interval}", durationString(4*step),
+		"$__range_ms", strconv.FormatInt(int64(rng/time.Millisecond), 10),
+		"${__range_ms}", strconv.FormatInt(int64(rng/time.Millisecond), 10),
+		"$__range_s", strconv.FormatInt(int64(rng.Seconds()), 10),
+		"${__range_s}", strconv.FormatInt(int64(rng.Seconds()), 10),
+		"$__range", durationString(rng),
+		"${__range}", durationString(rng),
+	)
+
+	seen := make(map[string]bool)
+	queries := make([]string, 0)
+
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil || len(files) == 0 {
+			log.WarnOnce("warmer.dashboards."+pattern, "no dashboard files found", log.Pairs{"path": pattern})
+			continue
+		}
+		for _, file := range files {
+			targets, err := readDashboardTargets(file)
+			if err != nil {
+				log.Warn("could not read dashboard file", log.Pairs{"path": file, "detail": err.Error()})
+				continue
+			}
+			for _, t := range targets {
+				if t.Hide || t.Instant || t.Expr == "" {
+					continue
+				}
+				q := r.Replace(t.Expr)
+				if reDashboardVariable.MatchString(q) {
+					log.Debug("skipping dashboard query with template variables", log.Pairs{"path": file, "query": t.Expr})
+					continue
+				}
+				if !seen[q] {
+					seen[q] = true
+					queries = append(queries, q)
+				}
+			}
+		}
+	}
+
+	return queries
+}
+
+// readDashboardTargets returns the targets of every panel in the Grafana dashboard file
+func readDashboardTargets(file string) ([]*dashboardTarget, error) {
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dashboard{}
+	if err = json.Unmarshal(b, d); err != nil {
+		return nil, err
+	}
+	if d.Dashboard != nil {
+		d = d.Dashboard
+	}
+
+	targets := make([]*dashboardTarget, 0)
+	var walk func(panels []*dashboardPanel)
+	walk = func(panels []*dashboardPanel) {
+		for _, p := range panels {
+			if p == nil {
+				continue
+			}
+			targets = append(targets, p.Targets...)
+			walk(p.Panels)
+		}
+	}
+	walk(d.Panels)
+	walk(d.Rows)
+
+	return targets, nil
+}
+
+// durationString returns the duration in the whole seconds format used in Prometheus range selectors
+func durationString(d time.Duration) string {
+	return strconv.FormatInt(int64(d.Seconds()), 10) + "s"
+}
@@ -0,0 +1,74 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package warmer
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testDashboard = "../../../testdata/test.dashboard.json"
+
+func TestDashboardQueries(t *testing.T) {
+
+	queries := dashboardQueries([]string{testDashboard}, time.Hour, time.Minute)
+
+	expected := []string{
+		"sum(rate(http_requests_total[240s]))",
+		"avg_over_time(up[60s])",
+		`up{instance=~"a$|b"}`,
+	}
+
+	if len(queries) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, queries)
+	}
+
+	for i, q := range expected {
+		if queries[i] != q {
+			t.Errorf("expected %s got %s", q, queries[i])
+		}
+	}
+}
+
+func TestDashboardQueriesInvalidFiles(t *testing.T) {
+
+	queries := dashboardQueries([]string{"../../../testdata/nonexistent*.json",
+		"../../../testdata/test.rootca.pem", "[invalid"}, time.Hour, time.Minute)
+
+	if len(queries) != 0 {
+		t.Errorf("expected no queries got %v", queries)
+	}
+}
+
+func TestPrometheusRangeRequest(t *testing.T) {
+
+	end := time.Unix(1577836800, 0)
+	p := prometheusRangeRequest("sum(up)", end.Add(-time.Hour), end, 15*time.Second)
+
+	u, err := url.Parse(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Path != "/api/v1/query_range" {
+		t.Errorf("expected %s got %s", "/api/v1/query_range", u.Path)
+	}
+
+	v := u.Query()
+	if v.Get("query") != "sum(up)" || v.Get("start") != "1577833200" ||
+		v.Get("end") != "1577836800" || v.Get("step") != "15" {
+		t.Errorf("unexpected query parameters %v", v)
+	}
+}
@@ -0,0 +1,222 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package warmer periodically makes an origin's frequently-used requests through Trickster's own
+// handlers, so that their cached results are extended to the current time before clients ask for them
+package warmer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+// Warmer periodically makes the requests configured in an origin's WarmerConfig
+type Warmer struct {
+	originName string
+	originType string
+	config     *config.WarmerConfig
+	handler    http.Handler
+
+	stop     chan bool
+	stopOnce sync.Once
+}
+
+// New returns a Warmer for the origin, which makes its requests through the handler
+func New(oc *config.OriginConfig, handler http.Handler) *Warmer {
+	return &Warmer{
+		originName: oc.Name,
+		originType: oc.OriginType,
+		config:     &oc.Warmer,
+		handler:    handler,
+		stop:       make(chan bool),
+	}
+}
+
+// StartWarmers starts a Warmer for each origin that has requests or dashboards to warm,
+// which makes its requests through the handler, and returns the started Warmers
+func StartWarmers(origins map[string]*config.OriginConfig, handler http.Handler) []*Warmer {
+	warmers := make([]*Warmer, 0, len(origins))
+	for _, oc := range origins {
+		if !oc.Warmer.Enabled() {
+			continue
+		}
+		w := New(oc, handler)
+		w.Start()
+		warmers = append(warmers, w)
+	}
+	return warmers
+}
+
+// Start makes the Warmer's requests immediately, and then at each interval until the Warmer is stopped
+func (w *Warmer) Start() {
+	log.Info("starting cache warmer", log.Pairs{"originName": w.originName,
+		"interval": w.config.Interval, "range": w.config.Range})
+	go w.run()
+}
+
+// Stop stops the Warmer after any requests in progress have completed
+func (w *Warmer) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+func (w *Warmer) run() {
+	w.Warm()
+	// a run that takes longer than the interval delays the next one, rather than overlapping it
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.Warm()
+		}
+	}
+}
+
+// Warm makes each of the Warmer's requests once, no more than the configured concurrency at a time,
+// and returns the number of requests that succeeded and that failed
+func (w *Warmer) Warm() (int, int) {
+
+	start := time.Now()
+	paths := w.requestPaths(start)
+
+	var succeeded, failed int
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan bool, w.config.Concurrency)
+
+	for _, p := range paths {
+		select {
+		case <-w.stop:
+			// don't start any more requests once stopped
+			wg.Wait()
+			return succeeded, failed
+		case sem <- true:
+		}
+		wg.Add(1)
+		go func(path string) {
+			ok := w.request(path)
+			mtx.Lock()
+			if ok {
+				succeeded++
+			} else {
+				failed++
+			}
+			mtx.Unlock()
+			<-sem
+			wg.Done()
+		}(p)
+	}
+	wg.Wait()
+
+	metrics.WarmerRunDuration.WithLabelValues(w.originName, w.originType).Observe(time.Since(start).Seconds())
+	log.Debug("cache warmer run complete", log.Pairs{"originName": w.originName,
+		"succeeded": succeeded, "failed": failed, "elapsed": time.Since(start).String()})
+
+	return succeeded, failed
+}
+
+// requestPaths returns the paths and query strings of the configured requests, with their
+// placeholders replaced for the range ending at now, followed by those of any dashboard queries
+func (w *Warmer) requestPaths(now time.Time) []string {
+
+	start, end := now.Add(-w.config.Range), now
+
+	r := strings.NewReplacer(
+		"{{start}}", strconv.FormatInt(start.Unix(), 10),
+		"{{end}}", strconv.FormatInt(end.Unix(), 10),
+		"{{step}}", strconv.FormatInt(int64(w.config.Step.Seconds()), 10),
+	)
+
+	paths := make([]string, 0, len(w.config.Requests))
+	for _, t := range w.config.Requests {
+		paths = append(paths, r.Replace(t))
+	}
+
+	if len(w.config.DashboardPaths) == 0 {
+		return paths
+	}
+
+	rf, ok := rangeRequestFormatters[strings.ToLower(w.originType)]
+	if !ok {
+		log.WarnOnce("warmer.dashboards."+w.originName, "dashboard warming is not supported for origin type",
+			log.Pairs{"originName": w.originName, "originType": w.originType})
+		return paths
+	}
+
+	for _, q := range dashboardQueries(w.config.DashboardPaths, w.config.Range, w.config.Step) {
+		paths = append(paths, rf(q, start, end, w.config.Step))
+	}
+
+	return paths
+}
+
+// request makes a GET request for the path through the Warmer's handler, and returns
+// true if it was successful. The response body is discarded
+func (w *Warmer) request(path string) bool {
+
+	r, err := http.NewRequest(http.MethodGet, "http://localhost/"+w.originName+path, nil)
+	if err != nil {
+		log.Error("invalid cache warmer request", log.Pairs{"originName": w.originName, "path": path, "detail": err.Error()})
+		metrics.WarmerRequests.WithLabelValues(w.originName, w.originType, "failure").Inc()
+		return false
+	}
+	r.RemoteAddr = "127.0.0.1:0"
+	for k, v := range w.config.Headers {
+		r.Header.Set(k, v)
+	}
+
+	rw := &discardResponseWriter{header: make(http.Header)}
+	w.handler.ServeHTTP(rw, r)
+
+	if rw.statusCode >= http.StatusBadRequest {
+		log.Debug("cache warmer request failed", log.Pairs{"originName": w.originName,
+			"path": path, "httpStatus": rw.statusCode})
+		metrics.WarmerRequests.WithLabelValues(w.originName, w.originType, "failure").Inc()
+		return false
+	}
+
+	metrics.WarmerRequests.WithLabelValues(w.originName, w.originType, "success").Inc()
+	return true
+}
+
+// discardResponseWriter is an http.ResponseWriter that records the response status and discards the body
+type discardResponseWriter struct {
+	header     http.Header
+	statusCode int
+}
+
+func (rw *discardResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *discardResponseWriter) Write(b []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (rw *discardResponseWriter) WriteHeader(statusCode int) {
+	if rw.statusCode == 0 {
+		rw.statusCode = statusCode
+	}
+}
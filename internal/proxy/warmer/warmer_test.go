@@ -0,0 +1,191 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package warmer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/metrics"
+)
+
+func init() {
+	metrics.Init()
+}
+
+// testHandler records the requests it serves, and fails those whose path contains "fail"
+type testHandler struct {
+	mtx       sync.Mutex
+	requests  []*http.Request
+	inFlight  int
+	maxFlight int
+}
+
+func (h *testHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mtx.Lock()
+	h.requests = append(h.requests, r)
+	h.inFlight++
+	if h.inFlight > h.maxFlight {
+		h.maxFlight = h.inFlight
+	}
+	h.mtx.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	h.mtx.Lock()
+	h.inFlight--
+	h.mtx.Unlock()
+
+	if strings.Contains(r.URL.Path, "fail") {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+func newTestOriginConfig(requests ...string) *config.OriginConfig {
+	oc := config.NewOriginConfig()
+	oc.Name = "test"
+	oc.OriginType = "prometheus"
+	oc.Warmer.Requests = requests
+	oc.Warmer.Concurrency = 2
+	oc.Warmer.Interval = time.Hour
+	oc.Warmer.Range = time.Hour
+	oc.Warmer.Step = 15 * time.Second
+	oc.Warmer.Headers = map[string]string{"Authorization": "Bearer test"}
+	return oc
+}
+
+func TestWarm(t *testing.T) {
+
+	oc := newTestOriginConfig(
+		"/api/v1/query_range?query=up&start={{start}}&end={{end}}&step={{step}}",
+		"/api/v1/query?query=up",
+		"/fail",
+		"/api/v1/labels",
+	)
+	oc.Warmer.DashboardPaths = []string{testDashboard}
+
+	h := &testHandler{}
+	before := time.Now().Unix()
+	succeeded, failed := New(oc, h).Warm()
+
+	if succeeded != 6 || failed != 1 {
+		t.Errorf("expected %d succeeded and %d failed got %d and %d", 6, 1, succeeded, failed)
+	}
+
+	if len(h.requests) != 7 {
+		t.Fatalf("expected %d requests got %d", 7, len(h.requests))
+	}
+
+	if h.maxFlight > 2 {
+		t.Errorf("expected no more than %d concurrent requests got %d", 2, h.maxFlight)
+	}
+
+	var ranged, dashboard int
+	for _, r := range h.requests {
+		if !strings.HasPrefix(r.URL.Path, "/test/") {
+			t.Errorf("expected path prefix %s got %s", "/test/", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test" {
+			t.Errorf("expected %s got %s", "Bearer test", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path != "/test/api/v1/query_range" {
+			continue
+		}
+		qp := r.URL.Query()
+		start, _ := strconv.ParseInt(qp.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(qp.Get("end"), 10, 64)
+		if end < before || end-start != 3600 || qp.Get("step") != "15" {
+			t.Errorf("unexpected range query parameters %v", qp)
+		}
+		if qp.Get("query") == "up" {
+			ranged++
+		} else {
+			dashboard++
+		}
+	}
+
+	if ranged != 1 || dashboard != 3 {
+		t.Errorf("expected %d templated and %d dashboard range requests got %d and %d", 1, 3, ranged, dashboard)
+	}
+}
+
+func TestWarmUnsupportedDashboards(t *testing.T) {
+
+	oc := newTestOriginConfig("/query?q=select+1")
+	oc.OriginType = "influxdb"
+	oc.Warmer.DashboardPaths = []string{testDashboard}
+
+	h := &testHandler{}
+	succeeded, failed := New(oc, h).Warm()
+
+	if succeeded != 1 || failed != 0 {
+		t.Errorf("expected %d succeeded and %d failed got %d and %d", 1, 0, succeeded, failed)
+	}
+}
+
+func TestStartWarmers(t *testing.T) {
+
+	origins := map[string]*config.OriginConfig{
+		"test":     newTestOriginConfig("/api/v1/labels"),
+		"disabled": newTestOriginConfig(),
+	}
+
+	h := &testHandler{}
+	warmers := StartWarmers(origins, h)
+	if len(warmers) != 1 {
+		t.Fatalf("expected %d warmers got %d", 1, len(warmers))
+	}
+
+	// the first run starts immediately
+	for i := 0; i < 100; i++ {
+		h.mtx.Lock()
+		n := len(h.requests)
+		h.mtx.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	warmers[0].Stop()
+	warmers[0].Stop()
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if len(h.requests) != 1 {
+		t.Errorf("expected %d requests got %d", 1, len(h.requests))
+	}
+}
+
+func TestDiscardResponseWriter(t *testing.T) {
+
+	rw := &discardResponseWriter{header: make(http.Header)}
+	rw.Header().Set("Content-Type", "text/plain")
+	n, err := rw.Write([]byte("test"))
+	if n != 4 || err != nil {
+		t.Errorf("expected %d got %d %v", 4, n, err)
+	}
+	rw.WriteHeader(http.StatusInternalServerError)
+
+	if rw.statusCode != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, rw.statusCode)
+	}
+}
@@ -16,12 +16,18 @@ package registration
 import (
 	"fmt"
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/gorilla/mux"
+
 	"github.com/Comcast/trickster/internal/cache"
 	"github.com/Comcast/trickster/internal/cache/registration"
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/auth"
+	"github.com/Comcast/trickster/internal/proxy/breaker"
+	"github.com/Comcast/trickster/internal/proxy/limits"
 	"github.com/Comcast/trickster/internal/proxy/methods"
 	"github.com/Comcast/trickster/internal/proxy/origins"
 	"github.com/Comcast/trickster/internal/proxy/origins/clickhouse"
@@ -29,6 +35,7 @@ import (
 	"github.com/Comcast/trickster/internal/proxy/origins/irondb"
 	"github.com/Comcast/trickster/internal/proxy/origins/prometheus"
 	"github.com/Comcast/trickster/internal/proxy/origins/reverseproxycache"
+	"github.com/Comcast/trickster/internal/proxy/rules"
 	"github.com/Comcast/trickster/internal/routing"
 	"github.com/Comcast/trickster/internal/util/log"
 	"github.com/Comcast/trickster/internal/util/middleware"
@@ -37,6 +44,15 @@ import (
 // ProxyClients maintains a list of proxy clients configured for use by Trickster
 var ProxyClients = make(map[string]origins.Client)
 
+// ruleSets maintains the Rule Sets compiled from the Trickster Configuration, by name
+var ruleSets = make(map[string]*rules.RuleSet)
+
+// limiters maintains the Limiters for the Limits in the Trickster Configuration, by name
+var limiters = make(map[string]*limits.Limiter)
+
+// authenticators maintains the Authenticators for the Authenticators in the Trickster Configuration, by name
+var authenticators = make(map[string]*auth.Authenticator)
+
 // RegisterProxyRoutes iterates the Trickster Configuration and registers the routes for the configured origins
 func RegisterProxyRoutes() error {
 
@@ -44,6 +60,26 @@ func RegisterProxyRoutes() error {
 	var ndo *config.OriginConfig // points to the origin config named "default"
 	var cdo *config.OriginConfig // points to the origin config with IsDefault set to true
 
+	for k, v := range config.Rules {
+		rs, err := rules.New(k, v)
+		if err != nil {
+			return fmt.Errorf("invalid rule config [%s]: %s", k, err.Error())
+		}
+		ruleSets[k] = rs
+	}
+
+	for k, v := range config.Limits {
+		limiters[k] = limits.New(k, v)
+	}
+
+	for k, v := range config.Authenticators {
+		a, err := auth.New(k, v)
+		if err != nil {
+			return fmt.Errorf("invalid authenticator config [%s]: %s", k, err.Error())
+		}
+		authenticators[k] = a
+	}
+
 	// This iteration will ensure default origins are handled properly
 	for k, o := range config.Origins {
 
@@ -169,7 +205,8 @@ func registerPathRoutes(handlers map[string]http.Handler, client origins.Client,
 		o.HealthCheckUpstreamPath != "" && o.HealthCheckVerb != "" {
 		hp := "/trickster/health/" + o.Name
 		log.Debug("registering health handler path", log.Pairs{"path": hp, "originName": o.Name, "upstreamPath": o.HealthCheckUpstreamPath, "upstreamVerb": o.HealthCheckVerb})
-		routing.Router.PathPrefix(hp).Handler(middleware.WithResourcesContext(client, o, nil, nil, h)).Methods(methods.CacheableHTTPMethods()...)
+		routing.Router.PathPrefix(hp).Handler(middleware.WithResourcesContext(client, o, nil, nil,
+			breaker.StateHandler(o.Name, h))).Methods(methods.CacheableHTTPMethods()...)
 	}
 
 	plist := make([]string, 0, len(pathsWithVerbs))
@@ -177,6 +214,34 @@ func registerPathRoutes(handlers map[string]http.Handler, client origins.Client,
 	for k, p := range pathsWithVerbs {
 		if h, ok := handlers[p.HandlerName]; ok && h != nil {
 			p.Handler = h
+			if len(p.RuleNames) > 0 {
+				sets := make([]*rules.RuleSet, 0, len(p.RuleNames))
+				for _, n := range p.RuleNames {
+					if rs, ok := ruleSets[n]; ok {
+						sets = append(sets, rs)
+					}
+				}
+				p.Handler = rules.Handler(sets, routing.Router, p.Handler)
+			}
+			if len(o.LimitNames) > 0 || len(p.LimitNames) > 0 {
+				names := make([]string, 0, len(o.LimitNames)+len(p.LimitNames))
+				names = append(append(names, o.LimitNames...), p.LimitNames...)
+				lims := make([]*limits.Limiter, 0, len(names))
+				for _, n := range names {
+					if l, ok := limiters[n]; ok {
+						lims = append(lims, l)
+					}
+				}
+				p.Handler = limits.Handler(lims, o.Name, p.Handler)
+			}
+			// a path's authenticator overrides the origin's, and requests are authenticated before they are limited
+			an := o.AuthenticatorName
+			if p.AuthenticatorName != "" {
+				an = p.AuthenticatorName
+			}
+			if a, ok := authenticators[an]; ok {
+				p.Handler = auth.Handler(a, o.Name, p.Handler)
+			}
 			plist = append(plist, k)
 		} else {
 			log.Info("invalid handler name for path", log.Pairs{"path": p.Path, "handlerName": p.HandlerName})
@@ -187,11 +252,19 @@ func registerPathRoutes(handlers map[string]http.Handler, client origins.Client,
 		delete(pathsWithVerbs, p)
 	}
 
-	sort.Sort(ByLen(plist))
-	for i := len(plist)/2 - 1; i >= 0; i-- {
-		opp := len(plist) - 1 - i
-		plist[i], plist[opp] = plist[opp], plist[i]
-	}
+	// paths are registered in order of precedence, since the router handles a request with the first
+	// path that matches it: by match type, then from the longest to the shortest path, then by name
+	sort.Slice(plist, func(i, j int) bool {
+		ri, rj := matchTypePrecedence[pathsWithVerbs[plist[i]].MatchType],
+			matchTypePrecedence[pathsWithVerbs[plist[j]].MatchType]
+		if ri != rj {
+			return ri < rj
+		}
+		if len(plist[i]) != len(plist[j]) {
+			return len(plist[i]) > len(plist[j])
+		}
+		return plist[i] < plist[j]
+	})
 
 	for _, v := range plist {
 		p, ok := pathsWithVerbs[v]
@@ -214,8 +287,13 @@ func registerPathRoutes(handlers map[string]http.Handler, client origins.Client,
 				routing.Router.PathPrefix(p.Path).Handler(decorate(p)).Methods(p.Methods...).Host(o.Name)
 				// Path Routing
 				routing.Router.PathPrefix("/" + o.Name + p.Path).Handler(decorate(p)).Methods(p.Methods...)
+			case config.PathMatchTypeRegex:
+				// Host Header Routing
+				routing.Router.MatcherFunc(matchPathRegexp(p.PathRegexp, "")).Handler(decorate(p)).Methods(p.Methods...).Host(o.Name)
+				// Path Routing
+				routing.Router.MatcherFunc(matchPathRegexp(p.PathRegexp, "/"+o.Name)).Handler(decorate(p)).Methods(p.Methods...)
 			default:
-				// default to exact match
+				// default to exact match, where the router also matches the variables of template paths
 				// Host Header Routing
 				routing.Router.Handle(p.Path, decorate(p)).Methods(p.Methods...).Host(o.Name)
 				// Path Routing
@@ -237,6 +315,10 @@ func registerPathRoutes(handlers map[string]http.Handler, client origins.Client,
 				case config.PathMatchTypePrefix:
 					// Case where we path match by prefix
 					routing.Router.PathPrefix(p.Path).Handler(decorate(p)).Methods(p.Methods...)
+				case config.PathMatchTypeRegex:
+					// the pattern is not also registered as an exact path below
+					routing.Router.MatcherFunc(matchPathRegexp(p.PathRegexp, "")).Handler(decorate(p)).Methods(p.Methods...)
+					continue
 				default:
 					// default to exact match
 					routing.Router.Handle(p.Path, decorate(p)).Methods(p.Methods...)
@@ -248,17 +330,28 @@ func registerPathRoutes(handlers map[string]http.Handler, client origins.Client,
 	o.Paths = pathsWithVerbs
 }
 
-// ByLen allows sorting of a string slice by string length
-type ByLen []string
-
-func (a ByLen) Len() int {
-	return len(a)
-}
-
-func (a ByLen) Less(i, j int) bool {
-	return len(a[i]) < len(a[j])
+// matchTypePrecedence orders the registration of paths by their match type, from the most to the least specific
+var matchTypePrecedence = map[config.PathMatchType]int{
+	config.PathMatchTypeExact:    0,
+	config.PathMatchTypeTemplate: 1,
+	config.PathMatchTypeRegex:    2,
+	config.PathMatchTypePrefix:   3,
 }
 
-func (a ByLen) Swap(i, j int) {
-	a[i], a[j] = a[j], a[i]
+// matchPathRegexp returns a router MatcherFunc that matches requests whose path, after the
+// provided prefix, matches the regular expression
+func matchPathRegexp(re *regexp.Regexp, prefix string) mux.MatcherFunc {
+	return func(r *http.Request, rm *mux.RouteMatch) bool {
+		if re == nil {
+			return false
+		}
+		path := r.URL.Path
+		if prefix != "" {
+			if !strings.HasPrefix(path, prefix+"/") {
+				return false
+			}
+			path = path[len(prefix):]
+		}
+		return re.MatchString(path)
+	}
 }
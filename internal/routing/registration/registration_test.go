@@ -14,10 +14,18 @@
 package registration
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"testing"
 
+	"github.com/gorilla/mux"
+
 	"github.com/Comcast/trickster/internal/cache/registration"
 	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/proxy/auth"
+	"github.com/Comcast/trickster/internal/proxy/request"
+	"github.com/Comcast/trickster/internal/routing"
 	"github.com/Comcast/trickster/internal/util/metrics"
 )
 
@@ -237,3 +245,190 @@ func TestRegisterMultipleOriginsPlusDefault(t *testing.T) {
 		t.Errorf("expected origin %s.IsDefault to be true", "default")
 	}
 }
+
+func TestRegisterPathRoutesMatchTypes(t *testing.T) {
+
+	err := config.Load("trickster", "test", []string{"-origin-url", "http://1", "-origin-type", "rpc"})
+	if err != nil {
+		t.Errorf("Could not load configuration: %s", err.Error())
+	}
+	registration.LoadCachesFromConfig()
+	c, _ := registration.GetCache("default")
+
+	router := routing.Router
+	routing.Router = mux.NewRouter()
+	defer func() { routing.Router = router }()
+
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Handler", name)
+			rsc := request.GetResources(r)
+			for k, v := range rsc.PathVars {
+				w.Header().Set("X-Var-"+k, v)
+			}
+		})
+	}
+	handlers := map[string]http.Handler{"exact": handler("exact"), "template": handler("template"),
+		"regex": handler("regex"), "prefix": handler("prefix")}
+
+	newPath := func(path, handlerName string, mt config.PathMatchType) *config.PathConfig {
+		p := config.NewPathConfig()
+		p.Path = path
+		p.HandlerName = handlerName
+		p.MatchType = mt
+		p.Methods = []string{http.MethodGet}
+		return p
+	}
+	paths := map[string]*config.PathConfig{
+		"exact":    newPath("/api/v1/label/job/values", "exact", config.PathMatchTypeExact),
+		"template": newPath("/api/v1/label/{name}/values", "template", config.PathMatchTypeTemplate),
+		"regex":    newPath("/api/(?P<version>v[0-9]+)/series", "regex", config.PathMatchTypeRegex),
+		"prefix":   newPath("/api/", "prefix", config.PathMatchTypePrefix),
+	}
+	paths["regex"].PathRegexp = regexp.MustCompile("^(?:" + paths["regex"].Path + ")$")
+
+	o := config.NewOriginConfig()
+	o.Name = "test"
+	registerPathRoutes(handlers, nil, o, c, paths)
+
+	tests := []struct {
+		path, handler, varName, varValue string
+	}{
+		{"/api/v1/label/job/values", "exact", "", ""},
+		{"/api/v1/label/instance/values", "template", "name", "instance"},
+		{"/api/v2/series", "regex", "version", "v2"},
+		{"/api/v2/series/x", "prefix", "", ""},
+		{"/api/v1/label/instance", "prefix", "", ""},
+	}
+
+	for i, test := range tests {
+		for _, r := range []*http.Request{
+			httptest.NewRequest("GET", "http://test"+test.path, nil),
+			httptest.NewRequest("GET", "http://trickster/test"+test.path, nil),
+		} {
+			w := httptest.NewRecorder()
+			routing.Router.ServeHTTP(w, r)
+			if v := w.Header().Get("X-Handler"); v != test.handler {
+				t.Errorf("test %d: expected handler %s got %s for %s", i, test.handler, v, r.URL.String())
+			}
+			if test.varName == "" {
+				continue
+			}
+			if v := w.Header().Get("X-Var-" + test.varName); v != test.varValue {
+				t.Errorf("test %d: expected %s got %s for %s", i, test.varValue, v, r.URL.String())
+			}
+		}
+	}
+}
+
+func TestRegisterPathRoutesRegexOriginPrefix(t *testing.T) {
+
+	err := config.Load("trickster", "test", []string{"-origin-url", "http://1", "-origin-type", "rpc"})
+	if err != nil {
+		t.Errorf("Could not load configuration: %s", err.Error())
+	}
+	registration.LoadCachesFromConfig()
+	c, _ := registration.GetCache("default")
+
+	router := routing.Router
+	routing.Router = mux.NewRouter()
+	defer func() { routing.Router = router }()
+
+	handlers := map[string]http.Handler{"regex": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Var-first", request.GetResources(r).PathVars["first"])
+	})}
+
+	p := config.NewPathConfig()
+	p.Path = "/(?P<first>[a-z]+)/.*"
+	p.HandlerName = "regex"
+	p.MatchType = config.PathMatchTypeRegex
+	p.Methods = []string{http.MethodGet}
+	p.PathRegexp = regexp.MustCompile("^(?:" + p.Path + ")$")
+
+	o := config.NewOriginConfig()
+	o.Name = "test"
+	registerPathRoutes(handlers, nil, o, c, map[string]*config.PathConfig{p.Path: p})
+
+	tests := []struct {
+		url, expected string
+	}{
+		// routed by Host header, so the path is matched in full
+		{"http://test/test/api/series", "test"},
+		{"http://test:8480/test/api/series", "test"},
+		// routed by origin name, so the path is matched after the origin name
+		{"http://trickster/test/api/series", "api"},
+	}
+
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		routing.Router.ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+		if v := w.Header().Get("X-Var-first"); v != test.expected {
+			t.Errorf("test %d: expected %s got %s for %s", i, test.expected, v, test.url)
+		}
+	}
+}
+
+func TestRegisterPathRoutesAuthenticator(t *testing.T) {
+
+	err := config.Load("trickster", "test", []string{"-origin-url", "http://1", "-origin-type", "rpc"})
+	if err != nil {
+		t.Errorf("Could not load configuration: %s", err.Error())
+	}
+	registration.LoadCachesFromConfig()
+	c, _ := registration.GetCache("default")
+
+	router := routing.Router
+	routing.Router = mux.NewRouter()
+	defer func() { routing.Router = router }()
+
+	a, err := auth.New("test-auth", &config.AuthenticatorConfig{AuthenticatorType: "api_key",
+		APIKeys: map[string]string{"test-key": "test-tenant"}, APIKeyHeader: "X-API-Key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticators["test-auth"] = a
+	defer delete(authenticators, "test-auth")
+
+	handlers := map[string]http.Handler{"test": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Identity", request.GetResources(r).AuthIdentity)
+	})}
+
+	paths := make(map[string]*config.PathConfig)
+	for _, v := range []string{"/secure", "/public"} {
+		p := config.NewPathConfig()
+		p.Path = v
+		p.HandlerName = "test"
+		p.Methods = []string{http.MethodGet}
+		paths[v] = p
+	}
+	paths["/public"].AuthenticatorName = config.AuthenticatorNone
+
+	o := config.NewOriginConfig()
+	o.Name = "test"
+	o.AuthenticatorName = "test-auth"
+	registerPathRoutes(handlers, nil, o, c, paths)
+
+	tests := []struct {
+		path, key, identity string
+		code                int
+	}{
+		{"/secure", "", "", http.StatusUnauthorized},
+		{"/secure", "test-key", "test-tenant", http.StatusOK},
+		{"/public", "", "", http.StatusOK},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest("GET", "http://trickster/test"+test.path, nil)
+		if test.key != "" {
+			r.Header.Set("X-API-Key", test.key)
+		}
+		w := httptest.NewRecorder()
+		routing.Router.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("test %d: expected %d got %d", i, test.code, w.Code)
+		}
+		if v := w.Header().Get("X-Identity"); v != test.identity {
+			t.Errorf("test %d: expected %s got %s", i, test.identity, v)
+		}
+	}
+}
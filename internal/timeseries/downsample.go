@@ -0,0 +1,75 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package timeseries
+
+import "time"
+
+// Aggregator reduces the finer-step values falling within a single coarser-step window
+// to one value. The values are provided in chronological order and are never empty.
+type Aggregator func(values []float64) float64
+
+// Downsampler is an optional interface for Timeseries implementations that are able to
+// derive a coarser-step Timeseries from their own data points
+type Downsampler interface {
+	// Downsample returns a new Timeseries at the provided step, where each data point at time t
+	// is derived from the subject's data points in the window (t-step, t]. A nil Aggregator
+	// uses only the data point found exactly at t. Only coarser steps whose windows are fully
+	// covered by the subject's Extents are included in the result.
+	Downsample(step time.Duration, agg Aggregator) Timeseries
+}
+
+// Resampler is an optional interface for Timeseries implementations that are able to
+// realign their data points to a different step
+type Resampler interface {
+	// Resample returns a new Timeseries with a data point at each multiple of the provided step
+	// within the Extent. Each takes the value of the subject's most recent data point, provided
+	// it is not older than the subject's own step.
+	Resample(step time.Duration, e Extent) Timeseries
+}
+
+// AggregateMin returns the smallest of the provided values
+func AggregateMin(values []float64) float64 {
+	v := values[0]
+	for _, f := range values[1:] {
+		if f < v {
+			v = f
+		}
+	}
+	return v
+}
+
+// AggregateMax returns the largest of the provided values
+func AggregateMax(values []float64) float64 {
+	v := values[0]
+	for _, f := range values[1:] {
+		if f > v {
+			v = f
+		}
+	}
+	return v
+}
+
+// AggregateSum returns the sum of the provided values
+func AggregateSum(values []float64) float64 {
+	var v float64
+	for _, f := range values {
+		v += f
+	}
+	return v
+}
+
+// AggregateMean returns the arithmetic mean of the provided values
+func AggregateMean(values []float64) float64 {
+	return AggregateSum(values) / float64(len(values))
+}
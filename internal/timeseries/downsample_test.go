@@ -0,0 +1,45 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package timeseries
+
+import "testing"
+
+func TestAggregators(t *testing.T) {
+
+	values := []float64{4, 1, 7, 4}
+
+	tests := []struct {
+		name     string
+		agg      Aggregator
+		expected float64
+	}{
+		{"min", AggregateMin, 1},
+		{"max", AggregateMax, 7},
+		{"sum", AggregateSum, 16},
+		{"mean", AggregateMean, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if v := test.agg(values); v != test.expected {
+				t.Errorf("expected %f got %f", test.expected, v)
+			}
+		})
+	}
+
+	if v := AggregateMax([]float64{-3}); v != -3 {
+		t.Errorf("expected %f got %f", -3.0, v)
+	}
+
+}
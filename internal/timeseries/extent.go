@@ -18,11 +18,13 @@ import (
 	"time"
 )
 
+//go:generate msgp
+
 // Extent describes the start and end times for a given range of data
 type Extent struct {
-	Start    time.Time `json:"start"`
-	End      time.Time `json:"end"`
-	LastUsed time.Time `json:"-"`
+	Start    time.Time `json:"start" msg:"start"`
+	End      time.Time `json:"end" msg:"end"`
+	LastUsed time.Time `json:"-" msg:"last_used"`
 }
 
 // Includes returns true if the Extent includes the provided Time
@@ -0,0 +1,145 @@
+package timeseries
+
+// Code generated by github.com/tinylib/msgp DO NOT EDIT.
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// DecodeMsg implements msgp.Decodable
+func (z *Extent) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "start":
+			z.Start, err = dc.ReadTime()
+			if err != nil {
+				return
+			}
+		case "end":
+			z.End, err = dc.ReadTime()
+			if err != nil {
+				return
+			}
+		case "last_used":
+			z.LastUsed, err = dc.ReadTime()
+			if err != nil {
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z Extent) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "start"
+	err = en.Append(0x83, 0xa5, 0x73, 0x74, 0x61, 0x72, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.Start)
+	if err != nil {
+		return
+	}
+	// write "end"
+	err = en.Append(0xa3, 0x65, 0x6e, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.End)
+	if err != nil {
+		return
+	}
+	// write "last_used"
+	err = en.Append(0xa9, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.LastUsed)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z Extent) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "start"
+	o = append(o, 0x83, 0xa5, 0x73, 0x74, 0x61, 0x72, 0x74)
+	o = msgp.AppendTime(o, z.Start)
+	// string "end"
+	o = append(o, 0xa3, 0x65, 0x6e, 0x64)
+	o = msgp.AppendTime(o, z.End)
+	// string "last_used"
+	o = append(o, 0xa9, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x64)
+	o = msgp.AppendTime(o, z.LastUsed)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Extent) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "start":
+			z.Start, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				return
+			}
+		case "end":
+			z.End, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				return
+			}
+		case "last_used":
+			z.LastUsed, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z Extent) Msgsize() (s int) {
+	s = 1 + 6 + msgp.TimeSize + 4 + msgp.TimeSize + 10 + msgp.TimeSize
+	return
+}
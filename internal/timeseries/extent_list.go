@@ -20,6 +20,9 @@ import (
 	"time"
 )
 
+//go:generate msgp
+//msgp:ignore ExtentListLRU
+
 // ExtentList is a type of []Extent used for sorting the slice
 type ExtentList []Extent
 
@@ -135,6 +138,59 @@ func (el ExtentList) Compress(step time.Duration) ExtentList {
 	return compressed
 }
 
+// Downsample returns the ExtentList of coarser-step boundaries that can be derived from
+// data cached at the finer step. When windowed is true, a boundary t is only included if the
+// full window (t-to, t] is covered; otherwise only t itself must be covered.
+func (el ExtentList) Downsample(from, to time.Duration, windowed bool) ExtentList {
+	dl := make(ExtentList, 0, len(el))
+	for _, e := range el {
+		start := e.Start
+		if windowed {
+			start = start.Add(to - from)
+		}
+		t := start.Truncate(to)
+		if t.Before(start) {
+			t = t.Add(to)
+		}
+		end := e.End.Truncate(to)
+		if end.Before(t) {
+			continue
+		}
+		dl = append(dl, Extent{Start: t, End: end, LastUsed: e.LastUsed})
+	}
+	return dl
+}
+
+// Subtract returns the portions of the ExtentList that are not covered by the provided ExtentList.
+// Extents are inclusive of their Start and End times, which must fall on boundaries of the step.
+func (el ExtentList) Subtract(x ExtentList, step time.Duration) ExtentList {
+	if len(x) == 0 {
+		return el.Clone()
+	}
+	xc := x.Clone()
+	sort.Sort(xc)
+	rl := make(ExtentList, 0, len(el))
+	for _, e := range el {
+		start := e.Start
+		for _, s := range xc {
+			if s.End.Before(start) {
+				continue
+			}
+			if s.Start.After(e.End) {
+				break
+			}
+			if s.Start.After(start) {
+				rl = append(rl, Extent{Start: start, End: s.Start.Add(-step), LastUsed: e.LastUsed})
+			}
+			start = s.End.Add(step)
+		}
+		if !start.After(e.End) {
+			rl = append(rl, Extent{Start: start, End: e.End, LastUsed: e.LastUsed})
+		}
+	}
+	return rl
+}
+
 // Len returns the length of a slice of type ExtentList
 func (el ExtentList) Len() int {
 	return len(el)
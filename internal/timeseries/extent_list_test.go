@@ -600,3 +600,87 @@ func TestCompress(t *testing.T) {
 		})
 	}
 }
+
+func TestDownsample(t *testing.T) {
+
+	tests := []struct {
+		el       ExtentList
+		windowed bool
+		expected string
+	}{
+		{ // 0 - aligned extent, sampled
+			el:       ExtentList{Extent{Start: t300, End: t1200}},
+			expected: "300-1200",
+		},
+		{ // 1 - aligned extent, windowed loses the first boundary
+			el:       ExtentList{Extent{Start: t300, End: t1200}},
+			windowed: true,
+			expected: "600-1200",
+		},
+		{ // 2 - unaligned extent is rounded inward
+			el:       ExtentList{Extent{Start: t200, End: t1100}},
+			expected: "300-900",
+		},
+		{ // 3 - extent too small to hold a boundary is dropped
+			el:       ExtentList{Extent{Start: t1000, End: t1100}, Extent{Start: t1200, End: t1400}},
+			expected: "1200-1200",
+		},
+		{ // 4 - windowed extent too small to fill a window is dropped
+			el:       ExtentList{Extent{Start: t1000, End: t1100}, Extent{Start: t300, End: t900}},
+			windowed: true,
+			expected: "600-900",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			result := test.el.Downsample(time.Duration(100)*time.Second, time.Duration(300)*time.Second, test.windowed)
+			if result.String() != test.expected {
+				t.Errorf("mismatch in Downsample: expected=%s got=%s", test.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+
+	tests := []struct {
+		el       ExtentList
+		x        ExtentList
+		expected string
+	}{
+		{ // 0 - nothing to subtract
+			el:       ExtentList{Extent{Start: t100, End: t1000}},
+			expected: "100-1000",
+		},
+		{ // 1 - subtract the head
+			el:       ExtentList{Extent{Start: t100, End: t1000}},
+			x:        ExtentList{Extent{Start: t100, End: t300}},
+			expected: "400-1000",
+		},
+		{ // 2 - subtract the middle and the tail
+			el:       ExtentList{Extent{Start: t100, End: t1000}},
+			x:        ExtentList{Extent{Start: t900, End: t1000}, Extent{Start: t300, End: t600}},
+			expected: "100-200;700-800",
+		},
+		{ // 3 - subtract the entire extent and one outside of it
+			el:       ExtentList{Extent{Start: t100, End: t200}, Extent{Start: t600, End: t1000}},
+			x:        ExtentList{Extent{Start: t100, End: t200}, Extent{Start: t1100, End: t1400}},
+			expected: "600-1000",
+		},
+		{ // 4 - subtract a range that spans a gap
+			el:       ExtentList{Extent{Start: t100, End: t300}, Extent{Start: t600, End: t1000}},
+			x:        ExtentList{Extent{Start: t200, End: t900}},
+			expected: "100-100;1000-1000",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			result := test.el.Subtract(test.x, time.Duration(100)*time.Second)
+			if result.String() != test.expected {
+				t.Errorf("mismatch in Subtract: expected=%s got=%s", test.expected, result.String())
+			}
+		})
+	}
+}
@@ -46,3 +46,18 @@ type Timeseries interface {
 	// Size returns the approximate memory byte size of the timeseries object
 	Size() int
 }
+
+// ValueExtenter is an optional interface for Timeseries implementations that are able to report
+// the Extents of their data-bearing timestamps, which may be a subset of the Extents that were queried
+type ValueExtenter interface {
+	// ValueExtents returns the list of time Extents having at least one value, at the provided step
+	ValueExtents(step time.Duration) ExtentList
+}
+
+// Overwriter is an optional interface for Timeseries implementations that are able to replace
+// their data with that of a more recent query for the same time ranges
+type Overwriter interface {
+	// Overwrite replaces the data within the provided Extents with the provided Timeseries's data
+	// in those Extents, and returns the number of values that were added, changed or removed
+	Overwrite(ts Timeseries, el ExtentList) int
+}
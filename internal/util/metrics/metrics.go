@@ -24,6 +24,7 @@ import (
 
 	"github.com/Comcast/trickster/internal/config"
 	"github.com/Comcast/trickster/internal/util/log"
+	"github.com/Comcast/trickster/internal/util/socket"
 )
 
 const (
@@ -31,6 +32,7 @@ const (
 	cacheSubsystem    = "cache"
 	proxySubsystem    = "proxy"
 	frontendSubsystem = "frontend"
+	warmerSubsystem   = "warmer"
 )
 
 // Default histogram buckets used by trickster
@@ -44,6 +46,12 @@ var FrontendRequestStatus *prometheus.CounterVec
 // FrontendRequestDuration is a histogram that tracks the time it takes to process a request
 var FrontendRequestDuration *prometheus.HistogramVec
 
+// FrontendRejectedRequests is a Counter of the front end requests rejected by a Limit, by reason
+var FrontendRejectedRequests *prometheus.CounterVec
+
+// FrontendUnauthorizedRequests is a Counter of the front end requests that failed to authenticate
+var FrontendUnauthorizedRequests *prometheus.CounterVec
+
 // FrontendRequestWrittenBytes is a Counter of bytes written for front end requests
 var FrontendRequestWrittenBytes *prometheus.CounterVec
 
@@ -53,6 +61,21 @@ var ProxyRequestStatus *prometheus.CounterVec
 // ProxyRequestElements is a Counter of data points in the timeseries returned to the requesting client
 var ProxyRequestElements *prometheus.CounterVec
 
+// ProxyBackfillRevalidations is a Counter of recently-cached timeseries ranges refetched from the origin to check for backfilled data
+var ProxyBackfillRevalidations *prometheus.CounterVec
+
+// ProxyBackfillCorrections is a Counter of cached data points that were added, changed or removed by backfill revalidations
+var ProxyBackfillCorrections *prometheus.CounterVec
+
+// ProxyUpstreamRetries is a Counter of requests to an origin that were retried, by reason
+var ProxyUpstreamRetries *prometheus.CounterVec
+
+// ProxyUpstreamHedges is a Counter of hedged requests made to an origin that did not respond in time
+var ProxyUpstreamHedges *prometheus.CounterVec
+
+// ProxyCircuitBreakerState is a Gauge of the state of each origin's circuit breaker
+var ProxyCircuitBreakerState *prometheus.GaugeVec
+
 // ProxyRequestDuration is a Histogram of time required in seconds to proxy a given Prometheus query
 var ProxyRequestDuration *prometheus.HistogramVec
 
@@ -65,6 +88,12 @@ var CacheByteOperations *prometheus.CounterVec
 // CacheEvents is a Counter of events performed on a Trickster cache
 var CacheEvents *prometheus.CounterVec
 
+// CacheEvictions is a Counter of objects evicted from a Trickster cache
+var CacheEvictions *prometheus.CounterVec
+
+// CacheTierLookups is a Counter of lookups performed against each tier of a Trickster tiered cache
+var CacheTierLookups *prometheus.CounterVec
+
 // CacheObjects is a Gauge representing the number of objects in a Trickster cache
 var CacheObjects *prometheus.GaugeVec
 
@@ -77,6 +106,24 @@ var CacheMaxObjects *prometheus.GaugeVec
 // CacheMaxBytes is a Gauge representing the Trickster cache's Max Object Threshold for triggering an eviction exercise
 var CacheMaxBytes *prometheus.GaugeVec
 
+// CacheOriginObjects is a Gauge representing the number of objects each origin has in a Trickster cache
+var CacheOriginObjects *prometheus.GaugeVec
+
+// CacheOriginBytes is a Gauge representing the number of bytes each origin has in a Trickster cache
+var CacheOriginBytes *prometheus.GaugeVec
+
+// CacheOriginMaxObjects is a Gauge representing an origin's quota of objects in a Trickster cache
+var CacheOriginMaxObjects *prometheus.GaugeVec
+
+// CacheOriginMaxBytes is a Gauge representing an origin's quota of bytes in a Trickster cache
+var CacheOriginMaxBytes *prometheus.GaugeVec
+
+// WarmerRequests is a Counter of the requests made by the cache warmer of each origin, by result
+var WarmerRequests *prometheus.CounterVec
+
+// WarmerRunDuration is a Histogram of the time required in seconds for a cache warmer to make all of its requests
+var WarmerRunDuration *prometheus.HistogramVec
+
 // ProxyMaxConnections is a Gauge representing the max number of active concurrent connections in the server
 var ProxyMaxConnections prometheus.Gauge
 
@@ -134,6 +181,24 @@ func initialize() {
 		},
 		[]string{"origin_name", "origin_type", "method", "path", "http_status"})
 
+	FrontendRejectedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: frontendSubsystem,
+			Name:      "rejected_requests_total",
+			Help:      "Count of front end requests rejected by Trickster for exceeding a limit",
+		},
+		[]string{"origin_name", "limit_name", "reason"})
+
+	FrontendUnauthorizedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: frontendSubsystem,
+			Name:      "unauthorized_requests_total",
+			Help:      "Count of front end requests rejected by Trickster for failing to authenticate",
+		},
+		[]string{"origin_name", "authenticator_name"})
+
 	ProxyRequestStatus = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: metricNamespace,
@@ -154,6 +219,56 @@ func initialize() {
 		[]string{"origin_name", "origin_type", "cache_status", "path"},
 	)
 
+	ProxyBackfillRevalidations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: proxySubsystem,
+			Name:      "backfill_revalidations_total",
+			Help:      "Count of recently-cached timeseries ranges refetched from the origin to check for backfilled data.",
+		},
+		[]string{"origin_name", "origin_type", "result", "path"},
+	)
+
+	ProxyBackfillCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: proxySubsystem,
+			Name:      "backfill_corrected_points_total",
+			Help:      "Count of cached data points that were added, changed or removed by backfill revalidations.",
+		},
+		[]string{"origin_name", "origin_type", "path"},
+	)
+
+	ProxyUpstreamRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: proxySubsystem,
+			Name:      "upstream_retries_total",
+			Help:      "Count of requests to an origin that were retried.",
+		},
+		[]string{"origin_name", "reason"},
+	)
+
+	ProxyUpstreamHedges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: proxySubsystem,
+			Name:      "upstream_hedged_requests_total",
+			Help:      "Count of second requests made to an origin that did not respond within the hedge delay.",
+		},
+		[]string{"origin_name"},
+	)
+
+	ProxyCircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricNamespace,
+			Subsystem: proxySubsystem,
+			Name:      "circuit_breaker_state",
+			Help:      "State of an origin's circuit breaker: 0 is closed, 1 is half-open and 2 is open.",
+		},
+		[]string{"origin_name"},
+	)
+
 	ProxyRequestDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: metricNamespace,
@@ -165,6 +280,27 @@ func initialize() {
 		[]string{"origin_name", "origin_type", "method", "status", "http_status", "path"},
 	)
 
+	WarmerRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: warmerSubsystem,
+			Name:      "requests_total",
+			Help:      "Count of requests made by the cache warmer of an origin.",
+		},
+		[]string{"origin_name", "origin_type", "result"},
+	)
+
+	WarmerRunDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Subsystem: warmerSubsystem,
+			Name:      "run_duration_seconds",
+			Help:      "Time required in seconds for the cache warmer of an origin to make all of its requests.",
+			Buckets:   defaultBuckets,
+		},
+		[]string{"origin_name", "origin_type"},
+	)
+
 	ProxyMaxConnections = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: metricNamespace,
@@ -248,6 +384,26 @@ func initialize() {
 		[]string{"cache_name", "cache_type", "event", "reason"},
 	)
 
+	CacheEvictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: cacheSubsystem,
+			Name:      "evictions_total",
+			Help:      "Count of objects evicted from a Trickster cache.",
+		},
+		[]string{"cache_name", "cache_type", "policy", "reason"},
+	)
+
+	CacheTierLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Subsystem: cacheSubsystem,
+			Name:      "tier_lookups_total",
+			Help:      "Count of lookups performed against each tier of a Trickster tiered cache.",
+		},
+		[]string{"cache_name", "tier", "status"},
+	)
+
 	CacheObjects = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: metricNamespace,
@@ -288,13 +444,62 @@ func initialize() {
 		[]string{"cache_name", "cache_type"},
 	)
 
+	CacheOriginObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricNamespace,
+			Subsystem: cacheSubsystem,
+			Name:      "origin_usage_objects",
+			Help:      "Number of objects an origin has in a Trickster cache.",
+		},
+		[]string{"cache_name", "cache_type", "origin_name"},
+	)
+
+	CacheOriginBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricNamespace,
+			Subsystem: cacheSubsystem,
+			Name:      "origin_usage_bytes",
+			Help:      "Number of bytes an origin has in a Trickster cache.",
+		},
+		[]string{"cache_name", "cache_type", "origin_name"},
+	)
+
+	CacheOriginMaxObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricNamespace,
+			Subsystem: cacheSubsystem,
+			Name:      "origin_max_usage_objects",
+			Help:      "Origin's quota of objects in a Trickster cache, beyond which its objects are evicted.",
+		},
+		[]string{"cache_name", "cache_type", "origin_name"},
+	)
+
+	CacheOriginMaxBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricNamespace,
+			Subsystem: cacheSubsystem,
+			Name:      "origin_max_usage_bytes",
+			Help:      "Origin's quota of bytes in a Trickster cache, beyond which its objects are evicted.",
+		},
+		[]string{"cache_name", "cache_type", "origin_name"},
+	)
+
 	// Register Metrics
 	prometheus.MustRegister(FrontendRequestStatus)
 	prometheus.MustRegister(FrontendRequestDuration)
 	prometheus.MustRegister(FrontendRequestWrittenBytes)
+	prometheus.MustRegister(FrontendRejectedRequests)
+	prometheus.MustRegister(FrontendUnauthorizedRequests)
 	prometheus.MustRegister(ProxyRequestStatus)
 	prometheus.MustRegister(ProxyRequestElements)
+	prometheus.MustRegister(ProxyBackfillRevalidations)
+	prometheus.MustRegister(ProxyBackfillCorrections)
+	prometheus.MustRegister(ProxyUpstreamRetries)
+	prometheus.MustRegister(ProxyUpstreamHedges)
+	prometheus.MustRegister(ProxyCircuitBreakerState)
 	prometheus.MustRegister(ProxyRequestDuration)
+	prometheus.MustRegister(WarmerRequests)
+	prometheus.MustRegister(WarmerRunDuration)
 	prometheus.MustRegister(ProxyMaxConnections)
 	prometheus.MustRegister(ProxyActiveConnections)
 	prometheus.MustRegister(ProxyConnectionRequested)
@@ -304,19 +509,29 @@ func initialize() {
 	prometheus.MustRegister(CacheObjectOperations)
 	prometheus.MustRegister(CacheByteOperations)
 	prometheus.MustRegister(CacheEvents)
+	prometheus.MustRegister(CacheEvictions)
+	prometheus.MustRegister(CacheTierLookups)
 	prometheus.MustRegister(CacheObjects)
 	prometheus.MustRegister(CacheBytes)
 	prometheus.MustRegister(CacheMaxObjects)
 	prometheus.MustRegister(CacheMaxBytes)
+	prometheus.MustRegister(CacheOriginObjects)
+	prometheus.MustRegister(CacheOriginBytes)
+	prometheus.MustRegister(CacheOriginMaxObjects)
+	prometheus.MustRegister(CacheOriginMaxBytes)
 
 	// Turn up the Metrics HTTP Server
-	if config.Metrics != nil && config.Metrics.ListenPort > 0 {
+	if config.Metrics != nil && socket.Enabled(config.Metrics.ListenAddress, config.Metrics.ListenPort) {
 		go func() {
 
 			log.Info("metrics http endpoint starting", log.Pairs{"address": config.Metrics.ListenAddress, "port": fmt.Sprintf("%d", config.Metrics.ListenPort)})
 
 			http.Handle("/metrics", promhttp.Handler())
-			if err := http.ListenAndServe(fmt.Sprintf("%s:%d", config.Metrics.ListenAddress, config.Metrics.ListenPort), nil); err != nil {
+			l, err := socket.Listen(config.Metrics.ListenAddress, config.Metrics.ListenPort, config.Metrics.UnixSocketFileMode)
+			if err == nil {
+				err = http.Serve(l, nil)
+			}
+			if err != nil {
 				log.Error("unable to start metrics http server", log.Pairs{"detail": err.Error()})
 				os.Exit(1)
 			}
@@ -15,6 +15,9 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
 
 	"github.com/Comcast/trickster/internal/cache"
 	"github.com/Comcast/trickster/internal/config"
@@ -27,6 +30,43 @@ import (
 func WithResourcesContext(client origins.Client, oc *config.OriginConfig, c cache.Cache, p *config.PathConfig, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resources := request.NewResources(oc, p, c.Configuration(), c, client)
+		resources.PathVars = pathVars(r, oc, p)
 		next.ServeHTTP(w, r.WithContext(context.WithResources(r.Context(), resources)))
 	})
 }
+
+// pathVars returns the variables captured from the request's path by the path config's match
+func pathVars(r *http.Request, oc *config.OriginConfig, p *config.PathConfig) map[string]string {
+	if p == nil || p.MatchType != config.PathMatchTypeRegex || p.PathRegexp == nil {
+		return mux.Vars(r)
+	}
+	path := r.URL.Path
+	// as the router does, match against the path after the origin name when the request was
+	// routed by the origin name in its path, rather than by its Host header
+	if oc != nil && requestHost(r) != oc.Name && strings.HasPrefix(path, "/"+oc.Name+"/") {
+		path = path[len(oc.Name)+1:]
+	}
+	m := p.PathRegexp.FindStringSubmatch(path)
+	if m == nil {
+		return nil
+	}
+	vars := make(map[string]string)
+	for i, name := range p.PathRegexp.SubexpNames() {
+		if name != "" {
+			vars[name] = m[i]
+		}
+	}
+	return vars
+}
+
+// requestHost returns the request's host, without any port, as the router matches it
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if r.URL.IsAbs() {
+		host = r.URL.Host
+	}
+	if i := strings.Index(host, ":"); i != -1 {
+		host = host[:i]
+	}
+	return host
+}
@@ -0,0 +1,107 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package socket provides network listeners on TCP addresses and on Unix domain sockets
+package socket
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// UnixPrefix is the prefix of a listen address that is the path of a Unix domain socket
+const UnixPrefix = "unix://"
+
+// IsUnix returns true if the listen address is the path of a Unix domain socket
+func IsUnix(address string) bool {
+	return strings.HasPrefix(address, UnixPrefix)
+}
+
+// Enabled returns true if a listener is configured with the address and port,
+// meaning the address is a Unix domain socket, or the port is set
+func Enabled(address string, port int) bool {
+	return port > 0 || IsUnix(address)
+}
+
+// Listen returns a listener on the address and port. If the address is a Unix domain socket
+// (unix:///path/to/socket), the port is ignored, any socket file left at the path by a previous
+// listener is removed, and the new socket file's permissions are set to mode, if it is not 0
+// The socket file is removed when the listener is closed
+func Listen(address string, port int, mode os.FileMode) (net.Listener, error) {
+
+	if !IsUnix(address) {
+		return net.Listen("tcp", fmt.Sprintf("%s:%d", address, port))
+	}
+
+	path := strings.TrimPrefix(address, UnixPrefix)
+	if path == "" {
+		return nil, fmt.Errorf("missing unix socket path in listen address %s", address)
+	}
+
+	// only a socket is removed, so that a misconfigured path cannot remove a regular file
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if mode == 0 {
+		return net.Listen("unix", path)
+	}
+
+	// the socket is bound in a private directory, and moved to the path once its permissions are
+	// set, so that it is never accessible with wider permissions than mode
+	dir, err := ioutil.TempDir(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "s")
+
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// the listener would remove the socket file from the path where it was bound
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err = os.Chmod(tmp, mode); err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return &unixListener{Listener: l, path: path}, nil
+}
+
+// unixListener is a listener on a Unix domain socket that was moved after it was bound,
+// which removes the socket file from the path it was moved to when it is closed
+type unixListener struct {
+	net.Listener
+	path      string
+	closeOnce sync.Once
+}
+
+// Close closes the listener and removes its socket file
+func (l *unixListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() { os.Remove(l.path) })
+	return err
+}
@@ -0,0 +1,104 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package socket
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsUnix(t *testing.T) {
+	if !IsUnix("unix:///var/run/trickster.sock") {
+		t.Errorf("expected %t got %t", true, false)
+	}
+	if IsUnix("127.0.0.1") {
+		t.Errorf("expected %t got %t", false, true)
+	}
+	if Enabled("", 0) || !Enabled("", 8480) || !Enabled("unix:///tmp/test.sock", 0) {
+		t.Errorf("unexpected result enabling listeners")
+	}
+}
+
+func TestListen(t *testing.T) {
+
+	l, err := Listen("127.0.0.1", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Addr().Network() != "tcp" {
+		t.Errorf("expected %s got %s", "tcp", l.Addr().Network())
+	}
+	l.Close()
+
+	_, err = Listen(UnixPrefix, 0, 0)
+	if err == nil {
+		t.Errorf("expected error for missing socket path")
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "trickster-socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.sock")
+
+	l, err := Listen(UnixPrefix+path, 8480, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Addr().Network() != "unix" {
+		t.Errorf("expected %s got %s", "unix", l.Addr().Network())
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected %o got %o", 0600, fi.Mode().Perm())
+	}
+
+	// the private directory in which the socket was bound is removed, as is the socket on close
+	l.Close()
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected no files in %s got %d", dir, len(files))
+	}
+
+	// a socket file left behind by an earlier listener is replaced
+	l, err = net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	l, err = Listen(UnixPrefix+path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	// while other files are not
+	file := filepath.Join(dir, "test.file")
+	ioutil.WriteFile(file, []byte("test"), 0600)
+	_, err = Listen(UnixPrefix+file, 0, 0)
+	if err == nil {
+		t.Errorf("expected error for existing file")
+	}
+}